@@ -35,6 +35,7 @@ type DB interface {
 
     // 内部实现API的方法(不同数据库可覆盖这些方法实现自定义的操作)
     doQuery(link dbLink, query string, args ...interface{}) (rows *sql.Rows, err error)
+    doGetAll(link dbLink, query string, args ...interface{}) (result Result, err error)
     doExec(link dbLink, query string, args ...interface{}) (result sql.Result, err error)
     doPrepare(link dbLink, query string) (*sql.Stmt, error)
     doInsert(link dbLink, table string, data interface{}, option int, batch...int) (result sql.Result, err error)
@@ -59,6 +60,11 @@ type DB interface {
 	PingMaster() error
 	PingSlave() error
 
+    // 集群管理
+    SetBalanceStrategy(strategy int)
+    SetHealthCheck(interval time.Duration)
+    Sticky() DB
+
 	// 开启事务操作
 	Begin() (*TX, error)
 
@@ -76,6 +82,10 @@ type DB interface {
 	Update(table string, data interface{}, condition interface{}, args ...interface{}) (sql.Result, error)
 	Delete(table string, condition interface{}, args ...interface{}) (sql.Result, error)
 
+	// 数据库结构信息
+	Tables() (tables []string, err error)
+	TableFields(table string) (map[string]*TableField, error)
+
 	// 创建链式操作对象(Table为From的别名)
 	Table(tables string) *Model
 	From(tables string) *Model
@@ -90,6 +100,16 @@ type DB interface {
     SetMaxOpenConns(n int)
     SetConnMaxLifetime(n int)
 
+    // 查询缓存管理
+    SetCacheAdapter(adapter CacheAdapter)
+    GetCacheAdapter() CacheAdapter
+    GetCacheStats() CacheStats
+
+    // SQL回调及慢查询日志
+    AddBeforeHook(hook BeforeHookFunc)
+    AddAfterHook(hook AfterHookFunc)
+    SetSlowThreshold(threshold time.Duration)
+
 	// 内部方法接口
 	getCache() (*gcache.Cache)
 	getChars() (charLeft string, charRight string)
@@ -99,6 +119,8 @@ type DB interface {
     getTableFields(table string) (map[string]string, error)
     rowsToResult(rows *sql.Rows) (Result, error)
     handleSqlBeforeExec(sql string) string
+    getQueryCache(key string) (Result, bool)
+    removeTableCache(tables string)
 }
 
 // 执行底层数据库操作的核心接口
@@ -116,20 +138,31 @@ type dbBase struct {
 	sqls             *gring.Ring                  // (debug=true时有效)已执行的SQL列表
 	cache            *gcache.Cache                // 数据库缓存，包括底层连接池对象缓存及查询缓存；需要注意的是，事务查询不支持查询缓存
     schema           *gtype.String                // 手动切换的数据库名称
-    tables           map[string]map[string]string // 数据库表结构
 	maxIdleConnCount *gtype.Int                   // 连接池最大限制的连接数
     maxOpenConnCount *gtype.Int                   // 连接池最大打开的连接数
     maxConnLifetime  *gtype.Int                   // (单位秒)连接对象可重复使用的时间长度
+    cacheAdapter     *gtype.Interface             // 查询缓存适配对象(CacheAdapter)
+    cacheCounter     *cacheCounter                // 查询缓存统计计数器
+    balance          *gtype.Int                   // 节点负载均衡策略
+    masterCounter    *gtype.Int                   // master节点轮询计数
+    slaveCounter     *gtype.Int                   // slave节点轮询计数
+    healthCheck      *gtype.Interface             // 节点健康检查定时任务(*gtimer.Entry)
+    linkNodes        *gmap.Map                    // 底层链接对象与配置节点的映射(*sql.DB => *ConfigNode)
+    sticky           *gtype.Bool                  // (仅Sticky对象有效)是否已执行过写操作，是则读操作在master上执行
+    hooks            *sqlHooks                    // SQL执行回调及慢查询阈值
 }
 
 // 执行的SQL对象
 type Sql struct {
-	Sql   string        // SQL语句(可能带有预处理占位符)
-	Args  []interface{} // 预处理参数值列表
-	Error error         // 执行结果(nil为成功)
-	Start int64         // 执行开始时间(毫秒)
-	End   int64         // 执行结束时间(毫秒)
-	Func  string        // 执行方法
+	Sql      string        // SQL语句(可能带有预处理占位符)
+	Args     []interface{} // 预处理参数值列表
+	Error    error         // 执行结果(nil为成功)
+	Start    int64         // 执行开始时间(毫秒)
+	End      int64         // 执行结束时间(毫秒)
+	Func     string        // 执行方法
+	Duration time.Duration // 执行耗时
+	Caller   string        // 业务层调用位置(文件:行号)
+	begin    time.Time     // 执行开始时间(用于精确计算耗时)
 }
 
 // 返回数据表记录值
@@ -185,6 +218,18 @@ func New(name ...string) (db DB, err error) {
                 maxIdleConnCount : gtype.NewInt(),
                 maxOpenConnCount : gtype.NewInt(),
                 maxConnLifetime  : gtype.NewInt(gDEFAULT_CONN_MAX_LIFE_TIME),
+                cacheAdapter     : gtype.NewInterface(NewMemoryCacheAdapter()),
+                cacheCounter     : &cacheCounter {
+                    hits          : gtype.NewInt64(),
+                    misses        : gtype.NewInt64(),
+                    invalidations : gtype.NewInt64(),
+                },
+                balance          : gtype.NewInt(BALANCE_WEIGHTED_RANDOM),
+                masterCounter    : gtype.NewInt(),
+                slaveCounter     : gtype.NewInt(),
+                healthCheck      : gtype.NewInterface(),
+                linkNodes        : gmap.New(),
+                hooks            : newSqlHooks(),
             }
             switch node.Type {
                 case "mysql":
@@ -299,10 +344,15 @@ func getConfigNodeByPriority(cg ConfigGroup) *ConfigNode {
 // 获得底层数据库链接对象
 func (bs *dbBase) getSqlDb(master bool) (sqlDb *sql.DB, err error) {
     // 负载均衡
-    node, err := getConfigNodeByGroup(bs.group, master)
+    node, err := bs.selectNode(master)
     if err != nil {
         return nil, err
     }
+    return bs.getSqlDbByNode(node)
+}
+
+// 获得指定配置节点的底层数据库链接对象
+func (bs *dbBase) getSqlDbByNode(node *ConfigNode) (sqlDb *sql.DB, err error) {
     // 默认值设定
     if node.Charset == "" {
         node.Charset = "utf8"
@@ -330,8 +380,13 @@ func (bs *dbBase) getSqlDb(master bool) (sqlDb *sql.DB, err error) {
         } else if node.MaxConnLifetime > 0 {
             sqlDb.SetConnMaxLifetime(time.Duration(node.MaxConnLifetime) * time.Second)
         }
+        bs.linkNodes.Set(sqlDb, node)
         return sqlDb
     }, 0)
+    if err != nil {
+        getNodeHealth(node).fail()
+        return nil, err
+    }
     if v != nil && sqlDb == nil {
         sqlDb = v.(*sql.DB)
     }
@@ -353,6 +408,10 @@ func (bs *dbBase) Master() (*sql.DB, error) {
 }
 
 // 创建底层数据库slave链接对象。
+// 当为Sticky对象并且已经执行过写操作时，返回master链接对象。
 func (bs *dbBase) Slave() (*sql.DB, error) {
+    if bs.sticky != nil && bs.sticky.Val() {
+        return bs.getSqlDb(true)
+    }
     return bs.getSqlDb(false)
 }
@@ -11,7 +11,7 @@ import (
     "database/sql"
     "errors"
     "fmt"
-    "github.com/gogf/gf/g/container/gvar"
+    "github.com/gogf/gf/g/container/gset"
     "github.com/gogf/gf/g/os/gcache"
     "github.com/gogf/gf/g/os/gtime"
     "github.com/gogf/gf/g/text/gregex"
@@ -77,23 +77,18 @@ func (bs *dbBase) Query(query string, args ...interface{}) (rows *sql.Rows, err
 
 // 数据库sql查询操作，主要执行查询
 func (bs *dbBase) doQuery(link dbLink, query string, args ...interface{}) (rows *sql.Rows, err error) {
-    query = bs.db.handleSqlBeforeExec(query)
-    if bs.db.getDebug() {
-        mTime1    := gtime.Millisecond()
-        rows, err  = link.Query(query, args...)
-        mTime2    := gtime.Millisecond()
-        s         := &Sql {
-            Sql   : query,
-            Args  : args,
-            Error : err,
-            Start : mTime1,
-            End   : mTime2,
-        }
-        bs.sqls.Put(s)
-        printSql(s)
-    } else {
-        rows, err = link.Query(query, args ...)
-    }
+    s := &Sql {
+        Sql  : bs.db.handleSqlBeforeExec(query),
+        Args : args,
+        Func : "Query",
+    }
+    if err = bs.beforeSql(s); err != nil {
+        return nil, formatError(err, s.Sql, s.Args...)
+    }
+    query, args = s.Sql, s.Args
+    rows, err   = link.Query(query, args...)
+    bs.afterSql(s, err)
+    bs.checkLinkError(link, err)
     if err == nil {
         return rows, nil
     } else {
@@ -102,33 +97,42 @@ func (bs *dbBase) doQuery(link dbLink, query string, args ...interface{}) (rows
     return nil, err
 }
 
-// 执行一条sql，并返回执行情况，主要用于非查询操作
+// 执行一条sql，并返回执行情况，主要用于非查询操作。
+// 执行成功之后会解析INSERT/REPLACE/UPDATE/DELETE/TRUNCATE语句的目标数据表并清除其关联的查询缓存，
+// 通过Query/Prepare执行的写操作不会清除查询缓存。
 func (bs *dbBase) Exec(query string, args ...interface{}) (result sql.Result, err error) {
     link, err := bs.db.Master()
     if err != nil {
         return nil,err
     }
-    return bs.db.doExec(link, query, args...)
+    result, err = bs.db.doExec(link, query, args...)
+    if err == nil {
+        bs.db.removeTableCache(parseExecTables(query))
+    }
+    return
 }
 
 // 执行一条sql，并返回执行情况，主要用于非查询操作
 func (bs *dbBase) doExec(link dbLink, query string, args ...interface{}) (result sql.Result, err error) {
-    query = bs.db.handleSqlBeforeExec(query)
-    if bs.db.getDebug() {
-        mTime1     := gtime.Millisecond()
-        result, err = link.Exec(query, args ...)
-        mTime2     := gtime.Millisecond()
-        s := &Sql{
-            Sql   : query,
-            Args  : args,
-            Error : err,
-            Start : mTime1,
-            End   : mTime2,
-        }
-        bs.sqls.Put(s)
-        printSql(s)
-    } else {
-        result, err = link.Exec(query, args ...)
+    s := &Sql {
+        Sql  : bs.db.handleSqlBeforeExec(query),
+        Args : args,
+        Func : "Exec",
+    }
+    if err = bs.beforeSql(s); err != nil {
+        return nil, formatError(err, s.Sql, s.Args...)
+    }
+    query, args = s.Sql, s.Args
+    result, err = link.Exec(query, args...)
+    bs.afterSql(s, err)
+    bs.checkLinkError(link, err)
+    // 数据库结构发生变化时清除结构缓存
+    if err == nil && isSchemaChangeSql(query) {
+        bs.clearSchemaCache()
+    }
+    // 粘性对象执行写操作成功之后，后续读操作切换到master
+    if bs.sticky != nil && err == nil {
+        bs.sticky.Set(true)
     }
     return result, formatError(err, query, args...)
 }
@@ -149,14 +153,38 @@ func (bs *dbBase) Prepare(query string, execOnMaster...bool) (*sql.Stmt, error)
     return bs.db.doPrepare(link, query)
 }
 
-// SQL预处理，执行完成后调用返回值sql.Stmt.Exec完成sql操作
-func (bs *dbBase) doPrepare(link dbLink, query string) (*sql.Stmt, error) {
-    return link.Prepare(query)
+// SQL预处理，执行完成后调用返回值sql.Stmt.Exec完成sql操作。
+// SQL回调在预处理时执行(可改写或拒绝该SQL)，之后通过sql.Stmt的每次执行不再经过回调及慢查询日志。
+func (bs *dbBase) doPrepare(link dbLink, query string) (stmt *sql.Stmt, err error) {
+    s := &Sql {
+        Sql  : bs.db.handleSqlBeforeExec(query),
+        Func : "Prepare",
+    }
+    if err = bs.beforeSql(s); err != nil {
+        return nil, formatError(err, s.Sql)
+    }
+    stmt, err = link.Prepare(s.Sql)
+    bs.afterSql(s, err)
+    bs.checkLinkError(link, err)
+    if err != nil {
+        return nil, formatError(err, s.Sql)
+    }
+    return stmt, nil
 }
 
 // 数据库查询，获取查询结果集，以列表结构返回
 func (bs *dbBase) GetAll(query string, args ...interface{}) (Result, error) {
-    rows, err := bs.Query(query, args ...)
+    return bs.db.doGetAll(nil, query, args ...)
+}
+
+// 数据库查询，获取查询结果集，以列表结构返回，当link为nil时使用slave链接对象
+func (bs *dbBase) doGetAll(link dbLink, query string, args ...interface{}) (result Result, err error) {
+    if link == nil {
+        if link, err = bs.db.Slave(); err != nil {
+            return nil, err
+        }
+    }
+    rows, err := bs.db.doQuery(link, query, args ...)
     if err != nil || rows == nil {
         return nil, err
     }
@@ -269,6 +297,7 @@ func (bs *dbBase) Begin() (*TX, error) {
                 db     : bs.db,
                 tx     : tx,
                 master : master,
+                tables : gset.NewStringSet(),
             }, nil
         } else {
             return nil, err
@@ -323,7 +352,9 @@ func (bs *dbBase) doInsert(link dbLink, table string, data interface{}, option i
             return bs.db.doBatchInsert(link, table, data, option, batch...)
         case reflect.Map:   fallthrough
         case reflect.Struct:
-            dataMap = structToMap(data)
+            if dataMap, err = structToMap(data); err != nil {
+                return nil, err
+            }
         default:
             return result, errors.New(fmt.Sprint("unsupported data type:", kind))
     }
@@ -352,10 +383,14 @@ func (bs *dbBase) doInsert(link dbLink, table string, data interface{}, option i
             return nil, err
         }
     }
-    return bs.db.doExec(link, fmt.Sprintf("%s INTO %s(%s) VALUES(%s) %s",
+    result, err = bs.db.doExec(link, fmt.Sprintf("%s INTO %s(%s) VALUES(%s) %s",
         operation, table, strings.Join(fields, ","),
         strings.Join(values, ","), updateStr),
         params...)
+    if err == nil {
+        bs.removeTableCacheByLink(link, table)
+    }
+    return
 }
 
 // CURD操作:批量数据指定批次量写入
@@ -401,11 +436,17 @@ func (bs *dbBase) doBatchInsert(link dbLink, table string, list interface{}, opt
                 case reflect.Array:
                     listMap = make(List, rv.Len())
                     for i := 0; i < rv.Len(); i++ {
-                        listMap[i] = structToMap(rv.Index(i).Interface())
+                        if listMap[i], err = structToMap(rv.Index(i).Interface()); err != nil {
+                            return nil, err
+                        }
                     }
                 case reflect.Map:   fallthrough
                 case reflect.Struct:
-                    listMap = List{Map(structToMap(list))}
+                    m, err := structToMap(list)
+                    if err != nil {
+                        return nil, err
+                    }
+                    listMap = List{m}
                 default:
                     return result, errors.New(fmt.Sprint("unsupported list type:", kind))
             }
@@ -419,6 +460,8 @@ func (bs *dbBase) doBatchInsert(link dbLink, table string, list interface{}, opt
             return
         }
     }
+    // 批量写入可能部分成功，因此无论结果如何都清除该表的查询缓存
+    defer bs.removeTableCacheByLink(link, table)
     // 首先获取字段名称及记录长度
     holders := []string(nil)
     for k, _ := range listMap[0] {
@@ -515,7 +558,11 @@ func (bs *dbBase) doUpdate(link dbLink, table string, data interface{}, conditio
         case reflect.Map:   fallthrough
         case reflect.Struct:
             var fields []string
-            for k, v := range structToMap(data) {
+            m, err := structToMap(data)
+            if err != nil {
+                return nil, err
+            }
+            for k, v := range m {
                 fields = append(fields, fmt.Sprintf("%s%s%s=?", charL, k, charR))
                 params = append(params, convertParam(v))
             }
@@ -533,9 +580,14 @@ func (bs *dbBase) doUpdate(link dbLink, table string, data interface{}, conditio
         }
     }
     if len(condition) == 0 {
-        return bs.db.doExec(link, fmt.Sprintf("UPDATE %s SET %s", table, updates), args...)
+        result, err = bs.db.doExec(link, fmt.Sprintf("UPDATE %s SET %s", table, updates), args...)
+    } else {
+        result, err = bs.db.doExec(link, fmt.Sprintf("UPDATE %s SET %s WHERE %s", table, updates, condition), args...)
     }
-    return bs.db.doExec(link, fmt.Sprintf("UPDATE %s SET %s WHERE %s", table, updates, condition), args...)
+    if err == nil {
+        bs.removeTableCacheByLink(link, table)
+    }
+    return
 }
 
 // CURD操作:删除数据
@@ -552,9 +604,14 @@ func (bs *dbBase) doDelete(link dbLink, table string, condition string, args ...
         }
     }
     if len(condition) == 0 {
-        return bs.db.doExec(link, fmt.Sprintf("DELETE FROM %s", table), args...)
+        result, err = bs.db.doExec(link, fmt.Sprintf("DELETE FROM %s", table), args...)
+    } else {
+        result, err = bs.db.doExec(link, fmt.Sprintf("DELETE FROM %s WHERE %s", table, condition), args...)
     }
-    return bs.db.doExec(link, fmt.Sprintf("DELETE FROM %s WHERE %s", table, condition), args...)
+    if err == nil {
+        bs.removeTableCacheByLink(link, table)
+    }
+    return
 }
 
 // 获得缓存对象
@@ -564,38 +621,13 @@ func (bs *dbBase) getCache() *gcache.Cache {
 
 // 将数据查询的列表数据*sql.Rows转换为Result类型
 func (bs *dbBase) rowsToResult(rows *sql.Rows) (Result, error) {
-    // 列信息列表, 名称与类型
-    types          := make([]string, 0)
-    columns        := make([]string, 0)
-    columnTypes, _ := rows.ColumnTypes()
-    for _, t := range columnTypes {
-        types   = append(types, t.DatabaseTypeName())
-        columns = append(columns, t.Name())
-    }
-    // 返回结构组装
-    values   := make([]sql.RawBytes, len(columns))
-    scanArgs := make([]interface{}, len(values))
-    records  := make(Result, 0)
-    for i := range values {
-        scanArgs[i] = &values[i]
-    }
-    for rows.Next() {
-        if err := rows.Scan(scanArgs...); err != nil {
-            return records, err
-        }
-        row := make(Record)
-        // 注意col字段是一个[]byte类型(slice类型本身是一个指针)，多个记录循环时该变量指向的是同一个内存地址
-        for i, col := range values {
-            if col == nil {
-                row[columns[i]] = gvar.New(nil, true)
-            } else {
-                // 由于 sql.RawBytes 是slice类型, 这里必须使用值复制
-                v := make([]byte, len(col))
-                copy(v, col)
-                row[columns[i]] = gvar.New(bs.db.convertValue(v, types[i]), true)
-            }
-        }
-        records = append(records, row)
+    it, err := newIterator(bs.db, rows)
+    if err != nil {
+        return nil, err
+    }
+    records := make(Result, 0)
+    for it.Next() {
+        records = append(records, it.Record())
     }
-    return records, nil
+    return records, it.Err()
 }
@@ -0,0 +1,230 @@
+// Copyright 2019 gf Author(https://github.com/gogf/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package gdb
+
+import (
+    "database/sql"
+    "github.com/gogf/gf/g/container/gtype"
+    "github.com/gogf/gf/g/os/gcache"
+    "github.com/gogf/gf/g/text/gregex"
+    "strings"
+    "sync"
+)
+
+// 查询缓存适配接口，用于将查询缓存存放到自定义的缓存服务中(例如redis)，以便多进程共享查询缓存。
+// 缓存项在写入时会关联查询涉及的数据表，当数据表发生写入/更新/删除操作时，通过RemoveTables清除关联的查询缓存。
+type CacheAdapter interface {
+    // 获取缓存的查询结果，第二个返回值表示缓存是否存在(空结果集也是有效的缓存)
+    Get(key string) (Result, bool, error)
+    // 设置查询结果缓存，expire单位为毫秒，expire<=0表示不过期，tables为该查询涉及的数据表名称
+    Set(key string, value Result, expire int, tables []string) error
+    // 删除指定的缓存项
+    Remove(keys ...string) error
+    // 删除与指定数据表关联的所有缓存项
+    RemoveTables(tables ...string) error
+}
+
+// 查询缓存统计信息
+type CacheStats struct {
+    Hits          int64 // 缓存命中次数
+    Misses        int64 // 缓存未命中次数
+    Invalidations int64 // 数据表写操作引起的缓存清除次数
+}
+
+// 查询缓存统计计数器
+type cacheCounter struct {
+    hits          *gtype.Int64
+    misses        *gtype.Int64
+    invalidations *gtype.Int64
+}
+
+// 默认的内存查询缓存适配对象(进程内缓存)。
+// 缓存项过期或者被删除时，同时从数据表关联集合中删除该键名，避免关联集合无限增长。
+type memoryCacheAdapter struct {
+    mu     sync.Mutex                     // 关联集合的并发安全锁
+    cache  *gcache.Cache                  // 缓存数据(*memoryCacheItem)
+    tables map[string]map[string]struct{} // 数据表名称 => 缓存键名集合
+}
+
+// 内存查询缓存项
+type memoryCacheItem struct {
+    result Result   // 查询结果
+    tables []string // 查询涉及的数据表名称
+}
+
+// 创建基于gcache的内存查询缓存适配对象
+func NewMemoryCacheAdapter() CacheAdapter {
+    c := &memoryCacheAdapter {
+        cache  : gcache.New(),
+        tables : make(map[string]map[string]struct{}),
+    }
+    c.cache.OnEvict(c.onEvict)
+    return c
+}
+
+func (c *memoryCacheAdapter) Get(key string) (Result, bool, error) {
+    if v := c.cache.Get(key); v != nil {
+        return v.(*memoryCacheItem).result, true, nil
+    }
+    return nil, false, nil
+}
+
+func (c *memoryCacheAdapter) Set(key string, value Result, expire int, tables []string) error {
+    if value == nil {
+        value = make(Result, 0)
+    }
+    c.mu.Lock()
+    defer c.mu.Unlock()
+    // 覆盖已有缓存项时不会触发淘汰回调，需要先删除旧的关联关系
+    if v := c.cache.Get(key); v != nil {
+        c.unindex(key, v.(*memoryCacheItem).tables)
+    }
+    c.cache.Set(key, &memoryCacheItem{result : value, tables : tables}, expire)
+    for _, table := range tables {
+        keys, ok := c.tables[table]
+        if !ok {
+            keys            = make(map[string]struct{})
+            c.tables[table] = keys
+        }
+        keys[key] = struct{}{}
+    }
+    return nil
+}
+
+func (c *memoryCacheAdapter) Remove(keys ...string) error {
+    for _, key := range keys {
+        c.cache.Remove(key)
+    }
+    return nil
+}
+
+func (c *memoryCacheAdapter) RemoveTables(tables ...string) error {
+    keys := make([]string, 0)
+    c.mu.Lock()
+    for _, table := range tables {
+        for key := range c.tables[table] {
+            keys = append(keys, key)
+        }
+        delete(c.tables, table)
+    }
+    c.mu.Unlock()
+    // 删除缓存项时在淘汰回调中清除其他数据表的关联关系，因此不能持有锁
+    for _, key := range keys {
+        c.cache.Remove(key)
+    }
+    return nil
+}
+
+// 缓存项过期或者被删除时，删除其数据表关联关系
+func (c *memoryCacheAdapter) onEvict(key, value interface{}, reason gcache.EvictReason) {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+    // 淘汰回调执行前该键名可能已经被重新写入
+    if c.cache.Contains(key) {
+        return
+    }
+    c.unindex(key.(string), value.(*memoryCacheItem).tables)
+}
+
+// 从数据表关联集合中删除键名，调用时需要持有c.mu锁
+func (c *memoryCacheAdapter) unindex(key string, tables []string) {
+    for _, table := range tables {
+        if keys, ok := c.tables[table]; ok {
+            delete(keys, key)
+            if len(keys) == 0 {
+                delete(c.tables, table)
+            }
+        }
+    }
+}
+
+// 设置查询缓存适配对象，默认使用进程内的内存缓存
+func (bs *dbBase) SetCacheAdapter(adapter CacheAdapter) {
+    bs.cacheAdapter.Set(adapter)
+}
+
+// 获取查询缓存适配对象
+func (bs *dbBase) GetCacheAdapter() CacheAdapter {
+    return bs.cacheAdapter.Val().(CacheAdapter)
+}
+
+// 获取查询缓存统计信息
+func (bs *dbBase) GetCacheStats() CacheStats {
+    return CacheStats {
+        Hits          : bs.cacheCounter.hits.Val(),
+        Misses        : bs.cacheCounter.misses.Val(),
+        Invalidations : bs.cacheCounter.invalidations.Val(),
+    }
+}
+
+// 读取查询缓存，并记录命中统计，缓存服务出错时视为未命中
+func (bs *dbBase) getQueryCache(key string) (Result, bool) {
+    result, ok, err := bs.db.GetCacheAdapter().Get(key)
+    if err == nil && ok {
+        bs.cacheCounter.hits.Add(1)
+        return result, true
+    }
+    bs.cacheCounter.misses.Add(1)
+    return nil, false
+}
+
+// 数据表写操作成功之后，清除与该数据表关联的查询缓存
+func (bs *dbBase) removeTableCache(tables string) {
+    names := parseTableNames(tables)
+    if len(names) == 0 {
+        return
+    }
+    if err := bs.db.GetCacheAdapter().RemoveTables(names...); err == nil {
+        bs.cacheCounter.invalidations.Add(1)
+    }
+}
+
+// 写操作之后清除与数据表关联的查询缓存，事务中的写操作由事务对象在提交之后统一清除，
+// 避免并发的查询在事务提交前将旧数据重新写入缓存
+func (bs *dbBase) removeTableCacheByLink(link dbLink, table string) {
+    if _, ok := link.(*sql.Tx); !ok {
+        bs.db.removeTableCache(table)
+    }
+}
+
+// 从写操作(INSERT/REPLACE/UPDATE/DELETE/TRUNCATE)的SQL语句中解析出被修改的数据表，
+// 返回的数据表表达式可交由parseTableNames解析，无法识别时返回空字符串。
+func parseExecTables(query string) string {
+    patterns := []string {
+        `(?is)^\s*(?:INSERT|REPLACE)\s+(?:(?:LOW_PRIORITY|DELAYED|HIGH_PRIORITY|IGNORE)\s+)*(?:INTO\s+)?([^\s(]+)`,
+        `(?is)^\s*UPDATE\s+(?:(?:LOW_PRIORITY|IGNORE)\s+)*(.+?)\s+SET\s`,
+        `(?is)^\s*DELETE\s+(?:(?:LOW_PRIORITY|QUICK|IGNORE)\s+)*FROM\s+(.+?)(?:\s+(?:WHERE|ORDER|LIMIT|USING)\s.*)?;?\s*$`,
+        `(?is)^\s*TRUNCATE\s+(?:TABLE\s+)?([^\s;]+)`,
+    }
+    for _, pattern := range patterns {
+        if match, _ := gregex.MatchString(pattern, query); len(match) > 1 {
+            return match[1]
+        }
+    }
+    return ""
+}
+
+// 从链式操作的tables参数中解析出数据表名称列表，
+// 支持以半角逗号连接的多表，以及 LEFT/RIGHT/INNER JOIN 的联表语句，去掉别名及关键字操作符。
+func parseTableNames(tables string) []string {
+    names := make([]string, 0)
+    // 由内向外去掉所有括号内容(联表的ON条件、子查询等)，避免其中的逗号影响解析
+    for gregex.IsMatchString(`\([^()]*\)`, tables) {
+        tables, _ = gregex.ReplaceString(`\([^()]*\)`, "", tables)
+    }
+    for _, segment := range gregex.Split(`(?i),|\s(LEFT|RIGHT|INNER|OUTER|CROSS|FULL|\s)*JOIN\s`, tables) {
+        fields := strings.Fields(segment)
+        if len(fields) == 0 {
+            continue
+        }
+        name := strings.NewReplacer("`", "", `"`, "", "[", "", "]", "").Replace(fields[0])
+        if name != "" {
+            names = append(names, name)
+        }
+    }
+    return names
+}
@@ -0,0 +1,181 @@
+// Copyright 2019 gf Author(https://github.com/gogf/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package gdb
+
+import (
+    "bytes"
+    "encoding/gob"
+    "github.com/gogf/gf/g/container/gvar"
+    "github.com/gogf/gf/g/database/gredis"
+    "strings"
+)
+
+const (
+    gDEFAULT_REDIS_CACHE_PREFIX = "{gdb:cache}:" // 默认的redis查询缓存键名前缀(带有集群哈希标签)
+
+    // 写入缓存项并添加数据表关联关系。关联关系使用有序集合存储，分值为缓存项的过期时间(毫秒时间戳)，
+    // 写入时清除已过期的关联关系，并保证关联集合的过期时间不小于其中任一缓存项，避免关联集合无限增长。
+    // KEYS[1]为缓存键名，KEYS[2...]为数据表关联集合键名，ARGV[1]为缓存内容，ARGV[2]为过期时间，ARGV[3]为不带前缀的缓存键名。
+    gREDIS_CACHE_SET_SCRIPT = `
+redis.replicate_commands()
+local time   = redis.call("TIME")
+local now    = tonumber(time[1]) * 1000 + math.floor(tonumber(time[2]) / 1000)
+local expire = tonumber(ARGV[2])
+local score  = "+inf"
+if expire > 0 then
+    redis.call("SET", KEYS[1], ARGV[1], "PX", expire)
+    score = now + expire
+else
+    redis.call("SET", KEYS[1], ARGV[1])
+end
+for i = 2, #KEYS do
+    redis.call("ZREMRANGEBYSCORE", KEYS[i], "-inf", now)
+    local ttl = redis.call("PTTL", KEYS[i])
+    redis.call("ZADD", KEYS[i], score, ARGV[3])
+    if expire <= 0 then
+        redis.call("PERSIST", KEYS[i])
+    elseif ttl == -2 or (ttl >= 0 and ttl < expire) then
+        redis.call("PEXPIRE", KEYS[i], expire)
+    end
+end
+return 1`
+
+    // 原子性地删除数据表关联的所有缓存项及关联集合，KEYS为数据表关联集合键名，ARGV[1]为缓存键名前缀。
+    gREDIS_CACHE_REMOVE_TABLES_SCRIPT = `
+local count = 0
+for i = 1, #KEYS do
+    local keys = redis.call("ZRANGE", KEYS[i], 0, -1)
+    for _, key in ipairs(keys) do
+        redis.call("DEL", ARGV[1] .. key)
+    end
+    redis.call("DEL", KEYS[i])
+    count = count + #keys
+end
+return count`
+)
+
+// 基于gredis的查询缓存适配对象，查询结果以gob格式存储(保留[]byte等字段值的原始类型)，可在多进程/多节点之间共享。
+// 数据表与缓存键名的关联关系使用redis有序集合存储，键名为: 前缀 + "table:" + 表名。
+// 写入及清除缓存时会在同一个脚本中操作缓存项及关联集合，因此键名前缀必须带有哈希标签({...})，
+// 使所有键名在redis集群中位于同一个哈希槽。
+type redisCacheAdapter struct {
+    redis  *gredis.Redis // redis客户端
+    prefix string        // 缓存键名前缀
+}
+
+// 创建基于gredis的查询缓存适配对象，参数prefix为可选的缓存键名前缀，
+// 前缀中不包含哈希标签时会使用{}包裹整个前缀，例如"app:cache:"实际使用的前缀为"{app:cache:}"。
+func NewRedisCacheAdapter(redis *gredis.Redis, prefix...string) CacheAdapter {
+    adapter := &redisCacheAdapter {
+        redis  : redis,
+        prefix : gDEFAULT_REDIS_CACHE_PREFIX,
+    }
+    if len(prefix) > 0 {
+        adapter.prefix = prefix[0]
+        if !hasHashTag(adapter.prefix) {
+            adapter.prefix = "{" + adapter.prefix + "}"
+        }
+    }
+    return adapter
+}
+
+func (c *redisCacheAdapter) Get(key string) (Result, bool, error) {
+    v, err := c.redis.DoVar("GET", c.prefix + key)
+    if err != nil || v.IsNil() {
+        return nil, false, err
+    }
+    result, err := decodeCacheResult(v.Bytes())
+    if err != nil {
+        return nil, false, err
+    }
+    return result, true, nil
+}
+
+func (c *redisCacheAdapter) Set(key string, value Result, expire int, tables []string) error {
+    content, err := encodeCacheResult(value)
+    if err != nil {
+        return err
+    }
+    args := make([]interface{}, 0, len(tables) + 6)
+    args  = append(args, gREDIS_CACHE_SET_SCRIPT, len(tables) + 1, c.prefix + key)
+    for _, table := range tables {
+        args = append(args, c.tableKey(table))
+    }
+    args   = append(args, content, expire, key)
+    _, err = c.redis.Do("EVAL", args...)
+    return err
+}
+
+func (c *redisCacheAdapter) Remove(keys ...string) error {
+    if len(keys) == 0 {
+        return nil
+    }
+    args := make([]interface{}, len(keys))
+    for i, key := range keys {
+        args[i] = c.prefix + key
+    }
+    _, err := c.redis.Do("DEL", args...)
+    return err
+}
+
+func (c *redisCacheAdapter) RemoveTables(tables ...string) error {
+    if len(tables) == 0 {
+        return nil
+    }
+    args := make([]interface{}, 0, len(tables) + 3)
+    args  = append(args, gREDIS_CACHE_REMOVE_TABLES_SCRIPT, len(tables))
+    for _, table := range tables {
+        args = append(args, c.tableKey(table))
+    }
+    args   = append(args, c.prefix)
+    _, err := c.redis.Do("EVAL", args...)
+    return err
+}
+
+// 数据表关联集合的键名
+func (c *redisCacheAdapter) tableKey(table string) string {
+    return c.prefix + "table:" + table
+}
+
+// 判断键名是否包含有效的集群哈希标签，规则与gredis.Slot一致
+func hasHashTag(key string) bool {
+    if start := strings.IndexByte(key, '{'); start >= 0 {
+        return strings.IndexByte(key[start + 1:], '}') > 0
+    }
+    return false
+}
+
+// 使用gob编码查询结果，字段值为数据库驱动返回的基本类型(int/int64/float64/bool/string/[]byte/nil)，
+// 与JSON不同的是gob会保留字段值的原始类型。
+func encodeCacheResult(result Result) ([]byte, error) {
+    list := make([]map[string]interface{}, len(result))
+    for i, record := range result {
+        list[i] = record.ToMap()
+    }
+    buffer := bytes.NewBuffer(nil)
+    if err := gob.NewEncoder(buffer).Encode(list); err != nil {
+        return nil, err
+    }
+    return buffer.Bytes(), nil
+}
+
+// 解码gob编码的查询结果
+func decodeCacheResult(content []byte) (Result, error) {
+    list := make([]map[string]interface{}, 0)
+    if err := gob.NewDecoder(bytes.NewReader(content)).Decode(&list); err != nil {
+        return nil, err
+    }
+    result := make(Result, len(list))
+    for i, m := range list {
+        record := make(Record, len(m))
+        for k, v := range m {
+            record[k] = gvar.New(v, true)
+        }
+        result[i] = record
+    }
+    return result, nil
+}
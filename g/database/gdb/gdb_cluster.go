@@ -0,0 +1,231 @@
+// Copyright 2019 gf Author(https://github.com/gogf/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package gdb
+
+import (
+    "database/sql"
+    "database/sql/driver"
+    "errors"
+    "fmt"
+    "github.com/gogf/gf/g/container/gmap"
+    "github.com/gogf/gf/g/container/gtype"
+    "github.com/gogf/gf/g/os/gtime"
+    "github.com/gogf/gf/g/os/gtimer"
+    "net"
+    "strings"
+    "time"
+)
+
+const (
+    BALANCE_WEIGHTED_RANDOM = 0 // 按照Priority权重随机选择节点(默认)
+    BALANCE_ROUND_ROBIN     = 1 // 轮询选择节点
+    BALANCE_LEAST_CONN      = 2 // 选择当前使用中连接数最少的节点
+
+    gDEFAULT_HEALTH_MAX_FAILS  = 3     // 连续失败多少次之后剔除节点
+    gDEFAULT_HEALTH_EJECT_TIME = 30000 // (毫秒)节点被剔除的时间，到期后重新加入集群尝试
+)
+
+// 节点健康状态
+type nodeHealth struct {
+    fails        *gtype.Int   // 连续失败次数
+    ejectedUntil *gtype.Int64 // (毫秒)剔除截止时间
+}
+
+var (
+    // 节点健康状态，键名为ConfigNode.String()，同一节点在多个数据库对象间共享健康状态
+    nodeHealths = gmap.NewStrAnyMap()
+)
+
+// 获取节点的健康状态对象
+func getNodeHealth(node *ConfigNode) *nodeHealth {
+    return nodeHealths.GetOrSetFuncLock(node.String(), func() interface{} {
+        return &nodeHealth {
+            fails        : gtype.NewInt(),
+            ejectedUntil : gtype.NewInt64(),
+        }
+    }).(*nodeHealth)
+}
+
+// 节点当前是否可用(未被剔除)
+func (h *nodeHealth) available() bool {
+    return gtime.Millisecond() >= h.ejectedUntil.Val()
+}
+
+// 记录一次失败，连续失败达到阈值时剔除该节点
+func (h *nodeHealth) fail() {
+    if h.fails.Add(1) >= gDEFAULT_HEALTH_MAX_FAILS {
+        h.fails.Set(0)
+        h.ejectedUntil.Set(gtime.Millisecond() + gDEFAULT_HEALTH_EJECT_TIME)
+    }
+}
+
+// 记录一次成功，恢复节点状态
+func (h *nodeHealth) succeed() {
+    h.fails.Set(0)
+    h.ejectedUntil.Set(0)
+}
+
+// 设置节点负载均衡策略，可选值：BALANCE_WEIGHTED_RANDOM, BALANCE_ROUND_ROBIN, BALANCE_LEAST_CONN。
+func (bs *dbBase) SetBalanceStrategy(strategy int) {
+    bs.balance.Set(strategy)
+}
+
+// 开启节点健康检查，每隔interval时间ping一次集群中的所有节点，
+// 失败的节点将被临时剔除，恢复后自动重新加入集群。interval<=0时关闭健康检查。
+func (bs *dbBase) SetHealthCheck(interval time.Duration) {
+    entry := (*gtimer.Entry)(nil)
+    if interval > 0 {
+        entry = gtimer.AddSingleton(interval, bs.checkNodesHealth)
+    }
+    if v, ok := bs.healthCheck.Set(entry).(*gtimer.Entry); ok && v != nil {
+        v.Close()
+    }
+}
+
+// 返回一个与当前对象共享配置及连接池的"粘性"数据库对象，
+// 在该对象上执行任意写操作之后，后续的所有读操作都将在master节点上执行，以保证读到自己的写入(read-your-writes)。
+// 一般在每个请求开始时创建，请求结束后丢弃。
+func (bs *dbBase) Sticky() DB {
+    base       := *bs
+    base.sticky = gtype.NewBool()
+    switch bs.db.(type) {
+        case *dbPgsql:  base.db = &dbPgsql{dbBase  : &base}
+        case *dbMssql:  base.db = &dbMssql{dbBase  : &base}
+        case *dbSqlite: base.db = &dbSqlite{dbBase : &base}
+        case *dbOracle: base.db = &dbOracle{dbBase : &base}
+        default:        base.db = &dbMysql{dbBase  : &base}
+    }
+    return base.db
+}
+
+// 按照健康状态及负载均衡策略从数据库集群中选择一个配置节点
+func (bs *dbBase) selectNode(master bool) (*ConfigNode, error) {
+    configs.RLock()
+    list, ok := configs.config[bs.group]
+    configs.RUnlock()
+    if !ok {
+        return nil, errors.New(fmt.Sprintf("empty database configuration for item name '%s'", bs.group))
+    }
+    // 将master, slave集群列表拆分出来
+    masterList := make(ConfigGroup, 0)
+    slaveList  := make(ConfigGroup, 0)
+    for i := 0; i < len(list); i++ {
+        node := list[i]
+        // 默认值设定，保证节点标识(String)一致
+        if node.Charset == "" {
+            node.Charset = "utf8"
+        }
+        if node.Role == "slave" {
+            slaveList = append(slaveList, node)
+        } else {
+            masterList = append(masterList, node)
+        }
+    }
+    if len(masterList) < 1 {
+        return nil, errors.New("at least one master node configuration's need to make sense")
+    }
+    if len(slaveList) < 1 {
+        slaveList = masterList
+    }
+    nodes := slaveList
+    if master {
+        nodes = masterList
+    }
+    // 过滤掉被剔除的节点，如果所有节点都被剔除，那么仍然使用全部节点
+    healthy := make(ConfigGroup, 0, len(nodes))
+    for i := 0; i < len(nodes); i++ {
+        if getNodeHealth(&nodes[i]).available() {
+            healthy = append(healthy, nodes[i])
+        }
+    }
+    if len(healthy) > 0 {
+        nodes = healthy
+    }
+    switch bs.balance.Val() {
+        case BALANCE_ROUND_ROBIN:
+            counter := bs.slaveCounter
+            if master {
+                counter = bs.masterCounter
+            }
+            index := (counter.Add(1) - 1) % len(nodes)
+            if index < 0 {
+                index += len(nodes)
+            }
+            return &nodes[index], nil
+
+        case BALANCE_LEAST_CONN:
+            index   := 0
+            minimum := -1
+            for i := 0; i < len(nodes); i++ {
+                inUse := 0
+                if v := bs.cache.Get(nodes[i].String()); v != nil {
+                    inUse = v.(*sql.DB).Stats().InUse
+                }
+                if minimum < 0 || inUse < minimum {
+                    index   = i
+                    minimum = inUse
+                }
+            }
+            return &nodes[index], nil
+
+        default:
+            return getConfigNodeByPriority(nodes), nil
+    }
+}
+
+// 对集群中的所有节点执行一次ping检查，更新节点健康状态
+func (bs *dbBase) checkNodesHealth() {
+    configs.RLock()
+    list := make(ConfigGroup, len(configs.config[bs.group]))
+    copy(list, configs.config[bs.group])
+    configs.RUnlock()
+    for i := 0; i < len(list); i++ {
+        if list[i].Charset == "" {
+            list[i].Charset = "utf8"
+        }
+        sqlDb, err := bs.getSqlDbByNode(&list[i])
+        if err == nil {
+            err = sqlDb.Ping()
+        }
+        if err != nil {
+            getNodeHealth(&list[i]).fail()
+        } else {
+            getNodeHealth(&list[i]).succeed()
+        }
+    }
+}
+
+// 底层链接操作失败时，判断是否为连接错误，如果是那么记录对应节点的失败
+func (bs *dbBase) checkLinkError(link dbLink, err error) {
+    if err == nil || !isConnError(err) {
+        return
+    }
+    if v := bs.linkNodes.Get(link); v != nil {
+        getNodeHealth(v.(*ConfigNode)).fail()
+    }
+}
+
+// 判断错误是否为网络连接错误
+func isConnError(err error) bool {
+    if err == driver.ErrBadConn {
+        return true
+    }
+    if _, ok := err.(net.Error); ok {
+        return true
+    }
+    s := err.Error()
+    return strings.Contains(s, "invalid connection") ||
+        strings.Contains(s, "connection refused") ||
+        strings.Contains(s, "broken pipe")
+}
+
+// 链式操作，强制在master节点上执行查询，用于需要读取最新写入数据的场景
+func (md *Model) Master() *Model {
+    model       := md.getModel()
+    model.master = true
+    return model
+}
@@ -41,7 +41,9 @@ func formatCondition(where interface{}, args []interface{}) (newWhere string, ne
         // map/struct类型
         case reflect.Map:   fallthrough
         case reflect.Struct:
-            for key, value := range structToMap(where) {
+            // 属性值转换失败时保留原始的属性值作为查询参数，由数据库驱动在执行时返回错误
+            data, _ := structToMap(where)
+            for key, value := range data {
                 if buffer.Len() > 0 {
                     buffer.WriteString(" AND ")
                 }
@@ -204,7 +206,18 @@ func getInsertOperationByOption(option int) string {
 
 // 将对象转换为map，如果对象带有继承对象，那么执行递归转换。
 // 该方法用于将变量传递给数据库执行之前。
-func structToMap(obj interface{}) map[string]interface{} {
+// 属性值转换失败时返回错误，返回的map中该属性保留原始的属性值。
+func structToMap(obj interface{}) (map[string]interface{}, error) {
+	// struct类型按照属性标签的映射关系进行转换
+	rv := reflect.ValueOf(obj)
+	for rv.Kind() == reflect.Ptr && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	if rv.Kind() == reflect.Struct {
+		if _, ok := obj.(apiString); !ok {
+			return structValueToMap(rv)
+		}
+	}
 	data := gconv.Map(obj)
 	for key, value := range data {
 		rv   := reflect.ValueOf(value)
@@ -228,15 +241,20 @@ func structToMap(obj interface{}) map[string]interface{} {
 					continue
 				}
 				delete(data, key)
-				for k, v := range structToMap(value) {
+				m, err := structToMap(value)
+				if err != nil {
+					return nil, err
+				}
+				for k, v := range m {
 					data[k] = v
 				}
 		}
 	}
-	return data
+	return data, nil
 }
 
 // 使用递归的方式将map键值对映射到struct对象上，注意参数<pointer>是一个指向struct的指针。
+// 属性与字段的映射关系按照struct标签(orm/gconv/json)解析并缓存，支持sql.Scanner及JSON字段。
 func mapToStruct(data map[string]interface{}, pointer interface{}) error {
-	return gconv.StructDeep(data, pointer)
+	return mapToStructByFields(data, pointer)
 }
\ No newline at end of file
@@ -0,0 +1,422 @@
+// Copyright 2019 gf Author(https://github.com/gogf/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package gdb
+
+import (
+    "database/sql"
+    "database/sql/driver"
+    "encoding/json"
+    "fmt"
+    "github.com/gogf/gf/g/container/gmap"
+    "github.com/gogf/gf/g/util/gconv"
+    "reflect"
+    "strings"
+    "time"
+)
+
+// struct属性与数据表字段的映射关系
+type structFields struct {
+    fields []*structField          // 属性列表(按照定义顺序，外层属性在前)
+    names  map[string]*structField // 字段名称 => 属性(支持匿名嵌套struct)
+}
+
+// struct属性的映射信息，通过标签定义，例如：`orm:"user_name,omitempty"`、`orm:"profile,json"`
+type structField struct {
+    name      string // 数据表字段名称
+    index     []int  // 属性索引(匿名嵌套struct时为多级索引)
+    omitEmpty bool   // 写入时是否忽略零值
+    json      bool   // 是否为JSON字段，写入时编码为JSON字符串，读取时解码到属性
+}
+
+var (
+    // struct类型映射关系缓存，键名为reflect.Type
+    structFieldsCache = gmap.New()
+    // time.Time类型，匿名嵌套时不做展开
+    timeType          = reflect.TypeOf(time.Time{})
+    // 自定义类型的数据库读写接口
+    scannerType       = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+    valuerType        = reflect.TypeOf((*driver.Valuer)(nil)).Elem()
+    apiStringType     = reflect.TypeOf((*apiString)(nil)).Elem()
+)
+
+// 获取指定struct类型的字段映射关系，结果会按照类型进行缓存
+func getStructFields(t reflect.Type) *structFields {
+    return structFieldsCache.GetOrSetFuncLock(t, func() interface{} {
+        fields := &structFields {
+            fields : make([]*structField, 0),
+            names  : make(map[string]*structField),
+        }
+        fields.parse(t, nil)
+        return fields
+    }).(*structFields)
+}
+
+// 递归解析struct属性，外层属性优先级高于嵌套struct中的同名属性。
+// 字段名称标签优先级为：orm > gconv > json，未设置标签时使用属性名称。
+func (fs *structFields) parse(t reflect.Type, parent []int) {
+    embedded := make([]reflect.StructField, 0)
+    for i := 0; i < t.NumField(); i++ {
+        field := t.Field(i)
+        index := append(append([]int{}, parent...), i)
+        field.Index = index
+        if field.Anonymous {
+            ft := field.Type
+            if ft.Kind() == reflect.Ptr {
+                ft = ft.Elem()
+            }
+            if ft.Kind() == reflect.Struct && ft != timeType && !isCustomType(field.Type) {
+                embedded = append(embedded, field)
+                continue
+            }
+        }
+        // 只处理公开属性
+        if field.PkgPath != "" {
+            continue
+        }
+        name    := ""
+        options := make([]string, 0)
+        for _, tag := range []string{"orm", "gconv", "json"} {
+            if value := field.Tag.Get(tag); value != "" {
+                array := strings.Split(value, ",")
+                name   = strings.TrimSpace(array[0])
+                for _, option := range array[1:] {
+                    options = append(options, strings.TrimSpace(option))
+                }
+                break
+            }
+        }
+        if name == "-" {
+            continue
+        }
+        sf := &structField {
+            name  : name,
+            index : index,
+        }
+        if sf.name == "" {
+            sf.name = field.Name
+        }
+        for _, option := range options {
+            switch option {
+                case "omitempty": sf.omitEmpty = true
+                case "json":      sf.json      = true
+            }
+        }
+        if fs.add(sf.name, sf) {
+            fs.fields = append(fs.fields, sf)
+        }
+        fs.add(field.Name, sf)
+    }
+    for _, field := range embedded {
+        ft := field.Type
+        if ft.Kind() == reflect.Ptr {
+            ft = ft.Elem()
+        }
+        fs.parse(ft, field.Index)
+    }
+}
+
+// 添加映射关系，已存在的映射不覆盖，返回该名称是否为新增的映射
+func (fs *structFields) add(name string, field *structField) bool {
+    added := false
+    if _, ok := fs.names[name]; !ok {
+        fs.names[name] = field
+        added          = true
+    }
+    key := normalizeFieldName(name)
+    if _, ok := fs.names[key]; !ok {
+        fs.names[key] = field
+    }
+    return added
+}
+
+// 根据数据表字段名称查找对应的属性，优先精确匹配，其次忽略大小写及下划线匹配
+func (fs *structFields) lookup(column string) (*structField, bool) {
+    if field, ok := fs.names[column]; ok {
+        return field, true
+    }
+    field, ok := fs.names[normalizeFieldName(column)]
+    return field, ok
+}
+
+// 名称标准化：转换为小写并去掉下划线/中划线/空格
+func normalizeFieldName(name string) string {
+    return strings.ToLower(strings.NewReplacer("_", "", "-", "", " ", "").Replace(name))
+}
+
+// 判断类型(或其指针类型)是否实现了sql.Scanner/driver.Valuer接口
+func isCustomType(t reflect.Type) bool {
+    if t.Implements(scannerType) || t.Implements(valuerType) {
+        return true
+    }
+    if t.Kind() != reflect.Ptr {
+        t = reflect.PtrTo(t)
+        return t.Implements(scannerType) || t.Implements(valuerType)
+    }
+    return false
+}
+
+// 按照索引获取struct属性，当init为true时自动初始化路径上的nil嵌套指针，
+// 否则遇到nil嵌套指针时返回无效的reflect.Value
+func fieldByIndex(v reflect.Value, index []int, init bool) reflect.Value {
+    for i, x := range index {
+        if i > 0 && v.Kind() == reflect.Ptr {
+            if v.IsNil() {
+                if !init || !v.CanSet() {
+                    return reflect.Value{}
+                }
+                v.Set(reflect.New(v.Type().Elem()))
+            }
+            v = v.Elem()
+        }
+        v = v.Field(x)
+    }
+    return v
+}
+
+// 按照struct的映射关系将struct对象转换为数据表记录的map，用于数据写入及条件查询。
+// 属性值转换失败(driver.Valuer或者JSON编码返回错误)时返回第一个错误，该属性在map中保留原始的属性值。
+func structValueToMap(rv reflect.Value) (map[string]interface{}, error) {
+    data     := make(map[string]interface{})
+    firstErr := (error)(nil)
+    for _, field := range getStructFields(rv.Type()).fields {
+        fv := fieldByIndex(rv, field.index, false)
+        if !fv.IsValid() {
+            continue
+        }
+        if field.omitEmpty && fv.IsZero() {
+            continue
+        }
+        value, err := fieldValueToData(fv, field)
+        if err != nil {
+            if firstErr == nil {
+                firstErr = fmt.Errorf(`convert field "%s" failed: %s`, field.name, err.Error())
+            }
+            data[field.name] = fv.Interface()
+            continue
+        }
+        // 非JSON的普通嵌套struct保持原有的展开处理
+        if value != nil {
+            if v := reflect.ValueOf(value); v.Kind() == reflect.Struct {
+                if _, ok := value.(time.Time); !ok {
+                    m, err := structValueToMap(v)
+                    if err != nil && firstErr == nil {
+                        firstErr = err
+                    }
+                    for k, v := range m {
+                        data[k] = v
+                    }
+                    continue
+                }
+            }
+        }
+        data[field.name] = value
+    }
+    return data, firstErr
+}
+
+// 将struct属性值转换为可写入数据库的值
+func fieldValueToData(fv reflect.Value, field *structField) (interface{}, error) {
+    // 自定义的driver.Valuer类型交由其自身处理(包括sql.Null*类型)
+    if valuer, ok := getValuer(fv); ok {
+        if fv.Kind() == reflect.Ptr && fv.IsNil() {
+            return nil, nil
+        }
+        return valuer.Value()
+    }
+    if fv.Kind() == reflect.Ptr {
+        if fv.IsNil() {
+            return nil, nil
+        }
+        if field.json {
+            return jsonFieldValue(fv)
+        }
+        fv = fv.Elem()
+    }
+    if field.json {
+        return jsonFieldValue(fv)
+    }
+    value := fv.Interface()
+    if fv.Kind() == reflect.Struct {
+        if t, ok := value.(time.Time); ok {
+            return t, nil
+        }
+        // 实现了String方法的struct(例如gtime.Time)，执行字符串转换
+        if s, ok := value.(apiString); ok {
+            return s.String(), nil
+        }
+        if reflect.PtrTo(fv.Type()).Implements(apiStringType) {
+            e := reflect.New(fv.Type())
+            e.Elem().Set(fv)
+            return e.Interface().(apiString).String(), nil
+        }
+    }
+    return value, nil
+}
+
+// 获取属性值的driver.Valuer接口(支持指针接收者)
+func getValuer(fv reflect.Value) (driver.Valuer, bool) {
+    if !fv.CanInterface() {
+        return nil, false
+    }
+    if valuer, ok := fv.Interface().(driver.Valuer); ok {
+        return valuer, true
+    }
+    if fv.Kind() != reflect.Ptr && reflect.PtrTo(fv.Type()).Implements(valuerType) {
+        e := reflect.New(fv.Type())
+        e.Elem().Set(fv)
+        return e.Interface().(driver.Valuer), true
+    }
+    return nil, false
+}
+
+// 将属性值编码为JSON字符串
+func jsonFieldValue(fv reflect.Value) (interface{}, error) {
+    content, err := json.Marshal(fv.Interface())
+    if err != nil {
+        return nil, err
+    }
+    return string(content), nil
+}
+
+// 按照struct的映射关系将数据表记录映射到struct对象上，参数pointer为struct指针或者struct的reflect.Value
+func mapToStructByFields(data map[string]interface{}, pointer interface{}) error {
+    rv, ok := pointer.(reflect.Value)
+    if !ok {
+        rv = reflect.ValueOf(pointer)
+    }
+    if rv.Kind() == reflect.Ptr {
+        if rv.IsNil() {
+            return fmt.Errorf("params should be a non-nil pointer to struct")
+        }
+        rv = rv.Elem()
+    }
+    if rv.Kind() != reflect.Struct || !rv.CanSet() {
+        return fmt.Errorf("params should be a pointer to struct, but got: %v", rv.Kind())
+    }
+    fields := getStructFields(rv.Type())
+    for column, value := range data {
+        field, ok := fields.lookup(column)
+        if !ok {
+            continue
+        }
+        fv := fieldByIndex(rv, field.index, true)
+        if !fv.IsValid() {
+            continue
+        }
+        if err := bindDataToField(fv, field, value); err != nil {
+            return fmt.Errorf(`bind column "%s" failed: %s`, column, err.Error())
+        }
+    }
+    return nil
+}
+
+// 将数据库查询的原始字段值转换并设置到struct属性上
+func bindValueToField(db DB, field reflect.Value, info *structField, src sql.RawBytes, fieldType string) error {
+    // 自定义的sql.Scanner类型交由其自身处理，使用原始的字段值
+    if scanner, ok := getScanner(field); ok {
+        if src == nil {
+            return scanner.Scan(nil)
+        }
+        v := make([]byte, len(src))
+        copy(v, src)
+        return scanner.Scan(v)
+    }
+    if src == nil {
+        return bindDataToField(field, info, nil)
+    }
+    v := make([]byte, len(src))
+    copy(v, src)
+    if info.json {
+        return bindDataToField(field, info, v)
+    }
+    return bindDataToField(field, info, db.convertValue(v, fieldType))
+}
+
+// 将转换后的字段值设置到struct属性上
+func bindDataToField(field reflect.Value, info *structField, value interface{}) error {
+    if scanner, ok := getScanner(field); ok {
+        return scanner.Scan(value)
+    }
+    if value == nil {
+        field.Set(reflect.Zero(field.Type()))
+        return nil
+    }
+    if info.json {
+        content := gconv.Bytes(value)
+        if len(content) == 0 {
+            field.Set(reflect.Zero(field.Type()))
+            return nil
+        }
+        return json.Unmarshal(content, field.Addr().Interface())
+    }
+    return bindValueToReflectValue(field, value)
+}
+
+// 获取属性的sql.Scanner接口(指针接收者)，属性为nil指针时自动初始化
+func getScanner(field reflect.Value) (sql.Scanner, bool) {
+    if field.Kind() == reflect.Ptr && field.Type().Implements(scannerType) {
+        if field.IsNil() {
+            field.Set(reflect.New(field.Type().Elem()))
+        }
+        return field.Interface().(sql.Scanner), true
+    }
+    if field.CanAddr() {
+        if scanner, ok := field.Addr().Interface().(sql.Scanner); ok {
+            return scanner, true
+        }
+    }
+    return nil, false
+}
+
+// 按照属性的类型将给定的值转换后设置到属性上
+func bindValueToReflectValue(field reflect.Value, value interface{}) error {
+    t := field.Type()
+    switch field.Kind() {
+        case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+            field.SetInt(gconv.Int64(value))
+
+        case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+            field.SetUint(gconv.Uint64(value))
+
+        case reflect.Float32, reflect.Float64:
+            field.SetFloat(gconv.Float64(value))
+
+        case reflect.Bool:
+            field.SetBool(gconv.Bool(value))
+
+        case reflect.String:
+            field.SetString(gconv.String(value))
+
+        case reflect.Slice:
+            if t.Elem().Kind() != reflect.Uint8 {
+                return fmt.Errorf(`unsupported field type "%s"`, t.String())
+            }
+            field.SetBytes(gconv.Bytes(value))
+
+        case reflect.Interface:
+            field.Set(reflect.ValueOf(value))
+
+        case reflect.Ptr:
+            e := reflect.New(t.Elem())
+            if err := bindValueToReflectValue(e.Elem(), value); err != nil {
+                return err
+            }
+            field.Set(e)
+
+        case reflect.Struct:
+            rv := reflect.ValueOf(gconv.Convert(value, t.String()))
+            if rv.IsValid() && rv.Type().ConvertibleTo(t) {
+                field.Set(rv.Convert(t))
+                return nil
+            }
+            return gconv.Struct(value, field)
+
+        default:
+            return fmt.Errorf(`unsupported field type "%s"`, t.String())
+    }
+    return nil
+}
@@ -0,0 +1,147 @@
+// Copyright 2019 gf Author(https://github.com/gogf/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package gdb
+
+import (
+    "errors"
+    "fmt"
+    "github.com/gogf/gf/g/container/gtype"
+    "github.com/gogf/gf/g/os/glog"
+    "github.com/gogf/gf/g/os/gtime"
+    "github.com/gogf/gf/g/text/gregex"
+    "reflect"
+    "runtime"
+    "strings"
+    "sync"
+    "time"
+)
+
+// SQL执行前的回调函数，可修改s.Sql及s.Args改写即将执行的SQL语句，返回非nil的error将拒绝执行该SQL
+type BeforeHookFunc func(s *Sql) error
+
+// SQL执行后的回调函数，可获取SQL的执行耗时、错误信息及调用位置，用于日志、监控统计及链路追踪等
+type AfterHookFunc  func(s *Sql)
+
+// SQL回调管理对象，同一数据库对象(包括其Sticky对象)共享
+type sqlHooks struct {
+    mu     sync.RWMutex
+    before []BeforeHookFunc // SQL执行前回调列表(按注册顺序执行)
+    after  []AfterHookFunc  // SQL执行后回调列表(按注册顺序执行)
+    slow   *gtype.Int64     // (纳秒)慢查询阈值，<=0表示不记录慢查询
+}
+
+var (
+    // 当前包的函数名前缀，用于在调用栈中找到业务层的调用位置
+    pkgFuncPrefix = reflect.TypeOf(dbBase{}).PkgPath() + "."
+)
+
+// 创建SQL回调管理对象
+func newSqlHooks() *sqlHooks {
+    return &sqlHooks {
+        before : make([]BeforeHookFunc, 0),
+        after  : make([]AfterHookFunc, 0),
+        slow   : gtype.NewInt64(),
+    }
+}
+
+// 注册SQL执行前的回调函数
+func (bs *dbBase) AddBeforeHook(hook BeforeHookFunc) {
+    bs.hooks.mu.Lock()
+    bs.hooks.before = append(bs.hooks.before, hook)
+    bs.hooks.mu.Unlock()
+}
+
+// 注册SQL执行后的回调函数
+func (bs *dbBase) AddAfterHook(hook AfterHookFunc) {
+    bs.hooks.mu.Lock()
+    bs.hooks.after = append(bs.hooks.after, hook)
+    bs.hooks.mu.Unlock()
+}
+
+// 设置慢查询阈值，执行时间超过该阈值的SQL将通过glog输出警告日志(包含调用位置)，threshold<=0时关闭慢查询日志
+func (bs *dbBase) SetSlowThreshold(threshold time.Duration) {
+    bs.hooks.slow.Set(int64(threshold))
+}
+
+// SQL执行前处理：执行前置回调(可改写或拒绝SQL)，并记录开始时间
+func (bs *dbBase) beforeSql(s *Sql) error {
+    bs.hooks.mu.RLock()
+    before := bs.hooks.before
+    bs.hooks.mu.RUnlock()
+    if len(before) > 0 {
+        s.Caller = getSqlCaller()
+        for _, hook := range before {
+            if err := hook(s); err != nil {
+                s.Error = err
+                return err
+            }
+        }
+    }
+    s.Start = gtime.Millisecond()
+    s.begin = time.Now()
+    return nil
+}
+
+// SQL执行后处理：记录执行结果及耗时，输出调试及慢查询日志，并执行后置回调
+func (bs *dbBase) afterSql(s *Sql, err error) {
+    s.Error    = err
+    s.End      = gtime.Millisecond()
+    s.Duration = time.Since(s.begin)
+    bs.hooks.mu.RLock()
+    after := bs.hooks.after
+    bs.hooks.mu.RUnlock()
+    slow   := time.Duration(bs.hooks.slow.Val())
+    isSlow := slow > 0 && s.Duration >= slow
+    if s.Caller == "" && (len(after) > 0 || isSlow || bs.db.getDebug()) {
+        s.Caller = getSqlCaller()
+    }
+    if bs.db.getDebug() {
+        bs.sqls.Put(s)
+        printSql(s)
+    }
+    if isSlow {
+        glog.Backtrace(false).Warningf("[SLOW SQL] %s, %v, %d ms, %s", s.Sql, s.Args, s.Duration/time.Millisecond, s.Caller)
+    }
+    for _, hook := range after {
+        hook(s)
+    }
+}
+
+// 获取执行SQL的业务调用位置(文件:行号)，跳过gdb包内部的调用
+func getSqlCaller() string {
+    pcs    := make([]uintptr, 32)
+    n      := runtime.Callers(3, pcs)
+    frames := runtime.CallersFrames(pcs[:n])
+    for {
+        frame, more := frames.Next()
+        if !strings.HasPrefix(frame.Function, pkgFuncPrefix) {
+            return fmt.Sprintf("%s:%d", frame.File, frame.Line)
+        }
+        if !more {
+            break
+        }
+    }
+    return ""
+}
+
+// 内置的SQL前置回调，拒绝执行不带WHERE条件的DELETE语句，防止误删全表数据。
+// 只判断语句本身的WHERE条件，字符串及括号(子查询)中的WHERE不算在内。
+// 使用示例：db.AddBeforeHook(gdb.HookRejectDeleteWithoutWhere)
+func HookRejectDeleteWithoutWhere(s *Sql) error {
+    if !gregex.IsMatchString(`(?is)^\s*DELETE\s`, s.Sql) {
+        return nil
+    }
+    // 去掉字符串内容，以及由内向外去掉所有括号内容
+    statement, _ := gregex.ReplaceString(`'(?:[^'\\]|\\.|'')*'|"(?:[^"\\]|\\.)*"`, "''", s.Sql)
+    for gregex.IsMatchString(`\([^()]*\)`, statement) {
+        statement, _ = gregex.ReplaceString(`\([^()]*\)`, " ", statement)
+    }
+    if !gregex.IsMatchString(`(?i)\sWHERE\b`, statement) {
+        return errors.New("DELETE statement without WHERE condition is rejected")
+    }
+    return nil
+}
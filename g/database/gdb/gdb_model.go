@@ -30,12 +30,14 @@ type Model struct {
 	start        int           // 分页开始
 	limit        int           // 分页条数
 	data         interface{}   // 操作记录(支持Map/List/string类型)
+	dataError    error         // Data方法转换struct数据时产生的错误，在执行写操作时返回
 	batch        int           // 批量操作条数
 	filter       bool          // 是否按照表字段过滤data参数
 	cacheEnabled bool          // 当前SQL操作是否开启查询缓存功能
 	cacheTime    int           // 查询缓存时间
 	cacheName    string        // 查询缓存名称
     safe         bool          // 当前模型是否运行安全模式（可修改当前模型，否则每一次链式操作都是返回新的模型对象）
+    master       bool          // 是否强制在master节点上执行查询
 }
 
 // 链式操作，数据表字段，可支持多个表，以半角逗号连接
@@ -238,7 +240,8 @@ func (md *Model) Cache(time int, name ... string) *Model {
 // 链式操作，操作数据项，参数data类型支持 string/map/slice/struct/*struct ,
 // 也可以是：key,value,key,value,...。
 func (md *Model) Data(data ...interface{}) *Model {
-    model := md.getModel()
+    model          := md.getModel()
+    model.dataError = nil
 	if len(data) > 1 {
 		m := make(map[string]interface{})
 		for i := 0; i < len(data); i += 2 {
@@ -268,12 +271,17 @@ func (md *Model) Data(data ...interface{}) *Model {
                     case reflect.Array:
                         list := make(List, rv.Len())
                         for i := 0; i < rv.Len(); i++ {
-                            list[i] = structToMap(rv.Index(i).Interface())
+                            m, err := structToMap(rv.Index(i).Interface())
+                            if err != nil && model.dataError == nil {
+                                model.dataError = err
+                            }
+                            list[i] = m
                         }
                         model.data = list
                     case reflect.Map:   fallthrough
                     case reflect.Struct:
-                        model.data = Map(structToMap(data[0]))
+                        m, err := structToMap(data[0])
+                        model.data, model.dataError = Map(m), err
                     default:
                         model.data = data[0]
                 }
@@ -294,6 +302,9 @@ func (md *Model) Insert() (result sql.Result, err error) {
 	if md.data == nil {
 		return nil, errors.New("inserting into table with empty data")
 	}
+	if md.dataError != nil {
+		return nil, md.dataError
+	}
 	// 批量操作
 	if list, ok := md.data.(List); ok {
 		batch := 10
@@ -335,6 +346,9 @@ func (md *Model) Replace() (result sql.Result, err error) {
 	if md.data == nil {
 		return nil, errors.New("replacing into table with empty data")
 	}
+	if md.dataError != nil {
+		return nil, md.dataError
+	}
 	// 批量操作
 	if list, ok := md.data.(List); ok {
 		batch := 10
@@ -376,6 +390,9 @@ func (md *Model) Save() (result sql.Result, err error) {
 	if md.data == nil {
 		return nil, errors.New("replacing into table with empty data")
 	}
+	if md.dataError != nil {
+		return nil, md.dataError
+	}
 	// 批量操作
 	if list, ok := md.data.(List); ok {
 		batch := gDEFAULT_BATCH_NUM
@@ -415,6 +432,9 @@ func (md *Model) Update() (result sql.Result, err error) {
 	if md.data == nil {
 		return nil, errors.New("updating table with empty data")
 	}
+	if md.dataError != nil {
+		return nil, md.dataError
+	}
     if md.filter {
         if data, ok := md.data.(Map); ok {
             if md.filter {
@@ -553,31 +573,46 @@ func (md *Model) getAll(query string, args ...interface{}) (result Result, err e
 		if len(cacheKey) == 0 {
 			cacheKey = query + "/" + gconv.String(args)
 		}
-		if v := md.db.getCache().Get(cacheKey); v != nil {
-			return v.(Result), nil
+		if md.cacheTime >= 0 {
+			if v, ok := md.db.getQueryCache(cacheKey); ok {
+				return v, nil
+			}
 		}
 	}
 
-	if md.tx == nil {
-		result, err = md.db.GetAll(query, args...)
-	} else {
+	if md.tx != nil {
 		result, err = md.tx.GetAll(query, args...)
+	} else {
+		link, e := md.getLink()
+		if e != nil {
+			return nil, e
+		}
+		result, err = md.db.doGetAll(link, query, args...)
 	}
 	// 查询缓存保存处理
 	if len(cacheKey) > 0 && err == nil {
 		if md.cacheTime < 0 {
-			md.db.getCache().Remove(cacheKey)
+			md.db.GetCacheAdapter().Remove(cacheKey)
 		} else {
-			md.db.getCache().Set(cacheKey, result, md.cacheTime*1000)
+			md.db.GetCacheAdapter().Set(cacheKey, result, md.cacheTime*1000, parseTableNames(md.tables))
 		}
 	}
 	return result, err
 }
 
+// 获取非事务查询使用的底层链接对象，调用Master()后强制使用master节点，
+// 否则使用slave节点(Sticky对象执行过写操作后同样为master节点)
+func (md *Model) getLink() (dbLink, error) {
+	if md.master {
+		return md.db.Master()
+	}
+	return md.db.Slave()
+}
+
 // 检查是否需要查询查询缓存
 func (md *Model) checkAndRemoveCache() {
 	if md.cacheEnabled && md.cacheTime < 0 && len(md.cacheName) > 0 {
-		md.db.getCache().Remove(md.cacheName)
+		md.db.GetCacheAdapter().Remove(md.cacheName)
 	}
 }
 
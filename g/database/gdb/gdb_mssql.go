@@ -150,29 +150,61 @@ func (db *dbMssql) parseSql(sql string) string {
 	return sql
 }
 
-// 获得指定表表的数据结构，构造成map哈希表返回，其中键名为表字段名称，键值暂无用途(默认为字段数据类型).
-func (db *dbMssql) getTableFields(table string) (fields map[string]string, err error) {
-	// 缓存不存在时会查询数据表结构，缓存后不过期，直至程序重启(重新部署)
-	v := db.cache.GetOrSetFunc("table_fields_"+table, func() interface{} {
-		result := (Result)(nil)
-		result, err = db.GetAll(fmt.Sprintf(`
-		SELECT c.name as FIELD, CASE t.name 
-			WHEN 'numeric' THEN t.name + '(' + convert(varchar(20),c.xprec) + ',' + convert(varchar(20),c.xscale) + ')' 
-			WHEN 'char' THEN t.name + '(' + convert(varchar(20),c.length)+ ')'
-			WHEN 'varchar' THEN t.name + '(' + convert(varchar(20),c.length)+ ')'
-			ELSE t.name + '(' + convert(varchar(20),c.length)+ ')' END as TYPE
-		FROM systypes t,syscolumns c WHERE t.xtype=c.xtype AND c.id = (SELECT id FROM sysobjects WHERE name='%s') ORDER BY c.colid`, strings.ToUpper(table)))
+// 获取当前数据库的所有数据表名称
+func (db *dbMssql) Tables() (tables []string, err error) {
+	return db.getTablesWithCache(func() ([]string, error) {
+		result, err := db.GetAll(`SELECT NAME FROM SYS.TABLES WHERE TYPE='U' ORDER BY NAME`)
 		if err != nil {
-			return nil
+			return nil, err
 		}
-		fields = make(map[string]string)
-		for _, m := range result {
-			fields[strings.ToLower(m["FIELD"].String())] = strings.ToLower(m["TYPE"].String()) //sqlserver返回的field为大写的需要转为小写的
+		tables := make([]string, len(result))
+		for i, m := range result {
+			tables[i] = m["NAME"].String()
 		}
-		return fields
-	}, 0)
-	if err == nil {
-		fields = v.(map[string]string)
-	}
-	return
+		return tables, nil
+	})
+}
+
+// 获得指定表的字段结构信息(基于sys系统视图)，sqlserver返回的字段名称统一转换为小写
+func (db *dbMssql) TableFields(table string) (fields map[string]*TableField, err error) {
+	return db.getTableFieldsWithCache(table, func() (map[string]*TableField, error) {
+		result, err := db.GetAll(fmt.Sprintf(`
+		SELECT c.name AS FIELD,
+			CASE WHEN t.name IN ('numeric','decimal') THEN t.name + '(' + CONVERT(VARCHAR(20),c.precision) + ',' + CONVERT(VARCHAR(20),c.scale) + ')'
+				WHEN t.name IN ('char','varchar','nchar','nvarchar','binary','varbinary') THEN t.name + '(' + CONVERT(VARCHAR(20),c.max_length) + ')'
+				ELSE t.name END AS TYPE,
+			c.is_nullable AS IS_NULLABLE,
+			OBJECT_DEFINITION(c.default_object_id) AS DEFAULT_VALUE,
+			CASE WHEN EXISTS(SELECT 1 FROM sys.index_columns ic INNER JOIN sys.indexes i ON ic.object_id=i.object_id AND ic.index_id=i.index_id
+				WHERE i.is_primary_key=1 AND ic.object_id=c.object_id AND ic.column_id=c.column_id) THEN 'PRI'
+				WHEN EXISTS(SELECT 1 FROM sys.index_columns ic INNER JOIN sys.indexes i ON ic.object_id=i.object_id AND ic.index_id=i.index_id
+				WHERE i.is_unique=1 AND ic.object_id=c.object_id AND ic.column_id=c.column_id) THEN 'UNI'
+				WHEN EXISTS(SELECT 1 FROM sys.index_columns ic
+				WHERE ic.object_id=c.object_id AND ic.column_id=c.column_id) THEN 'MUL'
+				ELSE '' END AS KEY_TYPE,
+			CASE WHEN c.is_identity=1 THEN 'auto_increment' ELSE '' END AS EXTRA,
+			CAST(ep.value AS NVARCHAR(4000)) AS COMMENT
+		FROM sys.columns c
+		INNER JOIN sys.types t ON c.user_type_id=t.user_type_id
+		LEFT JOIN sys.extended_properties ep ON ep.major_id=c.object_id AND ep.minor_id=c.column_id AND ep.name='MS_Description'
+		WHERE c.object_id=OBJECT_ID('%s') ORDER BY c.column_id`, table))
+		if err != nil {
+			return nil, err
+		}
+		fields := make(map[string]*TableField, len(result))
+		for i, m := range result {
+			name := strings.ToLower(m["FIELD"].String())
+			fields[name] = &TableField{
+				Index:   i,
+				Name:    name,
+				Type:    strings.ToLower(m["TYPE"].String()),
+				Null:    m["IS_NULLABLE"].Bool(),
+				Key:     m["KEY_TYPE"].String(),
+				Default: m["DEFAULT_VALUE"].Val(),
+				Extra:   m["EXTRA"].String(),
+				Comment: m["COMMENT"].String(),
+			}
+		}
+		return fields, nil
+	})
 }
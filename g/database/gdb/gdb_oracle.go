@@ -143,29 +143,54 @@ func (db *dbOracle) parseSql(sql string) string {
 	return sql
 }
 
-// 获得指定表表的数据结构，构造成map哈希表返回，其中键名为表字段名称，键值暂无用途(默认为字段数据类型).
-func (db *dbOracle) getTableFields(table string) (fields map[string]string, err error) {
-	// 缓存不存在时会查询数据表结构，缓存后不过期，直至程序重启(重新部署)
-	v := db.cache.GetOrSetFunc("table_fields_"+table, func() interface{} {
-		result := (Result)(nil)
-		result, err = db.GetAll(fmt.Sprintf(`
-		SELECT COLUMN_NAME AS FIELD, CASE DATA_TYPE 
-		    WHEN 'NUMBER' THEN DATA_TYPE||'('||DATA_PRECISION||','||DATA_SCALE||')' 
-			WHEN 'FLOAT' THEN DATA_TYPE||'('||DATA_PRECISION||','||DATA_SCALE||')' 
-			ELSE DATA_TYPE||'('||DATA_LENGTH||')' END AS TYPE  
-		FROM USER_TAB_COLUMNS WHERE TABLE_NAME = '%s' ORDER BY COLUMN_ID`, strings.ToUpper(table)))
+// 获取当前用户的所有数据表名称，ORACLE返回的表名默认都是大写的，统一转换为小写
+func (db *dbOracle) Tables() (tables []string, err error) {
+	return db.getTablesWithCache(func() ([]string, error) {
+		result, err := db.GetAll(`SELECT TABLE_NAME FROM USER_TABLES ORDER BY TABLE_NAME`)
 		if err != nil {
-			return nil
+			return nil, err
 		}
+		tables := make([]string, len(result))
+		for i, m := range result {
+			tables[i] = strings.ToLower(m["TABLE_NAME"].String())
+		}
+		return tables, nil
+	})
+}
 
-		fields = make(map[string]string)
-		for _, m := range result {
-			fields[strings.ToLower(m["FIELD"].String())] = strings.ToLower(m["TYPE"].String()) //ORACLE返回的值默认都是大写的，需要转为小写
+// 获得指定表的字段结构信息(基于USER_TAB_COLUMNS等数据字典视图)，ORACLE返回的值默认都是大写的，字段名称及类型统一转换为小写
+func (db *dbOracle) TableFields(table string) (fields map[string]*TableField, err error) {
+	return db.getTableFieldsWithCache(table, func() (map[string]*TableField, error) {
+		result, err := db.GetAll(fmt.Sprintf(`
+		SELECT c.COLUMN_NAME AS FIELD, CASE c.DATA_TYPE
+			WHEN 'NUMBER' THEN c.DATA_TYPE||'('||c.DATA_PRECISION||','||c.DATA_SCALE||')'
+			WHEN 'FLOAT' THEN c.DATA_TYPE||'('||c.DATA_PRECISION||','||c.DATA_SCALE||')'
+			ELSE c.DATA_TYPE||'('||c.DATA_LENGTH||')' END AS TYPE,
+			c.NULLABLE AS NULLABLE,
+			c.DATA_DEFAULT AS DEFAULT_VALUE,
+			(SELECT MIN(CASE k.CONSTRAINT_TYPE WHEN 'P' THEN 'PRI' WHEN 'U' THEN 'UNI' ELSE 'MUL' END)
+				FROM USER_CONS_COLUMNS u INNER JOIN USER_CONSTRAINTS k ON u.CONSTRAINT_NAME=k.CONSTRAINT_NAME
+				WHERE u.TABLE_NAME=c.TABLE_NAME AND u.COLUMN_NAME=c.COLUMN_NAME AND k.CONSTRAINT_TYPE IN ('P','U','R')) AS KEY_TYPE,
+			m.COMMENTS AS COMMENTS
+		FROM USER_TAB_COLUMNS c
+		LEFT JOIN USER_COL_COMMENTS m ON m.TABLE_NAME=c.TABLE_NAME AND m.COLUMN_NAME=c.COLUMN_NAME
+		WHERE c.TABLE_NAME = '%s' ORDER BY c.COLUMN_ID`, strings.ToUpper(table)))
+		if err != nil {
+			return nil, err
 		}
-		return fields
-	}, 0)
-	if err == nil {
-		fields = v.(map[string]string)
-	}
-	return
+		fields := make(map[string]*TableField, len(result))
+		for i, m := range result {
+			name := strings.ToLower(m["FIELD"].String())
+			fields[name] = &TableField{
+				Index:   i,
+				Name:    name,
+				Type:    strings.ToLower(m["TYPE"].String()),
+				Null:    m["NULLABLE"].String() == "Y",
+				Key:     m["KEY_TYPE"].String(),
+				Default: m["DEFAULT_VALUE"].Val(),
+				Comment: m["COMMENTS"].String(),
+			}
+		}
+		return fields, nil
+	})
 }
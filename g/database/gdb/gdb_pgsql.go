@@ -11,6 +11,7 @@ import (
     "fmt"
     "regexp"
     "database/sql"
+    "strings"
 )
 
 // PostgreSQL的适配.
@@ -52,4 +53,63 @@ func (db *dbPgsql) handleSqlBeforeExec(query string) string {
         return fmt.Sprintf("$%d", index)
     })
     return str
-}
\ No newline at end of file
+}
+
+// 获取当前schema下的所有数据表名称
+func (db *dbPgsql) Tables() (tables []string, err error) {
+    return db.getTablesWithCache(func() ([]string, error) {
+        result, err := db.GetAll(`SELECT tablename FROM pg_tables WHERE schemaname = current_schema() ORDER BY tablename`)
+        if err != nil {
+            return nil, err
+        }
+        tables := make([]string, len(result))
+        for i, m := range result {
+            tables[i] = m["tablename"].String()
+        }
+        return tables, nil
+    })
+}
+
+// 获得指定表的字段结构信息(基于information_schema及pg_catalog)
+func (db *dbPgsql) TableFields(table string) (fields map[string]*TableField, err error) {
+    return db.getTableFieldsWithCache(table, func() (map[string]*TableField, error) {
+        result, err := db.GetAll(`
+        SELECT c.column_name AS field,
+            CASE WHEN c.character_maximum_length IS NOT NULL
+                THEN c.data_type || '(' || c.character_maximum_length || ')'
+                ELSE c.data_type END AS type,
+            c.is_nullable AS nullable,
+            c.column_default AS default_value,
+            (SELECT CASE MIN(CASE tc.constraint_type WHEN 'PRIMARY KEY' THEN 1 WHEN 'UNIQUE' THEN 2 ELSE 3 END)
+                WHEN 1 THEN 'PRI' WHEN 2 THEN 'UNI' WHEN 3 THEN 'MUL' END
+                FROM information_schema.key_column_usage k
+                INNER JOIN information_schema.table_constraints tc
+                ON k.constraint_name = tc.constraint_name AND k.table_schema = tc.table_schema
+                WHERE k.table_schema = c.table_schema AND k.table_name = c.table_name AND k.column_name = c.column_name) AS key_type,
+            col_description((quote_ident(c.table_schema) || '.' || quote_ident(c.table_name))::regclass, c.ordinal_position) AS comment
+        FROM information_schema.columns c
+        WHERE c.table_schema = current_schema() AND c.table_name = ?
+        ORDER BY c.ordinal_position`, table)
+        if err != nil {
+            return nil, err
+        }
+        fields := make(map[string]*TableField, len(result))
+        for i, m := range result {
+            extra := ""
+            if strings.HasPrefix(m["default_value"].String(), "nextval(") {
+                extra = "auto_increment"
+            }
+            fields[m["field"].String()] = &TableField {
+                Index   : i,
+                Name    : m["field"].String(),
+                Type    : m["type"].String(),
+                Null    : strings.EqualFold(m["nullable"].String(), "YES"),
+                Key     : m["key_type"].String(),
+                Default : m["default_value"].Val(),
+                Extra   : extra,
+                Comment : m["comment"].String(),
+            }
+        }
+        return fields, nil
+    })
+}
@@ -9,6 +9,8 @@ package gdb
 
 import (
 	"database/sql"
+	"fmt"
+	"strings"
 )
 
 // 使用时需要import:
@@ -46,4 +48,46 @@ func (db *dbSqlite) getChars () (charLeft string, charRight string) {
 // @todo 将ON DUPLICATE KEY UPDATE触发器修改为两条SQL语句(INSERT OR IGNORE & UPDATE)
 func (db *dbSqlite) handleSqlBeforeExec(query string) string {
 	return query
-}
\ No newline at end of file
+}
+
+// 获取当前数据库的所有数据表名称
+func (db *dbSqlite) Tables() (tables []string, err error) {
+	return db.getTablesWithCache(func() ([]string, error) {
+		result, err := db.GetAll(`SELECT name FROM sqlite_master WHERE type='table' AND name NOT LIKE 'sqlite_%' ORDER BY name`)
+		if err != nil {
+			return nil, err
+		}
+		tables := make([]string, len(result))
+		for i, m := range result {
+			tables[i] = m["name"].String()
+		}
+		return tables, nil
+	})
+}
+
+// 获得指定表的字段结构信息(基于PRAGMA table_info)，sqlite不支持字段注释
+func (db *dbSqlite) TableFields(table string) (fields map[string]*TableField, err error) {
+	return db.getTableFieldsWithCache(table, func() (map[string]*TableField, error) {
+		charL, charR := db.getChars()
+		result, err  := db.GetAll(fmt.Sprintf(`PRAGMA TABLE_INFO(%s%s%s)`, charL, table, charR))
+		if err != nil {
+			return nil, err
+		}
+		fields := make(map[string]*TableField, len(result))
+		for i, m := range result {
+			key := ""
+			if m["pk"].Int() > 0 {
+				key = "PRI"
+			}
+			fields[m["name"].String()] = &TableField {
+				Index   : i,
+				Name    : m["name"].String(),
+				Type    : strings.ToLower(m["type"].String()),
+				Null    : m["notnull"].Int() == 0,
+				Key     : key,
+				Default : m["dflt_value"].Val(),
+			}
+		}
+		return fields, nil
+	})
+}
@@ -13,15 +13,22 @@ import (
     "strings"
 )
 
-/*
-// 同步数据库表结构到内存中
-func (bs *dbBase) syncTableStructure() {
-    bs.tables = make(map[string]map[string]string)
-    for _, table := range bs.db.getTables() {
-        bs.tables[table], _ = bs.db.getTableFields(table)
-    }
+// 数据表字段结构信息
+type TableField struct {
+    Index   int         // 字段在表中的顺序(从0开始)
+    Name    string      // 字段名称
+    Type    string      // 字段类型
+    Null    bool        // 是否允许为NULL
+    Key     string      // 索引类型(PRI:主键, UNI:唯一索引, MUL:普通索引)
+    Default interface{} // 默认值，nil表示没有默认值
+    Extra   string      // 其他信息(例如auto_increment)
+    Comment string      // 字段注释
 }
-*/
+
+const (
+    gTABLES_CACHE_PREFIX       = "tables_"       // 数据表列表缓存键名前缀
+    gTABLE_FIELDS_CACHE_PREFIX = "table_fields_" // 数据表字段结构缓存键名前缀
+)
 
 // 字段类型转换，将数据库字段类型转换为golang变量类型
 func (bs *dbBase) convertValue(fieldValue interface{}, fieldType string) interface{} {
@@ -79,41 +86,100 @@ func (bs *dbBase) filterFields(table string, data map[string]interface{}) map[st
     return data
 }
 
-// 获得指定表表的数据结构，构造成map哈希表返回，其中键名为表字段名称，键值暂无用途(默认为字段数据类型).
+// 获得指定表表的数据结构，构造成map哈希表返回，其中键名为表字段名称，键值为字段数据类型.
 func (bs *dbBase) getTableFields(table string) (fields map[string]string, err error) {
-    // 缓存不存在时会查询数据表结构，缓存后不过期，直至程序重启(重新部署)
-    v := bs.cache.GetOrSetFunc("table_fields_" + table, func() interface{} {
-        result       := (Result)(nil)
+    tableFields, err := bs.db.TableFields(table)
+    if err != nil {
+        return nil, err
+    }
+    fields = make(map[string]string, len(tableFields))
+    for k, v := range tableFields {
+        fields[k] = v.Type
+    }
+    return
+}
+
+// 获取当前数据库的所有数据表名称(MySQL)
+func (bs *dbBase) Tables() (tables []string, err error) {
+    return bs.getTablesWithCache(func() ([]string, error) {
+        result, err := bs.db.GetAll(`SHOW TABLES`)
+        if err != nil {
+            return nil, err
+        }
+        tables := make([]string, len(result))
+        for i, m := range result {
+            for _, v := range m {
+                tables[i] = v.String()
+                break
+            }
+        }
+        return tables, nil
+    })
+}
+
+// 获取指定数据表的字段结构信息(MySQL)，键名为字段名称
+func (bs *dbBase) TableFields(table string) (fields map[string]*TableField, err error) {
+    return bs.getTableFieldsWithCache(table, func() (map[string]*TableField, error) {
         charL, charR := bs.db.getChars()
-        result, err   = bs.GetAll(fmt.Sprintf(`SHOW COLUMNS FROM %s%s%s`, charL, table, charR))
+        result, err  := bs.db.GetAll(fmt.Sprintf(`SHOW FULL COLUMNS FROM %s%s%s`, charL, table, charR))
         if err != nil {
+            return nil, err
+        }
+        fields := make(map[string]*TableField, len(result))
+        for i, m := range result {
+            fields[m["Field"].String()] = &TableField {
+                Index   : i,
+                Name    : m["Field"].String(),
+                Type    : m["Type"].String(),
+                Null    : strings.EqualFold(m["Null"].String(), "YES"),
+                Key     : m["Key"].String(),
+                Default : m["Default"].Val(),
+                Extra   : m["Extra"].String(),
+                Comment : m["Comment"].String(),
+            }
+        }
+        return fields, nil
+    })
+}
+
+// 获取数据表列表，结果按照当前数据库名称缓存，直到数据库结构发生变化(执行DDL语句)
+func (bs *dbBase) getTablesWithCache(f func() ([]string, error)) (tables []string, err error) {
+    v := bs.cache.GetOrSetFunc(gTABLES_CACHE_PREFIX + bs.schema.Val(), func() interface{} {
+        if tables, err = f(); err != nil {
             return nil
         }
-        fields = make(map[string]string)
-        for _, m := range result {
-            fields[m["Field"].String()] = m["Type"].String()
+        return tables
+    }, 0)
+    if err == nil && v != nil {
+        tables = v.([]string)
+    }
+    return
+}
+
+// 获取数据表字段结构，结果按照当前数据库名称及表名缓存，直到数据库结构发生变化(执行DDL语句)
+func (bs *dbBase) getTableFieldsWithCache(table string, f func() (map[string]*TableField, error)) (fields map[string]*TableField, err error) {
+    v := bs.cache.GetOrSetFunc(gTABLE_FIELDS_CACHE_PREFIX + bs.schema.Val() + "_" + table, func() interface{} {
+        if fields, err = f(); err != nil {
+            return nil
         }
         return fields
     }, 0)
-    if err == nil {
-        fields = v.(map[string]string)
+    if err == nil && v != nil {
+        fields = v.(map[string]*TableField)
     }
     return
 }
 
-/*
-// 获取当前数据库所有的表结构
-func (bs *dbBase) getTables() []string {
-    if result, _ := bs.GetAll(`SHOW TABLES`); result != nil {
-        array := make([]string, len(result))
-        for i, m := range result {
-            for _, v := range m {
-                array[i] = v.String()
-                break
-            }
+// 清除数据库结构缓存，在执行DDL语句或者切换数据库之后调用
+func (bs *dbBase) clearSchemaCache() {
+    for _, key := range bs.cache.KeyStrings() {
+        if strings.HasPrefix(key, gTABLES_CACHE_PREFIX) || strings.HasPrefix(key, gTABLE_FIELDS_CACHE_PREFIX) {
+            bs.cache.Remove(key)
         }
-        return array
     }
-    return nil
 }
-*/
\ No newline at end of file
+
+// 判断SQL语句是否会修改数据库结构(DDL)
+func isSchemaChangeSql(query string) bool {
+    return gregex.IsMatchString(`(?i)^\s*(CREATE|ALTER|DROP|RENAME)\s`, query)
+}
@@ -9,6 +9,7 @@ package gdb
 import (
     "database/sql"
     "fmt"
+    "github.com/gogf/gf/g/container/gset"
     "github.com/gogf/gf/g/text/gregex"
     "reflect"
 )
@@ -18,11 +19,19 @@ type TX struct {
     db     DB
     tx     *sql.Tx
     master *sql.DB
+    tables *gset.StringSet // 事务中执行过写操作的数据表，提交后清除其关联的查询缓存
 }
 
 // 事务操作，提交
 func (tx *TX) Commit() error {
-    return tx.tx.Commit()
+    err := tx.tx.Commit()
+    if err == nil {
+        tx.tables.Iterator(func(table string) bool {
+            tx.db.removeTableCache(table)
+            return true
+        })
+    }
+    return err
 }
 
 // 事务操作，回滚
@@ -35,8 +44,11 @@ func (tx *TX) Query(query string, args ...interface{}) (rows *sql.Rows, err erro
     return tx.db.doQuery(tx.tx, query, args...)
 }
 
-// (事务)执行一条sql，并返回执行情况，主要用于非查询操作
+// (事务)执行一条sql，并返回执行情况，主要用于非查询操作，写操作的目标数据表在事务提交之后清除其关联的查询缓存
 func (tx *TX) Exec(query string, args ...interface{}) (sql.Result, error) {
+    if table := parseExecTables(query); table != "" {
+        tx.tables.Add(table)
+    }
     return tx.db.doExec(tx.tx, query, args...)
 }
 
@@ -133,31 +145,37 @@ func (tx *TX) GetCount(query string, args ...interface{}) (int, error) {
 
 // CURD操作:单条数据写入, 仅仅执行写入操作，如果存在冲突的主键或者唯一索引，那么报错返回
 func (tx *TX) Insert(table string, data interface{}, batch...int) (sql.Result, error) {
+    tx.tables.Add(table)
     return tx.db.doInsert(tx.tx, table, data, OPTION_INSERT, batch...)
 }
 
 // CURD操作:单条数据写入, 如果数据存在(主键或者唯一索引)，那么删除后重新写入一条
 func (tx *TX) Replace(table string, data interface{}, batch...int) (sql.Result, error) {
+    tx.tables.Add(table)
     return tx.db.doInsert(tx.tx, table, data, OPTION_REPLACE, batch...)
 }
 
 // CURD操作:单条数据写入, 如果数据存在(主键或者唯一索引)，那么更新，否则写入一条新数据
 func (tx *TX) Save(table string, data interface{}, batch...int) (sql.Result, error) {
+    tx.tables.Add(table)
     return tx.db.doInsert(tx.tx, table, data, OPTION_SAVE, batch...)
 }
 
 // CURD操作:批量数据指定批次量写入
 func (tx *TX) BatchInsert(table string, list interface{}, batch...int) (sql.Result, error) {
+    tx.tables.Add(table)
     return tx.db.doBatchInsert(tx.tx, table, list, OPTION_INSERT, batch...)
 }
 
 // CURD操作:批量数据指定批次量写入, 如果数据存在(主键或者唯一索引)，那么删除后重新写入一条
 func (tx *TX) BatchReplace(table string, list interface{}, batch...int) (sql.Result, error) {
+    tx.tables.Add(table)
     return tx.db.doBatchInsert(tx.tx, table, list, OPTION_REPLACE, batch...)
 }
 
 // CURD操作:批量数据指定批次量写入, 如果数据存在(主键或者唯一索引)，那么更新，否则写入一条新数据
 func (tx *TX) BatchSave(table string, list interface{}, batch...int) (sql.Result, error) {
+    tx.tables.Add(table)
     return tx.db.doBatchInsert(tx.tx, table, list, OPTION_SAVE, batch...)
 }
 
@@ -170,6 +188,7 @@ func (tx *TX) Update(table string, data interface{}, condition interface{}, args
 
 // 与Update方法的区别是不处理条件参数
 func (tx *TX) doUpdate(table string, data interface{}, condition string, args ...interface{}) (sql.Result, error) {
+    tx.tables.Add(table)
     return tx.db.doUpdate(tx.tx, table, data, condition, args ...)
 }
 
@@ -181,6 +200,7 @@ func (tx *TX) Delete(table string, condition interface{}, args ...interface{}) (
 
 // 与Delete方法的区别是不处理条件参数
 func (tx *TX) doDelete(table string, condition string, args ...interface{}) (sql.Result, error) {
+    tx.tables.Add(table)
     return tx.db.doDelete(tx.tx, table, condition, args ...)
 }
 
@@ -0,0 +1,315 @@
+// Copyright 2019 gf Author(https://github.com/gogf/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package gdb
+
+import (
+    "database/sql"
+    "errors"
+    "fmt"
+    "github.com/gogf/gf/g/container/gvar"
+    "github.com/gogf/gf/g/text/gregex"
+    "reflect"
+    "sort"
+    "strings"
+)
+
+// 结果集流式迭代对象，每次只在内存中保留当前一条记录，
+// 适用于大结果集的遍历处理，使用完毕后必须调用Close方法释放底层链接。
+// 开启查询缓存时迭代的是缓存的结果集(此时结果集完整保存在内存中)。
+type Iterator struct {
+    db       DB             // 所属数据库对象
+    rows     *sql.Rows      // 底层结果集(迭代缓存结果集时为nil)
+    result   Result         // 查询缓存的结果集
+    index    int            // 当前记录在result中的索引
+    columns  []string       // 字段名称列表
+    types    []string       // 字段数据库类型列表
+    values   []sql.RawBytes // 当前记录的原始数据(每次Next时复用)
+    scanArgs []interface{}  // values对应的Scan参数
+    err      error          // 迭代过程中产生的错误
+}
+
+// 根据*sql.Rows创建迭代对象
+func newIterator(db DB, rows *sql.Rows) (*Iterator, error) {
+    columnTypes, err := rows.ColumnTypes()
+    if err != nil {
+        rows.Close()
+        return nil, err
+    }
+    it := &Iterator {
+        db       : db,
+        rows     : rows,
+        columns  : make([]string, len(columnTypes)),
+        types    : make([]string, len(columnTypes)),
+        values   : make([]sql.RawBytes, len(columnTypes)),
+        scanArgs : make([]interface{}, len(columnTypes)),
+    }
+    for i, t := range columnTypes {
+        it.types[i]    = t.DatabaseTypeName()
+        it.columns[i]  = t.Name()
+        it.scanArgs[i] = &it.values[i]
+    }
+    return it, nil
+}
+
+// 根据查询缓存的结果集创建迭代对象
+func newResultIterator(db DB, result Result) *Iterator {
+    it := &Iterator {
+        db     : db,
+        result : result,
+        index  : -1,
+    }
+    if len(result) > 0 {
+        it.columns = make([]string, 0, len(result[0]))
+        for k := range result[0] {
+            it.columns = append(it.columns, k)
+        }
+        sort.Strings(it.columns)
+    }
+    return it
+}
+
+// 返回结果集的字段名称列表(迭代缓存结果集时按照名称排序)
+func (it *Iterator) Columns() []string {
+    return it.columns
+}
+
+// 移动到下一条记录，没有更多记录或者发生错误时返回false。
+func (it *Iterator) Next() bool {
+    if it.err != nil {
+        return false
+    }
+    if it.rows == nil {
+        if it.index < len(it.result) {
+            it.index++
+        }
+        return it.index < len(it.result)
+    }
+    if !it.rows.Next() {
+        return false
+    }
+    if err := it.rows.Scan(it.scanArgs...); err != nil {
+        it.err = err
+        return false
+    }
+    return true
+}
+
+// 将当前记录转换为Record返回，返回的Record与迭代对象不共享内存。
+func (it *Iterator) Record() Record {
+    if it.rows == nil {
+        record := make(Record, len(it.columns))
+        for k, v := range it.result[it.index] {
+            record[k] = v
+        }
+        return record
+    }
+    record := make(Record, len(it.columns))
+    for i, col := range it.values {
+        if col == nil {
+            record[it.columns[i]] = gvar.New(nil, true)
+        } else {
+            // 由于 sql.RawBytes 在下一次Scan时会被覆盖, 这里必须使用值复制
+            v := make([]byte, len(col))
+            copy(v, col)
+            record[it.columns[i]] = gvar.New(it.db.convertValue(v, it.types[i]), true)
+        }
+    }
+    return record
+}
+
+// 将当前记录直接映射到给定的struct对象中(不经过中间Record转换)，参数必须为struct指针。
+func (it *Iterator) Struct(objPointer interface{}) error {
+    rv, ok := objPointer.(reflect.Value)
+    if !ok {
+        rv = reflect.ValueOf(objPointer)
+    }
+    if rv.Kind() != reflect.Ptr || rv.IsNil() {
+        return fmt.Errorf("params should be a non-nil pointer to struct, but got: %v", rv.Kind())
+    }
+    elem := rv.Elem()
+    if elem.Kind() != reflect.Struct {
+        return fmt.Errorf("params should be a pointer to struct, but got pointer to: %v", elem.Kind())
+    }
+    if it.rows == nil {
+        return mapToStructByFields(it.result[it.index].ToMap(), elem)
+    }
+    fields := getStructFields(elem.Type())
+    for i, column := range it.columns {
+        field, ok := fields.lookup(column)
+        if !ok {
+            continue
+        }
+        fv := fieldByIndex(elem, field.index, true)
+        if !fv.IsValid() {
+            continue
+        }
+        if err := bindValueToField(it.db, fv, field, it.values[i], it.types[i]); err != nil {
+            return fmt.Errorf(`scan column "%s" failed: %s`, column, err.Error())
+        }
+    }
+    return nil
+}
+
+// 返回迭代过程中产生的错误
+func (it *Iterator) Err() error {
+    if it.err != nil {
+        return it.err
+    }
+    if it.rows == nil {
+        return nil
+    }
+    return it.rows.Err()
+}
+
+// 关闭迭代对象，释放底层链接
+func (it *Iterator) Close() error {
+    if it.rows == nil {
+        return nil
+    }
+    return it.rows.Close()
+}
+
+// 链式操作，返回流式迭代对象，使用完毕后必须调用Iterator.Close方法。
+// 开启查询缓存时与All使用相同的缓存处理，迭代的是(可能来自缓存的)完整结果集。
+func (md *Model) Iterator() (*Iterator, error) {
+    if md.cacheEnabled {
+        result, err := md.All()
+        if err != nil {
+            return nil, err
+        }
+        return newResultIterator(md.db, result), nil
+    }
+    var rows *sql.Rows
+    var err  error
+    if md.tx != nil {
+        rows, err = md.tx.Query(md.getFormattedSql(), md.whereArgs...)
+    } else {
+        link, e := md.getLink()
+        if e != nil {
+            return nil, e
+        }
+        rows, err = md.db.doQuery(link, md.getFormattedSql(), md.whereArgs...)
+    }
+    if err != nil {
+        return nil, err
+    }
+    return newIterator(md.db, rows)
+}
+
+// 链式操作，流式遍历查询结果，每条记录调用一次回调函数，回调函数返回false时终止遍历。
+// 内存中只保留当前遍历的记录，适用于大结果集的处理。
+func (md *Model) Each(f func(record Record) bool) error {
+    it, err := md.Iterator()
+    if err != nil {
+        return err
+    }
+    defer it.Close()
+    for it.Next() {
+        if !f(it.Record()) {
+            break
+        }
+    }
+    return it.Err()
+}
+
+// 链式操作，查询多条记录，并直接扫描到指定的slice对象中(不经过中间Record转换), 如: []struct/[]*struct。
+func (md *Model) ScanStructs(objPointerSlice interface{}) error {
+    rv := reflect.ValueOf(objPointerSlice)
+    if rv.Kind() != reflect.Ptr || rv.Elem().Kind() != reflect.Slice {
+        return fmt.Errorf("params should be a pointer to slice, but got: %v", rv.Kind())
+    }
+    var (
+        slice    = rv.Elem()
+        itemType = slice.Type().Elem()
+        isPtr    = itemType.Kind() == reflect.Ptr
+    )
+    if isPtr {
+        itemType = itemType.Elem()
+    }
+    if itemType.Kind() != reflect.Struct {
+        return fmt.Errorf("slice element should be type of struct/*struct, but got: %v", itemType.Kind())
+    }
+    it, err := md.Iterator()
+    if err != nil {
+        return err
+    }
+    defer it.Close()
+    result := reflect.MakeSlice(slice.Type(), 0, 0)
+    for it.Next() {
+        e := reflect.New(itemType)
+        if err := it.Struct(e); err != nil {
+            return err
+        }
+        if isPtr {
+            result = reflect.Append(result, e)
+        } else {
+            result = reflect.Append(result, e.Elem())
+        }
+    }
+    if err := it.Err(); err != nil {
+        return err
+    }
+    slice.Set(result)
+    return nil
+}
+
+// 基于主键(或任意唯一递增字段)的组块结果集(keyset分页)。
+// 与Chunk使用OFFSET分页不同，每一批次通过 column > 上一批次最后值 的条件查询，
+// 查询性能不随分页深度下降，并且在数据变化时不会跳过记录。
+// 注意column字段值必须唯一且可排序，该方法会覆盖模型的OrderBy/Limit设置。
+func (md *Model) ChunkByID(column string, size int, callback func(result Result, err error) bool) {
+    if size <= 0 {
+        callback(nil, errors.New("chunk size should be greater than 0"))
+        return
+    }
+    charL, charR := md.db.getChars()
+    quoted       := column
+    if gregex.IsMatchString(`^\w+$`, column) {
+        quoted = charL + column + charR
+    }
+    // 结果集中的字段名称不包含表名前缀
+    key := column
+    if pos := strings.LastIndex(column, "."); pos >= 0 {
+        key = column[pos + 1:]
+    }
+    lastValue := (interface{})(nil)
+    for {
+        model          := md.Clone()
+        model.safe      = false
+        model.whereArgs = append([]interface{}{}, md.whereArgs...)
+        // 原有条件需要整体包裹后再追加分页条件，
+        // 否则 (a) OR (b) 形式的条件追加后会变为 (a) OR (b) AND (column>?)
+        if lastValue != nil {
+            if md.where != "" {
+                model.where = fmt.Sprintf("(%s) AND (%s>?)", md.where, quoted)
+            } else {
+                model.where = quoted + ">?"
+            }
+            model.whereArgs = append(model.whereArgs, lastValue)
+        }
+        data, err := model.OrderBy(quoted + " ASC").Limit(0, size).All()
+        if err != nil {
+            callback(nil, err)
+            break
+        }
+        if len(data) == 0 {
+            break
+        }
+        v, ok := data[len(data) - 1][key]
+        if !ok {
+            callback(nil, fmt.Errorf(`chunk column "%s" not found in result`, column))
+            break
+        }
+        lastValue = v.Val()
+        if callback(data, err) == false {
+            break
+        }
+        if len(data) < size {
+            break
+        }
+    }
+}
@@ -0,0 +1,79 @@
+// Copyright 2019 gf Author(https://github.com/gogf/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package gdb
+
+import (
+    "github.com/gogf/gf/g/database/gredis"
+    "github.com/gogf/gf/g/test/gtest"
+    "testing"
+)
+
+func Test_parseTableNames(t *testing.T) {
+    gtest.Case(t, func() {
+        gtest.Assert(parseTableNames("user"),                     []string{"user"})
+        gtest.Assert(parseTableNames("`user` u"),                 []string{"user"})
+        gtest.Assert(parseTableNames("user u, user_detail ud"),   []string{"user", "user_detail"})
+        gtest.Assert(parseTableNames(`"public"."user" AS u`),     []string{"public.user"})
+        gtest.Assert(parseTableNames("[dbo].[user]"),             []string{"dbo.user"})
+        gtest.Assert(parseTableNames("user u LEFT JOIN user_detail ud ON (u.id=ud.uid, 1)"), []string{"user", "user_detail"})
+        gtest.Assert(parseTableNames("user u left outer join a ON(u.id=a.uid) INNER JOIN b ON(a.id=b.aid) join c on (c.id=b.cid)"),
+            []string{"user", "a", "b", "c"})
+        gtest.Assert(parseTableNames(" "), []string{})
+    })
+}
+
+func Test_parseExecTables(t *testing.T) {
+    gtest.Case(t, func() {
+        gtest.Assert(parseExecTables("INSERT INTO user(id) VALUES(1)"),              "user")
+        gtest.Assert(parseExecTables("insert ignore `user` SET id=1"),               "`user`")
+        gtest.Assert(parseExecTables("REPLACE INTO user VALUES(1)"),                 "user")
+        gtest.Assert(parseExecTables("UPDATE user SET nickname='a' WHERE id=1"),     "user")
+        gtest.Assert(parseTableNames(parseExecTables("UPDATE user u LEFT JOIN user_detail ud ON (u.id=ud.uid)\nSET u.nickname='a'")),
+            []string{"user", "user_detail"})
+        gtest.Assert(parseExecTables("DELETE FROM user WHERE id=1"),                 "user")
+        gtest.Assert(parseExecTables("delete from user"),                            "user")
+        gtest.Assert(parseExecTables("TRUNCATE TABLE user;"),                        "user")
+        gtest.Assert(parseExecTables("SELECT * FROM user"),                          "")
+        gtest.Assert(parseExecTables("CREATE TABLE user(id int)"),                   "")
+    })
+}
+
+func Test_NewRedisCacheAdapter_Prefix(t *testing.T) {
+    gtest.Case(t, func() {
+        gtest.Assert(NewRedisCacheAdapter(nil).(*redisCacheAdapter).prefix,                  "{gdb:cache}:")
+        gtest.Assert(NewRedisCacheAdapter(nil, "app:cache:").(*redisCacheAdapter).prefix,    "{app:cache:}")
+        gtest.Assert(NewRedisCacheAdapter(nil, "{app}:cache:").(*redisCacheAdapter).prefix,  "{app}:cache:")
+        // 缓存项与数据表关联集合位于同一个哈希槽
+        c := NewRedisCacheAdapter(nil, "app:").(*redisCacheAdapter)
+        gtest.Assert(gredis.Slot(c.prefix + "key"), gredis.Slot(c.tableKey("user")))
+    })
+}
+
+func Test_memoryCacheAdapter_Index(t *testing.T) {
+    gtest.Case(t, func() {
+        c := NewMemoryCacheAdapter().(*memoryCacheAdapter)
+        gtest.Assert(c.Set("k1", Result{}, 0, []string{"user", "order"}), nil)
+        gtest.Assert(c.Set("k2", Result{}, 0, []string{"user"}),          nil)
+        gtest.Assert(len(c.tables["user"]),  2)
+        gtest.Assert(len(c.tables["order"]), 1)
+
+        // 覆盖缓存项时更新关联关系
+        gtest.Assert(c.Set("k1", Result{}, 0, []string{"user"}), nil)
+        gtest.Assert(len(c.tables["user"]), 2)
+        _, ok := c.tables["order"]
+        gtest.Assert(ok, false)
+
+        // 删除缓存项时删除关联关系
+        gtest.Assert(c.Remove("k1"), nil)
+        gtest.Assert(len(c.tables["user"]), 1)
+
+        gtest.Assert(c.RemoveTables("user"), nil)
+        gtest.Assert(len(c.tables), 0)
+        _, ok, _ = c.Get("k2")
+        gtest.Assert(ok, false)
+    })
+}
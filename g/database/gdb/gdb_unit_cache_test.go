@@ -0,0 +1,208 @@
+// Copyright 2019 gf Author(https://github.com/gogf/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package gdb_test
+
+import (
+    "fmt"
+    "github.com/gogf/gf/g"
+    "github.com/gogf/gf/g/container/gvar"
+    "github.com/gogf/gf/g/database/gdb"
+    "github.com/gogf/gf/g/database/gredis"
+    "github.com/gogf/gf/g/os/gtime"
+    "github.com/gogf/gf/g/test/gtest"
+    "sync"
+    "testing"
+)
+
+func TestModel_Cache_Stats(t *testing.T) {
+    table := createInitTable()
+    defer dropTable(table)
+    gtest.Case(t, func() {
+        stats := db.GetCacheStats()
+        for i := 0; i < 3; i++ {
+            one, err := db.Table(table).Cache(60).Where("id", 1).One()
+            gtest.Assert(err, nil)
+            gtest.Assert(one["passport"].String(), "t1")
+        }
+        gtest.Assert(db.GetCacheStats().Misses - stats.Misses, 1)
+        gtest.Assert(db.GetCacheStats().Hits   - stats.Hits,   2)
+    })
+}
+
+func TestModel_Cache_TableInvalidation(t *testing.T) {
+    table := createInitTable()
+    defer dropTable(table)
+    gtest.Case(t, func() {
+        one, err := db.Table(table).Cache(60).Where("id", 1).One()
+        gtest.Assert(err, nil)
+        gtest.Assert(one["nickname"].String(), "T1")
+
+        _, err = db.Table(table).Data(g.Map{"nickname" : "T100"}).Where("id", 1).Update()
+        gtest.Assert(err, nil)
+
+        one, err = db.Table(table).Cache(60).Where("id", 1).One()
+        gtest.Assert(err, nil)
+        gtest.Assert(one["nickname"].String(), "T100")
+    })
+    gtest.Case(t, func() {
+        count, err := db.Table(table + " u").Cache(60).Count()
+        gtest.Assert(err, nil)
+        gtest.Assert(count, INIT_DATA_SIZE)
+
+        _, err = db.Delete(table, "id", 1)
+        gtest.Assert(err, nil)
+
+        count, err = db.Table(table + " u").Cache(60).Count()
+        gtest.Assert(err, nil)
+        gtest.Assert(count, INIT_DATA_SIZE - 1)
+    })
+    // 原生SQL写操作同样清除查询缓存
+    gtest.Case(t, func() {
+        one, err := db.Table(table).Cache(60).Where("id", 2).One()
+        gtest.Assert(err, nil)
+        gtest.Assert(one["nickname"].String(), "T2")
+
+        _, err = db.Exec(fmt.Sprintf("UPDATE %s SET nickname=? WHERE id=?", table), "T200", 2)
+        gtest.Assert(err, nil)
+
+        one, err = db.Table(table).Cache(60).Where("id", 2).One()
+        gtest.Assert(err, nil)
+        gtest.Assert(one["nickname"].String(), "T200")
+    })
+}
+
+func TestDbBase_SetCacheAdapter(t *testing.T) {
+    table := createInitTable()
+    defer dropTable(table)
+    gtest.Case(t, func() {
+        adapter := db.GetCacheAdapter()
+        defer db.SetCacheAdapter(adapter)
+
+        custom := gdb.NewMemoryCacheAdapter()
+        db.SetCacheAdapter(custom)
+        _, err := db.Table(table).Cache(60, "custom-cache").Where("id", 1).One()
+        gtest.Assert(err, nil)
+
+        result, ok, err := custom.Get("custom-cache")
+        gtest.Assert(err, nil)
+        gtest.Assert(ok,     true)
+        gtest.Assert(len(result), 1)
+
+        gtest.Assert(custom.RemoveTables(table), nil)
+        _, ok, _ = custom.Get("custom-cache")
+        gtest.Assert(ok, false)
+    })
+}
+
+func TestModel_Cache_Transaction(t *testing.T) {
+    table := createInitTable()
+    defer dropTable(table)
+    gtest.Case(t, func() {
+        one, err := db.Table(table).Cache(60).Where("id", 1).One()
+        gtest.Assert(err, nil)
+        gtest.Assert(one["nickname"].String(), "T1")
+
+        tx, err := db.Begin()
+        gtest.Assert(err, nil)
+        _, err = tx.Table(table).Data(g.Map{"nickname" : "T100"}).Where("id", 1).Update()
+        gtest.Assert(err, nil)
+
+        // 事务提交前读取到的仍然是已提交的数据
+        one, err = db.Table(table).Cache(60).Where("id", 1).One()
+        gtest.Assert(err, nil)
+        gtest.Assert(one["nickname"].String(), "T1")
+
+        // 事务提交之后清除查询缓存
+        gtest.Assert(tx.Commit(), nil)
+        one, err = db.Table(table).Cache(60).Where("id", 1).One()
+        gtest.Assert(err, nil)
+        gtest.Assert(one["nickname"].String(), "T100")
+    })
+}
+
+func TestRedisCacheAdapter(t *testing.T) {
+    redis := gredis.New(gredis.Config {
+        Host : "127.0.0.1",
+        Port : 6379,
+        Db   : 1,
+    })
+    defer redis.Close()
+    prefix  := fmt.Sprintf("{gdb:test:%d}:", gtime.Nanosecond())
+    adapter := gdb.NewRedisCacheAdapter(redis, prefix)
+    gtest.Case(t, func() {
+        value := gdb.Result {
+            gdb.Record {
+                "id"     : gvar.New(1, true),
+                "score"  : gvar.New(1.5, true),
+                "name"   : gvar.New("john", true),
+                "avatar" : gvar.New([]byte{0, 1, 255}, true),
+                "remark" : gvar.New(nil, true),
+            },
+        }
+        gtest.Assert(adapter.Set("k1", value,       60000, []string{"user", "order"}), nil)
+        gtest.Assert(adapter.Set("k2", gdb.Result{}, 0,    []string{"user"}),          nil)
+
+        // 字段值保留原始类型
+        result, ok, err := adapter.Get("k1")
+        gtest.Assert(err, nil)
+        gtest.Assert(ok,  true)
+        gtest.Assert(len(result), 1)
+        gtest.Assert(result[0]["id"].Val(),     1)
+        gtest.Assert(result[0]["score"].Val(),  1.5)
+        gtest.Assert(result[0]["name"].Val(),   "john")
+        gtest.Assert(result[0]["avatar"].Val(), []byte{0, 1, 255})
+        gtest.Assert(result[0]["remark"].IsNil(), true)
+
+        result, ok, err = adapter.Get("k2")
+        gtest.Assert(err, nil)
+        gtest.Assert(ok,  true)
+        gtest.Assert(len(result), 0)
+
+        // 关联集合的过期时间不小于其中的缓存项，存在不过期的缓存项时不过期
+        ttl, err := redis.DoVar("PTTL", prefix + "table:order")
+        gtest.Assert(err, nil)
+        gtest.Assert(ttl.Int() > 0, true)
+        ttl, err = redis.DoVar("PTTL", prefix + "table:user")
+        gtest.Assert(err, nil)
+        gtest.Assert(ttl.Int(), -1)
+
+        gtest.Assert(adapter.RemoveTables("order"), nil)
+        _, ok, _ = adapter.Get("k1")
+        gtest.Assert(ok, false)
+        _, ok, _ = adapter.Get("k2")
+        gtest.Assert(ok, true)
+
+        gtest.Assert(adapter.RemoveTables("user"), nil)
+        _, ok, _ = adapter.Get("k2")
+        gtest.Assert(ok, false)
+        n, err := redis.DoVar("EXISTS", prefix + "table:user")
+        gtest.Assert(err, nil)
+        gtest.Assert(n.Int(), 0)
+    })
+    // 并发清除同一数据表的缓存时不会遗漏缓存项
+    gtest.Case(t, func() {
+        wg := sync.WaitGroup{}
+        for i := 0; i < 10; i++ {
+            wg.Add(2)
+            go func(i int) {
+                defer wg.Done()
+                adapter.Set(fmt.Sprintf("c%d", i), gdb.Result{}, 60000, []string{"user"})
+            }(i)
+            go func() {
+                defer wg.Done()
+                adapter.RemoveTables("user")
+            }()
+        }
+        wg.Wait()
+        gtest.Assert(adapter.RemoveTables("user"), nil)
+        for i := 0; i < 10; i++ {
+            _, ok, err := adapter.Get(fmt.Sprintf("c%d", i))
+            gtest.Assert(err, nil)
+            gtest.Assert(ok,  false)
+        }
+    })
+}
@@ -0,0 +1,144 @@
+// Copyright 2019 gf Author(https://github.com/gogf/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package gdb
+
+import (
+    "fmt"
+    "github.com/gogf/gf/g/os/gtime"
+    "github.com/gogf/gf/g/test/gtest"
+    "testing"
+)
+
+// newStubBase creates a database object for a stub cluster, whose nodes listen on no port of local host,
+// so that any connection to them is refused immediately.
+func newStubBase(group string, nodes ConfigGroup) *dbBase {
+    AddConfigGroup(group, nodes)
+    db, err := New(group)
+    if err != nil {
+        panic(err)
+    }
+    return db.(*dbMysql).dbBase
+}
+
+// stubNode creates a stub node configuration with unique port.
+func stubNode(port int, role string, priority int) ConfigNode {
+    return ConfigNode {
+        Host     : "127.0.0.1",
+        Port     : fmt.Sprintf("%d", port),
+        User     : "stub",
+        Charset  : "utf8",
+        Type     : "mysql",
+        Role     : role,
+        Priority : priority,
+    }
+}
+
+func Test_selectNode_RoundRobin(t *testing.T) {
+    gtest.Case(t, func() {
+        bs := newStubBase("stub_round_robin", ConfigGroup {
+            stubNode(1, "master", 1),
+            stubNode(2, "slave",  1),
+            stubNode(3, "slave",  1),
+            stubNode(4, "slave",  1),
+        })
+        bs.SetBalanceStrategy(BALANCE_ROUND_ROBIN)
+        ports := make([]string, 0)
+        for i := 0; i < 6; i++ {
+            node, err := bs.selectNode(false)
+            gtest.Assert(err, nil)
+            ports = append(ports, node.Port)
+        }
+        gtest.Assert(ports, []string{"2", "3", "4", "2", "3", "4"})
+        for i := 0; i < 3; i++ {
+            node, err := bs.selectNode(true)
+            gtest.Assert(err, nil)
+            gtest.Assert(node.Port, "1")
+        }
+    })
+}
+
+func Test_selectNode_Weighted(t *testing.T) {
+    gtest.Case(t, func() {
+        bs := newStubBase("stub_weighted", ConfigGroup {
+            stubNode(11, "master", 1),
+            stubNode(12, "slave",  1),
+            stubNode(13, "slave",  3),
+        })
+        counts := make(map[string]int)
+        for i := 0; i < 4000; i++ {
+            node, err := bs.selectNode(false)
+            gtest.Assert(err, nil)
+            counts[node.Port]++
+        }
+        gtest.Assert(counts["11"], 0)
+        // The expected counts are 1000 and 3000.
+        gtest.Assert(counts["12"] > 800  && counts["12"] < 1200, true)
+        gtest.Assert(counts["13"] > 2800 && counts["13"] < 3200, true)
+    })
+}
+
+func Test_selectNode_Ejection(t *testing.T) {
+    gtest.Case(t, func() {
+        nodes := ConfigGroup {
+            stubNode(21, "master", 1),
+            stubNode(22, "slave",  1),
+            stubNode(23, "slave",  1),
+        }
+        bs := newStubBase("stub_ejection", nodes)
+        bs.SetBalanceStrategy(BALANCE_ROUND_ROBIN)
+        health := getNodeHealth(&nodes[1])
+        // The node is ejected after continuous failures.
+        for i := 0; i < gDEFAULT_HEALTH_MAX_FAILS; i++ {
+            gtest.Assert(health.available(), true)
+            health.fail()
+        }
+        gtest.Assert(health.available(), false)
+        until := health.ejectedUntil.Val() - gtime.Millisecond()
+        gtest.Assert(until > gDEFAULT_HEALTH_EJECT_TIME - 1000 && until <= gDEFAULT_HEALTH_EJECT_TIME, true)
+        for i := 0; i < 4; i++ {
+            node, err := bs.selectNode(false)
+            gtest.Assert(err, nil)
+            gtest.Assert(node.Port, "23")
+        }
+        // The node is re-admitted after the ejection time.
+        health.ejectedUntil.Set(gtime.Millisecond() - 1)
+        ports := make(map[string]bool)
+        for i := 0; i < 4; i++ {
+            node, err := bs.selectNode(false)
+            gtest.Assert(err, nil)
+            ports[node.Port] = true
+        }
+        gtest.Assert(ports, map[string]bool{"22" : true, "23" : true})
+    })
+    // All the nodes are used if all of them are ejected.
+    gtest.Case(t, func() {
+        nodes := ConfigGroup {
+            stubNode(31, "master", 1),
+            stubNode(32, "master", 1),
+        }
+        bs := newStubBase("stub_ejection_all", nodes)
+        for i := 0; i < gDEFAULT_HEALTH_MAX_FAILS; i++ {
+            bs.checkNodesHealth()
+        }
+        gtest.Assert(getNodeHealth(&nodes[0]).available(), false)
+        gtest.Assert(getNodeHealth(&nodes[1]).available(), false)
+        node, err := bs.selectNode(true)
+        gtest.Assert(err, nil)
+        gtest.AssertNE(node, nil)
+    })
+}
+
+func Test_doExec_Sticky(t *testing.T) {
+    gtest.Case(t, func() {
+        bs     := newStubBase("stub_sticky", ConfigGroup{stubNode(41, "master", 1)})
+        sticky := bs.Sticky().(*dbMysql).dbBase
+        // Failed writing does not pin the reading to master.
+        _, err := sticky.Exec("UPDATE user SET nickname='stub'")
+        gtest.AssertNE(err, nil)
+        gtest.Assert(sticky.sticky.Val(), false)
+    })
+}
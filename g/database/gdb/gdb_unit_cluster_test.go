@@ -0,0 +1,63 @@
+// Copyright 2019 gf Author(https://github.com/gogf/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package gdb_test
+
+import (
+    "github.com/gogf/gf/g"
+    "github.com/gogf/gf/g/database/gdb"
+    "github.com/gogf/gf/g/test/gtest"
+    "testing"
+    "time"
+)
+
+func TestModel_Master(t *testing.T) {
+    table := createInitTable()
+    defer dropTable(table)
+    gtest.Case(t, func() {
+        one, err := db.Table(table).Master().Where("id", 1).One()
+        gtest.Assert(err, nil)
+        gtest.Assert(one["passport"].String(), "t1")
+
+        count, err := db.Table(table).Master().Count()
+        gtest.Assert(err, nil)
+        gtest.Assert(count, INIT_DATA_SIZE)
+    })
+}
+
+func TestDbBase_Sticky(t *testing.T) {
+    table := createInitTable()
+    defer dropTable(table)
+    gtest.Case(t, func() {
+        sticky := db.Sticky()
+        _, err := sticky.Table(table).Data(g.Map{"nickname" : "sticky"}).Where("id", 1).Update()
+        gtest.Assert(err, nil)
+
+        value, err := sticky.Table(table).Fields("nickname").Where("id", 1).Value()
+        gtest.Assert(err, nil)
+        gtest.Assert(value.String(), "sticky")
+    })
+}
+
+func TestDbBase_SetBalanceStrategy(t *testing.T) {
+    defer db.SetBalanceStrategy(gdb.BALANCE_WEIGHTED_RANDOM)
+    gtest.Case(t, func() {
+        for _, strategy := range []int{gdb.BALANCE_ROUND_ROBIN, gdb.BALANCE_LEAST_CONN, gdb.BALANCE_WEIGHTED_RANDOM} {
+            db.SetBalanceStrategy(strategy)
+            gtest.Assert(db.PingMaster(), nil)
+            gtest.Assert(db.PingSlave(),  nil)
+        }
+    })
+}
+
+func TestDbBase_SetHealthCheck(t *testing.T) {
+    gtest.Case(t, func() {
+        db.SetHealthCheck(100 * time.Millisecond)
+        time.Sleep(300 * time.Millisecond)
+        db.SetHealthCheck(0)
+        gtest.Assert(db.PingSlave(), nil)
+    })
+}
@@ -0,0 +1,123 @@
+// Copyright 2019 gf Author(https://github.com/gogf/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package gdb_test
+
+import (
+    "errors"
+    "github.com/gogf/gf/g"
+    "github.com/gogf/gf/g/database/gdb"
+    "github.com/gogf/gf/g/test/gtest"
+    "strings"
+    "testing"
+)
+
+func TestDbBase_Hooks(t *testing.T) {
+    table := createInitTable()
+    defer dropTable(table)
+    gtest.Case(t, func() {
+        db, err := gdb.New()
+        gtest.Assert(err, nil)
+        db.SetSchema("test")
+
+        sqls := make([]*gdb.Sql, 0)
+        db.AddAfterHook(func(s *gdb.Sql) {
+            sqls = append(sqls, s)
+        })
+        one, err := db.Table(table).Where("id", 1).One()
+        gtest.Assert(err, nil)
+        gtest.Assert(one["id"].Int(), 1)
+        gtest.Assert(len(sqls), 1)
+        gtest.Assert(sqls[0].Func,  "Query")
+        gtest.Assert(sqls[0].Error, nil)
+        gtest.Assert(sqls[0].Args,  g.Slice{1})
+        gtest.Assert(strings.Contains(sqls[0].Caller, "gdb_unit_hook_test.go"), true)
+    })
+}
+
+func TestDbBase_HookRewriteAndReject(t *testing.T) {
+    table := createInitTable()
+    defer dropTable(table)
+    gtest.Case(t, func() {
+        db, err := gdb.New()
+        gtest.Assert(err, nil)
+        db.SetSchema("test")
+        db.AddBeforeHook(gdb.HookRejectDeleteWithoutWhere)
+        db.AddBeforeHook(func(s *gdb.Sql) error {
+            if strings.Contains(s.Sql, "forbidden") {
+                return errors.New("forbidden")
+            }
+            s.Sql = strings.Replace(s.Sql, "__TABLE__", table, -1)
+            return nil
+        })
+
+        _, err = db.Table(table).Delete()
+        gtest.AssertNE(err, nil)
+        count, err := db.Table(table).Count()
+        gtest.Assert(err, nil)
+        gtest.Assert(count, INIT_DATA_SIZE)
+
+        _, err = db.Exec("UPDATE __TABLE__ SET nickname='hook' WHERE id=?", 1)
+        gtest.Assert(err, nil)
+        value, err := db.Table(table).Fields("nickname").Where("id", 1).Value()
+        gtest.Assert(err, nil)
+        gtest.Assert(value.String(), "hook")
+
+        _, err = db.GetAll("SELECT 'forbidden'")
+        gtest.AssertNE(err, nil)
+    })
+}
+
+func TestHookRejectDeleteWithoutWhere(t *testing.T) {
+    gtest.Case(t, func() {
+        for _, sql := range []string {
+            "DELETE FROM user",
+            " delete from `user`",
+            "DELETE FROM user_where",
+            "DELETE FROM user WHERE_ID",
+            "DELETE FROM user ORDER BY (SELECT id FROM log WHERE uid=1)",
+            "DELETE u FROM user u INNER JOIN (SELECT id FROM log WHERE uid=1) l ON (u.id=l.id)",
+            "DELETE FROM user LIMIT 1 /* ' WHERE ' */",
+        } {
+            gtest.AssertNE(gdb.HookRejectDeleteWithoutWhere(&gdb.Sql{Sql : sql}), nil)
+        }
+        for _, sql := range []string {
+            "DELETE FROM user WHERE id=1",
+            "delete from user\nwhere id=1",
+            "DELETE FROM user WHERE(id=1)",
+            "DELETE FROM user WHERE id IN (SELECT uid FROM log WHERE uid>1)",
+            "DELETE FROM user WHERE nickname='it''s (a) test'",
+            "SELECT * FROM user",
+        } {
+            gtest.Assert(gdb.HookRejectDeleteWithoutWhere(&gdb.Sql{Sql : sql}), nil)
+        }
+    })
+}
+
+func TestDbBase_HookPrepare(t *testing.T) {
+    table := createInitTable()
+    defer dropTable(table)
+    gtest.Case(t, func() {
+        db, err := gdb.New()
+        gtest.Assert(err, nil)
+        db.SetSchema("test")
+        db.AddBeforeHook(gdb.HookRejectDeleteWithoutWhere)
+        sqls := make([]*gdb.Sql, 0)
+        db.AddAfterHook(func(s *gdb.Sql) {
+            sqls = append(sqls, s)
+        })
+
+        _, err = db.Prepare("DELETE FROM " + table, true)
+        gtest.AssertNE(err, nil)
+
+        stmt, err := db.Prepare("SELECT nickname FROM " + table + " WHERE id=?")
+        gtest.Assert(err, nil)
+        defer stmt.Close()
+        gtest.Assert(len(sqls), 1)
+        gtest.Assert(sqls[0].Func,  "Prepare")
+        gtest.Assert(sqls[0].Error, nil)
+    })
+}
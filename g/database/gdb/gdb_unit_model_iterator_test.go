@@ -0,0 +1,163 @@
+// Copyright 2019 gf Author(https://github.com/gogf/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package gdb_test
+
+import (
+    "github.com/gogf/gf/g/database/gdb"
+    "github.com/gogf/gf/g/os/gtime"
+    "github.com/gogf/gf/g/test/gtest"
+    "testing"
+)
+
+func TestModel_Iterator(t *testing.T) {
+    table := createInitTable()
+    defer dropTable(table)
+    gtest.Case(t, func() {
+        it, err := db.Table(table).OrderBy("id asc").Iterator()
+        gtest.Assert(err, nil)
+        defer it.Close()
+        ids := make([]int, 0)
+        for it.Next() {
+            ids = append(ids, it.Record()["id"].Int())
+        }
+        gtest.Assert(it.Err(), nil)
+        gtest.Assert(len(ids), INIT_DATA_SIZE)
+        gtest.Assert(ids[0], 1)
+        gtest.Assert(ids[INIT_DATA_SIZE - 1], INIT_DATA_SIZE)
+    })
+}
+
+func TestModel_Each(t *testing.T) {
+    table := createInitTable()
+    defer dropTable(table)
+    gtest.Case(t, func() {
+        count := 0
+        err   := db.Table(table).Where("id>?", 5).Each(func(record gdb.Record) bool {
+            count++
+            return true
+        })
+        gtest.Assert(err, nil)
+        gtest.Assert(count, INIT_DATA_SIZE - 5)
+    })
+    gtest.Case(t, func() {
+        count := 0
+        err   := db.Table(table).Each(func(record gdb.Record) bool {
+            count++
+            return count < 3
+        })
+        gtest.Assert(err, nil)
+        gtest.Assert(count, 3)
+    })
+}
+
+func TestModel_ScanStructs(t *testing.T) {
+    table := createInitTable()
+    defer dropTable(table)
+    gtest.Case(t, func() {
+        type User struct {
+            Id         int
+            Passport   string
+            Password   string
+            NickName   string
+            CreateTime gtime.Time
+        }
+        var users []User
+        err := db.Table(table).OrderBy("id asc").ScanStructs(&users)
+        gtest.Assert(err, nil)
+        gtest.Assert(len(users), INIT_DATA_SIZE)
+        gtest.Assert(users[0].Id,       1)
+        gtest.Assert(users[0].Passport, "t1")
+        gtest.Assert(users[0].NickName, "T1")
+        gtest.Assert(users[0].CreateTime.IsZero(), false)
+    })
+    gtest.Case(t, func() {
+        type Base struct {
+            Id int
+        }
+        type User struct {
+            Base
+            Name       string `json:"nickname"`
+            CreateTime *gtime.Time
+        }
+        var users []*User
+        err := db.Table(table).OrderBy("id desc").ScanStructs(&users)
+        gtest.Assert(err, nil)
+        gtest.Assert(len(users), INIT_DATA_SIZE)
+        gtest.Assert(users[0].Id,   INIT_DATA_SIZE)
+        gtest.Assert(users[0].Name, "T10")
+        gtest.AssertNE(users[0].CreateTime, nil)
+    })
+}
+
+func TestModel_ChunkByID(t *testing.T) {
+    table := createInitTable()
+    defer dropTable(table)
+    gtest.Case(t, func() {
+        ids    := make([]int, 0)
+        chunks := 0
+        db.Table(table).Where("id>?", 1).ChunkByID("id", 3, func(result gdb.Result, err error) bool {
+            gtest.Assert(err, nil)
+            chunks++
+            for _, record := range result {
+                ids = append(ids, record["id"].Int())
+            }
+            return true
+        })
+        gtest.Assert(chunks,   3)
+        gtest.Assert(len(ids), INIT_DATA_SIZE - 1)
+        gtest.Assert(ids[0],   2)
+        gtest.Assert(ids[len(ids) - 1], INIT_DATA_SIZE)
+    })
+    // 原有条件为OR条件时，分页条件必须作用于整个原有条件
+    gtest.Case(t, func() {
+        ids := make([]int, 0)
+        db.Table(table).Where("id=?", 1).Or("id>?", 5).ChunkByID("id", 2, func(result gdb.Result, err error) bool {
+            gtest.Assert(err, nil)
+            for _, record := range result {
+                ids = append(ids, record["id"].Int())
+            }
+            return len(ids) <= INIT_DATA_SIZE
+        })
+        gtest.Assert(ids, []int{1, 6, 7, 8, 9, 10})
+    })
+    gtest.Case(t, func() {
+        chunks := 0
+        db.Table(table).ChunkByID("id", 4, func(result gdb.Result, err error) bool {
+            chunks++
+            return false
+        })
+        gtest.Assert(chunks, 1)
+    })
+}
+
+func TestModel_Iterator_Cache(t *testing.T) {
+    table := createInitTable()
+    defer dropTable(table)
+    gtest.Case(t, func() {
+        it, err := db.Table(table).Cache(60, "iterator").OrderBy("id asc").Iterator()
+        gtest.Assert(err, nil)
+        count := 0
+        for it.Next() {
+            count++
+        }
+        gtest.Assert(it.Close(), nil)
+        gtest.Assert(count, INIT_DATA_SIZE)
+        // 直接执行的SQL不会清除查询缓存，命中缓存时迭代缓存的结果集
+        _, err = db.Exec("DELETE FROM " + table)
+        gtest.Assert(err, nil)
+        it, err = db.Table(table).Cache(60, "iterator").OrderBy("id asc").Iterator()
+        gtest.Assert(err, nil)
+        defer it.Close()
+        ids := make([]int, 0)
+        for it.Next() {
+            ids = append(ids, it.Record()["id"].Int())
+        }
+        gtest.Assert(it.Err(), nil)
+        gtest.Assert(len(ids), INIT_DATA_SIZE)
+        gtest.Assert(ids[0], 1)
+    })
+}
@@ -0,0 +1,47 @@
+// Copyright 2019 gf Author(https://github.com/gogf/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package gdb
+
+import (
+    "database/sql/driver"
+    "errors"
+    "github.com/gogf/gf/g/test/gtest"
+    "testing"
+)
+
+type testFailedValuer string
+
+func (v testFailedValuer) Value() (driver.Value, error) {
+    return nil, errors.New("invalid value")
+}
+
+func Test_structToMap_Error(t *testing.T) {
+    type Detail struct {
+        Remark testFailedValuer `orm:"remark"`
+    }
+    type User struct {
+        Id       int      `orm:"id"`
+        Settings chan int `orm:"settings,json"`
+    }
+    type Profile struct {
+        Id     int `orm:"id"`
+        Detail Detail
+    }
+    gtest.Case(t, func() {
+        data, err := structToMap(&User{Id : 1, Settings : make(chan int)})
+        gtest.AssertNE(err, nil)
+        gtest.Assert(data["id"], 1)
+
+        // 嵌套struct的属性转换失败
+        _, err = structToMap(Profile{Id : 1, Detail : Detail{Remark : "a"}})
+        gtest.AssertNE(err, nil)
+
+        data, err = structToMap(Detail{})
+        gtest.AssertNE(err, nil)
+        gtest.Assert(data["remark"], testFailedValuer(""))
+    })
+}
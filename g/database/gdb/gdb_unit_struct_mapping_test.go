@@ -0,0 +1,146 @@
+// Copyright 2019 gf Author(https://github.com/gogf/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package gdb_test
+
+import (
+    "database/sql"
+    "database/sql/driver"
+    "errors"
+    "github.com/gogf/gf/g/os/gtime"
+    "github.com/gogf/gf/g/test/gtest"
+    "testing"
+)
+
+func TestModel_StructMapping_Write(t *testing.T) {
+    table := createTable()
+    defer dropTable(table)
+    gtest.Case(t, func() {
+        type Base struct {
+            Id int `orm:"id,omitempty"`
+        }
+        type User struct {
+            *Base
+            Account    string      `orm:"passport"`
+            Secret     string      `orm:"password"`
+            Name       string      `orm:"nickname"`
+            CreateTime *gtime.Time `orm:"create_time"`
+            Remark     string      `orm:"-"`
+        }
+        // 自增主键为零值时忽略
+        result, err := db.Table(table).Data(User{
+            Base       : &Base{},
+            Account    : "john",
+            Secret     : "123456",
+            Name       : "John",
+            CreateTime : gtime.Now(),
+            Remark     : "ignored",
+        }).Insert()
+        gtest.Assert(err, nil)
+        id, _ := result.LastInsertId()
+        gtest.Assert(id, 1)
+
+        one, err := db.Table(table).Where("id", 1).One()
+        gtest.Assert(err, nil)
+        gtest.Assert(one["passport"].String(), "john")
+        gtest.Assert(one["nickname"].String(), "John")
+    })
+}
+
+func TestModel_StructMapping_Read(t *testing.T) {
+    table := createInitTable()
+    defer dropTable(table)
+    gtest.Case(t, func() {
+        type User struct {
+            Uid      uint           `orm:"id"`
+            Account  string         `orm:"passport"`
+            Name     sql.NullString `orm:"nickname"`
+            Created  *gtime.Time    `orm:"create_time"`
+        }
+        user := new(User)
+        err  := db.Table(table).Where("id", 2).Struct(user)
+        gtest.Assert(err, nil)
+        gtest.Assert(user.Uid,          2)
+        gtest.Assert(user.Account,      "t2")
+        gtest.Assert(user.Name.Valid,   true)
+        gtest.Assert(user.Name.String,  "T2")
+        gtest.AssertNE(user.Created,    nil)
+
+        users := ([]User)(nil)
+        err    = db.Table(table).OrderBy("id asc").Structs(&users)
+        gtest.Assert(err, nil)
+        gtest.Assert(len(users),       INIT_DATA_SIZE)
+        gtest.Assert(users[9].Account, "t10")
+    })
+}
+
+func TestModel_StructMapping_Json(t *testing.T) {
+    table := createTable()
+    defer dropTable(table)
+    gtest.Case(t, func() {
+        type Profile struct {
+            Age  int      `json:"age"`
+            Tags []string `json:"tags"`
+        }
+        type User struct {
+            Id       int     `orm:"id"`
+            Passport string  `orm:"passport"`
+            Password string  `orm:"password"`
+            Profile  Profile `orm:"nickname,json"`
+            Created  string  `orm:"create_time"`
+        }
+        _, err := db.Table(table).Data(User{
+            Id       : 1,
+            Passport : "john",
+            Password : "123456",
+            Profile  : Profile{Age : 18, Tags : []string{"a", "b"}},
+            Created  : gtime.Now().String(),
+        }).Insert()
+        gtest.Assert(err, nil)
+
+        value, err := db.Table(table).Fields("nickname").Where("id", 1).Value()
+        gtest.Assert(err, nil)
+        gtest.Assert(value.String(), `{"age":18,"tags":["a","b"]}`)
+
+        user := new(User)
+        gtest.Assert(db.Table(table).Where("id", 1).Struct(user), nil)
+        gtest.Assert(user.Profile.Age,  18)
+        gtest.Assert(user.Profile.Tags, []string{"a", "b"})
+    })
+}
+
+// 写入时返回错误的driver.Valuer类型
+type failedValuer string
+
+func (v failedValuer) Value() (driver.Value, error) {
+    return nil, errors.New("invalid value")
+}
+
+func TestModel_StructMapping_ValueError(t *testing.T) {
+    table := createTable()
+    defer dropTable(table)
+    gtest.Case(t, func() {
+        type User struct {
+            Id       int          `orm:"id"`
+            Account  string       `orm:"passport"`
+            Name     failedValuer `orm:"nickname"`
+            Settings chan int     `orm:"password,json"`
+        }
+        // 属性值转换失败时不会忽略该字段写入，而是返回错误
+        _, err := db.Table(table).Data(User{Id : 1, Account : "john", Name : "John"}).Insert()
+        gtest.AssertNE(err, nil)
+        _, err = db.Insert(table, User{Id : 1, Account : "john", Name : "John"})
+        gtest.AssertNE(err, nil)
+        _, err = db.BatchInsert(table, []User{{Id : 1, Account : "john", Name : "John"}})
+        gtest.AssertNE(err, nil)
+        _, err = db.Update(table, User{Id : 1, Account : "john", Name : "John"}, "id=1")
+        gtest.AssertNE(err, nil)
+
+        count, err := db.Table(table).Count()
+        gtest.Assert(err,   nil)
+        gtest.Assert(count, 0)
+    })
+}
@@ -0,0 +1,52 @@
+// Copyright 2019 gf Author(https://github.com/gogf/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package gdb_test
+
+import (
+    "fmt"
+    "github.com/gogf/gf/g/test/gtest"
+    "testing"
+)
+
+func TestDbBase_Tables(t *testing.T) {
+    table := createTable()
+    defer dropTable(table)
+    gtest.Case(t, func() {
+        tables, err := db.Tables()
+        gtest.Assert(err, nil)
+        gtest.AssertIN(table, tables)
+    })
+}
+
+func TestDbBase_TableFields(t *testing.T) {
+    table := createTable()
+    defer dropTable(table)
+    gtest.Case(t, func() {
+        fields, err := db.TableFields(table)
+        gtest.Assert(err, nil)
+        gtest.Assert(len(fields), 5)
+        gtest.Assert(fields["id"].Index,   0)
+        gtest.Assert(fields["id"].Key,     "PRI")
+        gtest.Assert(fields["id"].Null,    false)
+        gtest.Assert(fields["id"].Extra,   "auto_increment")
+        gtest.Assert(fields["id"].Comment, "用户ID")
+        gtest.Assert(fields["passport"].Type, "varchar(45)")
+        gtest.Assert(fields["nickname"].Comment, "昵称")
+    })
+    // 表结构变化之后自动清除结构缓存
+    gtest.Case(t, func() {
+        _, err := db.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN remark varchar(100) NULL DEFAULT 'none' COMMENT '备注'", table))
+        gtest.Assert(err, nil)
+        fields, err := db.TableFields(table)
+        gtest.Assert(err, nil)
+        gtest.Assert(len(fields), 6)
+        gtest.Assert(fields["remark"].Index,   5)
+        gtest.Assert(fields["remark"].Null,    true)
+        gtest.Assert(fields["remark"].Default, "none")
+        gtest.Assert(fields["remark"].Comment, "备注")
+    })
+}
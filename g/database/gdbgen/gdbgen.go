@@ -0,0 +1,424 @@
+// Copyright 2019 gf Author(https://github.com/gogf/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://github.com/gogf/gf.
+
+// Package gdbgen generates Go entity structs, column constants and DAO wrappers
+// from the table metadata of a gdb database.
+//
+// The generated files are completely rewritten on each run and the output is
+// deterministic, so regenerating against an unchanged schema produces no changes.
+package gdbgen
+
+import (
+    "bytes"
+    "errors"
+    "fmt"
+    "github.com/gogf/gf/g/database/gdb"
+    "github.com/gogf/gf/g/os/gfile"
+    "github.com/gogf/gf/g/text/gregex"
+    "github.com/gogf/gf/g/text/gstr"
+    "go/format"
+    "sort"
+    "strings"
+    "text/template"
+)
+
+// Generating configuration.
+type Config struct {
+    Path    string   // Target directory of generated files.
+    Package string   // Package name of generated files, it's the base name of <Path> in default.
+    Tables  []string // Tables to generate, all tables of the database are generated if empty.
+    Prefix  string   // Table name prefix that is removed from the generated type names.
+}
+
+// Column describes a table field for template rendering.
+type Column struct {
+    Name    string // Column name in database.
+    Field   string // Struct field name in Go.
+    Type      string // Struct field type in Go, it's a pointer type if the column is nullable.
+    Comment   string // Column comment.
+    OmitEmpty bool   // Whether the column is generated by database, its zero value is omitted when writing.
+}
+
+// Table describes a table for template rendering.
+type Table struct {
+    Package string    // Package name.
+    Name    string    // Table name in database.
+    Type    string    // Entity struct name in Go, it's also the prefix of the column type name.
+    Imports []string  // Imported packages.
+    Columns []*Column // Ordered table columns.
+}
+
+const (
+    gFILE_HEADER = "// Code generated by gdbgen. DO NOT EDIT.\n"
+)
+
+// Generate generates the entity/DAO files for tables of <db> into <config.Path>,
+// and returns the paths of all generated files.
+// Files whose content does not change are not rewritten.
+func Generate(db gdb.DB, config Config) (files []string, err error) {
+    if config.Path == "" {
+        return nil, errors.New("empty target path for generating")
+    }
+    if config.Package == "" {
+        config.Package = gfile.Basename(config.Path)
+    }
+    tables := config.Tables
+    if len(tables) == 0 {
+        if tables, err = db.Tables(); err != nil {
+            return nil, err
+        }
+    }
+    if !gfile.Exists(config.Path) {
+        if err = gfile.Mkdir(config.Path); err != nil {
+            return nil, err
+        }
+    }
+    for _, name := range tables {
+        fields, err := db.TableFields(name)
+        if err != nil {
+            return files, err
+        }
+        table   := NewTable(config.Package, name, config.Prefix, fields)
+        content, err := Render(table)
+        if err != nil {
+            return files, err
+        }
+        path := config.Path + gfile.Separator + FileName(name, config.Prefix)
+        if gfile.GetContents(path) != string(content) {
+            if err = gfile.PutContents(path, string(content)); err != nil {
+                return files, err
+            }
+        }
+        files = append(files, path)
+    }
+    return files, nil
+}
+
+// NewTable creates and returns the rendering object of table <name> with given <fields>.
+func NewTable(pkg, name, prefix string, fields map[string]*gdb.TableField) *Table {
+    table := &Table {
+        Package : pkg,
+        Name    : name,
+        Type    : CamelCase(strings.TrimPrefix(name, prefix)),
+        Columns : make([]*Column, 0, len(fields)),
+    }
+    list := make([]*gdb.TableField, 0, len(fields))
+    for _, field := range fields {
+        list = append(list, field)
+    }
+    sort.Slice(list, func(i, j int) bool {
+        if list[i].Index != list[j].Index {
+            return list[i].Index < list[j].Index
+        }
+        return list[i].Name < list[j].Name
+    })
+    imports := make(map[string]struct{})
+    names   := fieldNames(list)
+    for i, field := range list {
+        goType := GoType(field.Type)
+        if field.Null {
+            goType = nullableType(goType)
+        }
+        if strings.Contains(goType, "gtime.") {
+            imports["github.com/gogf/gf/g/os/gtime"] = struct{}{}
+        }
+        table.Columns = append(table.Columns, &Column {
+            Name      : field.Name,
+            Field     : names[i],
+            Type      : goType,
+            Comment   : strings.Replace(strings.TrimSpace(field.Comment), "\n", " ", -1),
+            OmitEmpty : isGenerated(field),
+        })
+    }
+    table.Imports = []string{"database/sql", "github.com/gogf/gf/g/database/gdb"}
+    for pkg := range imports {
+        table.Imports = append(table.Imports, pkg)
+    }
+    sort.Strings(table.Imports)
+    return table
+}
+
+// fieldNames returns the unique struct field names of the ordered <list>.
+// Columns that convert to the same name (eg: user_id and UserID) are disambiguated
+// by appending the smallest numeric suffix that does not conflict, in column order.
+func fieldNames(list []*gdb.TableField) []string {
+    names := make([]string, len(list))
+    taken := make(map[string]int, len(list))
+    for i, field := range list {
+        names[i] = CamelCase(field.Name)
+        taken[names[i]]++
+    }
+    used := make(map[string]struct{}, len(list))
+    for i, name := range names {
+        if _, ok := used[name]; ok {
+            for n := 2; ; n++ {
+                candidate := fmt.Sprintf("%s%d", name, n)
+                if _, ok := used[candidate]; ok {
+                    continue
+                }
+                if taken[candidate] > 0 {
+                    continue
+                }
+                name = candidate
+                break
+            }
+            names[i] = name
+        }
+        used[name] = struct{}{}
+    }
+    return names
+}
+
+// isGenerated checks whether the value of column <field> is generated by database,
+// which is an auto increment column, or a primary key of serial type or with default value.
+func isGenerated(field *gdb.TableField) bool {
+    if strings.Contains(strings.ToLower(field.Extra), "auto_increment") {
+        return true
+    }
+    if field.Key != "PRI" {
+        return false
+    }
+    return field.Default != nil || strings.HasSuffix(strings.ToLower(field.Type), "serial")
+}
+
+// nullableType returns the Go type of nullable column for <goType>,
+// in which NULL value is represented by nil.
+func nullableType(goType string) string {
+    if goType[0] == '*' || strings.HasPrefix(goType, "[]") {
+        return goType
+    }
+    return "*" + goType
+}
+
+// Render renders and returns the formatted Go source of <table>.
+func Render(table *Table) ([]byte, error) {
+    buffer := bytes.NewBuffer(nil)
+    if err := tableTemplate.Execute(buffer, table); err != nil {
+        return nil, err
+    }
+    content, err := format.Source(buffer.Bytes())
+    if err != nil {
+        return nil, fmt.Errorf(`format source of table "%s" failed: %s`, table.Name, err.Error())
+    }
+    return content, nil
+}
+
+// FileName returns the generated file name of table <name>.
+func FileName(name, prefix string) string {
+    name, _ = gregex.ReplaceString(`[^\w]+`, "_", strings.TrimPrefix(name, prefix))
+    return strings.ToLower(name) + ".go"
+}
+
+// CamelCase converts the database name <s> to exported Go identifier,
+// eg: user_detail -> UserDetail, 2fa_code -> T2faCode.
+func CamelCase(s string) string {
+    buffer := bytes.NewBuffer(nil)
+    for _, word := range gregex.Split(`[^a-zA-Z0-9]+`, s) {
+        if word == "" {
+            continue
+        }
+        switch strings.ToLower(word) {
+            case "id", "ip", "url", "uid", "uuid", "api", "http", "json", "sql", "html":
+                buffer.WriteString(strings.ToUpper(word))
+            default:
+                buffer.WriteString(gstr.UcFirst(word))
+        }
+    }
+    name := buffer.String()
+    if name == "" || (name[0] >= '0' && name[0] <= '9') {
+        name = "T" + name
+    }
+    return name
+}
+
+// goTypes maps the base database field types (lower case, without length and attributes) to Go types.
+var goTypes = map[string]string {
+    "bool"                           : "bool",
+    "boolean"                        : "bool",
+
+    "bigint"                         : "int64",
+    "int8"                           : "int64",
+    "bigserial"                      : "int64",
+
+    "int"                            : "int",
+    "integer"                        : "int",
+    "tinyint"                        : "int",
+    "smallint"                       : "int",
+    "mediumint"                      : "int",
+    "int2"                           : "int",
+    "int4"                           : "int",
+    "serial"                         : "int",
+    "smallserial"                    : "int",
+    "bit"                            : "int",
+    "year"                           : "int",
+
+    "float"                          : "float64",
+    "float4"                         : "float64",
+    "float8"                         : "float64",
+    "double"                         : "float64",
+    "double precision"               : "float64",
+    "real"                           : "float64",
+    "decimal"                        : "float64",
+    "dec"                            : "float64",
+    "numeric"                        : "float64",
+    "number"                         : "float64",
+    "money"                          : "float64",
+    "smallmoney"                     : "float64",
+
+    "blob"                           : "[]byte",
+    "tinyblob"                       : "[]byte",
+    "mediumblob"                     : "[]byte",
+    "longblob"                       : "[]byte",
+    "binary"                         : "[]byte",
+    "varbinary"                      : "[]byte",
+    "bytea"                          : "[]byte",
+    "image"                          : "[]byte",
+    "raw"                            : "[]byte",
+    "long raw"                       : "[]byte",
+
+    "date"                           : "*gtime.Time",
+    "datetime"                       : "*gtime.Time",
+    "datetime2"                      : "*gtime.Time",
+    "smalldatetime"                  : "*gtime.Time",
+    "datetimeoffset"                 : "*gtime.Time",
+    "time"                           : "*gtime.Time",
+    "timetz"                         : "*gtime.Time",
+    "time with time zone"            : "*gtime.Time",
+    "time without time zone"         : "*gtime.Time",
+    "timestamp"                      : "*gtime.Time",
+    "timestamptz"                    : "*gtime.Time",
+    "timestamp with time zone"       : "*gtime.Time",
+    "timestamp without time zone"    : "*gtime.Time",
+    "timestamp with local time zone" : "*gtime.Time",
+}
+
+// GoType converts the database field type <t> to Go type.
+// The type name is matched exactly after removing the length and attributes,
+// unknown types (eg: json, point, interval) are converted to string.
+func GoType(t string) string {
+    t         = strings.ToLower(strings.TrimSpace(t))
+    unsigned := strings.Contains(t, "unsigned")
+    base, _  := gregex.ReplaceString(`\([^)]*\)|\bunsigned\b|\bzerofill\b`, " ", t)
+    base      = strings.Join(strings.Fields(base), " ")
+    goType, ok := goTypes[base]
+    if !ok {
+        return "string"
+    }
+    switch goType {
+        case "int":
+            // BIT(1) is generally used as boolean.
+            if base == "bit" && gregex.IsMatchString(`\(1\)`, t) {
+                return "bool"
+            }
+            if unsigned {
+                return "uint"
+            }
+        case "int64":
+            if unsigned {
+                return "uint64"
+            }
+        case "float64":
+            // NUMBER(p,0) of oracle is integer.
+            if base == "number" && gregex.IsMatchString(`\(\s*\d+\s*,\s*0\s*\)`, t) {
+                return "int64"
+            }
+    }
+    return goType
+}
+
+var tableTemplate = template.Must(template.New("table").Parse(gFILE_HEADER + `
+package {{.Package}}
+
+import (
+{{- range .Imports}}
+    "{{.}}"
+{{- end}}
+)
+
+// {{.Type}} is the entity of table "{{.Name}}".
+type {{.Type}} struct {
+{{- range .Columns}}
+    {{.Field}} {{.Type}} ` + "`" + `orm:"{{.Name}}{{if .OmitEmpty}},omitempty{{end}}" json:"{{.Name}}"` + "`" + `{{if .Comment}} // {{.Comment}}{{end}}
+{{- end}}
+}
+
+// {{.Type}}Table is the name of table "{{.Name}}".
+const {{.Type}}Table = "{{.Name}}"
+
+// {{.Type}}Column is the column name of table "{{.Name}}".
+type {{.Type}}Column string
+
+// Column names of table "{{.Name}}".
+const (
+{{- $type := .Type}}
+{{- range .Columns}}
+    {{$type}}Column{{.Field}} {{$type}}Column = "{{.Name}}"
+{{- end}}
+)
+
+// String returns the column name as string.
+func (c {{.Type}}Column) String() string {
+    return string(c)
+}
+
+// {{.Type}}Dao is the data access object of table "{{.Name}}".
+type {{.Type}}Dao struct {
+    db gdb.DB
+}
+
+// New{{.Type}}Dao creates and returns the data access object of table "{{.Name}}" on <db>.
+func New{{.Type}}Dao(db gdb.DB) *{{.Type}}Dao {
+    return &{{.Type}}Dao{db: db}
+}
+
+// Model creates and returns a chaining model of table "{{.Name}}".
+func (d *{{.Type}}Dao) Model() *gdb.Model {
+    return d.db.Table({{.Type}}Table)
+}
+
+// One retrieves one record by <where> condition, it returns nil if no record found.
+func (d *{{.Type}}Dao) One(where interface{}, args ...interface{}) (*{{.Type}}, error) {
+    record, err := d.Model().Where(where, args...).One()
+    if err != nil || record == nil {
+        return nil, err
+    }
+    entity := new({{.Type}})
+    if err := record.ToStruct(entity); err != nil {
+        return nil, err
+    }
+    return entity, nil
+}
+
+// All retrieves all records by <where> condition.
+func (d *{{.Type}}Dao) All(where interface{}, args ...interface{}) ([]*{{.Type}}, error) {
+    var entities []*{{.Type}}
+    if err := d.Model().Where(where, args...).Structs(&entities); err != nil {
+        return nil, err
+    }
+    return entities, nil
+}
+
+// Insert inserts <entity> into the table.
+func (d *{{.Type}}Dao) Insert(entity *{{.Type}}) (sql.Result, error) {
+    return d.Model().Data(entity).Insert()
+}
+
+// Update updates the records by <where> condition with <data>,
+// which can be type of *{{.Type}}, map or string.
+func (d *{{.Type}}Dao) Update(data interface{}, where interface{}, args ...interface{}) (sql.Result, error) {
+    return d.Model().Data(data).Where(where, args...).Update()
+}
+
+// Delete deletes the records by <where> condition.
+func (d *{{.Type}}Dao) Delete(where interface{}, args ...interface{}) (sql.Result, error) {
+    return d.Model().Where(where, args...).Delete()
+}
+
+// Count returns the count of records by <where> condition.
+func (d *{{.Type}}Dao) Count(where interface{}, args ...interface{}) (int, error) {
+    return d.Model().Where(where, args...).Count()
+}
+`))
@@ -0,0 +1,57 @@
+// Copyright 2019 gf Author(https://github.com/gogf/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package gdbgen
+
+import (
+    "github.com/gogf/gf/g/database/gdb"
+    "github.com/gogf/gf/g/frame/gins"
+    "github.com/gogf/gf/g/os/gcmd"
+    "github.com/gogf/gf/g/os/glog"
+    "strings"
+)
+
+// Command is the console handler for generating, which can be registered using gcmd:
+//
+//     gcmd.BindHandle("gen", gdbgen.Command)
+//     gcmd.AutoRun()
+//
+// and then executed like: ./main gen --path=./app/model --tables=user,user_detail
+//
+// The supported options:
+//     --group   : database configuration group in config file, "default" in default;
+//     --path    : target directory of generated files, required;
+//     --package : package name of generated files, base name of <path> in default;
+//     --tables  : table names separated by ',', all tables are generated if empty;
+//     --prefix  : table name prefix that is removed from the generated type names;
+func Command() {
+    config := Config {
+        Path    : gcmd.Option.Get("path"),
+        Package : gcmd.Option.Get("package"),
+        Prefix  : gcmd.Option.Get("prefix"),
+    }
+    if tables := gcmd.Option.Get("tables"); tables != "" {
+        for _, table := range strings.Split(tables, ",") {
+            if table = strings.TrimSpace(table); table != "" {
+                config.Tables = append(config.Tables, table)
+            }
+        }
+    }
+    if config.Path == "" {
+        glog.Fatal(`option "path" is required, eg: --path=./app/model`)
+    }
+    db := gins.Database(gcmd.Option.Get("group", gdb.DEFAULT_GROUP_NAME))
+    if db == nil {
+        glog.Fatal("database initialization failed")
+    }
+    files, err := Generate(db, config)
+    for _, file := range files {
+        glog.Info("generated:", file)
+    }
+    if err != nil {
+        glog.Fatal(err)
+    }
+}
@@ -0,0 +1,117 @@
+// Copyright 2019 gf Author(https://github.com/gogf/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package gdbgen_test
+
+import (
+    "github.com/gogf/gf/g/database/gdb"
+    "github.com/gogf/gf/g/database/gdbgen"
+    "github.com/gogf/gf/g/test/gtest"
+    "strings"
+    "testing"
+)
+
+func Test_CamelCase(t *testing.T) {
+    gtest.Case(t, func() {
+        gtest.Assert(gdbgen.CamelCase("user"),        "User")
+        gtest.Assert(gdbgen.CamelCase("user_detail"), "UserDetail")
+        gtest.Assert(gdbgen.CamelCase("user_id"),     "UserID")
+        gtest.Assert(gdbgen.CamelCase("create-time"), "CreateTime")
+        gtest.Assert(gdbgen.CamelCase("2fa_code"),    "T2faCode")
+    })
+}
+
+func Test_GoType(t *testing.T) {
+    gtest.Case(t, func() {
+        gtest.Assert(gdbgen.GoType("int(10) unsigned"), "uint")
+        gtest.Assert(gdbgen.GoType("tinyint(4)"),       "int")
+        gtest.Assert(gdbgen.GoType("bigint(20)"),       "int64")
+        gtest.Assert(gdbgen.GoType("bit(1)"),           "bool")
+        gtest.Assert(gdbgen.GoType("decimal(10,2)"),    "float64")
+        gtest.Assert(gdbgen.GoType("number(10,0)"),     "int64")
+        gtest.Assert(gdbgen.GoType("varchar(45)"),      "string")
+        gtest.Assert(gdbgen.GoType("longblob"),         "[]byte")
+        gtest.Assert(gdbgen.GoType("timestamp"),        "*gtime.Time")
+        gtest.Assert(gdbgen.GoType("json"),             "string")
+        gtest.Assert(gdbgen.GoType("point"),            "string")
+        gtest.Assert(gdbgen.GoType("interval"),         "string")
+        gtest.Assert(gdbgen.GoType("international"),    "string")
+        gtest.Assert(gdbgen.GoType("datetime(3)"),      "*gtime.Time")
+        gtest.Assert(gdbgen.GoType("timestamp(6) with time zone"), "*gtime.Time")
+        gtest.Assert(gdbgen.GoType("double precision"), "float64")
+        gtest.Assert(gdbgen.GoType("bigint unsigned zerofill"), "uint64")
+    })
+}
+
+func Test_Render(t *testing.T) {
+    fields := map[string]*gdb.TableField {
+        "id"          : {Index : 0, Name : "id",          Type : "int(10) unsigned", Comment : "用户ID", Key : "PRI", Extra : "auto_increment"},
+        "passport"    : {Index : 1, Name : "passport",    Type : "varchar(45)"},
+        "create_time" : {Index : 2, Name : "create_time", Type : "timestamp"},
+        "nickname"    : {Index : 3, Name : "nickname",    Type : "varchar(45)", Null : true},
+        "avatar"      : {Index : 4, Name : "avatar",      Type : "blob",        Null : true},
+    }
+    gtest.Case(t, func() {
+        table := gdbgen.NewTable("model", "gf_user", "gf_", fields)
+        gtest.Assert(table.Type, "User")
+        gtest.Assert(len(table.Columns), 5)
+        gtest.Assert(table.Columns[0].Field, "ID")
+        gtest.Assert(table.Columns[2].Field, "CreateTime")
+        gtest.Assert(table.Columns[2].Type,  "*gtime.Time")
+        gtest.Assert(table.Columns[3].Type,  "*string")
+        gtest.Assert(table.Columns[4].Type,  "[]byte")
+        gtest.AssertIN("github.com/gogf/gf/g/os/gtime", table.Imports)
+
+        content, err := gdbgen.Render(table)
+        gtest.Assert(err, nil)
+        source := string(content)
+        gtest.Assert(strings.HasPrefix(source, "// Code generated by gdbgen. DO NOT EDIT."), true)
+        gtest.Assert(strings.Contains(source, "package model"), true)
+        gtest.Assert(strings.Contains(source, "type User struct"), true)
+        gtest.Assert(strings.Contains(source, "`orm:\"create_time\" json:\"create_time\"`"), true)
+        gtest.Assert(strings.Contains(source, "`orm:\"id,omitempty\" json:\"id\"`"), true)
+        gtest.Assert(strings.Contains(source, `const UserTable = "gf_user"`), true)
+        gtest.Assert(strings.Contains(source, "type UserColumn string"), true)
+        gtest.Assert(strings.Contains(source, `UserColumnCreateTime UserColumn = "create_time"`), true)
+        gtest.Assert(strings.Contains(source, "Nickname   *string"), true)
+        gtest.Assert(strings.Contains(source, "func NewUserDao(db gdb.DB) *UserDao"), true)
+        gtest.Assert(strings.Contains(source, "// 用户ID"), true)
+
+        // Regenerating produces the same content.
+        again, err := gdbgen.Render(gdbgen.NewTable("model", "gf_user", "gf_", fields))
+        gtest.Assert(err, nil)
+        gtest.Assert(string(again), source)
+    })
+    gtest.Case(t, func() {
+        // Columns converting to the same field name are disambiguated in column order.
+        table := gdbgen.NewTable("model", "user", "", map[string]*gdb.TableField {
+            "user_id"   : {Index : 0, Name : "user_id",   Type : "int"},
+            "UserID"    : {Index : 1, Name : "UserID",    Type : "int"},
+            "user_id_2" : {Index : 2, Name : "user_id_2", Type : "int"},
+        })
+        gtest.Assert(table.Columns[0].Field, "UserID")
+        gtest.Assert(table.Columns[1].Field, "UserID3")
+        gtest.Assert(table.Columns[2].Field, "UserID2")
+        _, err := gdbgen.Render(table)
+        gtest.Assert(err, nil)
+    })
+    gtest.Case(t, func() {
+        // Primary keys generated by database omit zero values when writing.
+        table := gdbgen.NewTable("model", "user", "", map[string]*gdb.TableField {
+            "id"   : {Index : 0, Name : "id",   Type : "bigserial", Key : "PRI"},
+            "uuid" : {Index : 1, Name : "uuid", Type : "uuid",      Key : "PRI", Default : "gen_random_uuid()"},
+            "code" : {Index : 2, Name : "code", Type : "varchar(8)", Key : "PRI"},
+            "num"  : {Index : 3, Name : "num",  Type : "int",        Default : 0},
+        })
+        gtest.Assert(table.Columns[0].OmitEmpty, true)
+        gtest.Assert(table.Columns[1].OmitEmpty, true)
+        gtest.Assert(table.Columns[2].OmitEmpty, false)
+        gtest.Assert(table.Columns[3].OmitEmpty, false)
+    })
+    gtest.Case(t, func() {
+        gtest.Assert(gdbgen.FileName("gf_user_detail", "gf_"), "user_detail.go")
+    })
+}
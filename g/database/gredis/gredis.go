@@ -22,15 +22,20 @@ import (
 )
 
 const (
+    MODE_STANDALONE = 0 // Single redis server (default).
+    MODE_SENTINEL   = 1 // Redis master discovered and monitored by Sentinel.
+    MODE_CLUSTER    = 2 // Redis Cluster.
+
     gDEFAULT_POOL_IDLE_TIMEOUT  = 60 * time.Second
     gDEFAULT_POOL_MAX_LIFE_TIME = 60 * time.Second
 )
 
 // Redis client.
 type Redis struct {
-    pool   *redis.Pool // Underlying connection pool.
-    group  string      // Configuration group.
-    config Config      // Configuration.
+    pool    *redis.Pool // Underlying connection pool for standalone and sentinel mode.
+    cluster *cluster    // Underlying cluster client for cluster mode.
+    group   string      // Configuration group.
+    config  Config      // Configuration.
 }
 
 // Redis connection.
@@ -42,10 +47,13 @@ type Conn struct {
 type Config struct {
     Host            string
     Port            int
-    Db              int
+    Db              int           // Database index, which is always 0 in cluster mode.
     Pass            string        // Password for AUTH.
-    MaxIdle         int           // Maximum number of connections allowed to be idle (default is 0 means no idle connection)
-    MaxActive       int           // Maximum number of connections limit (default is 0 means no limit)
+    Mode            int           // Deployment mode: MODE_STANDALONE, MODE_SENTINEL or MODE_CLUSTER.
+    Addrs           []string      // Seed node addresses like "host:port", sentinel addresses in sentinel mode, cluster nodes in cluster mode.
+    MasterName      string        // Master name monitored by sentinels, which is required in sentinel mode.
+    MaxIdle         int           // Maximum number of connections allowed to be idle (default is 0 means no idle connection), ignored in standalone mode.
+    MaxActive       int           // Maximum number of connections limit (default is 0 means no limit), ignored in standalone mode.
     IdleTimeout     time.Duration // Maximum idle time for connection (default is 60 seconds, not allowed to be set to 0)
     MaxConnLifetime time.Duration // Maximum lifetime of the connection (default is 60 seconds, not allowed to be set to 0)
 }
@@ -64,6 +72,14 @@ var (
 
 // New creates a redis client object with given configuration.
 // Redis client maintains a connection pool automatically.
+//
+// In MODE_SENTINEL mode, the master address is queried from sentinels of <Addrs>
+// and connections to a former master are dropped automatically after failover.
+// In MODE_CLUSTER mode, the slot map is discovered from cluster nodes of <Addrs>,
+// commands are routed to the node owning the key and MOVED/ASK redirections are handled.
+//
+// Note that <MaxIdle> and <MaxActive> of <config> apply to the pools of sentinel and cluster
+// mode only, the standalone pool keeps no limit like before unless SetMaxIdle/SetMaxActive is called.
 func New(config Config) *Redis {
     if config.IdleTimeout == 0 {
         config.IdleTimeout = gDEFAULT_POOL_IDLE_TIMEOUT
@@ -71,40 +87,76 @@ func New(config Config) *Redis {
     if config.MaxConnLifetime == 0 {
         config.MaxConnLifetime = gDEFAULT_POOL_MAX_LIFE_TIME
     }
+    if config.Mode == MODE_CLUSTER {
+        return &Redis{
+            config  : config,
+            cluster : pools.GetOrSetFuncLock(fmt.Sprintf("%v", config), func() interface{} {
+                return newCluster(config)
+            }).(*cluster),
+        }
+    }
     return &Redis{
         config : config,
         pool   : pools.GetOrSetFuncLock(fmt.Sprintf("%v", config), func() interface{} {
-            return &redis.Pool {
-                IdleTimeout     : config.IdleTimeout,
-                MaxConnLifetime : config.MaxConnLifetime,
-                Dial            : func() (redis.Conn, error) {
-                    c, err := redis.Dial("tcp", fmt.Sprintf("%s:%d", config.Host, config.Port))
-                    if err != nil {
-                        return nil, err
-                    }
-                    // AUTH
-                    if len(config.Pass) > 0 {
-                        if _, err := c.Do("AUTH", config.Pass); err != nil {
-                            return nil, err
-                        }
-                    }
-                    // DB
-                    if _, err := c.Do("SELECT", config.Db); err != nil {
-                        return nil, err
-                    }
-                    return c, nil
-                },
-	            // After the conn is taken from the connection pool, to test if the connection is available,
-                // If error is returned then it closes the connection object and recreate a new connection.
-                TestOnBorrow: func(c redis.Conn, t time.Time) error {
-                    _, err := c.Do("PING")
-                    return err
-                },
+            if config.Mode == MODE_SENTINEL {
+                return newSentinelPool(config)
+            }
+            addr := fmt.Sprintf("%s:%d", config.Host, config.Port)
+            if config.Host == "" && len(config.Addrs) > 0 {
+                addr = config.Addrs[0]
             }
+            // The pool limits of configuration were not applied in standalone mode,
+            // so they're still ignored to keep the pool behavior of existing usage,
+            // use SetMaxIdle/SetMaxActive to limit the pool.
+            config.MaxIdle, config.MaxActive = 0, 0
+            return newPool(config, func() (redis.Conn, error) {
+                return dial(config, addr, true)
+            }, func(c redis.Conn, t time.Time) error {
+                _, err := c.Do("PING")
+                return err
+            })
         }).(*redis.Pool),
     }
 }
 
+// newPool creates a connection pool with given dial and test function.
+func newPool(config Config, dialFunc func() (redis.Conn, error), testFunc func(c redis.Conn, t time.Time) error) *redis.Pool {
+    return &redis.Pool {
+        MaxIdle         : config.MaxIdle,
+        MaxActive       : config.MaxActive,
+        IdleTimeout     : config.IdleTimeout,
+        MaxConnLifetime : config.MaxConnLifetime,
+        Dial            : dialFunc,
+        // After the conn is taken from the connection pool, to test if the connection is available,
+        // If error is returned then it closes the connection object and recreate a new connection.
+        TestOnBorrow    : testFunc,
+    }
+}
+
+// dial creates a connection to redis server <addr>, authenticates it with password of <config>,
+// and selects the database if <selectDb> is true.
+func dial(config Config, addr string, selectDb bool) (redis.Conn, error) {
+    c, err := redis.Dial("tcp", addr)
+    if err != nil {
+        return nil, err
+    }
+    // AUTH
+    if len(config.Pass) > 0 {
+        if _, err := c.Do("AUTH", config.Pass); err != nil {
+            c.Close()
+            return nil, err
+        }
+    }
+    // DB
+    if selectDb {
+        if _, err := c.Do("SELECT", config.Db); err != nil {
+            c.Close()
+            return nil, err
+        }
+    }
+    return c, nil
+}
+
 // Instance returns an instance of redis client with specified group.
 // The <group> param is unnecessary, if <group> is not passed,
 // it returns a redis instance with default group.
@@ -136,15 +188,18 @@ func (r *Redis) Close() error {
         instances.Remove(r.group)
     }
     pools.Remove(fmt.Sprintf("%v", r.config))
+    if r.cluster != nil {
+        return r.cluster.Close()
+    }
     return r.pool.Close()
 }
 
-
 // Conn returns a raw underlying connection object,
 // which expose more methods to communicate with server.
+// In cluster mode, the returned connection routes commands to the nodes owning the keys.
 // **You should call Close function manually if you do not use this connection any further.**
 func (r *Redis) Conn() *Conn {
-    return &Conn{ r.pool.Get() }
+    return &Conn{ r.getConn() }
 }
 
 // Alias of Conn, see Conn.
@@ -152,28 +207,65 @@ func (r *Redis) GetConn() *Conn {
     return r.Conn()
 }
 
+// getConn returns an underlying connection according to the deployment mode.
+func (r *Redis) getConn() redis.Conn {
+    if r.cluster != nil {
+        return r.cluster.Get()
+    }
+    return r.pool.Get()
+}
+
+// dial creates a new connection which is not managed by the connection pool,
+// it's used by long-lived connections like subscriptions.
+func (r *Redis) dial() (redis.Conn, error) {
+    if r.cluster != nil {
+        return r.cluster.dial()
+    }
+    return r.pool.Dial()
+}
+
 // SetMaxIdle sets the MaxIdle attribute of the connection pool.
 func (r *Redis) SetMaxIdle(value int) {
+    if r.cluster != nil {
+        r.cluster.setPools(func(pool *redis.Pool) { pool.MaxIdle = value })
+        return
+    }
     r.pool.MaxIdle = value
 }
 
 // SetMaxActive sets the MaxActive attribute of the connection pool.
 func (r *Redis) SetMaxActive(value int) {
+    if r.cluster != nil {
+        r.cluster.setPools(func(pool *redis.Pool) { pool.MaxActive = value })
+        return
+    }
     r.pool.MaxActive = value
 }
 
 // SetIdleTimeout sets the IdleTimeout attribute of the connection pool.
 func (r *Redis) SetIdleTimeout(value time.Duration) {
+    if r.cluster != nil {
+        r.cluster.setPools(func(pool *redis.Pool) { pool.IdleTimeout = value })
+        return
+    }
     r.pool.IdleTimeout = value
 }
 
 // SetMaxConnLifetime sets the MaxConnLifetime attribute of the connection pool.
 func (r *Redis) SetMaxConnLifetime(value time.Duration) {
+    if r.cluster != nil {
+        r.cluster.setPools(func(pool *redis.Pool) { pool.MaxConnLifetime = value })
+        return
+    }
     r.pool.MaxConnLifetime = value
 }
 
 // Stats returns pool's statistics.
+// In cluster mode, it returns the sum of statistics of all node pools.
 func (r *Redis) Stats() *PoolStats {
+    if r.cluster != nil {
+        return &PoolStats{r.cluster.Stats()}
+    }
     return &PoolStats{r.pool.Stats()}
 }
 
@@ -181,7 +273,7 @@ func (r *Redis) Stats() *PoolStats {
 // Do automatically get a connection from pool, and close it when reply received.
 // It does not really "close" the connection, but drop it back to the connection pool.
 func (r *Redis) Do(command string, args ...interface{}) (interface{}, error) {
-	conn := &Conn{ r.pool.Get() }
+	conn := &Conn{ r.getConn() }
     defer conn.Close()
     return conn.Do(command, args...)
 }
@@ -195,8 +287,7 @@ func (r *Redis) DoVar(command string, args ...interface{}) (*gvar.Var, error) {
 // Deprecated.
 // Send writes the command to the client's output buffer.
 func (r *Redis) Send(command string, args ...interface{}) error {
-	conn := &Conn{ r.pool.Get() }
+	conn := &Conn{ r.getConn() }
     defer conn.Close()
     return conn.Send(command, args...)
 }
-
@@ -0,0 +1,551 @@
+// Copyright 2019 gf Author(https://github.com/gogf/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package gredis
+
+import (
+    "bytes"
+    "encoding/gob"
+    "encoding/json"
+    "github.com/gogf/gf/g/os/gcache"
+    "github.com/gogf/gf/g/os/glog"
+    "github.com/gogf/gf/g/util/gconv"
+    "github.com/gogf/gf/third/github.com/gomodule/redigo/redis"
+    "strings"
+    "sync"
+    "time"
+)
+
+const (
+    gCACHE_DEFAULT_PREFIX  = "{gcache}:"          // Default key prefix of cache adapter, which has a hash tag for cluster.
+    gCACHE_DEFAULT_CHANNEL = "gcache:invalidation" // Default channel of cache invalidator.
+    gCACHE_INDEX_SUFFIX    = "\x00keys"            // Suffix of the key index name, which never conflicts with cache keys in practice.
+    gCACHE_TYPE_STRING     = 's'                   // Encoding type of string value.
+    gCACHE_TYPE_BYTES      = 'b'                   // Encoding type of []byte value.
+    gCACHE_TYPE_GOB        = 'g'                   // Encoding type of values encoded as gob, which keeps the value type.
+    gCACHE_TYPE_JSON       = 'j'                   // Encoding type of values whose type is not registered to gob, which are encoded as JSON.
+
+    // Removes the expired keys from the key index, the scores of the index are the expiring timestamps in milliseconds.
+    gCACHE_SCRIPT_CLEAN_INDEX = `
+redis.replicate_commands()
+local time = redis.call("TIME")
+local now  = tonumber(time[1]) * 1000 + math.floor(tonumber(time[2]) / 1000)
+redis.call("ZREMRANGEBYSCORE", KEYS[1], "-inf", now)
+`
+
+    // Sets the values and adds the keys to the key index.
+    // KEYS[1] is the key index, KEYS[2...] are the cache keys, ARGV[1] is the expiring time in milliseconds,
+    // ARGV[2] is "1" for setting only if not exists, ARGV[3...] are the values. It returns the count of keys set.
+    gCACHE_SET_SCRIPT = gCACHE_SCRIPT_CLEAN_INDEX + `
+local expire = tonumber(ARGV[1])
+local score  = "+inf"
+if expire > 0 then
+    score = now + expire
+end
+local count = 0
+for i = 2, #KEYS do
+    local args = {"SET", KEYS[i], ARGV[i + 1]}
+    if expire > 0 then
+        table.insert(args, "PX")
+        table.insert(args, expire)
+    end
+    if ARGV[2] == "1" then
+        table.insert(args, "NX")
+    end
+    if redis.call(unpack(args)) then
+        redis.call("ZADD", KEYS[1], score, KEYS[i])
+        count = count + 1
+    end
+end
+return count`
+
+    // Returns the count of keys in the key index.
+    gCACHE_SIZE_SCRIPT = gCACHE_SCRIPT_CLEAN_INDEX + `
+return redis.call("ZCARD", KEYS[1])`
+
+    // Returns the keys in the key index.
+    gCACHE_KEYS_SCRIPT = gCACHE_SCRIPT_CLEAN_INDEX + `
+return redis.call("ZRANGE", KEYS[1], 0, -1)`
+
+    // Deletes all keys in the key index and the index itself.
+    gCACHE_CLEAR_SCRIPT = `
+local keys = redis.call("ZRANGE", KEYS[1], 0, -1)
+for i = 1, #keys, 1000 do
+    redis.call("DEL", unpack(keys, i, math.min(i + 999, #keys)))
+end
+redis.call("DEL", KEYS[1])
+return #keys`
+)
+
+// CacheAdapter implements gcache.Adapter using redis,
+// which makes gcache.Cache shared between processes.
+//
+// The keys are converted to string and stored with a prefix, and the values are serialized
+// by the serializer. The default serializer keeps string and []byte values as they are,
+// and encodes other values as gob, so the decoded value has the same type as the value set.
+// The basic types, map[string]interface{}, []interface{}, map[string]string and time.Time are
+// registered already, custom types like structs should be registered using gob.Register.
+// Values of unregistered types are encoded as JSON, in which case the decoded value is the
+// generic JSON type, like map[string]interface{}, []interface{} or json.Number.
+//
+// The keys set by the adapter are recorded in a sorted set scored by expiring time, which is used by
+// Size, Keys, Data and Clear instead of scanning the whole database. So the keys not set by the adapter
+// are not counted, and the keys evicted by the maxmemory policy of server are counted until they expire.
+// The index and the cache keys are updated in the same script or transaction, so the key prefix always
+// has a hash tag, which makes all keys of the adapter in the same slot in cluster mode.
+//
+// The adapter has no error returned as gcache.Adapter, the errors are logged using glog.
+type CacheAdapter struct {
+    redis  *Redis
+    prefix string                                 // Key prefix.
+    index  string                                 // Key of the sorted set recording cache keys.
+    locker *Locker                                // Distributed locker for GetOrSetFuncLock.
+    encode func(value interface{}) ([]byte, error) // Serializer of values.
+    decode func(data []byte) (interface{}, error)  // Deserializer of values.
+}
+
+// NewCacheAdapter creates and returns a gcache adapter using <redis>,
+// the optional <prefix> is the key prefix which is "{gcache}:" in default.
+// The whole <prefix> is used as hash tag if it has no hash tag, eg: "app:" is used as "{app:}".
+//
+// Usage:
+//     cache := gcache.NewWithAdapter(gredis.NewCacheAdapter(g.Redis()))
+func NewCacheAdapter(redis *Redis, prefix...string) *CacheAdapter {
+    a := &CacheAdapter {
+        redis  : redis,
+        prefix : gCACHE_DEFAULT_PREFIX,
+        locker : NewLocker(redis),
+        encode : encodeCacheValue,
+        decode : decodeCacheValue,
+    }
+    if len(prefix) > 0 {
+        a.prefix = prefix[0]
+        if _, ok := hashTag(a.prefix); !ok {
+            a.prefix = "{" + a.prefix + "}"
+        }
+    }
+    a.index = a.prefix + gCACHE_INDEX_SUFFIX
+    return a
+}
+
+// SetSerializer sets custom serializer of cache values.
+func (a *CacheAdapter) SetSerializer(encode func(value interface{}) ([]byte, error), decode func(data []byte) (interface{}, error)) {
+    a.encode = encode
+    a.decode = decode
+}
+
+// Set sets cache with <key>-<value> pair, which is expired after <expire> milliseconds.
+// It does not expire if <expire> is 0, and it removes the <key> if <expire> < 0 or <value> is nil.
+func (a *CacheAdapter) Set(key interface{}, value interface{}, expire int) {
+    if value == nil || expire < 0 {
+        a.Remove(key)
+        return
+    }
+    a.set(map[interface{}]interface{}{key : value}, expire, false)
+}
+
+// SetIfNotExist sets cache with <key>-<value> pair if <key> does not exist,
+// and returns true if it's set. The <value> can be type of func() interface{}.
+func (a *CacheAdapter) SetIfNotExist(key interface{}, value interface{}, expire int) bool {
+    if f, ok := value.(func() interface{}); ok {
+        value = f()
+    }
+    if value == nil {
+        return false
+    }
+    return a.setNX(key, value, expire)
+}
+
+// Sets batch sets cache with key-value pairs by <data>, which is expired after <expire> milliseconds.
+func (a *CacheAdapter) Sets(data map[interface{}]interface{}, expire int) {
+    values  := make(map[interface{}]interface{}, len(data))
+    removes := make([]interface{}, 0)
+    for k, v := range data {
+        if v == nil || expire < 0 {
+            removes = append(removes, k)
+        } else {
+            values[k] = v
+        }
+    }
+    if len(removes) > 0 {
+        a.Removes(removes)
+    }
+    if len(values) > 0 {
+        a.set(values, expire, false)
+    }
+}
+
+// Get returns the value of <key>, it returns nil if it does not exist or expired.
+func (a *CacheAdapter) Get(key interface{}) interface{} {
+    reply, err := redis.Bytes(a.redis.Do("GET", a.key(key)))
+    if err != nil {
+        if err != redis.ErrNil {
+            a.error(err)
+        }
+        return nil
+    }
+    return a.decodeValue(reply)
+}
+
+// GetOrSet returns the value of <key>, or sets <key> with <value> and returns <value> if it does not exist.
+func (a *CacheAdapter) GetOrSet(key interface{}, value interface{}, expire int) interface{} {
+    if v := a.Get(key); v != nil {
+        return v
+    }
+    if f, ok := value.(func() interface{}); ok {
+        value = f()
+    }
+    return a.doSetIfNotExist(key, value, expire)
+}
+
+// GetOrSetFunc returns the value of <key>, or sets <key> with result of function <f>
+// and returns the result if it does not exist.
+func (a *CacheAdapter) GetOrSetFunc(key interface{}, f func() interface{}, expire int) interface{} {
+    if v := a.Get(key); v != nil {
+        return v
+    }
+    return a.doSetIfNotExist(key, f(), expire)
+}
+
+// GetOrSetFuncLock is like GetOrSetFunc, but the function <f> is executed within a distributed lock,
+// so that only one process executes <f> for the same key at the same time.
+func (a *CacheAdapter) GetOrSetFuncLock(key interface{}, f func() interface{}, expire int) interface{} {
+    if v := a.Get(key); v != nil {
+        return v
+    }
+    value := (interface{})(nil)
+    a.locker.LockFunc(a.key(key) + ":lock", func() {
+        if value = a.Get(key); value == nil {
+            if value = f(); value != nil {
+                a.Set(key, value, expire)
+            }
+        }
+    })
+    return value
+}
+
+// Contains checks whether <key> exists in the cache.
+func (a *CacheAdapter) Contains(key interface{}) bool {
+    n, err := redis.Int(a.redis.Do("EXISTS", a.key(key)))
+    if err != nil {
+        a.error(err)
+    }
+    return n > 0
+}
+
+// Remove deletes <key> from the cache, and returns its value.
+func (a *CacheAdapter) Remove(key interface{}) interface{} {
+    results, err := a.redis.TxPipeline().
+        Do("GET", a.key(key)).
+        Do("DEL", a.key(key)).
+        Do("ZREM", a.index, a.key(key)).
+        Exec()
+    if err != nil {
+        a.error(err)
+        return nil
+    }
+    if data := results[0].Val.Bytes(); data != nil {
+        return a.decodeValue(data)
+    }
+    return nil
+}
+
+// Removes deletes <keys> from the cache.
+func (a *CacheAdapter) Removes(keys []interface{}) {
+    if len(keys) == 0 {
+        return
+    }
+    pipe := a.redis.TxPipeline()
+    args := make([]interface{}, 0, len(keys) + 1)
+    args  = append(args, a.index)
+    for _, key := range keys {
+        pipe.Do("DEL", a.key(key))
+        args = append(args, a.key(key))
+    }
+    pipe.Do("ZREM", args...)
+    if _, err := pipe.Exec(); err != nil {
+        a.error(err)
+    }
+}
+
+// Data returns a copy of all key-value pairs in the cache, the keys are type of string.
+func (a *CacheAdapter) Data() map[interface{}]interface{} {
+    keys := a.indexKeys()
+    data := make(map[interface{}]interface{}, len(keys))
+    pipe := a.redis.Pipeline()
+    for _, key := range keys {
+        pipe.Do("GET", key)
+    }
+    results, _ := pipe.Exec()
+    for i, result := range results {
+        if result.Err != nil || result.Val.IsNil() {
+            continue
+        }
+        data[strings.TrimPrefix(keys[i], a.prefix)] = a.decodeValue(result.Val.Bytes())
+    }
+    return data
+}
+
+// Keys returns all keys in the cache, which are type of string.
+func (a *CacheAdapter) Keys() []interface{} {
+    keys  := a.KeyStrings()
+    array := make([]interface{}, len(keys))
+    for i, key := range keys {
+        array[i] = key
+    }
+    return array
+}
+
+// KeyStrings returns all keys in the cache as string slice.
+func (a *CacheAdapter) KeyStrings() []string {
+    keys := a.indexKeys()
+    for i, key := range keys {
+        keys[i] = strings.TrimPrefix(key, a.prefix)
+    }
+    return keys
+}
+
+// Values returns all values in the cache.
+func (a *CacheAdapter) Values() []interface{} {
+    data   := a.Data()
+    values := make([]interface{}, 0, len(data))
+    for _, v := range data {
+        values = append(values, v)
+    }
+    return values
+}
+
+// Size returns the count of keys in the cache.
+func (a *CacheAdapter) Size() int {
+    n, err := redis.Int(a.redis.Do("EVAL", gCACHE_SIZE_SCRIPT, 1, a.index))
+    if err != nil {
+        a.error(err)
+    }
+    return n
+}
+
+// Clear deletes all keys set by the adapter.
+func (a *CacheAdapter) Clear() {
+    if _, err := a.redis.Do("EVAL", gCACHE_CLEAR_SCRIPT, 1, a.index); err != nil {
+        a.error(err)
+    }
+}
+
+// Close does nothing, as the redis client is managed by the caller.
+func (a *CacheAdapter) Close() {
+
+}
+
+// key returns the redis key of cache <key>.
+func (a *CacheAdapter) key(key interface{}) string {
+    return a.prefix + gconv.String(key)
+}
+
+// set sets the key-value pairs of <data> and records the keys in the key index,
+// it sets only the keys not existing if <nx> is true, and returns the count of keys set.
+func (a *CacheAdapter) set(data map[interface{}]interface{}, expire int, nx bool) int {
+    keys   := make([]interface{}, 0, len(data))
+    values := make([]interface{}, 0, len(data))
+    for k, v := range data {
+        b, err := a.encode(v)
+        if err != nil {
+            a.error(err)
+            continue
+        }
+        keys   = append(keys, a.key(k))
+        values = append(values, b)
+    }
+    if len(keys) == 0 {
+        return 0
+    }
+    flag := "0"
+    if nx {
+        flag = "1"
+    }
+    args := make([]interface{}, 0, 2 * len(keys) + 5)
+    args  = append(args, gCACHE_SET_SCRIPT, len(keys) + 1, a.index)
+    args  = append(args, keys...)
+    args  = append(args, expire, flag)
+    args  = append(args, values...)
+    n, err := redis.Int(a.redis.Do("EVAL", args...))
+    if err != nil {
+        a.error(err)
+    }
+    return n
+}
+
+// setNX sets <key> with <value> if <key> does not exist, and returns true if it's set.
+func (a *CacheAdapter) setNX(key interface{}, value interface{}, expire int) bool {
+    if expire < 0 {
+        return false
+    }
+    return a.set(map[interface{}]interface{}{key : value}, expire, true) > 0
+}
+
+// doSetIfNotExist sets <key> with <value> if <key> does not exist,
+// and returns the value set or the value set by others.
+func (a *CacheAdapter) doSetIfNotExist(key interface{}, value interface{}, expire int) interface{} {
+    if value == nil {
+        return nil
+    }
+    if a.setNX(key, value, expire) {
+        return value
+    }
+    if v := a.Get(key); v != nil {
+        return v
+    }
+    return value
+}
+
+// indexKeys returns the redis keys recorded in the key index, the expired ones are excluded.
+func (a *CacheAdapter) indexKeys() []string {
+    keys, err := redis.Strings(a.redis.Do("EVAL", gCACHE_KEYS_SCRIPT, 1, a.index))
+    if err != nil {
+        a.error(err)
+    }
+    if keys == nil {
+        keys = make([]string, 0)
+    }
+    return keys
+}
+
+// decodeValue decodes <data> using the deserializer, it returns nil if fails.
+func (a *CacheAdapter) decodeValue(data []byte) interface{} {
+    value, err := a.decode(data)
+    if err != nil {
+        a.error(err)
+        return nil
+    }
+    return value
+}
+
+// error logs the error of redis operation.
+func (a *CacheAdapter) error(err error) {
+    glog.Backtrace(false).Warningf("redis cache adapter: %v", err)
+}
+
+func init() {
+    // Registers the common types for encoding them in interface values.
+    gob.Register(map[string]interface{}{})
+    gob.Register([]interface{}{})
+    gob.Register(map[string]string{})
+    gob.Register(time.Time{})
+}
+
+// encodeCacheValue is the default serializer of cache values.
+func encodeCacheValue(value interface{}) ([]byte, error) {
+    switch v := value.(type) {
+        case string:
+            return append([]byte{gCACHE_TYPE_STRING}, v...), nil
+        case []byte:
+            return append([]byte{gCACHE_TYPE_BYTES}, v...), nil
+    }
+    buffer := bytes.NewBuffer([]byte{gCACHE_TYPE_GOB})
+    if err := gob.NewEncoder(buffer).Encode(&value); err == nil {
+        return buffer.Bytes(), nil
+    }
+    // The type of value or its fields is not registered to gob.
+    data, err := json.Marshal(value)
+    if err != nil {
+        return nil, err
+    }
+    return append([]byte{gCACHE_TYPE_JSON}, data...), nil
+}
+
+// decodeCacheValue is the default deserializer of cache values.
+func decodeCacheValue(data []byte) (interface{}, error) {
+    if len(data) == 0 {
+        return nil, nil
+    }
+    switch data[0] {
+        case gCACHE_TYPE_STRING:
+            return string(data[1:]), nil
+        case gCACHE_TYPE_BYTES:
+            return data[1:], nil
+        case gCACHE_TYPE_GOB:
+            value := (interface{})(nil)
+            if err := gob.NewDecoder(bytes.NewReader(data[1:])).Decode(&value); err != nil {
+                return nil, err
+            }
+            return value, nil
+        case gCACHE_TYPE_JSON:
+            value   := (interface{})(nil)
+            decoder := json.NewDecoder(bytes.NewReader(data[1:]))
+            decoder.UseNumber()
+            if err := decoder.Decode(&value); err != nil {
+                return nil, err
+            }
+            return value, nil
+    }
+    // Value not set by the adapter is returned as string.
+    return string(data), nil
+}
+
+// CacheInvalidator implements gcache.Invalidator using redis publish/subscribe,
+// which broadcasts invalidation messages of gcache.TieredAdapter between processes.
+//
+// Usage:
+//     adapter := gcache.NewTieredAdapter(gredis.NewCacheAdapter(g.Redis()), 10000)
+//     adapter.SetInvalidator(gredis.NewCacheInvalidator(g.Redis()))
+//     cache   := gcache.NewWithAdapter(adapter)
+type CacheInvalidator struct {
+    mu            sync.Mutex
+    redis         *Redis
+    channel       string          // Channel for invalidation messages.
+    subscriptions []*Subscription // Subscriptions created by Subscribe.
+}
+
+// NewCacheInvalidator creates and returns a cache invalidator using <redis>,
+// the optional <channel> is the publishing channel which is "gcache:invalidation" in default.
+func NewCacheInvalidator(redis *Redis, channel...string) *CacheInvalidator {
+    i := &CacheInvalidator {
+        redis   : redis,
+        channel : gCACHE_DEFAULT_CHANNEL,
+    }
+    if len(channel) > 0 {
+        i.channel = channel[0]
+    }
+    return i
+}
+
+// Publish publishes <message> to the channel.
+func (i *CacheInvalidator) Publish(message []byte) error {
+    _, err := i.redis.Do("PUBLISH", i.channel, message)
+    return err
+}
+
+// Subscribe subscribes the channel, and calls <handler> for each message received.
+func (i *CacheInvalidator) Subscribe(handler func(message []byte)) error {
+    s, err := i.redis.Subscribe(func(msg *Message) {
+        handler(msg.Data)
+    }, i.channel)
+    if err != nil {
+        return err
+    }
+    i.mu.Lock()
+    i.subscriptions = append(i.subscriptions, s)
+    i.mu.Unlock()
+    return nil
+}
+
+// Close closes all the subscriptions, the redis client is not closed.
+func (i *CacheInvalidator) Close() error {
+    i.mu.Lock()
+    subscriptions  := i.subscriptions
+    i.subscriptions = nil
+    i.mu.Unlock()
+    for _, s := range subscriptions {
+        s.Close()
+    }
+    return nil
+}
+
+var (
+    // Ensures the adapter implements gcache.Adapter.
+    _ gcache.Adapter     = (*CacheAdapter)(nil)
+    // Ensures the invalidator implements gcache.Invalidator.
+    _ gcache.Invalidator = (*CacheInvalidator)(nil)
+)
@@ -0,0 +1,624 @@
+// Copyright 2019 gf Author(https://github.com/gogf/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package gredis
+
+import (
+    "errors"
+    "fmt"
+    "github.com/gogf/gf/g/container/gmap"
+    "github.com/gogf/gf/g/container/gtype"
+    "github.com/gogf/gf/g/util/gconv"
+    "github.com/gogf/gf/third/github.com/gomodule/redigo/redis"
+    "net"
+    "strconv"
+    "strings"
+    "sync"
+    "time"
+)
+
+const (
+    gCLUSTER_SLOTS          = 16384                  // Total slot count of redis cluster.
+    gCLUSTER_MAX_REDIRECTS  = 5                      // Maximum redirections for one command.
+    gCLUSTER_TRY_AGAIN_WAIT = 100 * time.Millisecond // Waiting time for TRYAGAIN/CLUSTERDOWN errors.
+)
+
+// cluster is the client for Redis Cluster,
+// which maintains the slot map and a connection pool for each node.
+type cluster struct {
+    mu         sync.RWMutex
+    config     Config
+    seeds      []string        // Seed node addresses from configuration.
+    slots      []string        // Slot => master node address.
+    pools      *gmap.StrAnyMap // Node address => *redis.Pool.
+    refreshing *gtype.Bool     // Whether the slot map is being refreshed asynchronously.
+}
+
+// newCluster creates and returns a cluster client with given configuration.
+// The slot map is lazily discovered when the first command is executed.
+func newCluster(config Config) *cluster {
+    seeds := append([]string{}, config.Addrs...)
+    if len(seeds) == 0 && config.Host != "" {
+        seeds = append(seeds, fmt.Sprintf("%s:%d", config.Host, config.Port))
+    }
+    return &cluster{
+        config     : config,
+        seeds      : seeds,
+        slots      : make([]string, gCLUSTER_SLOTS),
+        pools      : gmap.NewStrAnyMap(),
+        refreshing : gtype.NewBool(),
+    }
+}
+
+// Get returns a connection which routes commands to the cluster nodes.
+func (c *cluster) Get() redis.Conn {
+    return &clusterConn{cluster : c}
+}
+
+// Close closes all node pools of the cluster.
+func (c *cluster) Close() error {
+    var err error
+    for _, v := range c.pools.Map() {
+        if e := v.(*redis.Pool).Close(); e != nil {
+            err = e
+        }
+    }
+    c.pools.Clear()
+    return err
+}
+
+// Stats returns the sum of statistics of all node pools.
+func (c *cluster) Stats() redis.PoolStats {
+    stats := redis.PoolStats{}
+    for _, v := range c.pools.Map() {
+        s := v.(*redis.Pool).Stats()
+        stats.ActiveCount += s.ActiveCount
+        stats.IdleCount   += s.IdleCount
+    }
+    return stats
+}
+
+// setPools updates the configuration of all node pools with <f>,
+// the configuration also applies to the pools created later.
+func (c *cluster) setPools(f func(pool *redis.Pool)) {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+    pool := &redis.Pool{
+        MaxIdle         : c.config.MaxIdle,
+        MaxActive       : c.config.MaxActive,
+        IdleTimeout     : c.config.IdleTimeout,
+        MaxConnLifetime : c.config.MaxConnLifetime,
+    }
+    f(pool)
+    c.config.MaxIdle         = pool.MaxIdle
+    c.config.MaxActive       = pool.MaxActive
+    c.config.IdleTimeout     = pool.IdleTimeout
+    c.config.MaxConnLifetime = pool.MaxConnLifetime
+    for _, v := range c.pools.Map() {
+        f(v.(*redis.Pool))
+    }
+}
+
+// getPool returns the connection pool of node <addr>, it creates one if not exists.
+func (c *cluster) getPool(addr string) *redis.Pool {
+    return c.pools.GetOrSetFuncLock(addr, func() interface{} {
+        c.mu.RLock()
+        config := c.config
+        c.mu.RUnlock()
+        return newPool(config, func() (redis.Conn, error) {
+            return dial(config, addr, false)
+        }, func(conn redis.Conn, t time.Time) error {
+            _, err := conn.Do("PING")
+            return err
+        })
+    }).(*redis.Pool)
+}
+
+// dial creates a new connection to any available node, which is not managed by the node pools.
+func (c *cluster) dial() (redis.Conn, error) {
+    lastErr := errors.New("redis: no cluster node configured")
+    for _, addr := range c.nodes() {
+        conn, err := c.getPool(addr).Dial()
+        if err == nil {
+            return conn, nil
+        }
+        lastErr = err
+    }
+    return nil, lastErr
+}
+
+// nodes returns all known node addresses, including seeds and masters in slot map.
+func (c *cluster) nodes() []string {
+    c.mu.RLock()
+    defer c.mu.RUnlock()
+    addrs := append([]string{}, c.seeds...)
+    known := make(map[string]struct{})
+    for _, addr := range addrs {
+        known[addr] = struct{}{}
+    }
+    for _, addr := range c.slots {
+        if _, ok := known[addr]; !ok && addr != "" {
+            known[addr] = struct{}{}
+            addrs = append(addrs, addr)
+        }
+    }
+    return addrs
+}
+
+// refresh reloads the slot map using CLUSTER SLOTS from any available node.
+func (c *cluster) refresh() error {
+    lastErr := errors.New("redis: no cluster node configured")
+    for _, addr := range c.nodes() {
+        conn  := c.getPool(addr).Get()
+        reply, err := redis.Values(conn.Do("CLUSTER", "SLOTS"))
+        conn.Close()
+        if err != nil {
+            lastErr = err
+            continue
+        }
+        slots := make([]string, gCLUSTER_SLOTS)
+        for _, item := range reply {
+            values, err := redis.Values(item, nil)
+            if err != nil || len(values) < 3 {
+                continue
+            }
+            start, _ := redis.Int(values[0], nil)
+            end,   _ := redis.Int(values[1], nil)
+            master, _ := redis.Values(values[2], nil)
+            if len(master) < 2 {
+                continue
+            }
+            host, _ := redis.String(master[0], nil)
+            port, _ := redis.Int(master[1], nil)
+            // Empty host means the same host as the node replying.
+            if host == "" {
+                host, _, _ = net.SplitHostPort(addr)
+            }
+            node := net.JoinHostPort(host, strconv.Itoa(port))
+            for slot := start; slot <= end && slot < gCLUSTER_SLOTS; slot++ {
+                slots[slot] = node
+            }
+        }
+        c.mu.Lock()
+        c.slots = slots
+        c.mu.Unlock()
+        return nil
+    }
+    return lastErr
+}
+
+// refreshAsync refreshes the slot map asynchronously, concurrent refreshing is merged.
+func (c *cluster) refreshAsync() {
+    if !c.refreshing.Set(true) {
+        go func() {
+            defer c.refreshing.Set(false)
+            c.refresh()
+        }()
+    }
+}
+
+// slotAddr returns the master address serving <slot>, it refreshes the slot map if unknown.
+func (c *cluster) slotAddr(slot int) string {
+    c.mu.RLock()
+    addr := c.slots[slot]
+    c.mu.RUnlock()
+    if addr == "" {
+        c.refresh()
+        c.mu.RLock()
+        addr = c.slots[slot]
+        c.mu.RUnlock()
+    }
+    return addr
+}
+
+// setSlot updates the master address of <slot>, which is used for MOVED redirection.
+func (c *cluster) setSlot(slot int, addr string) {
+    if slot < 0 || slot >= gCLUSTER_SLOTS {
+        return
+    }
+    c.mu.Lock()
+    c.slots[slot] = addr
+    c.mu.Unlock()
+}
+
+// commandAddr returns the node address that <command> should be sent to.
+func (c *cluster) commandAddr(command string, args []interface{}) string {
+    if key, ok := commandKey(command, args); ok {
+        if addr := c.slotAddr(Slot(key)); addr != "" {
+            return addr
+        }
+    }
+    // Keyless commands are sent to any available node.
+    if nodes := c.nodes(); len(nodes) > 0 {
+        return nodes[0]
+    }
+    return ""
+}
+
+// do executes <command> on the node owning the key, handling MOVED/ASK redirections.
+func (c *cluster) do(command string, args []interface{}) (interface{}, error) {
+    addr := c.commandAddr(command, args)
+    if addr == "" {
+        return nil, errors.New("redis: no cluster node available")
+    }
+    asking    := false
+    refreshed := false
+    for i := 0; i <= gCLUSTER_MAX_REDIRECTS; i++ {
+        conn := c.getPool(addr).Get()
+        // The connection cannot be made, so the command is not sent yet and it's safe to retry.
+        if err := conn.Err(); err != nil {
+            conn.Close()
+            if refreshed {
+                return nil, err
+            }
+            refreshed = true
+            c.refresh()
+            if addr = c.commandAddr(command, args); addr == "" {
+                return nil, err
+            }
+            continue
+        }
+        if asking {
+            conn.Do("ASKING")
+        }
+        reply, err := conn.Do(command, args...)
+        conn.Close()
+        if err == nil {
+            return reply, nil
+        }
+        kind, slot, target := parseRedirect(err)
+        switch kind {
+            case "MOVED":
+                c.setSlot(slot, target)
+                c.refreshAsync()
+                addr, asking = target, false
+
+            case "ASK":
+                addr, asking = target, true
+
+            case "TRYAGAIN", "CLUSTERDOWN":
+                time.Sleep(gCLUSTER_TRY_AGAIN_WAIT)
+
+            default:
+                return reply, err
+        }
+    }
+    return nil, fmt.Errorf("redis: too many cluster redirections for command %s", command)
+}
+
+// parseRedirect parses cluster redirection error like "MOVED 3999 127.0.0.1:6381".
+func parseRedirect(err error) (kind string, slot int, addr string) {
+    e, ok := err.(redis.Error)
+    if !ok {
+        return
+    }
+    array := strings.Fields(string(e))
+    if len(array) == 0 {
+        return
+    }
+    switch array[0] {
+        case "MOVED", "ASK":
+            if len(array) == 3 {
+                slot, _ = strconv.Atoi(array[1])
+                return array[0], slot, array[2]
+            }
+        case "TRYAGAIN", "CLUSTERDOWN":
+            return array[0], -1, ""
+    }
+    return
+}
+
+// commandKey returns the key which decides the slot of <command>.
+func commandKey(command string, args []interface{}) (string, bool) {
+    switch strings.ToUpper(command) {
+        case "PING", "ECHO", "INFO", "TIME", "DBSIZE", "FLUSHDB", "FLUSHALL", "CLUSTER", "CONFIG",
+             "CLIENT", "COMMAND", "SCRIPT", "KEYS", "SCAN", "RANDOMKEY", "SLOWLOG", "AUTH", "SELECT",
+             "MULTI", "EXEC", "DISCARD", "UNWATCH", "READONLY", "READWRITE", "ROLE", "SAVE", "BGSAVE", "LASTSAVE":
+            return "", false
+
+        case "EVAL", "EVALSHA":
+            if len(args) > 2 && gconv.Int(args[1]) > 0 {
+                return gconv.String(args[2]), true
+            }
+            return "", false
+
+        case "BITOP", "OBJECT", "MEMORY":
+            if len(args) > 1 {
+                return gconv.String(args[1]), true
+            }
+            return "", false
+
+        case "XREAD", "XREADGROUP":
+            for i := 0; i < len(args) - 1; i++ {
+                if strings.ToUpper(gconv.String(args[i])) == "STREAMS" {
+                    return gconv.String(args[i + 1]), true
+                }
+            }
+            return "", false
+    }
+    if len(args) > 0 {
+        return gconv.String(args[0]), true
+    }
+    return "", false
+}
+
+// Slot returns the cluster hash slot of <key>, which respects the hash tag "{...}".
+func Slot(key string) int {
+    if tag, ok := hashTag(key); ok {
+        key = tag
+    }
+    return int(crc16(key) % gCLUSTER_SLOTS)
+}
+
+// hashTag returns the hash tag of <key>, which is the non-empty content between the first "{" and the following "}".
+func hashTag(key string) (string, bool) {
+    if start := strings.IndexByte(key, '{'); start >= 0 {
+        if end := strings.IndexByte(key[start + 1:], '}'); end > 0 {
+            return key[start + 1 : start + 1 + end], true
+        }
+    }
+    return "", false
+}
+
+// crc16 implements the CRC16-CCITT (XMODEM) checksum used by redis cluster.
+func crc16(s string) uint16 {
+    crc := uint16(0)
+    for i := 0; i < len(s); i++ {
+        crc ^= uint16(s[i]) << 8
+        for j := 0; j < 8; j++ {
+            if crc & 0x8000 != 0 {
+                crc = crc << 1 ^ 0x1021
+            } else {
+                crc = crc << 1
+            }
+        }
+    }
+    return crc
+}
+
+// clusterCommand is a command buffered by Send.
+type clusterCommand struct {
+    name string
+    args []interface{}
+}
+
+// clusterReply is a reply waiting for Receive.
+type clusterReply struct {
+    reply interface{}
+    err   error
+}
+
+// clusterConn implements redis.Conn for cluster mode.
+// Commands are routed to nodes separately, except that transactions (WATCH/MULTI)
+// and subscriptions are bound to a single node connection until they finish.
+type clusterConn struct {
+    cluster *cluster
+    conn    redis.Conn       // Bound node connection for transaction or subscription.
+    multi   bool             // MULTI is requested but not bound to any node yet.
+    pending []clusterCommand // Commands buffered by Send.
+    replies []clusterReply   // Replies of flushed commands.
+    closed  bool
+}
+
+// Close releases the bound node connection.
+func (c *clusterConn) Close() error {
+    c.closed = true
+    c.release()
+    c.pending = nil
+    c.replies = nil
+    return nil
+}
+
+// Err returns a non-nil value when the connection is not usable.
+func (c *clusterConn) Err() error {
+    if c.closed {
+        return errors.New("redis: connection closed")
+    }
+    if c.conn != nil {
+        return c.conn.Err()
+    }
+    return nil
+}
+
+// Do sends a command to the cluster and returns the received reply.
+// Like redigo, the buffered commands are flushed and the last reply is returned
+// if <command> is empty.
+func (c *clusterConn) Do(command string, args ...interface{}) (interface{}, error) {
+    if c.closed {
+        return nil, errors.New("redis: connection closed")
+    }
+    if c.conn != nil {
+        return c.doBound(command, args...)
+    }
+    var pendingErr error
+    if len(c.pending) > 0 {
+        start := len(c.replies)
+        c.Flush()
+        replies  := c.replies[start:]
+        c.replies = c.replies[:start]
+        for _, r := range replies {
+            if r.err != nil && pendingErr == nil {
+                pendingErr = r.err
+            }
+        }
+        if command == "" {
+            values := make([]interface{}, len(replies))
+            for i, r := range replies {
+                values[i] = r.reply
+                if r.err != nil {
+                    values[i] = r.err
+                }
+            }
+            // The rest replies are pending on the node connection bound while flushing.
+            if c.conn != nil {
+                rest, err := redis.Values(c.conn.Do(""))
+                if err != nil && err != redis.ErrNil {
+                    return nil, err
+                }
+                values = append(values, rest...)
+            }
+            return values, nil
+        }
+        // The commands may have bound a node connection, eg: MULTI/WATCH.
+        if c.conn != nil {
+            reply, err := c.doBound(command, args...)
+            if err == nil {
+                err = pendingErr
+            }
+            return reply, err
+        }
+    }
+    if command == "" {
+        return nil, nil
+    }
+    reply, err := c.doUnbound(command, args...)
+    if err == nil {
+        err = pendingErr
+    }
+    return reply, err
+}
+
+// doUnbound executes command without bound node connection.
+func (c *clusterConn) doUnbound(command string, args ...interface{}) (interface{}, error) {
+    switch strings.ToUpper(command) {
+        case "MULTI":
+            c.multi = true
+            return "OK", nil
+
+        case "EXEC":
+            if c.multi {
+                c.multi = false
+                return []interface{}{}, nil
+            }
+
+        case "DISCARD":
+            if c.multi {
+                c.multi = false
+                return "OK", nil
+            }
+
+        case "WATCH", "SUBSCRIBE", "PSUBSCRIBE":
+            if err := c.bind(command, args); err != nil {
+                return nil, err
+            }
+            return c.doBound(command, args...)
+    }
+    if c.multi {
+        if err := c.bind(command, args); err != nil {
+            return nil, err
+        }
+        return c.doBound(command, args...)
+    }
+    return c.cluster.do(command, args)
+}
+
+// doBound executes command on the bound node connection,
+// the connection is released when the transaction finishes.
+func (c *clusterConn) doBound(command string, args ...interface{}) (interface{}, error) {
+    reply, err := c.conn.Do(command, args...)
+    switch strings.ToUpper(command) {
+        case "EXEC", "DISCARD":
+            c.release()
+        case "UNWATCH":
+            if !c.multi {
+                c.release()
+            }
+        case "MULTI":
+            c.multi = true
+    }
+    return reply, err
+}
+
+// bind binds a node connection for <command>, MULTI is sent if it's requested before.
+func (c *clusterConn) bind(command string, args []interface{}) error {
+    addr := c.cluster.commandAddr(command, args)
+    if addr == "" {
+        return errors.New("redis: no cluster node available")
+    }
+    conn := c.cluster.getPool(addr).Get()
+    if err := conn.Err(); err != nil {
+        conn.Close()
+        return err
+    }
+    if c.multi {
+        if _, err := conn.Do("MULTI"); err != nil {
+            conn.Close()
+            return err
+        }
+    }
+    c.conn = conn
+    return nil
+}
+
+// release returns the bound node connection to its pool.
+func (c *clusterConn) release() {
+    if c.conn != nil {
+        c.conn.Close()
+        c.conn = nil
+    }
+    c.multi = false
+}
+
+// Send writes the command to the output buffer.
+func (c *clusterConn) Send(command string, args ...interface{}) error {
+    if c.closed {
+        return errors.New("redis: connection closed")
+    }
+    if c.conn != nil {
+        return c.conn.Send(command, args...)
+    }
+    switch strings.ToUpper(command) {
+        // Subscription needs a bound connection for receiving messages.
+        case "SUBSCRIBE", "PSUBSCRIBE":
+            if len(c.pending) == 0 {
+                if err := c.bind(command, args); err != nil {
+                    return err
+                }
+                return c.conn.Send(command, args...)
+            }
+    }
+    c.pending = append(c.pending, clusterCommand{command, args})
+    return nil
+}
+
+// Flush executes the buffered commands, the replies can be retrieved using Receive.
+func (c *clusterConn) Flush() error {
+    if c.closed {
+        return errors.New("redis: connection closed")
+    }
+    pending  := c.pending
+    c.pending = nil
+    for i, cmd := range pending {
+        if c.conn != nil {
+            // The rest commands are sent to the bound connection.
+            for _, cmd := range pending[i:] {
+                if err := c.conn.Send(cmd.name, cmd.args...); err != nil {
+                    return err
+                }
+            }
+            return c.conn.Flush()
+        }
+        reply, err := c.doUnbound(cmd.name, cmd.args...)
+        c.replies = append(c.replies, clusterReply{reply, err})
+    }
+    if c.conn != nil {
+        return c.conn.Flush()
+    }
+    return nil
+}
+
+// Receive receives a single reply of flushed commands.
+func (c *clusterConn) Receive() (interface{}, error) {
+    if len(c.replies) > 0 {
+        r        := c.replies[0]
+        c.replies = c.replies[1:]
+        return r.reply, r.err
+    }
+    if c.conn != nil {
+        return c.conn.Receive()
+    }
+    return nil, errors.New("redis: no pending reply to receive")
+}
@@ -0,0 +1,74 @@
+// Copyright 2019 gf Author(https://github.com/gogf/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package gredis
+
+import (
+    "github.com/gogf/gf/g/container/gvar"
+    "github.com/gogf/gf/third/github.com/gomodule/redigo/redis"
+    "time"
+)
+
+var (
+    // ErrNil indicates that a reply value is nil, eg: the key or member does not exist.
+    ErrNil = redis.ErrNil
+)
+
+// toVar converts the reply to *gvar.Var.
+// A nil reply (key does not exist) is converted to a Var whose IsNil returns true.
+func toVar(reply interface{}, err error) (*gvar.Var, error) {
+    if err != nil {
+        return nil, err
+    }
+    return gvar.New(reply, true), nil
+}
+
+// toVars converts the multi-bulk reply to slice of *gvar.Var.
+func toVars(reply interface{}, err error) ([]*gvar.Var, error) {
+    values, err := redis.Values(reply, err)
+    if err != nil {
+        if err == redis.ErrNil {
+            return nil, nil
+        }
+        return nil, err
+    }
+    vars := make([]*gvar.Var, len(values))
+    for i, v := range values {
+        vars[i] = gvar.New(v, true)
+    }
+    return vars, nil
+}
+
+// toStrings converts the multi-bulk reply to slice of string, a nil reply is converted to empty slice.
+func toStrings(reply interface{}, err error) ([]string, error) {
+    array, err := redis.Strings(reply, err)
+    if err == redis.ErrNil {
+        return nil, nil
+    }
+    return array, err
+}
+
+// keyArgs returns the command arguments with leading <key> and following <values>.
+func keyArgs(key string, values []interface{}) []interface{} {
+    return append([]interface{}{key}, values...)
+}
+
+// stringArgs converts <values> to command arguments.
+func stringArgs(values []string) []interface{} {
+    args := make([]interface{}, len(values))
+    for i, v := range values {
+        args[i] = v
+    }
+    return args
+}
+
+// durationMs converts <d> to milliseconds, which is at least 1 for positive durations.
+func durationMs(d time.Duration) int64 {
+    if ms := int64(d / time.Millisecond); ms > 0 || d <= 0 {
+        return ms
+    }
+    return 1
+}
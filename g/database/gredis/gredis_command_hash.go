@@ -0,0 +1,110 @@
+// Copyright 2019 gf Author(https://github.com/gogf/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package gredis
+
+import (
+    "github.com/gogf/gf/g/container/gvar"
+    "github.com/gogf/gf/g/util/gconv"
+    "github.com/gogf/gf/third/github.com/gomodule/redigo/redis"
+)
+
+// HGet returns the value associated with <field> in the hash stored at <key>.
+// The returned Var is nil(IsNil returns true) if <field> or <key> does not exist.
+func (r *Redis) HGet(key, field string) (*gvar.Var, error) {
+    return toVar(r.Do("HGET", key, field))
+}
+
+// HSet sets <field> in the hash stored at <key> to <value>.
+// It returns true if <field> is a new field in the hash.
+func (r *Redis) HSet(key, field string, value interface{}) (bool, error) {
+    return redis.Bool(r.Do("HSET", key, field, value))
+}
+
+// HSetNX sets <field> in the hash stored at <key> to <value>, only if <field> does not exist yet.
+func (r *Redis) HSetNX(key, field string, value interface{}) (bool, error) {
+    return redis.Bool(r.Do("HSETNX", key, field, value))
+}
+
+// HMSet sets the specified fields to their respective values in the hash stored at <key>.
+// The parameter <data> can be type of map or struct, which is converted to map using gconv.Map,
+// so the struct attributes can be mapped to hash fields using "gconv" or "json" tags.
+func (r *Redis) HMSet(key string, data interface{}) error {
+    m := gconv.Map(data)
+    if len(m) == 0 {
+        return nil
+    }
+    args := make([]interface{}, 0, len(m)*2 + 1)
+    args  = append(args, key)
+    for k, v := range m {
+        args = append(args, k, v)
+    }
+    _, err := r.Do("HMSET", args...)
+    return err
+}
+
+// HMGet returns the values associated with the specified <fields> in the hash stored at <key>,
+// the Var is nil for every field that does not exist.
+func (r *Redis) HMGet(key string, fields...string) ([]*gvar.Var, error) {
+    return toVars(r.Do("HMGET", keyArgs(key, stringArgs(fields))...))
+}
+
+// HGetAll returns all fields and values of the hash stored at <key>.
+func (r *Redis) HGetAll(key string) (map[string]string, error) {
+    return redis.StringMap(r.Do("HGETALL", key))
+}
+
+// HGetAllStruct retrieves all fields and values of the hash stored at <key>,
+// and converts them to struct object <pointer> using gconv.Struct.
+// It returns ErrNil if <key> does not exist.
+func (r *Redis) HGetAllStruct(key string, pointer interface{}) error {
+    m, err := r.HGetAll(key)
+    if err != nil {
+        return err
+    }
+    if len(m) == 0 {
+        return ErrNil
+    }
+    return gconv.Struct(m, pointer)
+}
+
+// HDel removes the specified <fields> from the hash stored at <key>,
+// and returns the number of fields that were removed.
+func (r *Redis) HDel(key string, fields...string) (int64, error) {
+    return redis.Int64(r.Do("HDEL", keyArgs(key, stringArgs(fields))...))
+}
+
+// HExists checks whether <field> is an existing field in the hash stored at <key>.
+func (r *Redis) HExists(key, field string) (bool, error) {
+    return redis.Bool(r.Do("HEXISTS", key, field))
+}
+
+// HIncrBy increments the number stored at <field> in the hash stored at <key> by <increment>,
+// and returns the value after the increment.
+func (r *Redis) HIncrBy(key, field string, increment int64) (int64, error) {
+    return redis.Int64(r.Do("HINCRBY", key, field, increment))
+}
+
+// HIncrByFloat increments the float number stored at <field> in the hash stored at <key> by <increment>,
+// and returns the value after the increment.
+func (r *Redis) HIncrByFloat(key, field string, increment float64) (float64, error) {
+    return redis.Float64(r.Do("HINCRBYFLOAT", key, field, increment))
+}
+
+// HKeys returns all field names in the hash stored at <key>.
+func (r *Redis) HKeys(key string) ([]string, error) {
+    return toStrings(r.Do("HKEYS", key))
+}
+
+// HVals returns all values in the hash stored at <key>.
+func (r *Redis) HVals(key string) ([]*gvar.Var, error) {
+    return toVars(r.Do("HVALS", key))
+}
+
+// HLen returns the number of fields contained in the hash stored at <key>.
+func (r *Redis) HLen(key string) (int64, error) {
+    return redis.Int64(r.Do("HLEN", key))
+}
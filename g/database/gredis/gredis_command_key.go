@@ -0,0 +1,72 @@
+// Copyright 2019 gf Author(https://github.com/gogf/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package gredis
+
+import (
+    "github.com/gogf/gf/third/github.com/gomodule/redigo/redis"
+    "time"
+)
+
+const (
+    // TTL returned by TTL if the key exists but has no associated expire.
+    TTL_NO_EXPIRE  = time.Duration(-1)
+    // TTL returned by TTL if the key does not exist.
+    TTL_NOT_EXISTS = time.Duration(-2)
+)
+
+// Del removes the specified <keys> and returns the number of keys that were removed.
+func (r *Redis) Del(keys...string) (int64, error) {
+    return redis.Int64(r.Do("DEL", stringArgs(keys)...))
+}
+
+// Exists returns the number of <keys> existing.
+func (r *Redis) Exists(keys...string) (int64, error) {
+    return redis.Int64(r.Do("EXISTS", stringArgs(keys)...))
+}
+
+// Expire sets a timeout on <key> in milliseconds precision.
+// It returns false if <key> does not exist.
+func (r *Redis) Expire(key string, ttl time.Duration) (bool, error) {
+    return redis.Bool(r.Do("PEXPIRE", key, durationMs(ttl)))
+}
+
+// ExpireAt sets the expiring time of <key> to <t>.
+// It returns false if <key> does not exist.
+func (r *Redis) ExpireAt(key string, t time.Time) (bool, error) {
+    return redis.Bool(r.Do("PEXPIREAT", key, t.UnixNano()/int64(time.Millisecond)))
+}
+
+// Persist removes the existing timeout on <key>.
+// It returns false if <key> does not exist or does not have an associated timeout.
+func (r *Redis) Persist(key string) (bool, error) {
+    return redis.Bool(r.Do("PERSIST", key))
+}
+
+// TTL returns the remaining time to live of <key> in milliseconds precision.
+// It returns TTL_NO_EXPIRE if <key> has no associated expire,
+// and TTL_NOT_EXISTS if <key> does not exist.
+func (r *Redis) TTL(key string) (time.Duration, error) {
+    ms, err := redis.Int64(r.Do("PTTL", key))
+    if err != nil {
+        return 0, err
+    }
+    if ms < 0 {
+        return time.Duration(ms), nil
+    }
+    return time.Duration(ms) * time.Millisecond, nil
+}
+
+// Type returns the type of value stored at <key>, which is "none" if <key> does not exist.
+func (r *Redis) Type(key string) (string, error) {
+    return redis.String(r.Do("TYPE", key))
+}
+
+// Rename renames <key> to <newKey>.
+func (r *Redis) Rename(key, newKey string) error {
+    _, err := r.Do("RENAME", key, newKey)
+    return err
+}
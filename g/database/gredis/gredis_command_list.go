@@ -0,0 +1,97 @@
+// Copyright 2019 gf Author(https://github.com/gogf/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package gredis
+
+import (
+    "github.com/gogf/gf/g/container/gvar"
+    "github.com/gogf/gf/third/github.com/gomodule/redigo/redis"
+    "time"
+)
+
+// LPush inserts all the specified <values> at the head of the list stored at <key>,
+// and returns the length of the list after the push operations.
+func (r *Redis) LPush(key string, values...interface{}) (int64, error) {
+    return redis.Int64(r.Do("LPUSH", keyArgs(key, values)...))
+}
+
+// RPush inserts all the specified <values> at the tail of the list stored at <key>,
+// and returns the length of the list after the push operations.
+func (r *Redis) RPush(key string, values...interface{}) (int64, error) {
+    return redis.Int64(r.Do("RPUSH", keyArgs(key, values)...))
+}
+
+// LPop removes and returns the first element of the list stored at <key>.
+// The returned Var is nil(IsNil returns true) if the list is empty.
+func (r *Redis) LPop(key string) (*gvar.Var, error) {
+    return toVar(r.Do("LPOP", key))
+}
+
+// RPop removes and returns the last element of the list stored at <key>.
+// The returned Var is nil(IsNil returns true) if the list is empty.
+func (r *Redis) RPop(key string) (*gvar.Var, error) {
+    return toVar(r.Do("RPOP", key))
+}
+
+// BLPop is the blocking version of LPop, which pops from the first non-empty list of <keys>.
+// It returns the key where the element was popped and the element,
+// or ErrNil if no element could be popped in <timeout>, a zero <timeout> blocks indefinitely.
+func (r *Redis) BLPop(timeout time.Duration, keys...string) (string, *gvar.Var, error) {
+    return r.bpop("BLPOP", timeout, keys)
+}
+
+// BRPop is the blocking version of RPop, which pops from the first non-empty list of <keys>.
+// It returns the key where the element was popped and the element,
+// or ErrNil if no element could be popped in <timeout>, a zero <timeout> blocks indefinitely.
+func (r *Redis) BRPop(timeout time.Duration, keys...string) (string, *gvar.Var, error) {
+    return r.bpop("BRPOP", timeout, keys)
+}
+
+// bpop executes blocking list pop <command>.
+func (r *Redis) bpop(command string, timeout time.Duration, keys []string) (string, *gvar.Var, error) {
+    seconds := int64(timeout / time.Second)
+    if timeout > 0 && seconds == 0 {
+        seconds = 1
+    }
+    values, err := redis.Values(r.Do(command, append(stringArgs(keys), seconds)...))
+    if err != nil {
+        return "", nil, err
+    }
+    if len(values) != 2 {
+        return "", nil, ErrNil
+    }
+    key, _ := redis.String(values[0], nil)
+    return key, gvar.New(values[1], true), nil
+}
+
+// LIndex returns the element at <index> in the list stored at <key>.
+// The returned Var is nil(IsNil returns true) if <index> is out of range.
+func (r *Redis) LIndex(key string, index int64) (*gvar.Var, error) {
+    return toVar(r.Do("LINDEX", key, index))
+}
+
+// LRange returns the elements between <start> and <stop> (both inclusive) of the list stored at <key>,
+// negative index indicates offsets starting at the end of the list.
+func (r *Redis) LRange(key string, start, stop int64) ([]string, error) {
+    return toStrings(r.Do("LRANGE", key, start, stop))
+}
+
+// LLen returns the length of the list stored at <key>.
+func (r *Redis) LLen(key string) (int64, error) {
+    return redis.Int64(r.Do("LLEN", key))
+}
+
+// LRem removes the first <count> occurrences of elements equal to <value> from the list stored at <key>,
+// and returns the number of removed elements.
+func (r *Redis) LRem(key string, count int64, value interface{}) (int64, error) {
+    return redis.Int64(r.Do("LREM", key, count, value))
+}
+
+// LTrim trims the list stored at <key> so that it will contain only the specified range of elements.
+func (r *Redis) LTrim(key string, start, stop int64) error {
+    _, err := r.Do("LTRIM", key, start, stop)
+    return err
+}
@@ -0,0 +1,61 @@
+// Copyright 2019 gf Author(https://github.com/gogf/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package gredis
+
+import (
+    "github.com/gogf/gf/g/container/gvar"
+    "github.com/gogf/gf/third/github.com/gomodule/redigo/redis"
+)
+
+// SAdd adds the specified <members> to the set stored at <key>,
+// and returns the number of members that were added.
+func (r *Redis) SAdd(key string, members...interface{}) (int64, error) {
+    return redis.Int64(r.Do("SADD", keyArgs(key, members)...))
+}
+
+// SRem removes the specified <members> from the set stored at <key>,
+// and returns the number of members that were removed.
+func (r *Redis) SRem(key string, members...interface{}) (int64, error) {
+    return redis.Int64(r.Do("SREM", keyArgs(key, members)...))
+}
+
+// SMembers returns all the members of the set stored at <key>.
+func (r *Redis) SMembers(key string) ([]string, error) {
+    return toStrings(r.Do("SMEMBERS", key))
+}
+
+// SIsMember checks whether <member> is a member of the set stored at <key>.
+func (r *Redis) SIsMember(key string, member interface{}) (bool, error) {
+    return redis.Bool(r.Do("SISMEMBER", key, member))
+}
+
+// SCard returns the number of members of the set stored at <key>.
+func (r *Redis) SCard(key string) (int64, error) {
+    return redis.Int64(r.Do("SCARD", key))
+}
+
+// SPop removes and returns a random member from the set stored at <key>.
+// The returned Var is nil(IsNil returns true) if the set is empty.
+func (r *Redis) SPop(key string) (*gvar.Var, error) {
+    return toVar(r.Do("SPOP", key))
+}
+
+// SInter returns the members of the set resulting from the intersection of all the given sets.
+func (r *Redis) SInter(keys...string) ([]string, error) {
+    return toStrings(r.Do("SINTER", stringArgs(keys)...))
+}
+
+// SUnion returns the members of the set resulting from the union of all the given sets.
+func (r *Redis) SUnion(keys...string) ([]string, error) {
+    return toStrings(r.Do("SUNION", stringArgs(keys)...))
+}
+
+// SDiff returns the members of the set resulting from the difference between the first set
+// and all the successive sets.
+func (r *Redis) SDiff(keys...string) ([]string, error) {
+    return toStrings(r.Do("SDIFF", stringArgs(keys)...))
+}
@@ -0,0 +1,107 @@
+// Copyright 2019 gf Author(https://github.com/gogf/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package gredis
+
+import (
+    "github.com/gogf/gf/g/container/gvar"
+    "github.com/gogf/gf/g/util/gconv"
+    "github.com/gogf/gf/third/github.com/gomodule/redigo/redis"
+    "time"
+)
+
+// Get returns the value of <key>.
+// The returned Var is nil(IsNil returns true) if <key> does not exist.
+func (r *Redis) Get(key string) (*gvar.Var, error) {
+    return toVar(r.Do("GET", key))
+}
+
+// Set sets <key> to hold <value>, with optional expiring time <ttl>.
+func (r *Redis) Set(key string, value interface{}, ttl...time.Duration) error {
+    args := []interface{}{key, value}
+    if len(ttl) > 0 && ttl[0] > 0 {
+        args = append(args, "PX", durationMs(ttl[0]))
+    }
+    _, err := r.Do("SET", args...)
+    return err
+}
+
+// SetNX sets <key> to hold <value> only if <key> does not exist, with optional expiring time <ttl>.
+// It returns true if the value was set.
+func (r *Redis) SetNX(key string, value interface{}, ttl...time.Duration) (bool, error) {
+    args := []interface{}{key, value, "NX"}
+    if len(ttl) > 0 && ttl[0] > 0 {
+        args = append(args, "PX", durationMs(ttl[0]))
+    }
+    reply, err := r.Do("SET", args...)
+    if err != nil {
+        return false, err
+    }
+    return reply != nil, nil
+}
+
+// GetSet sets <key> to <value> and returns the old value stored at <key>.
+func (r *Redis) GetSet(key string, value interface{}) (*gvar.Var, error) {
+    return toVar(r.Do("GETSET", key, value))
+}
+
+// MGet returns the values of all specified <keys> in order,
+// the Var is nil for every key that does not exist.
+func (r *Redis) MGet(keys...string) ([]*gvar.Var, error) {
+    return toVars(r.Do("MGET", stringArgs(keys)...))
+}
+
+// MSet sets the given keys to their respective values of <data>.
+// The parameter <data> can be type of map or struct, which is converted to map using gconv.Map.
+func (r *Redis) MSet(data interface{}) error {
+    m := gconv.Map(data)
+    if len(m) == 0 {
+        return nil
+    }
+    args := make([]interface{}, 0, len(m)*2)
+    for k, v := range m {
+        args = append(args, k, v)
+    }
+    _, err := r.Do("MSET", args...)
+    return err
+}
+
+// Incr increments the number stored at <key> by one, and returns the value after the increment.
+func (r *Redis) Incr(key string) (int64, error) {
+    return redis.Int64(r.Do("INCR", key))
+}
+
+// IncrBy increments the number stored at <key> by <increment>, and returns the value after the increment.
+func (r *Redis) IncrBy(key string, increment int64) (int64, error) {
+    return redis.Int64(r.Do("INCRBY", key, increment))
+}
+
+// IncrByFloat increments the float number stored at <key> by <increment>,
+// and returns the value after the increment.
+func (r *Redis) IncrByFloat(key string, increment float64) (float64, error) {
+    return redis.Float64(r.Do("INCRBYFLOAT", key, increment))
+}
+
+// Decr decrements the number stored at <key> by one, and returns the value after the decrement.
+func (r *Redis) Decr(key string) (int64, error) {
+    return redis.Int64(r.Do("DECR", key))
+}
+
+// DecrBy decrements the number stored at <key> by <decrement>, and returns the value after the decrement.
+func (r *Redis) DecrBy(key string, decrement int64) (int64, error) {
+    return redis.Int64(r.Do("DECRBY", key, decrement))
+}
+
+// Append appends <value> at the end of the string stored at <key>,
+// and returns the length of the string after the append operation.
+func (r *Redis) Append(key string, value interface{}) (int64, error) {
+    return redis.Int64(r.Do("APPEND", key, value))
+}
+
+// StrLen returns the length of the string stored at <key>.
+func (r *Redis) StrLen(key string) (int64, error) {
+    return redis.Int64(r.Do("STRLEN", key))
+}
@@ -0,0 +1,124 @@
+// Copyright 2019 gf Author(https://github.com/gogf/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package gredis
+
+import (
+    "github.com/gogf/gf/g/util/gconv"
+    "github.com/gogf/gf/third/github.com/gomodule/redigo/redis"
+)
+
+// Z is a member with score of sorted set.
+type Z struct {
+    Score  float64
+    Member interface{} // Member, which is always type of string for retrieved members.
+}
+
+// ZAdd adds all the specified <members> with their scores to the sorted set stored at <key>,
+// and returns the number of members added (not including the members whose score was updated).
+func (r *Redis) ZAdd(key string, members...Z) (int64, error) {
+    args := make([]interface{}, 0, len(members)*2 + 1)
+    args  = append(args, key)
+    for _, z := range members {
+        args = append(args, z.Score, z.Member)
+    }
+    return redis.Int64(r.Do("ZADD", args...))
+}
+
+// ZRem removes the specified <members> from the sorted set stored at <key>,
+// and returns the number of members removed.
+func (r *Redis) ZRem(key string, members...interface{}) (int64, error) {
+    return redis.Int64(r.Do("ZREM", keyArgs(key, members)...))
+}
+
+// ZScore returns the score of <member> in the sorted set stored at <key>.
+// It returns ErrNil if <member> or <key> does not exist.
+func (r *Redis) ZScore(key string, member interface{}) (float64, error) {
+    return redis.Float64(r.Do("ZSCORE", key, member))
+}
+
+// ZIncrBy increments the score of <member> in the sorted set stored at <key> by <increment>,
+// and returns the new score.
+func (r *Redis) ZIncrBy(key string, increment float64, member interface{}) (float64, error) {
+    return redis.Float64(r.Do("ZINCRBY", key, increment, member))
+}
+
+// ZCard returns the number of members of the sorted set stored at <key>.
+func (r *Redis) ZCard(key string) (int64, error) {
+    return redis.Int64(r.Do("ZCARD", key))
+}
+
+// ZCount returns the number of members in the sorted set stored at <key> with a score between <min> and <max>.
+// The <min> and <max> can be "-inf", "+inf" or exclusive intervals like "(1".
+func (r *Redis) ZCount(key string, min, max interface{}) (int64, error) {
+    return redis.Int64(r.Do("ZCOUNT", key, min, max))
+}
+
+// ZRank returns the rank of <member> in the sorted set stored at <key>, with the scores ordered from low to high.
+// It returns ErrNil if <member> or <key> does not exist.
+func (r *Redis) ZRank(key string, member interface{}) (int64, error) {
+    return redis.Int64(r.Do("ZRANK", key, member))
+}
+
+// ZRevRank returns the rank of <member> in the sorted set stored at <key>, with the scores ordered from high to low.
+// It returns ErrNil if <member> or <key> does not exist.
+func (r *Redis) ZRevRank(key string, member interface{}) (int64, error) {
+    return redis.Int64(r.Do("ZREVRANK", key, member))
+}
+
+// ZRange returns the members between <start> and <stop> of the sorted set stored at <key>,
+// with the scores ordered from low to high.
+func (r *Redis) ZRange(key string, start, stop int64) ([]string, error) {
+    return toStrings(r.Do("ZRANGE", key, start, stop))
+}
+
+// ZRangeWithScores is like ZRange, but it also returns the scores of members.
+func (r *Redis) ZRangeWithScores(key string, start, stop int64) ([]Z, error) {
+    return toZs(r.Do("ZRANGE", key, start, stop, "WITHSCORES"))
+}
+
+// ZRevRange returns the members between <start> and <stop> of the sorted set stored at <key>,
+// with the scores ordered from high to low.
+func (r *Redis) ZRevRange(key string, start, stop int64) ([]string, error) {
+    return toStrings(r.Do("ZREVRANGE", key, start, stop))
+}
+
+// ZRevRangeWithScores is like ZRevRange, but it also returns the scores of members.
+func (r *Redis) ZRevRangeWithScores(key string, start, stop int64) ([]Z, error) {
+    return toZs(r.Do("ZREVRANGE", key, start, stop, "WITHSCORES"))
+}
+
+// ZRangeByScore returns the members in the sorted set stored at <key> with a score between <min> and <max>,
+// with the scores ordered from low to high. The optional <limit> is the offset and count of returned members.
+func (r *Redis) ZRangeByScore(key string, min, max interface{}, limit...int64) ([]string, error) {
+    args := []interface{}{key, min, max}
+    if len(limit) > 1 {
+        args = append(args, "LIMIT", limit[0], limit[1])
+    }
+    return toStrings(r.Do("ZRANGEBYSCORE", args...))
+}
+
+// ZRemRangeByScore removes the members in the sorted set stored at <key> with a score between <min> and <max>,
+// and returns the number of members removed.
+func (r *Redis) ZRemRangeByScore(key string, min, max interface{}) (int64, error) {
+    return redis.Int64(r.Do("ZREMRANGEBYSCORE", key, min, max))
+}
+
+// toZs converts the reply of member-score pairs to slice of Z.
+func toZs(reply interface{}, err error) ([]Z, error) {
+    values, err := toStrings(reply, err)
+    if err != nil {
+        return nil, err
+    }
+    array := make([]Z, len(values)/2)
+    for i := range array {
+        array[i] = Z {
+            Member : values[i*2],
+            Score  : gconv.Float64(values[i*2 + 1]),
+        }
+    }
+    return array, nil
+}
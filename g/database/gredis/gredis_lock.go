@@ -0,0 +1,230 @@
+// Copyright 2019 gf Author(https://github.com/gogf/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package gredis
+
+import (
+    "github.com/gogf/gf/g/container/gmap"
+    "github.com/gogf/gf/g/os/glog"
+    "github.com/gogf/gf/g/util/grand"
+    "github.com/gogf/gf/third/github.com/gomodule/redigo/redis"
+    "sync"
+    "time"
+)
+
+const (
+    gLOCK_DEFAULT_TTL    = 30 * time.Second       // Lease time of lock without expire, which is renewed automatically.
+    gLOCK_RETRY_INTERVAL = 50 * time.Millisecond  // Interval for retrying in blocking Lock.
+    gLOCK_CLOCK_DRIFT    = 0.01                   // Clock drift factor of lease time for Redlock.
+    gLOCK_TOKEN_LENGTH   = 32                     // Length of random token identifying the lock holder.
+
+    // Deletes the key only if it's held by the token.
+    gLOCK_RELEASE_SCRIPT = `if redis.call("GET", KEYS[1]) == ARGV[1] then return redis.call("DEL", KEYS[1]) else return 0 end`
+    // Extends the lease of the key only if it's held by the token.
+    gLOCK_RENEW_SCRIPT   = `if redis.call("GET", KEYS[1]) == ARGV[1] then return redis.call("PEXPIRE", KEYS[1], ARGV[2]) else return 0 end`
+)
+
+// Locker is a distributed locker based on redis, which has the same write lock methods as gmlock.Locker.
+//
+// A lock is acquired using "SET key token NX PX ttl" and released using a Lua script that deletes
+// the key only if it still holds the token, so a lock can never be released by other holders.
+//
+// If the locker is created with multiple independent redis instances, it works in Redlock mode,
+// in which a lock is acquired only if it's acquired on the majority of the instances.
+type Locker struct {
+    instances []*Redis        // Independent redis instances, more than one means Redlock mode.
+    locks     *gmap.StrAnyMap // Key => *lockHolder, the locks held by current locker.
+}
+
+// lockHolder is a lock held by current locker.
+type lockHolder struct {
+    token string        // Random token identifying the holder.
+    stop  chan struct{} // Closed to stop the renewing goroutine.
+    once  sync.Once
+}
+
+// NewLocker creates and returns a distributed locker using given redis <instances>.
+// It works in Redlock mode if more than one instance is given,
+// which should be independent redis masters, not replicas or nodes of the same cluster.
+func NewLocker(instances...*Redis) *Locker {
+    return &Locker {
+        instances : instances,
+        locks     : gmap.NewStrAnyMap(),
+    }
+}
+
+// Locker creates and returns a distributed locker using current redis.
+func (r *Redis) Locker() *Locker {
+    return NewLocker(r)
+}
+
+// TryLock tries locking the <key> with write lock,
+// it returns true if success, or if the <key> is locked by others or any error occurs,
+// it returns false.
+//
+// The parameter <expire> specifies the max duration it locks.
+// If <expire> is not passed, the lock has a lease time which is renewed automatically
+// until it's unlocked, so the lock is released by server if the holder crashes.
+func (l *Locker) TryLock(key string, expire...time.Duration) bool {
+    return l.doLock(key, l.getExpire(expire...))
+}
+
+// Lock locks the <key> with write lock.
+// If the <key> is locked by others, it will block until the lock is released.
+// The parameter <expire> specifies the max duration it locks, see TryLock.
+func (l *Locker) Lock(key string, expire...time.Duration) {
+    e := l.getExpire(expire...)
+    for !l.doLock(key, e) {
+        time.Sleep(gLOCK_RETRY_INTERVAL)
+    }
+}
+
+// Unlock unlocks the write lock of the <key>, if it's held by current locker.
+func (l *Locker) Unlock(key string) {
+    v := l.locks.Remove(key)
+    if v == nil {
+        return
+    }
+    holder := v.(*lockHolder)
+    holder.once.Do(func() {
+        close(holder.stop)
+    })
+    l.eval(gLOCK_RELEASE_SCRIPT, key, holder.token)
+}
+
+// TryLockFunc locks the <key> with write lock and callback function <f>.
+// It returns true if success, or else if the <key> is locked by others, it returns false.
+//
+// It releases the lock after <f> is executed.
+//
+// The parameter <expire> specifies the max duration it locks.
+func (l *Locker) TryLockFunc(key string, f func(), expire...time.Duration) bool {
+    if l.TryLock(key, expire...) {
+        defer l.Unlock(key)
+        f()
+        return true
+    }
+    return false
+}
+
+// LockFunc locks the <key> with write lock and callback function <f>.
+// If the <key> is locked by others, it will block until the lock is released.
+//
+// It releases the lock after <f> is executed.
+//
+// The parameter <expire> specifies the max duration it locks.
+func (l *Locker) LockFunc(key string, f func(), expire...time.Duration) {
+    l.Lock(key, expire...)
+    defer l.Unlock(key)
+    f()
+}
+
+// getExpire returns the duration object passed.
+// If <expire> is not passed, it returns zero duration which means automatic renewal.
+func (l *Locker) getExpire(expire...time.Duration) time.Duration {
+    e := time.Duration(0)
+    if len(expire) > 0 {
+        e = expire[0]
+    }
+    return e
+}
+
+// doLock tries locking <key> on the instances, it returns true if success.
+func (l *Locker) doLock(key string, expire time.Duration) bool {
+    ttl := expire
+    if ttl <= 0 {
+        ttl = gLOCK_DEFAULT_TTL
+    }
+    token := grand.Str(gLOCK_TOKEN_LENGTH)
+    start := time.Now()
+    count := l.count(func(r *Redis) bool {
+        ok, err := r.SetNX(key, token, ttl)
+        return err == nil && ok
+    })
+    // The validity time left should be positive considering the time elapsed and clock drift.
+    validity := ttl - time.Since(start) - time.Duration(float64(ttl)*gLOCK_CLOCK_DRIFT) - time.Millisecond
+    if count < l.quorum() || validity <= 0 {
+        l.eval(gLOCK_RELEASE_SCRIPT, key, token)
+        return false
+    }
+    holder := &lockHolder {
+        token : token,
+        stop  : make(chan struct{}),
+    }
+    // The former holder may be lost because of expiration.
+    if v := l.locks.Get(key); v != nil {
+        former := v.(*lockHolder)
+        former.once.Do(func() {
+            close(former.stop)
+        })
+    }
+    l.locks.Set(key, holder)
+    if expire <= 0 {
+        go l.renew(key, holder, ttl)
+    }
+    return true
+}
+
+// renew extends the lease of the lock periodically until it's unlocked.
+func (l *Locker) renew(key string, holder *lockHolder, ttl time.Duration) {
+    ticker := time.NewTicker(ttl/3)
+    defer ticker.Stop()
+    for {
+        select {
+            case <-holder.stop:
+                return
+            case <-ticker.C:
+        }
+        count := l.count(func(r *Redis) bool {
+            n, err := redis.Int(r.Do("EVAL", gLOCK_RENEW_SCRIPT, 1, key, holder.token, durationMs(ttl)))
+            return err == nil && n == 1
+        })
+        if count < l.quorum() {
+            glog.Warningf(`redis lock "%s" renewing failed, the lock may be lost`, key)
+        }
+    }
+}
+
+// eval executes the lock <script> with <key> and <token> on all instances.
+func (l *Locker) eval(script, key, token string) {
+    l.count(func(r *Redis) bool {
+        _, err := r.Do("EVAL", script, 1, key, token)
+        return err == nil
+    })
+}
+
+// count executes <f> on all instances concurrently, and returns the count of successes.
+func (l *Locker) count(f func(r *Redis) bool) int {
+    if len(l.instances) == 1 {
+        if f(l.instances[0]) {
+            return 1
+        }
+        return 0
+    }
+    wg      := sync.WaitGroup{}
+    results := make(chan bool, len(l.instances))
+    for _, r := range l.instances {
+        wg.Add(1)
+        go func(r *Redis) {
+            defer wg.Done()
+            results <- f(r)
+        }(r)
+    }
+    wg.Wait()
+    close(results)
+    count := 0
+    for ok := range results {
+        if ok {
+            count++
+        }
+    }
+    return count
+}
+
+// quorum returns the minimum count of instances that a lock should be acquired on.
+func (l *Locker) quorum() int {
+    return len(l.instances)/2 + 1
+}
@@ -0,0 +1,146 @@
+// Copyright 2019 gf Author(https://github.com/gogf/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package gredis
+
+import (
+    "errors"
+    "github.com/gogf/gf/g/container/gvar"
+    "github.com/gogf/gf/third/github.com/gomodule/redigo/redis"
+)
+
+// Pipeline batches commands and sends them to server in one round trip.
+// A transactional pipeline wraps the commands with MULTI/EXEC.
+//
+// Usage:
+//     results, err := r.Pipeline().Do("SET", "k", "v").Do("INCR", "n").Exec()
+type Pipeline struct {
+    redis    *Redis
+    tx       bool              // Whether the commands are executed in MULTI/EXEC.
+    commands []pipelineCommand // Batched commands.
+}
+
+// pipelineCommand is a command batched in pipeline.
+type pipelineCommand struct {
+    name string
+    args []interface{}
+}
+
+// PipelineResult is the result of one command in pipeline.
+type PipelineResult struct {
+    Command string    // Command name.
+    Val     *gvar.Var // Reply of the command, which is nil if Err is not nil.
+    Err     error     // Error of the command.
+}
+
+var (
+    // ErrTxAborted is returned by transactional pipeline if the transaction is aborted,
+    // eg: the keys watched are modified.
+    ErrTxAborted = errors.New("redis: transaction aborted")
+)
+
+// Pipeline creates and returns a pipeline for batching commands.
+func (r *Redis) Pipeline() *Pipeline {
+    return &Pipeline{redis : r}
+}
+
+// TxPipeline creates and returns a pipeline whose commands are executed in a MULTI/EXEC transaction.
+// Note that in cluster mode all keys of the transaction should be in the same slot.
+func (r *Redis) TxPipeline() *Pipeline {
+    return &Pipeline{redis : r, tx : true}
+}
+
+// Do adds a command to the pipeline, it returns the pipeline itself for chaining.
+func (p *Pipeline) Do(command string, args...interface{}) *Pipeline {
+    p.commands = append(p.commands, pipelineCommand{command, args})
+    return p
+}
+
+// Len returns the number of batched commands.
+func (p *Pipeline) Len() int {
+    return len(p.commands)
+}
+
+// Exec sends all batched commands and returns the results in order of commands.
+// The returned error is the first error of the commands, or the error of the transaction.
+// The pipeline is reset after execution so it can be reused.
+func (p *Pipeline) Exec() ([]*PipelineResult, error) {
+    commands  := p.commands
+    p.commands = nil
+    if len(commands) == 0 {
+        return nil, nil
+    }
+    conn := p.redis.getConn()
+    defer conn.Close()
+    if p.tx {
+        if err := conn.Send("MULTI"); err != nil {
+            return nil, err
+        }
+    }
+    for _, c := range commands {
+        if err := conn.Send(c.name, c.args...); err != nil {
+            return nil, err
+        }
+    }
+    if p.tx {
+        if err := conn.Send("EXEC"); err != nil {
+            return nil, err
+        }
+    }
+    replies, err := redis.Values(conn.Do(""))
+    if err != nil {
+        return nil, err
+    }
+    if !p.tx {
+        return newPipelineResults(commands, replies)
+    }
+    // Replies of transaction: OK for MULTI, QUEUED or error for commands, and reply of EXEC.
+    if len(replies) != len(commands) + 2 {
+        return nil, errors.New("redis: invalid transaction reply")
+    }
+    switch exec := replies[len(replies) - 1].(type) {
+        case []interface{}:
+            return newPipelineResults(commands, exec)
+
+        case nil:
+            return nil, ErrTxAborted
+
+        case redis.Error:
+            // Transaction is discarded because of errors when queueing commands.
+            results := make([]*PipelineResult, len(commands))
+            for i, c := range commands {
+                results[i] = &PipelineResult{Command : c.name, Err : exec}
+                if e, ok := replies[i + 1].(redis.Error); ok {
+                    results[i].Err = e
+                }
+            }
+            return results, exec
+
+        default:
+            return nil, errors.New("redis: invalid transaction reply")
+    }
+}
+
+// newPipelineResults creates and returns the results of <commands> with <replies>.
+func newPipelineResults(commands []pipelineCommand, replies []interface{}) ([]*PipelineResult, error) {
+    if len(replies) != len(commands) {
+        return nil, errors.New("redis: invalid pipeline reply")
+    }
+    var firstErr error
+    results := make([]*PipelineResult, len(commands))
+    for i, c := range commands {
+        results[i] = &PipelineResult{Command : c.name}
+        if e, ok := replies[i].(redis.Error); ok {
+            results[i].Err = e
+            if firstErr == nil {
+                firstErr = e
+            }
+        } else {
+            results[i].Val = gvar.New(replies[i], true)
+        }
+    }
+    return results, firstErr
+}
@@ -0,0 +1,415 @@
+// Copyright 2019 gf Author(https://github.com/gogf/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package gredis
+
+import (
+    "errors"
+    "fmt"
+    "github.com/gogf/gf/g/container/gtype"
+    "github.com/gogf/gf/g/os/glog"
+    "github.com/gogf/gf/g/util/gconv"
+    "github.com/gogf/gf/g/util/grand"
+    "github.com/gogf/gf/third/github.com/gomodule/redigo/redis"
+    "os"
+    "strings"
+    "sync"
+    "time"
+)
+
+const (
+    gQUEUE_DEFAULT_GROUP      = "default"              // Default consumer group name.
+    gQUEUE_DEFAULT_WORKERS    = 10                     // Default count of workers.
+    gQUEUE_DEFAULT_RETRIES    = 3                      // Default maximum retries of a failed job.
+    gQUEUE_DEFAULT_VISIBILITY = 30 * time.Second       // Default visibility timeout of a fetched job.
+    gQUEUE_BLOCK_TIMEOUT      = time.Second            // Blocking time for fetching new jobs, which also limits the shutdown time.
+    gQUEUE_POLL_INTERVAL      = 500 * time.Millisecond // Interval for moving due delayed jobs to the stream.
+    gQUEUE_BATCH_SIZE         = 100                    // Maximum jobs for each moving/reclaiming batch.
+    gQUEUE_FIELD_PAYLOAD      = "payload"              // Stream field of job payload.
+
+    // Moves the due jobs from the delayed sorted set to the stream atomically.
+    // The member of the sorted set is "<random id>:<payload>".
+    gQUEUE_MOVE_SCRIPT = `
+local items = redis.call("ZRANGEBYSCORE", KEYS[1], "-inf", ARGV[1], "LIMIT", 0, ARGV[2])
+for _, item in ipairs(items) do
+    local pos = string.find(item, ":", 1, true)
+    redis.call("XADD", KEYS[2], "*", "payload", string.sub(item, pos + 1))
+    redis.call("ZREM", KEYS[1], item)
+end
+return #items`
+)
+
+// Job is a job fetched from queue.
+type Job struct {
+    Id      string // Unique id of the job, which is the stream entry id.
+    Payload []byte // Payload of the job.
+    Retries int    // Count of retries, which is 0 for the first delivery.
+}
+
+// QueueStats is the statistics of queue.
+type QueueStats struct {
+    Ready   int64 // Count of jobs in the stream, including the pending ones.
+    Pending int64 // Count of jobs fetched by consumers but not acknowledged.
+    Delayed int64 // Count of delayed jobs that are not due yet.
+    Dead    int64 // Count of jobs in the dead-letter stream.
+}
+
+// Queue is a reliable job queue based on Redis Streams and consumer groups.
+//
+// Jobs are persisted in stream <name>, delayed jobs are kept in sorted set "<name>:delayed"
+// until due, and jobs failing more than max retries are moved to stream "<name>:dead".
+// A job fetched by a consumer is acknowledged only after handled successfully,
+// if the consumer crashes or the handler fails, the job is reclaimed using XCLAIM by any consumer
+// after the visibility timeout, which means the handler should be idempotent.
+//
+// Note that in cluster mode the <name> should contain a hash tag like "{jobs}",
+// so that all keys of the queue are in the same slot.
+type Queue struct {
+    mu         sync.Mutex
+    redis      *Redis
+    name       string         // Stream key of ready jobs.
+    group      string         // Consumer group name.
+    consumer   string         // Consumer name of current process.
+    workers    int            // Count of workers.
+    retries    int            // Maximum retries of a failed job.
+    visibility time.Duration  // Visibility timeout of a fetched job.
+    handler    func(job *Job) error
+    reclaimed  chan *Job      // Jobs reclaimed from other consumers.
+    running    *gtype.Bool    // Whether the queue is consuming.
+    closing    chan struct{}  // Closed to stop consuming.
+    wg         sync.WaitGroup // Waits for all consuming goroutines.
+}
+
+// Queue creates and returns a job queue named <name> with optional consumer <group> name.
+// Consumers of the same group share the jobs, and each job is handled by only one consumer of the group.
+func (r *Redis) Queue(name string, group...string) *Queue {
+    q := &Queue {
+        redis      : r,
+        name       : name,
+        group      : gQUEUE_DEFAULT_GROUP,
+        workers    : gQUEUE_DEFAULT_WORKERS,
+        retries    : gQUEUE_DEFAULT_RETRIES,
+        visibility : gQUEUE_DEFAULT_VISIBILITY,
+        running    : gtype.NewBool(),
+    }
+    if len(group) > 0 && group[0] != "" {
+        q.group = group[0]
+    }
+    hostname, _ := os.Hostname()
+    q.consumer   = fmt.Sprintf("%s-%d-%s", hostname, os.Getpid(), grand.Str(6))
+    return q
+}
+
+// SetWorkers sets the count of workers handling jobs concurrently, which should be set before Start.
+func (q *Queue) SetWorkers(workers int) {
+    q.workers = workers
+}
+
+// SetMaxRetries sets the maximum retries of a failed job,
+// the job is moved to the dead-letter stream if it still fails after retries.
+func (q *Queue) SetMaxRetries(retries int) {
+    q.retries = retries
+}
+
+// SetVisibilityTimeout sets the visibility timeout of a fetched job.
+// A job not acknowledged within the timeout is reclaimed and retried,
+// so the timeout should be longer than the handling time of jobs.
+func (q *Queue) SetVisibilityTimeout(timeout time.Duration) {
+    q.visibility = timeout
+}
+
+// Enqueue adds a job with <payload> to the queue, and returns the job id.
+// The optional <delay> specifies the delay before the job is available,
+// in which case the returned id is a temporary id of the delayed job.
+func (q *Queue) Enqueue(payload interface{}, delay...time.Duration) (string, error) {
+    data := gconv.Bytes(payload)
+    if len(delay) > 0 && delay[0] > 0 {
+        id     := grand.Str(16)
+        member := append([]byte(id + ":"), data...)
+        due    := time.Now().Add(delay[0]).UnixNano()/int64(time.Millisecond)
+        if _, err := q.redis.Do("ZADD", q.delayedKey(), due, member); err != nil {
+            return "", err
+        }
+        return id, nil
+    }
+    return redis.String(q.redis.Do("XADD", q.name, "*", gQUEUE_FIELD_PAYLOAD, data))
+}
+
+// Start starts consuming the jobs with <handler> in background workers.
+// The job is acknowledged if <handler> returns nil, or else it's retried after the visibility timeout.
+func (q *Queue) Start(handler func(job *Job) error) error {
+    q.mu.Lock()
+    defer q.mu.Unlock()
+    if q.running.Val() {
+        return errors.New("redis: queue is already started")
+    }
+    // Creates the group reading from the beginning, so the jobs added before are also consumed.
+    if _, err := q.redis.Do("XGROUP", "CREATE", q.name, q.group, "0", "MKSTREAM"); err != nil {
+        if !strings.HasPrefix(err.Error(), "BUSYGROUP") {
+            return err
+        }
+    }
+    q.handler   = handler
+    q.reclaimed = make(chan *Job, q.workers)
+    q.closing   = make(chan struct{})
+    q.running.Set(true)
+    for i := 0; i < q.workers; i++ {
+        q.wg.Add(1)
+        go q.work()
+    }
+    q.wg.Add(2)
+    go q.loop(gQUEUE_POLL_INTERVAL, q.moveDelayed)
+    go q.loop(q.visibility/2, q.reclaim)
+    return nil
+}
+
+// Shutdown stops consuming gracefully, it waits until the running jobs are done.
+// The jobs reclaimed but not handled yet are retried by other consumers after the visibility timeout.
+func (q *Queue) Shutdown() {
+    q.mu.Lock()
+    defer q.mu.Unlock()
+    if !q.running.Set(false) {
+        return
+    }
+    close(q.closing)
+    q.wg.Wait()
+    // Gives up the unhandled reclaimed jobs, so they can be reclaimed by other consumers.
+    for len(q.reclaimed) > 0 {
+        <-q.reclaimed
+    }
+}
+
+// Stats returns the statistics of the queue.
+func (q *Queue) Stats() (*QueueStats, error) {
+    var err error
+    stats := new(QueueStats)
+    if stats.Ready, err = redis.Int64(q.redis.Do("XLEN", q.name)); err != nil {
+        return nil, err
+    }
+    if stats.Delayed, err = redis.Int64(q.redis.Do("ZCARD", q.delayedKey())); err != nil {
+        return nil, err
+    }
+    if stats.Dead, err = redis.Int64(q.redis.Do("XLEN", q.deadKey())); err != nil {
+        return nil, err
+    }
+    if values, err := redis.Values(q.redis.Do("XPENDING", q.name, q.group)); err == nil && len(values) > 0 {
+        stats.Pending, _ = redis.Int64(values[0], nil)
+    }
+    return stats, nil
+}
+
+// delayedKey returns the key of delayed jobs.
+func (q *Queue) delayedKey() string {
+    return q.name + ":delayed"
+}
+
+// deadKey returns the key of dead-letter stream.
+func (q *Queue) deadKey() string {
+    return q.name + ":dead"
+}
+
+// work fetches and handles jobs until the queue is shut down.
+func (q *Queue) work() {
+    defer q.wg.Done()
+    for {
+        select {
+            case <-q.closing:
+                return
+            case job := <-q.reclaimed:
+                q.handle(job)
+                continue
+            default:
+        }
+        job, err := q.fetch()
+        if err != nil {
+            glog.Warningf(`redis queue "%s" fetching failed: %v`, q.name, err)
+            select {
+                case <-q.closing:
+                    return
+                case <-time.After(gQUEUE_BLOCK_TIMEOUT):
+            }
+            continue
+        }
+        if job != nil {
+            q.handle(job)
+        }
+    }
+}
+
+// fetch fetches a new job for current consumer, it returns nil if no job available in block time.
+func (q *Queue) fetch() (*Job, error) {
+    reply, err := redis.Values(q.redis.Do(
+        "XREADGROUP", "GROUP", q.group, q.consumer, "COUNT", 1,
+        "BLOCK", durationMs(gQUEUE_BLOCK_TIMEOUT), "STREAMS", q.name, ">",
+    ))
+    if err != nil {
+        if err == redis.ErrNil {
+            return nil, nil
+        }
+        return nil, err
+    }
+    // Reply: [[stream, [[id, [field, value, ...]], ...]]]
+    for _, item := range reply {
+        stream, err := redis.Values(item, nil)
+        if err != nil || len(stream) != 2 {
+            continue
+        }
+        entries, _ := redis.Values(stream[1], nil)
+        for _, entry := range entries {
+            if job := parseJob(entry); job != nil {
+                return job, nil
+            }
+        }
+    }
+    return nil, nil
+}
+
+// handle executes the handler for <job>, and acknowledges the job if success.
+func (q *Queue) handle(job *Job) {
+    err := q.callHandler(job)
+    if err == nil {
+        q.ack(job.Id)
+        return
+    }
+    if job.Retries >= q.retries {
+        q.bury(job, err)
+    }
+    // Or else the job keeps pending and is retried after the visibility timeout.
+}
+
+// callHandler calls the handler for <job>, a panic of the handler is converted to error.
+func (q *Queue) callHandler(job *Job) (err error) {
+    defer func() {
+        if e := recover(); e != nil {
+            err = fmt.Errorf("panic: %v", e)
+        }
+    }()
+    return q.handler(job)
+}
+
+// ack acknowledges and deletes the job of <id>.
+func (q *Queue) ack(id string) {
+    _, err := q.redis.Pipeline().
+        Do("XACK", q.name, q.group, id).
+        Do("XDEL", q.name, id).
+        Exec()
+    if err != nil {
+        glog.Warningf(`redis queue "%s" acknowledging job "%s" failed: %v`, q.name, id, err)
+    }
+}
+
+// bury moves <job> to the dead-letter stream with the last error <cause>.
+func (q *Queue) bury(job *Job, cause error) {
+    _, err := q.redis.Do(
+        "XADD", q.deadKey(), "*",
+        gQUEUE_FIELD_PAYLOAD, job.Payload,
+        "id",      job.Id,
+        "retries", job.Retries,
+        "error",   cause.Error(),
+    )
+    if err != nil {
+        glog.Warningf(`redis queue "%s" moving job "%s" to dead-letter failed: %v`, q.name, job.Id, err)
+        return
+    }
+    q.ack(job.Id)
+}
+
+// loop executes <f> every <interval> until the queue is shut down.
+func (q *Queue) loop(interval time.Duration, f func()) {
+    defer q.wg.Done()
+    if interval <= 0 {
+        interval = gQUEUE_POLL_INTERVAL
+    }
+    ticker := time.NewTicker(interval)
+    defer ticker.Stop()
+    for {
+        select {
+            case <-q.closing:
+                return
+            case <-ticker.C:
+                f()
+        }
+    }
+}
+
+// moveDelayed moves the due delayed jobs to the stream.
+func (q *Queue) moveDelayed() {
+    now := time.Now().UnixNano()/int64(time.Millisecond)
+    _, err := q.redis.Do("EVAL", gQUEUE_MOVE_SCRIPT, 2, q.delayedKey(), q.name, now, gQUEUE_BATCH_SIZE)
+    if err != nil {
+        glog.Warningf(`redis queue "%s" moving delayed jobs failed: %v`, q.name, err)
+    }
+}
+
+// reclaim claims the jobs that are not acknowledged within the visibility timeout,
+// and passes them to the workers of current consumer.
+func (q *Queue) reclaim() {
+    // Reply: [[id, consumer, idle milliseconds, delivery count], ...]
+    reply, err := redis.Values(q.redis.Do("XPENDING", q.name, q.group, "-", "+", gQUEUE_BATCH_SIZE))
+    if err != nil {
+        glog.Warningf(`redis queue "%s" checking pending jobs failed: %v`, q.name, err)
+        return
+    }
+    minIdle := durationMs(q.visibility)
+    for _, item := range reply {
+        values, err := redis.Values(item, nil)
+        if err != nil || len(values) < 4 {
+            continue
+        }
+        id,    _ := redis.String(values[0], nil)
+        idle,  _ := redis.Int64(values[2], nil)
+        count, _ := redis.Int(values[3], nil)
+        if idle < minIdle {
+            continue
+        }
+        entries, err := redis.Values(q.redis.Do("XCLAIM", q.name, q.group, q.consumer, minIdle, id))
+        if err != nil || len(entries) == 0 {
+            // Claimed by other consumer already.
+            continue
+        }
+        job := parseJob(entries[0])
+        if job == nil {
+            // The entry was deleted, so just acknowledges it.
+            q.ack(id)
+            continue
+        }
+        // XCLAIM increments the delivery count.
+        job.Retries = count
+        if job.Retries > q.retries {
+            q.bury(job, errors.New("max retries exceeded"))
+            continue
+        }
+        select {
+            case q.reclaimed <- job:
+            case <-q.closing:
+                return
+        }
+    }
+}
+
+// parseJob parses the stream entry [id, [field, value, ...]] to Job.
+// It returns nil if the entry is invalid or deleted.
+func parseJob(entry interface{}) *Job {
+    values, err := redis.Values(entry, nil)
+    if err != nil || len(values) != 2 {
+        return nil
+    }
+    id, err := redis.String(values[0], nil)
+    if err != nil {
+        return nil
+    }
+    fields, err := redis.Values(values[1], nil)
+    if err != nil {
+        return nil
+    }
+    job := &Job{Id : id}
+    for i := 0; i + 1 < len(fields); i += 2 {
+        if name, _ := redis.String(fields[i], nil); name == gQUEUE_FIELD_PAYLOAD {
+            job.Payload, _ = redis.Bytes(fields[i + 1], nil)
+        }
+    }
+    return job
+}
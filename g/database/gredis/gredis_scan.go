@@ -0,0 +1,145 @@
+// Copyright 2019 gf Author(https://github.com/gogf/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package gredis
+
+import (
+    "fmt"
+    "github.com/gogf/gf/third/github.com/gomodule/redigo/redis"
+)
+
+// ScanIterator iterates the elements of SCAN/HSCAN/SSCAN/ZSCAN commands,
+// which fetches the next batch from server automatically.
+//
+// Usage:
+//     iter := r.Scan("user:*", 100)
+//     for iter.Next() {
+//         fmt.Println(iter.Key())
+//     }
+//     if err := iter.Err(); err != nil {
+//         ...
+//     }
+type ScanIterator struct {
+    redis   *Redis
+    command string   // SCAN, HSCAN, SSCAN or ZSCAN.
+    key     string   // Key of the scanned hash/set/sorted set, empty for SCAN.
+    match   string   // Optional MATCH pattern.
+    count   int      // Optional COUNT hint.
+    step    int      // Number of reply items for each element, which is 2 for HSCAN and ZSCAN.
+    cursor  string   // Cursor for next batch, "0" means the iteration is finished.
+    started bool     // Whether the first batch is fetched.
+    items   []string // Items of current batch.
+    index   int      // Index of current element in <items>.
+    err     error
+}
+
+// Scan returns an iterator for keys of current database matching <match> pattern,
+// <match> can be empty to match all keys, and <count> is the hint of batch size.
+// Note that in cluster mode it only scans the keys of one node.
+func (r *Redis) Scan(match string, count int) *ScanIterator {
+    return newScanIterator(r, "SCAN", "", match, count, 1)
+}
+
+// HScan returns an iterator for fields of hash <key>,
+// the Key method returns the field name and Val method returns the value.
+func (r *Redis) HScan(key string, match string, count int) *ScanIterator {
+    return newScanIterator(r, "HSCAN", key, match, count, 2)
+}
+
+// SScan returns an iterator for members of set <key>.
+func (r *Redis) SScan(key string, match string, count int) *ScanIterator {
+    return newScanIterator(r, "SSCAN", key, match, count, 1)
+}
+
+// ZScan returns an iterator for members of sorted set <key>,
+// the Key method returns the member and Val method returns the score.
+func (r *Redis) ZScan(key string, match string, count int) *ScanIterator {
+    return newScanIterator(r, "ZSCAN", key, match, count, 2)
+}
+
+// newScanIterator creates and returns a scan iterator.
+func newScanIterator(r *Redis, command, key, match string, count, step int) *ScanIterator {
+    return &ScanIterator{
+        redis   : r,
+        command : command,
+        key     : key,
+        match   : match,
+        count   : count,
+        step    : step,
+        cursor  : "0",
+        index   : -step,
+    }
+}
+
+// Next moves to the next element, it returns false if the iteration is finished or an error occurs.
+func (it *ScanIterator) Next() bool {
+    for it.err == nil {
+        if it.index + it.step < len(it.items) {
+            it.index += it.step
+            return true
+        }
+        if it.started && it.cursor == "0" {
+            return false
+        }
+        it.fetch()
+    }
+    return false
+}
+
+// fetch retrieves the next batch from server.
+func (it *ScanIterator) fetch() {
+    args := make([]interface{}, 0, 6)
+    if it.key != "" {
+        args = append(args, it.key)
+    }
+    args = append(args, it.cursor)
+    if it.match != "" {
+        args = append(args, "MATCH", it.match)
+    }
+    if it.count > 0 {
+        args = append(args, "COUNT", it.count)
+    }
+    values, err := redis.Values(it.redis.Do(it.command, args...))
+    if err == nil && len(values) != 2 {
+        err = fmt.Errorf("redis: invalid %s reply", it.command)
+    }
+    if err != nil {
+        it.err = err
+        return
+    }
+    it.started = true
+    if it.cursor, err = redis.String(values[0], nil); err != nil {
+        it.err = err
+        return
+    }
+    if it.items, err = redis.Strings(values[1], nil); err != nil {
+        it.err = err
+        return
+    }
+    it.index = -it.step
+}
+
+// Key returns the current key for SCAN, field for HSCAN, member for SSCAN and ZSCAN.
+func (it *ScanIterator) Key() string {
+    if it.index >= 0 && it.index < len(it.items) {
+        return it.items[it.index]
+    }
+    return ""
+}
+
+// Val returns the current value for HSCAN, score for ZSCAN,
+// and it's the same as Key for SCAN and SSCAN.
+func (it *ScanIterator) Val() string {
+    if it.index >= 0 && it.index + it.step - 1 < len(it.items) {
+        return it.items[it.index + it.step - 1]
+    }
+    return ""
+}
+
+// Err returns the error occurred during iteration.
+func (it *ScanIterator) Err() error {
+    return it.err
+}
@@ -0,0 +1,123 @@
+// Copyright 2019 gf Author(https://github.com/gogf/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package gredis
+
+import (
+    "errors"
+    "fmt"
+    "github.com/gogf/gf/third/github.com/gomodule/redigo/redis"
+    "net"
+    "sync"
+    "time"
+)
+
+const (
+    gSENTINEL_DIAL_TIMEOUT = 3 * time.Second // Timeout for connecting and querying sentinels.
+)
+
+// sentinel discovers the current master address from sentinels.
+type sentinel struct {
+    mu     sync.Mutex
+    config Config
+    addrs  []string // Sentinel addresses, the last responding one is moved to the front.
+}
+
+// newSentinelPool creates a connection pool whose connections are always made to
+// the current master discovered from sentinels.
+func newSentinelPool(config Config) *redis.Pool {
+    s := &sentinel{
+        config : config,
+        addrs  : append([]string{}, config.Addrs...),
+    }
+    return newPool(config, func() (redis.Conn, error) {
+        addr, err := s.MasterAddr()
+        if err != nil {
+            return nil, err
+        }
+        c, err := dial(config, addr, true)
+        if err != nil {
+            return nil, err
+        }
+        // The sentinels may not be aware of the failover yet.
+        if err := checkMasterRole(c); err != nil {
+            c.Close()
+            return nil, err
+        }
+        return c, nil
+    }, func(c redis.Conn, t time.Time) error {
+        // Connections to the former master are dropped after failover.
+        return checkMasterRole(c)
+    })
+}
+
+// MasterAddr queries and returns the current master address from sentinels.
+func (s *sentinel) MasterAddr() (string, error) {
+    if s.config.MasterName == "" {
+        return "", errors.New("redis: master name is required in sentinel mode")
+    }
+    s.mu.Lock()
+    addrs := append([]string{}, s.addrs...)
+    s.mu.Unlock()
+    if len(addrs) == 0 {
+        return "", errors.New("redis: no sentinel address configured")
+    }
+    lastErr := error(nil)
+    for i, addr := range addrs {
+        master, err := s.queryMaster(addr)
+        if err != nil {
+            lastErr = err
+            continue
+        }
+        // Moves the responding sentinel to the front, so it's queried first next time.
+        if i > 0 {
+            s.mu.Lock()
+            s.addrs = append([]string{addr}, append(addrs[:i:i], addrs[i + 1:]...)...)
+            s.mu.Unlock()
+        }
+        return master, nil
+    }
+    return "", fmt.Errorf("redis: no sentinel available for master \"%s\": %v", s.config.MasterName, lastErr)
+}
+
+// queryMaster queries the master address from sentinel <addr>.
+func (s *sentinel) queryMaster(addr string) (string, error) {
+    c, err := redis.Dial("tcp", addr,
+        redis.DialConnectTimeout(gSENTINEL_DIAL_TIMEOUT),
+        redis.DialReadTimeout(gSENTINEL_DIAL_TIMEOUT),
+        redis.DialWriteTimeout(gSENTINEL_DIAL_TIMEOUT),
+    )
+    if err != nil {
+        return "", err
+    }
+    defer c.Close()
+    reply, err := redis.Strings(c.Do("SENTINEL", "get-master-addr-by-name", s.config.MasterName))
+    if err != nil {
+        if err == redis.ErrNil {
+            return "", fmt.Errorf("redis: master \"%s\" is unknown to sentinel %s", s.config.MasterName, addr)
+        }
+        return "", err
+    }
+    if len(reply) != 2 {
+        return "", fmt.Errorf("redis: invalid master address reply from sentinel %s: %v", addr, reply)
+    }
+    return net.JoinHostPort(reply[0], reply[1]), nil
+}
+
+// checkMasterRole checks whether the server of connection <c> is a master.
+func checkMasterRole(c redis.Conn) error {
+    reply, err := redis.Values(c.Do("ROLE"))
+    if err != nil {
+        return err
+    }
+    if len(reply) == 0 {
+        return errors.New("redis: invalid ROLE reply")
+    }
+    if role, _ := redis.String(reply[0], nil); role != "master" {
+        return fmt.Errorf(`redis: server role is "%s", not master`, role)
+    }
+    return nil
+}
@@ -0,0 +1,276 @@
+// Copyright 2019 gf Author(https://github.com/gogf/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package gredis
+
+import (
+    "errors"
+    "github.com/gogf/gf/g/container/gtype"
+    "github.com/gogf/gf/g/os/glog"
+    "github.com/gogf/gf/g/os/grpool"
+    "github.com/gogf/gf/third/github.com/gomodule/redigo/redis"
+    "sync"
+    "time"
+)
+
+const (
+    gSUBSCRIBE_WORKERS       = 10                     // Goroutines for executing message handler.
+    gSUBSCRIBE_QUEUE_SIZE    = 1000                   // Maximum messages waiting for handling, receiving blocks if it's full.
+    gSUBSCRIBE_PING_INTERVAL = 10 * time.Second       // Interval for health checking using PING.
+    gSUBSCRIBE_MIN_BACKOFF   = 100 * time.Millisecond // Minimum waiting time before reconnecting.
+    gSUBSCRIBE_MAX_BACKOFF   = 30 * time.Second       // Maximum waiting time before reconnecting.
+)
+
+// Message is a message received by subscription.
+type Message struct {
+    Channel string // The originating channel.
+    Pattern string // The matched pattern for PSubscribe.
+    Data    []byte // The message data.
+}
+
+// Subscription is a subscriber of channels or patterns,
+// which reconnects and resubscribes automatically if the connection is lost.
+type Subscription struct {
+    mu         sync.Mutex
+    redis      *Redis
+    command    string              // SUBSCRIBE or PSUBSCRIBE.
+    channels   []string            // Subscribed channels or patterns.
+    handler    func(msg *Message)  // Message handler.
+    pool       *grpool.Pool        // Goroutine pool executing handler.
+    slots      chan struct{}       // Slots limiting the messages waiting for handling.
+    pending    sync.WaitGroup      // Messages dispatched but not handled yet.
+    conn       redis.Conn          // Current connection, which is nil if disconnected.
+    active     *gtype.Int64        // (Nanoseconds) Last time receiving any reply from server.
+    reconnects *gtype.Int          // Count of reconnections.
+    closed     *gtype.Bool         // Whether the subscription is closed.
+    closing    chan struct{}       // Closed when Close is called.
+    done       chan struct{}       // Closed when the receiving goroutine exits.
+}
+
+// Subscribe subscribes the given <channels>, and calls <handler> for each message received.
+//
+// The handler is executed asynchronously by a goroutine pool of limited size, so the messages may be handled
+// concurrently and out of order. The count of messages waiting for handling is bounded, and receiving from the
+// connection blocks if the limit is reached. The connection is checked periodically using PING,
+// and it reconnects and resubscribes with backoff if the connection is lost.
+// It returns error only if the first connection fails.
+func (r *Redis) Subscribe(handler func(msg *Message), channels...string) (*Subscription, error) {
+    return r.subscribe("SUBSCRIBE", handler, channels)
+}
+
+// PSubscribe subscribes the given glob-style <patterns>, and calls <handler> for each message received.
+// See Subscribe.
+func (r *Redis) PSubscribe(handler func(msg *Message), patterns...string) (*Subscription, error) {
+    return r.subscribe("PSUBSCRIBE", handler, patterns)
+}
+
+// subscribe creates and starts a subscription using <command>.
+func (r *Redis) subscribe(command string, handler func(msg *Message), channels []string) (*Subscription, error) {
+    if len(channels) == 0 {
+        return nil, errors.New("redis: no channel to subscribe")
+    }
+    s := &Subscription {
+        redis      : r,
+        command    : command,
+        channels   : append([]string{}, channels...),
+        handler    : handler,
+        pool       : grpool.New(gSUBSCRIBE_WORKERS),
+        slots      : make(chan struct{}, gSUBSCRIBE_QUEUE_SIZE),
+        active     : gtype.NewInt64(),
+        reconnects : gtype.NewInt(),
+        closed     : gtype.NewBool(),
+        closing    : make(chan struct{}),
+        done       : make(chan struct{}),
+    }
+    conn, err := s.connect()
+    if err != nil {
+        return nil, err
+    }
+    go s.run(conn)
+    go s.monitor()
+    return s, nil
+}
+
+// dispatch adds <msg> to the goroutine pool for handling, it blocks if there are too many
+// messages waiting for handling, and returns false if the subscription is closed while blocking.
+func (s *Subscription) dispatch(msg *Message) bool {
+    select {
+        case s.slots <- struct{}{}:
+        case <-s.closing:
+            return false
+    }
+    s.pending.Add(1)
+    s.pool.Add(func() {
+        defer func() {
+            <-s.slots
+            s.pending.Done()
+        }()
+        s.handler(msg)
+    })
+    return true
+}
+
+// Channels returns the subscribed channels or patterns.
+func (s *Subscription) Channels() []string {
+    return append([]string{}, s.channels...)
+}
+
+// Healthy checks whether the connection is alive,
+// which means it's connected and a reply is received from server recently.
+func (s *Subscription) Healthy() bool {
+    s.mu.Lock()
+    connected := s.conn != nil
+    s.mu.Unlock()
+    return connected && time.Since(time.Unix(0, s.active.Val())) < 2*gSUBSCRIBE_PING_INTERVAL
+}
+
+// Reconnects returns the count of reconnections.
+func (s *Subscription) Reconnects() int {
+    return s.reconnects.Val()
+}
+
+// Ping sends PING to server through the subscribing connection,
+// the reply is received asynchronously and refreshes the health state.
+func (s *Subscription) Ping() error {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    if s.conn == nil {
+        return errors.New("redis: subscription is not connected")
+    }
+    if err := s.conn.Send("PING"); err != nil {
+        return err
+    }
+    return s.conn.Flush()
+}
+
+// Close unsubscribes and closes the connection,
+// it waits until all queued messages are handled.
+func (s *Subscription) Close() error {
+    if s.closed.Set(true) {
+        return nil
+    }
+    close(s.closing)
+    s.mu.Lock()
+    if s.conn != nil {
+        s.conn.Close()
+    }
+    s.mu.Unlock()
+    <-s.done
+    s.pending.Wait()
+    s.pool.Close()
+    return nil
+}
+
+// connect creates a new connection and sends the subscribing command.
+func (s *Subscription) connect() (redis.Conn, error) {
+    conn, err := s.redis.dial()
+    if err != nil {
+        return nil, err
+    }
+    if err := conn.Send(s.command, stringArgs(s.channels)...); err != nil {
+        conn.Close()
+        return nil, err
+    }
+    if err := conn.Flush(); err != nil {
+        conn.Close()
+        return nil, err
+    }
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    if s.closed.Val() {
+        conn.Close()
+        return nil, errors.New("redis: subscription is closed")
+    }
+    s.conn = conn
+    s.active.Set(time.Now().UnixNano())
+    return conn, nil
+}
+
+// run receives messages from <conn>, and reconnects with backoff if the connection is lost.
+func (s *Subscription) run(conn redis.Conn) {
+    defer close(s.done)
+    backoff := gSUBSCRIBE_MIN_BACKOFF
+    for {
+        if conn != nil {
+            err := s.receive(conn)
+            s.mu.Lock()
+            s.conn = nil
+            s.mu.Unlock()
+            conn.Close()
+            if s.closed.Val() {
+                return
+            }
+            glog.Warningf("redis subscription connection lost: %v", err)
+        }
+        select {
+            case <-s.closing:
+                return
+            case <-time.After(backoff):
+        }
+        var err error
+        if conn, err = s.connect(); err != nil {
+            if s.closed.Val() {
+                return
+            }
+            glog.Warningf("redis subscription reconnecting failed: %v", err)
+            if backoff *= 2; backoff > gSUBSCRIBE_MAX_BACKOFF {
+                backoff = gSUBSCRIBE_MAX_BACKOFF
+            }
+            continue
+        }
+        backoff = gSUBSCRIBE_MIN_BACKOFF
+        s.reconnects.Add(1)
+    }
+}
+
+// receive receives replies from <conn> and dispatches messages to handler,
+// it returns when any error occurs.
+func (s *Subscription) receive(conn redis.Conn) error {
+    psc := redis.PubSubConn{Conn : conn}
+    for {
+        switch v := psc.Receive().(type) {
+            case redis.Message:
+                s.active.Set(time.Now().UnixNano())
+                msg := &Message {
+                    Channel : v.Channel,
+                    Pattern : v.Pattern,
+                    Data    : v.Data,
+                }
+                if !s.dispatch(msg) {
+                    return errors.New("redis: subscription is closed")
+                }
+
+            case redis.Subscription, redis.Pong:
+                s.active.Set(time.Now().UnixNano())
+
+            case error:
+                return v
+        }
+    }
+}
+
+// monitor pings server periodically, and closes the connection if no reply
+// is received for a long time, which makes it reconnect.
+func (s *Subscription) monitor() {
+    ticker := time.NewTicker(gSUBSCRIBE_PING_INTERVAL)
+    defer ticker.Stop()
+    for {
+        select {
+            case <-s.closing:
+                return
+            case <-ticker.C:
+        }
+        if time.Since(time.Unix(0, s.active.Val())) > 2*gSUBSCRIBE_PING_INTERVAL {
+            s.mu.Lock()
+            if s.conn != nil {
+                s.conn.Close()
+            }
+            s.mu.Unlock()
+            continue
+        }
+        s.Ping()
+    }
+}
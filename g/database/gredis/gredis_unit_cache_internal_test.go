@@ -0,0 +1,91 @@
+// Copyright 2019 gf Author(https://github.com/gogf/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package gredis
+
+import (
+	"encoding/gob"
+	"encoding/json"
+	"github.com/gogf/gf/g/test/gtest"
+	"testing"
+	"time"
+)
+
+type testCacheItem struct {
+	Name  string
+	Count int64
+}
+
+type testCacheUnregistered struct {
+	Name string
+}
+
+func Test_CacheValue_Encoding(t *testing.T) {
+	gtest.Case(t, func() {
+		now := time.Unix(1560000000, 123).UTC()
+		for _, value := range []interface{}{
+			"s", []byte("b"), 1, int8(-8), int64(1 << 40), uint(1), uint64(1 << 63), 1.5, float32(2.5), true,
+			map[string]interface{}{"a": 1, "b": []interface{}{"x", 2.5}},
+			map[string]string{"a": "b"}, []int{1, 2}, now,
+		} {
+			data, err := encodeCacheValue(value)
+			gtest.Assert(err, nil)
+			decoded, err := decodeCacheValue(data)
+			gtest.Assert(err, nil)
+			gtest.AssertEQ(decoded, value)
+		}
+	})
+	gtest.Case(t, func() {
+		// The types of map values are kept.
+		data, err := encodeCacheValue(map[string]interface{}{"a": 1, "b": []byte("b")})
+		gtest.Assert(err, nil)
+		decoded, err := decodeCacheValue(data)
+		gtest.Assert(err, nil)
+		gtest.AssertEQ(decoded.(map[string]interface{})["a"], 1)
+		gtest.AssertEQ(decoded.(map[string]interface{})["b"], []byte("b"))
+	})
+	gtest.Case(t, func() {
+		// Registered custom type keeps its type.
+		gob.Register(testCacheItem{})
+		data, err := encodeCacheValue(testCacheItem{"gf", 100})
+		gtest.Assert(err, nil)
+		gtest.Assert(data[0], gCACHE_TYPE_GOB)
+		decoded, err := decodeCacheValue(data)
+		gtest.Assert(err, nil)
+		gtest.AssertEQ(decoded, testCacheItem{"gf", 100})
+
+		// Unregistered type falls back to JSON.
+		data, err = encodeCacheValue(testCacheUnregistered{"gf"})
+		gtest.Assert(err, nil)
+		gtest.Assert(data[0], gCACHE_TYPE_JSON)
+		decoded, err = decodeCacheValue(data)
+		gtest.Assert(err, nil)
+		gtest.Assert(decoded, map[string]interface{}{"Name": "gf"})
+
+		data, err = encodeCacheValue(map[string]interface{}{"n": testCacheUnregistered{"gf"}})
+		gtest.Assert(err, nil)
+		gtest.Assert(data[0], gCACHE_TYPE_JSON)
+		decoded, err = decodeCacheValue(append([]byte{gCACHE_TYPE_JSON}, []byte(`{"n":1}`)...))
+		gtest.Assert(err, nil)
+		gtest.Assert(decoded, map[string]interface{}{"n": json.Number("1")})
+
+		// Value not set by the adapter.
+		decoded, err = decodeCacheValue([]byte("raw"))
+		gtest.Assert(err, nil)
+		gtest.Assert(decoded, "raw")
+	})
+}
+
+func Test_NewCacheAdapter_Prefix(t *testing.T) {
+	gtest.Case(t, func() {
+		gtest.Assert(NewCacheAdapter(nil).prefix, "{gcache}:")
+		gtest.Assert(NewCacheAdapter(nil, "app:").prefix, "{app:}")
+		gtest.Assert(NewCacheAdapter(nil, "{app}:cache:").prefix, "{app}:cache:")
+		// The index and cache keys are in the same slot.
+		a := NewCacheAdapter(nil, "app:")
+		gtest.Assert(Slot(a.index), Slot(a.key("k")))
+	})
+}
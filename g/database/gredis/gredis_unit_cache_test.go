@@ -0,0 +1,141 @@
+// Copyright 2019 gf Author(https://github.com/gogf/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package gredis_test
+
+import (
+	"github.com/gogf/gf/g/database/gredis"
+	"github.com/gogf/gf/g/os/gcache"
+	"github.com/gogf/gf/g/test/gtest"
+	"testing"
+	"time"
+)
+
+func Test_CacheAdapter_Set_Get(t *testing.T) {
+	gtest.Case(t, func() {
+		redis := gredis.New(config)
+		defer redis.Close()
+		cache := gcache.NewWithAdapter(gredis.NewCacheAdapter(redis, "gf.cache.1:"))
+		defer cache.Clear()
+
+		cache.Set(1, 11, 0)
+		cache.Set("k2", "v2", 0)
+		cache.Set("k3", []byte("v3"), 0)
+		cache.Set("k4", map[string]interface{}{"a": 1}, 0)
+		gtest.Assert(cache.Get(1), 11)
+		gtest.Assert(cache.Get("1"), 11)
+		gtest.Assert(cache.Get("k2"), "v2")
+		gtest.Assert(cache.Get("k3"), []byte("v3"))
+		gtest.Assert(cache.Get("k4"), map[string]interface{}{"a": 1})
+		gtest.Assert(cache.Get("k5"), nil)
+		gtest.Assert(cache.Contains("k2"), true)
+		gtest.Assert(cache.Contains("k5"), false)
+		gtest.Assert(cache.Size(), 4)
+
+		cache.Set("k2", "v2", -1)
+		gtest.Assert(cache.Contains("k2"), false)
+		gtest.Assert(cache.Remove("k3"), []byte("v3"))
+		gtest.Assert(cache.Contains("k3"), false)
+		gtest.Assert(cache.Size(), 2)
+	})
+}
+
+func Test_CacheAdapter_Expire(t *testing.T) {
+	gtest.Case(t, func() {
+		redis := gredis.New(config)
+		defer redis.Close()
+		cache := gcache.NewWithAdapter(gredis.NewCacheAdapter(redis, "gf.cache.2:"))
+		defer cache.Clear()
+
+		cache.Set("k1", "v1", 100)
+		gtest.Assert(cache.SetIfNotExist("k1", "v2", 0), false)
+		gtest.Assert(cache.Get("k1"), "v1")
+		time.Sleep(200 * time.Millisecond)
+		gtest.Assert(cache.Get("k1"), nil)
+		gtest.Assert(cache.Size(), 0)
+		gtest.Assert(cache.SetIfNotExist("k1", "v2", 0), true)
+		gtest.Assert(cache.Get("k1"), "v2")
+	})
+}
+
+func Test_CacheAdapter_Batch(t *testing.T) {
+	gtest.Case(t, func() {
+		redis := gredis.New(config)
+		defer redis.Close()
+		cache := gcache.NewWithAdapter(gredis.NewCacheAdapter(redis, "gf.cache.3:"))
+		defer cache.Clear()
+
+		cache.Sets(map[interface{}]interface{}{"k1": "v1", "k2": "v2", "k3": "v3"}, 0)
+		gtest.Assert(cache.Data(), map[interface{}]interface{}{"k1": "v1", "k2": "v2", "k3": "v3"})
+		gtest.AssertIN("k1", cache.KeyStrings())
+		gtest.AssertIN("v2", cache.Values())
+		gtest.Assert(len(cache.Keys()), 3)
+
+		// Keys not set by the adapter are not counted and not cleared.
+		_, err := redis.Do("SET", "{gf.cache.3:}raw", "raw")
+		gtest.Assert(err, nil)
+		defer redis.Do("DEL", "{gf.cache.3:}raw")
+		gtest.Assert(cache.Size(), 3)
+
+		cache.Removes([]interface{}{"k1", "k2"})
+		gtest.Assert(cache.KeyStrings(), []string{"k3"})
+		cache.Clear()
+		gtest.Assert(cache.Size(), 0)
+		gtest.Assert(cache.Get("raw"), "raw")
+	})
+}
+
+func Test_CacheAdapter_GetOrSet(t *testing.T) {
+	gtest.Case(t, func() {
+		redis := gredis.New(config)
+		defer redis.Close()
+		cache := gcache.NewWithAdapter(gredis.NewCacheAdapter(redis, "gf.cache.4:"))
+		defer cache.Clear()
+
+		gtest.Assert(cache.GetOrSet("k1", "v1", 0), "v1")
+		gtest.Assert(cache.GetOrSet("k1", "v2", 0), "v1")
+		gtest.Assert(cache.GetOrSetFunc("k2", func() interface{} {
+			return "v2"
+		}, 0), "v2")
+		gtest.Assert(cache.GetOrSetFunc("k2", func() interface{} {
+			return "v3"
+		}, 0), "v2")
+
+		count := 0
+		for i := 0; i < 3; i++ {
+			gtest.Assert(cache.GetOrSetFuncLock("k3", func() interface{} {
+				count++
+				return "v3"
+			}, 0), "v3")
+		}
+		gtest.Assert(count, 1)
+	})
+}
+
+func Test_CacheInvalidator(t *testing.T) {
+	gtest.Case(t, func() {
+		redis := gredis.New(config)
+		defer redis.Close()
+		adapter1 := gcache.NewTieredAdapter(gredis.NewCacheAdapter(redis, "gf.cache.5:"), 100)
+		adapter2 := gcache.NewTieredAdapter(gredis.NewCacheAdapter(redis, "gf.cache.5:"), 100)
+		gtest.Assert(adapter1.SetInvalidator(gredis.NewCacheInvalidator(redis, "gf.cache.5")), nil)
+		gtest.Assert(adapter2.SetInvalidator(gredis.NewCacheInvalidator(redis, "gf.cache.5")), nil)
+		cache1 := gcache.NewWithAdapter(adapter1)
+		cache2 := gcache.NewWithAdapter(adapter2)
+		defer cache1.Close()
+		defer cache2.Close()
+		defer cache1.Clear()
+
+		cache1.Set("k1", "v1", 0)
+		gtest.Assert(cache2.Get("k1"), "v1")
+		cache1.Set("k1", "v2", 0)
+		time.Sleep(100 * time.Millisecond)
+		gtest.Assert(cache2.Get("k1"), "v2")
+		cache1.Remove("k1")
+		time.Sleep(100 * time.Millisecond)
+		gtest.Assert(cache2.Get("k1"), nil)
+	})
+}
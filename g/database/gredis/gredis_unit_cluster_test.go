@@ -0,0 +1,415 @@
+// Copyright 2019 gf Author(https://github.com/gogf/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://github.com/gogf/gf.
+
+// Cluster and sentinel tests starting local redis-server processes,
+// they're skipped if redis-server is not found in PATH.
+
+package gredis
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gogf/gf/g/test/gtest"
+	"github.com/gogf/gf/third/github.com/gomodule/redigo/redis"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+const (
+	testClusterPort  = 17001 // Ports of cluster nodes are 17001-17003, the bus ports are 27001-27003.
+	testSentinelPort = 17101 // Ports of master, replica and sentinel are 17101-17103.
+	testWaitTimeout  = 20 * time.Second
+)
+
+// testServers manages the redis-server processes started for testing.
+type testServers struct {
+	t     *testing.T
+	dir   string
+	procs []*exec.Cmd
+}
+
+// newTestServers creates a process manager, it skips the test if redis-server is not installed.
+func newTestServers(t *testing.T) *testServers {
+	if _, err := exec.LookPath("redis-server"); err != nil {
+		t.Skip("redis-server not found, skipping")
+	}
+	dir, err := ioutil.TempDir("", "gredis")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &testServers{t: t, dir: dir}
+}
+
+// start starts a redis-server process with <args> and waits until it serves on <port>.
+func (s *testServers) start(port int, args ...string) string {
+	cmd := exec.Command("redis-server", args...)
+	cmd.Dir = s.dir
+	if err := cmd.Start(); err != nil {
+		s.t.Fatal(err)
+	}
+	s.procs = append(s.procs, cmd)
+	addr := fmt.Sprintf("127.0.0.1:%d", port)
+	if err := waitFor(func() error {
+		_, err := testDo(addr, "PING")
+		return err
+	}); err != nil {
+		s.t.Fatalf("redis-server on %s is not started: %v", addr, err)
+	}
+	return addr
+}
+
+// startServer starts a redis-server without persistence on <port>.
+func (s *testServers) startServer(port int, args ...string) string {
+	base := []string{
+		"--port", strconv.Itoa(port), "--bind", "127.0.0.1", "--save", "", "--appendonly", "no",
+		"--dir", s.dir, "--dbfilename", fmt.Sprintf("dump-%d.rdb", port),
+	}
+	return s.start(port, append(base, args...)...)
+}
+
+// close kills all processes and removes the temporary directory.
+func (s *testServers) close() {
+	for _, cmd := range s.procs {
+		cmd.Process.Kill()
+		cmd.Wait()
+	}
+	os.RemoveAll(s.dir)
+}
+
+// testDo executes a command on a new connection to <addr>.
+func testDo(addr, command string, args ...interface{}) (interface{}, error) {
+	c, err := redis.Dial("tcp", addr, redis.DialConnectTimeout(time.Second))
+	if err != nil {
+		return nil, err
+	}
+	defer c.Close()
+	return c.Do(command, args...)
+}
+
+// waitFor calls <f> until it returns nil or timeout.
+func waitFor(f func() error) error {
+	deadline := time.Now().Add(testWaitTimeout)
+	for {
+		err := f()
+		if err == nil || time.Now().After(deadline) {
+			return err
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
+
+// testNodeID returns the cluster node id of <addr>.
+func testNodeID(addr string) (string, error) {
+	nodes, err := redis.String(testDo(addr, "CLUSTER", "NODES"))
+	if err != nil {
+		return "", err
+	}
+	for _, line := range strings.Split(nodes, "\n") {
+		if fields := strings.Fields(line); len(fields) > 2 && strings.Contains(fields[2], "myself") {
+			return fields[0], nil
+		}
+	}
+	return "", errors.New("myself not found in CLUSTER NODES")
+}
+
+// testCluster is a 3 masters redis cluster started for testing,
+// the slots are assigned evenly in node order.
+type testCluster struct {
+	addrs []string
+	ids   []string
+}
+
+// startCluster starts the cluster nodes, assigns the slots and waits until the cluster is ok.
+func startCluster(t *testing.T, s *testServers) *testCluster {
+	c := &testCluster{}
+	for i := 0; i < 3; i++ {
+		port := testClusterPort + i
+		addr := s.startServer(port,
+			"--cluster-enabled", "yes",
+			"--cluster-config-file", fmt.Sprintf("nodes-%d.conf", port),
+			"--cluster-node-timeout", "2000",
+		)
+		id, err := testNodeID(addr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		c.addrs = append(c.addrs, addr)
+		c.ids = append(c.ids, id)
+	}
+	for i, addr := range c.addrs {
+		if i > 0 {
+			if _, err := testDo(addr, "CLUSTER", "MEET", "127.0.0.1", testClusterPort); err != nil {
+				t.Fatal(err)
+			}
+		}
+		args := []interface{}{"ADDSLOTS"}
+		for slot := i * gCLUSTER_SLOTS / 3; slot < (i+1)*gCLUSTER_SLOTS/3; slot++ {
+			args = append(args, slot)
+		}
+		if _, err := testDo(addr, "CLUSTER", args...); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, addr := range c.addrs {
+		if err := waitFor(func() error {
+			info, err := redis.String(testDo(addr, "CLUSTER", "INFO"))
+			if err == nil && !(strings.Contains(info, "cluster_state:ok") && strings.Contains(info, "cluster_known_nodes:3")) {
+				err = fmt.Errorf("cluster is not ready on %s", addr)
+			}
+			return err
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return c
+}
+
+// owner returns the index of node serving <slot> initially.
+func (c *testCluster) owner(slot int) int {
+	return slot * 3 / gCLUSTER_SLOTS
+}
+
+// setSlot assigns <slot> to node <to> on all nodes, target node first.
+func (c *testCluster) setSlot(slot, to int) error {
+	if _, err := testDo(c.addrs[to], "CLUSTER", "SETSLOT", slot, "NODE", c.ids[to]); err != nil {
+		return err
+	}
+	for i, addr := range c.addrs {
+		if i != to {
+			if _, err := testDo(addr, "CLUSTER", "SETSLOT", slot, "NODE", c.ids[to]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// migrate starts migrating <slot> from node <from> to node <to> and moves <keys> to the target node,
+// the migration is finished by setSlot.
+func (c *testCluster) migrate(slot, from, to int, keys ...string) error {
+	if _, err := testDo(c.addrs[to], "CLUSTER", "SETSLOT", slot, "IMPORTING", c.ids[from]); err != nil {
+		return err
+	}
+	if _, err := testDo(c.addrs[from], "CLUSTER", "SETSLOT", slot, "MIGRATING", c.ids[to]); err != nil {
+		return err
+	}
+	host, port, _ := strings.Cut(c.addrs[to], ":")
+	for _, key := range keys {
+		if _, err := testDo(c.addrs[from], "MIGRATE", host, port, key, 0, 5000); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// testKey returns a key whose slot is served by node <node> initially.
+func (c *testCluster) testKey(node int, prefix string) string {
+	for i := 0; ; i++ {
+		key := fmt.Sprintf("%s%d", prefix, i)
+		if c.owner(Slot(key)) == node {
+			return key
+		}
+	}
+}
+
+// clusterSlot returns the node address of <slot> in the slot map of <r>.
+func clusterSlot(r *Redis, slot int) string {
+	r.cluster.mu.RLock()
+	defer r.cluster.mu.RUnlock()
+	return r.cluster.slots[slot]
+}
+
+func Test_Cluster_Moved(t *testing.T) {
+	servers := newTestServers(t)
+	defer servers.close()
+	c := startCluster(t, servers)
+	gtest.Case(t, func() {
+		r := New(Config{Mode: MODE_CLUSTER, Addrs: c.addrs[:1]})
+		defer r.Close()
+
+		key := c.testKey(0, "moved")
+		slot := Slot(key)
+		_, err := r.Do("SET", key, "v1")
+		gtest.Assert(err, nil)
+		gtest.Assert(clusterSlot(r, slot), c.addrs[0])
+
+		// The slot map of client is stale after migrating, the MOVED redirection is followed.
+		gtest.Assert(c.migrate(slot, 0, 1, key), nil)
+		gtest.Assert(c.setSlot(slot, 1), nil)
+		v, err := redis.String(r.Do("GET", key))
+		gtest.Assert(err, nil)
+		gtest.Assert(v, "v1")
+		_, err = r.Do("SET", key, "v2")
+		gtest.Assert(err, nil)
+		v, err = redis.String(testDo(c.addrs[1], "GET", key))
+		gtest.Assert(err, nil)
+		gtest.Assert(v, "v2")
+		gtest.Assert(clusterSlot(r, slot), c.addrs[1])
+	})
+}
+
+func Test_Cluster_Ask(t *testing.T) {
+	servers := newTestServers(t)
+	defer servers.close()
+	c := startCluster(t, servers)
+	gtest.Case(t, func() {
+		r := New(Config{Mode: MODE_CLUSTER, Addrs: c.addrs[:1]})
+		defer r.Close()
+
+		key := c.testKey(0, "ask")
+		slot := Slot(key)
+		_, err := r.Do("SET", key, "v1")
+		gtest.Assert(err, nil)
+
+		// The key has been moved to the importing node while the slot is still migrating,
+		// so the source node replies ASK and the command is retried with ASKING.
+		gtest.Assert(c.migrate(slot, 0, 2, key), nil)
+		_, err = testDo(c.addrs[0], "GET", key)
+		gtest.AssertNE(err, nil)
+		gtest.Assert(strings.HasPrefix(err.Error(), "ASK "), true)
+		v, err := redis.String(r.Do("GET", key))
+		gtest.Assert(err, nil)
+		gtest.Assert(v, "v1")
+		// ASK redirection does not change the slot map.
+		gtest.Assert(clusterSlot(r, slot), c.addrs[0])
+
+		gtest.Assert(c.setSlot(slot, 2), nil)
+		v, err = redis.String(r.Do("GET", key))
+		gtest.Assert(err, nil)
+		gtest.Assert(v, "v1")
+	})
+}
+
+func Test_Cluster_Refresh(t *testing.T) {
+	servers := newTestServers(t)
+	defer servers.close()
+	c := startCluster(t, servers)
+	gtest.Case(t, func() {
+		r := New(Config{Mode: MODE_CLUSTER, Addrs: c.addrs[:1]})
+		defer r.Close()
+
+		// The slot map is discovered from the seed node.
+		gtest.Assert(r.cluster.refresh(), nil)
+		gtest.Assert(clusterSlot(r, 0), c.addrs[0])
+		gtest.Assert(clusterSlot(r, gCLUSTER_SLOTS/2), c.addrs[1])
+		gtest.Assert(clusterSlot(r, gCLUSTER_SLOTS-1), c.addrs[2])
+		gtest.Assert(len(r.cluster.nodes()), 3)
+
+		// Both slots are moved, the MOVED redirection of the first one refreshes the slot map
+		// asynchronously, which also updates the other one.
+		gtest.Assert(c.setSlot(0, 2), nil)
+		gtest.Assert(c.setSlot(1, 2), nil)
+		key0, key1 := "", ""
+		for i := 0; key0 == "" || key1 == ""; i++ {
+			key := fmt.Sprintf("refresh%d", i)
+			switch Slot(key) {
+			case 0:
+				key0 = key
+			case 1:
+				key1 = key
+			}
+		}
+		_, err := r.Do("SET", key0, "v")
+		gtest.Assert(err, nil)
+		gtest.Assert(waitFor(func() error {
+			if addr := clusterSlot(r, 1); addr != c.addrs[2] {
+				return fmt.Errorf("slot 1 is still served by %s", addr)
+			}
+			return nil
+		}), nil)
+		_, err = r.Do("SET", key1, "v")
+		gtest.Assert(err, nil)
+	})
+}
+
+func Test_Sentinel_Failover(t *testing.T) {
+	servers := newTestServers(t)
+	defer servers.close()
+	master := servers.startServer(testSentinelPort)
+	replica := servers.startServer(testSentinelPort+1, "--replicaof", "127.0.0.1", strconv.Itoa(testSentinelPort))
+	conf := filepath.Join(servers.dir, "sentinel.conf")
+	content := fmt.Sprintf(
+		"port %d\nbind 127.0.0.1\nsentinel monitor mymaster 127.0.0.1 %d 1\n"+
+			"sentinel down-after-milliseconds mymaster 1000\nsentinel failover-timeout mymaster 5000\n",
+		testSentinelPort+2, testSentinelPort,
+	)
+	if err := ioutil.WriteFile(conf, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	sentinelAddr := servers.start(testSentinelPort+2, conf, "--sentinel")
+	// The sentinel discovers the replica from INFO of the master.
+	if err := waitFor(func() error {
+		replicas, err := redis.Values(testDo(sentinelAddr, "SENTINEL", "SLAVES", "mymaster"))
+		if err == nil && len(replicas) == 0 {
+			err = errors.New("replica is not discovered by sentinel")
+		}
+		return err
+	}); err != nil {
+		t.Fatal(err)
+	}
+	gtest.Case(t, func() {
+		r := New(Config{Mode: MODE_SENTINEL, Addrs: []string{sentinelAddr}, MasterName: "mymaster"})
+		defer r.Close()
+
+		_, err := r.Do("SET", "k1", "v1")
+		gtest.Assert(err, nil)
+		v, err := redis.String(testDo(master, "GET", "k1"))
+		gtest.Assert(err, nil)
+		gtest.Assert(v, "v1")
+
+		// The failover is refused until the sentinel has collected the replica state.
+		gtest.Assert(waitFor(func() error {
+			_, err := testDo(sentinelAddr, "SENTINEL", "FAILOVER", "mymaster")
+			return err
+		}), nil)
+		gtest.Assert(waitFor(func() error {
+			role, err := redis.Values(testDo(replica, "ROLE"))
+			if err == nil {
+				if name, _ := redis.String(role[0], nil); name != "master" {
+					err = errors.New("replica is not promoted")
+				}
+			}
+			return err
+		}), nil)
+
+		// The idle connection to the former master is dropped, and the new connection is made to the new master.
+		gtest.Assert(waitFor(func() error {
+			_, err := r.Do("SET", "k2", "v2")
+			return err
+		}), nil)
+		v, err = redis.String(testDo(replica, "GET", "k2"))
+		gtest.Assert(err, nil)
+		gtest.Assert(v, "v2")
+		v, err = redis.String(r.Do("GET", "k1"))
+		gtest.Assert(err, nil)
+		gtest.Assert(v, "v1")
+	})
+}
+
+func Test_New_PoolLimits(t *testing.T) {
+	gtest.Case(t, func() {
+		// The pool limits of configuration are ignored in standalone mode like before.
+		r := New(Config{Host: "127.0.0.1", Port: 6379, MaxIdle: 10, MaxActive: 100})
+		defer r.Close()
+		gtest.Assert(r.pool.MaxIdle, 0)
+		gtest.Assert(r.pool.MaxActive, 0)
+
+		r.SetMaxIdle(10)
+		gtest.Assert(r.pool.MaxIdle, 10)
+	})
+	gtest.Case(t, func() {
+		r := New(Config{Mode: MODE_SENTINEL, Addrs: []string{"127.0.0.1:26379"}, MasterName: "m", MaxIdle: 10, MaxActive: 100})
+		defer r.Close()
+		gtest.Assert(r.pool.MaxIdle, 10)
+		gtest.Assert(r.pool.MaxActive, 100)
+	})
+}
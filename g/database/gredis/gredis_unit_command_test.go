@@ -0,0 +1,260 @@
+// Copyright 2019 gf Author(https://github.com/gogf/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package gredis_test
+
+import (
+	"github.com/gogf/gf/g/database/gredis"
+	"github.com/gogf/gf/g/test/gtest"
+	"testing"
+	"time"
+)
+
+func Test_Command_String(t *testing.T) {
+	gtest.Case(t, func() {
+		redis := gredis.New(config)
+		defer redis.Close()
+		defer redis.Del("k1", "k2", "n")
+
+		gtest.Assert(redis.Set("k1", "v1"), nil)
+		v, err := redis.Get("k1")
+		gtest.Assert(err, nil)
+		gtest.Assert(v.String(), "v1")
+
+		v, err = redis.Get("none")
+		gtest.Assert(err, nil)
+		gtest.Assert(v.IsNil(), true)
+
+		ok, err := redis.SetNX("k1", "v2")
+		gtest.Assert(err, nil)
+		gtest.Assert(ok, false)
+
+		gtest.Assert(redis.MSet(map[string]interface{}{"k1": 1, "k2": 2}), nil)
+		vars, err := redis.MGet("k1", "k2", "none")
+		gtest.Assert(err, nil)
+		gtest.Assert(len(vars), 3)
+		gtest.Assert(vars[1].Int(), 2)
+		gtest.Assert(vars[2].IsNil(), true)
+
+		n, err := redis.IncrBy("n", 10)
+		gtest.Assert(err, nil)
+		gtest.Assert(n, 10)
+		n, err = redis.Decr("n")
+		gtest.Assert(err, nil)
+		gtest.Assert(n, 9)
+	})
+}
+
+func Test_Command_Expire(t *testing.T) {
+	gtest.Case(t, func() {
+		redis := gredis.New(config)
+		defer redis.Close()
+		defer redis.Del("k")
+
+		ttl, err := redis.TTL("k")
+		gtest.Assert(err, nil)
+		gtest.Assert(ttl, gredis.TTL_NOT_EXISTS)
+
+		gtest.Assert(redis.Set("k", "v"), nil)
+		ttl, err = redis.TTL("k")
+		gtest.Assert(err, nil)
+		gtest.Assert(ttl, gredis.TTL_NO_EXPIRE)
+
+		ok, err := redis.Expire("k", time.Minute)
+		gtest.Assert(err, nil)
+		gtest.Assert(ok, true)
+		ttl, err = redis.TTL("k")
+		gtest.Assert(err, nil)
+		gtest.Assert(ttl > 50*time.Second && ttl <= time.Minute, true)
+
+		gtest.Assert(redis.Set("k", "v", 100*time.Millisecond), nil)
+		time.Sleep(200 * time.Millisecond)
+		n, err := redis.Exists("k")
+		gtest.Assert(err, nil)
+		gtest.Assert(n, 0)
+	})
+}
+
+func Test_Command_Hash(t *testing.T) {
+	type User struct {
+		Id   int
+		Name string
+	}
+	gtest.Case(t, func() {
+		redis := gredis.New(config)
+		defer redis.Close()
+		defer redis.Del("user")
+
+		gtest.Assert(redis.HMSet("user", User{Id: 1, Name: "john"}), nil)
+		m, err := redis.HGetAll("user")
+		gtest.Assert(err, nil)
+		gtest.Assert(m, map[string]string{"Id": "1", "Name": "john"})
+
+		user := new(User)
+		gtest.Assert(redis.HGetAllStruct("user", user), nil)
+		gtest.Assert(user.Id, 1)
+		gtest.Assert(user.Name, "john")
+		gtest.Assert(redis.HGetAllStruct("none", user), gredis.ErrNil)
+
+		v, err := redis.HGet("user", "Name")
+		gtest.Assert(err, nil)
+		gtest.Assert(v.String(), "john")
+
+		n, err := redis.HIncrBy("user", "Id", 2)
+		gtest.Assert(err, nil)
+		gtest.Assert(n, 3)
+
+		n, err = redis.HDel("user", "Name", "none")
+		gtest.Assert(err, nil)
+		gtest.Assert(n, 1)
+		n, err = redis.HLen("user")
+		gtest.Assert(err, nil)
+		gtest.Assert(n, 1)
+	})
+}
+
+func Test_Command_List(t *testing.T) {
+	gtest.Case(t, func() {
+		redis := gredis.New(config)
+		defer redis.Close()
+		defer redis.Del("list")
+
+		n, err := redis.RPush("list", 1, 2, 3)
+		gtest.Assert(err, nil)
+		gtest.Assert(n, 3)
+		array, err := redis.LRange("list", 0, -1)
+		gtest.Assert(err, nil)
+		gtest.Assert(array, []string{"1", "2", "3"})
+
+		v, err := redis.LPop("list")
+		gtest.Assert(err, nil)
+		gtest.Assert(v.Int(), 1)
+
+		key, v, err := redis.BRPop(time.Second, "list")
+		gtest.Assert(err, nil)
+		gtest.Assert(key, "list")
+		gtest.Assert(v.Int(), 3)
+
+		redis.LPop("list")
+		_, _, err = redis.BLPop(time.Second, "list")
+		gtest.Assert(err, gredis.ErrNil)
+	})
+}
+
+func Test_Command_Set(t *testing.T) {
+	gtest.Case(t, func() {
+		redis := gredis.New(config)
+		defer redis.Close()
+		defer redis.Del("set")
+
+		n, err := redis.SAdd("set", "a", "b", "a")
+		gtest.Assert(err, nil)
+		gtest.Assert(n, 2)
+		ok, err := redis.SIsMember("set", "b")
+		gtest.Assert(err, nil)
+		gtest.Assert(ok, true)
+		members, err := redis.SMembers("set")
+		gtest.Assert(err, nil)
+		gtest.Assert(len(members), 2)
+		n, err = redis.SCard("set")
+		gtest.Assert(err, nil)
+		gtest.Assert(n, 2)
+	})
+}
+
+func Test_Command_ZSet(t *testing.T) {
+	gtest.Case(t, func() {
+		redis := gredis.New(config)
+		defer redis.Close()
+		defer redis.Del("zset")
+
+		n, err := redis.ZAdd("zset", gredis.Z{Score: 2, Member: "b"}, gredis.Z{Score: 1, Member: "a"})
+		gtest.Assert(err, nil)
+		gtest.Assert(n, 2)
+		score, err := redis.ZIncrBy("zset", 2, "a")
+		gtest.Assert(err, nil)
+		gtest.Assert(score, 3)
+
+		array, err := redis.ZRange("zset", 0, -1)
+		gtest.Assert(err, nil)
+		gtest.Assert(array, []string{"b", "a"})
+		zs, err := redis.ZRevRangeWithScores("zset", 0, 0)
+		gtest.Assert(err, nil)
+		gtest.Assert(zs, []gredis.Z{{Score: 3, Member: "a"}})
+
+		_, err = redis.ZScore("zset", "none")
+		gtest.Assert(err, gredis.ErrNil)
+		rank, err := redis.ZRank("zset", "a")
+		gtest.Assert(err, nil)
+		gtest.Assert(rank, 1)
+	})
+}
+
+func Test_Command_Scan(t *testing.T) {
+	gtest.Case(t, func() {
+		redis := gredis.New(config)
+		defer redis.Close()
+		defer redis.Del("scan:1", "scan:2", "scan:3", "hash")
+
+		redis.MSet(map[string]interface{}{"scan:1": 1, "scan:2": 2, "scan:3": 3})
+		keys := make(map[string]struct{})
+		iter := redis.Scan("scan:*", 1)
+		for iter.Next() {
+			keys[iter.Key()] = struct{}{}
+		}
+		gtest.Assert(iter.Err(), nil)
+		gtest.Assert(len(keys), 3)
+
+		redis.HMSet("hash", map[string]interface{}{"f1": "v1", "f2": "v2"})
+		m := make(map[string]string)
+		iter = redis.HScan("hash", "", 10)
+		for iter.Next() {
+			m[iter.Key()] = iter.Val()
+		}
+		gtest.Assert(iter.Err(), nil)
+		gtest.Assert(m, map[string]string{"f1": "v1", "f2": "v2"})
+	})
+}
+
+func Test_Pipeline(t *testing.T) {
+	gtest.Case(t, func() {
+		redis := gredis.New(config)
+		defer redis.Close()
+		defer redis.Del("k", "n")
+
+		pipe := redis.Pipeline().Do("SET", "k", "v").Do("INCR", "n").Do("INCR", "k").Do("GET", "k")
+		gtest.Assert(pipe.Len(), 4)
+		results, err := pipe.Exec()
+		gtest.AssertNE(err, nil)
+		gtest.Assert(len(results), 4)
+		gtest.Assert(results[0].Val.String(), "OK")
+		gtest.Assert(results[1].Val.Int(), 1)
+		gtest.AssertNE(results[2].Err, nil)
+		gtest.Assert(results[3].Val.String(), "v")
+		gtest.Assert(pipe.Len(), 0)
+	})
+}
+
+func Test_TxPipeline(t *testing.T) {
+	gtest.Case(t, func() {
+		redis := gredis.New(config)
+		defer redis.Close()
+		defer redis.Del("n")
+
+		results, err := redis.TxPipeline().Do("INCR", "n").Do("INCRBY", "n", 10).Exec()
+		gtest.Assert(err, nil)
+		gtest.Assert(len(results), 2)
+		gtest.Assert(results[0].Val.Int(), 1)
+		gtest.Assert(results[1].Val.Int(), 11)
+
+		// Syntax error in queueing discards the whole transaction.
+		results, err = redis.TxPipeline().Do("INCR", "n").Do("INCRBY", "n").Exec()
+		gtest.AssertNE(err, nil)
+		gtest.AssertNE(results[1].Err, nil)
+		v, _ := redis.Get("n")
+		gtest.Assert(v.Int(), 11)
+	})
+}
@@ -0,0 +1,128 @@
+// Copyright 2019 gf Author(https://github.com/gogf/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package gredis_test
+
+import (
+	"github.com/gogf/gf/g/container/garray"
+	"github.com/gogf/gf/g/database/gredis"
+	"github.com/gogf/gf/g/test/gtest"
+	"testing"
+	"time"
+)
+
+func Test_Locker_Lock_Unlock(t *testing.T) {
+	gtest.Case(t, func() {
+		redis := gredis.New(config)
+		defer redis.Close()
+		key := "gf.lock.1"
+		array := garray.New()
+		// Different lockers act as different processes.
+		go func() {
+			locker := redis.Locker()
+			locker.Lock(key)
+			array.Append(1)
+			time.Sleep(200 * time.Millisecond)
+			array.Append(1)
+			locker.Unlock(key)
+		}()
+		go func() {
+			time.Sleep(50 * time.Millisecond)
+			locker := redis.Locker()
+			locker.Lock(key)
+			array.Append(1)
+			locker.Unlock(key)
+		}()
+		time.Sleep(100 * time.Millisecond)
+		gtest.Assert(array.Len(), 1)
+		time.Sleep(300 * time.Millisecond)
+		gtest.Assert(array.Len(), 3)
+	})
+}
+
+func Test_Locker_TryLock(t *testing.T) {
+	gtest.Case(t, func() {
+		redis := gredis.New(config)
+		defer redis.Close()
+		key := "gf.lock.2"
+		l1 := redis.Locker()
+		l2 := gredis.NewLocker(redis)
+		gtest.Assert(l1.TryLock(key), true)
+		gtest.Assert(l2.TryLock(key), false)
+		// Unlocking the lock held by others takes no effect.
+		l2.Unlock(key)
+		gtest.Assert(l2.TryLock(key), false)
+		l1.Unlock(key)
+		gtest.Assert(l2.TryLock(key), true)
+		l2.Unlock(key)
+	})
+}
+
+func Test_Locker_Expire(t *testing.T) {
+	gtest.Case(t, func() {
+		redis := gredis.New(config)
+		defer redis.Close()
+		key := "gf.lock.3"
+		l1 := redis.Locker()
+		l2 := redis.Locker()
+		gtest.Assert(l1.TryLock(key, 100*time.Millisecond), true)
+		gtest.Assert(l2.TryLock(key), false)
+		time.Sleep(200 * time.Millisecond)
+		gtest.Assert(l2.TryLock(key), true)
+		// The expired lock cannot release the lock of others.
+		l1.Unlock(key)
+		gtest.Assert(l1.TryLock(key), false)
+		l2.Unlock(key)
+	})
+}
+
+func Test_Locker_LockFunc(t *testing.T) {
+	gtest.Case(t, func() {
+		redis := gredis.New(config)
+		defer redis.Close()
+		key := "gf.lock.4"
+		array := garray.New()
+		go func() {
+			redis.Locker().LockFunc(key, func() {
+				array.Append(1)
+				time.Sleep(200 * time.Millisecond)
+			})
+		}()
+		time.Sleep(50 * time.Millisecond)
+		gtest.Assert(redis.Locker().TryLockFunc(key, func() {
+			array.Append(1)
+		}), false)
+		time.Sleep(200 * time.Millisecond)
+		gtest.Assert(redis.Locker().TryLockFunc(key, func() {
+			array.Append(1)
+		}), true)
+		gtest.Assert(array.Len(), 2)
+	})
+}
+
+func Test_Locker_Redlock(t *testing.T) {
+	gtest.Case(t, func() {
+		instances := make([]*gredis.Redis, 0)
+		for _, db := range []int{1, 2, 3} {
+			c := config
+			c.Db = db
+			instances = append(instances, gredis.New(c))
+		}
+		key := "gf.lock.5"
+		l1 := gredis.NewLocker(instances...)
+		l2 := gredis.NewLocker(instances...)
+		// The lock still works if the minority of instances hold the key already.
+		instances[2].Set(key, "other")
+		defer instances[2].Del(key)
+		gtest.Assert(l1.TryLock(key), true)
+		gtest.Assert(l2.TryLock(key), false)
+		l1.Unlock(key)
+		gtest.Assert(l2.TryLock(key), true)
+		l2.Unlock(key)
+		v, _ := instances[2].Get(key)
+		gtest.Assert(v.String(), "other")
+	})
+}
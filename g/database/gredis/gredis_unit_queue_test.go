@@ -0,0 +1,130 @@
+// Copyright 2019 gf Author(https://github.com/gogf/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package gredis_test
+
+import (
+	"errors"
+	"github.com/gogf/gf/g/container/garray"
+	"github.com/gogf/gf/g/container/gtype"
+	"github.com/gogf/gf/g/database/gredis"
+	"github.com/gogf/gf/g/test/gtest"
+	"testing"
+	"time"
+)
+
+func Test_Queue(t *testing.T) {
+	gtest.Case(t, func() {
+		redis := gredis.New(config)
+		defer redis.Close()
+		defer redis.Del("gf.queue.1", "gf.queue.1:delayed", "gf.queue.1:dead")
+
+		queue := redis.Queue("gf.queue.1")
+		// Jobs enqueued before starting are also consumed.
+		_, err := queue.Enqueue("1")
+		gtest.Assert(err, nil)
+
+		array := garray.NewStringArray()
+		err = queue.Start(func(job *gredis.Job) error {
+			array.Append(string(job.Payload))
+			return nil
+		})
+		gtest.Assert(err, nil)
+		defer queue.Shutdown()
+		gtest.AssertNE(queue.Start(nil), nil)
+
+		queue.Enqueue([]byte("2"))
+		time.Sleep(500 * time.Millisecond)
+		gtest.Assert(array.Len(), 2)
+		gtest.AssertIN("1", array.Slice())
+		gtest.AssertIN("2", array.Slice())
+
+		stats, err := queue.Stats()
+		gtest.Assert(err, nil)
+		gtest.Assert(stats.Ready, 0)
+		gtest.Assert(stats.Pending, 0)
+	})
+}
+
+func Test_Queue_Delay(t *testing.T) {
+	gtest.Case(t, func() {
+		redis := gredis.New(config)
+		defer redis.Close()
+		defer redis.Del("gf.queue.2", "gf.queue.2:delayed", "gf.queue.2:dead")
+
+		queue := redis.Queue("gf.queue.2")
+		handled := gtype.NewInt()
+		gtest.Assert(queue.Start(func(job *gredis.Job) error {
+			handled.Add(1)
+			return nil
+		}), nil)
+		defer queue.Shutdown()
+
+		_, err := queue.Enqueue("delayed", time.Second)
+		gtest.Assert(err, nil)
+		stats, _ := queue.Stats()
+		gtest.Assert(stats.Delayed, 1)
+		time.Sleep(500 * time.Millisecond)
+		gtest.Assert(handled.Val(), 0)
+		time.Sleep(1500 * time.Millisecond)
+		gtest.Assert(handled.Val(), 1)
+		stats, _ = queue.Stats()
+		gtest.Assert(stats.Delayed, 0)
+	})
+}
+
+func Test_Queue_Retry(t *testing.T) {
+	gtest.Case(t, func() {
+		redis := gredis.New(config)
+		defer redis.Close()
+		defer redis.Del("gf.queue.3", "gf.queue.3:delayed", "gf.queue.3:dead")
+
+		queue := redis.Queue("gf.queue.3")
+		queue.SetMaxRetries(2)
+		queue.SetVisibilityTimeout(200 * time.Millisecond)
+		retries := garray.NewIntArray()
+		gtest.Assert(queue.Start(func(job *gredis.Job) error {
+			retries.Append(job.Retries)
+			return errors.New("failed")
+		}), nil)
+		defer queue.Shutdown()
+
+		queue.Enqueue("fail")
+		time.Sleep(4 * time.Second)
+		gtest.Assert(retries.Slice(), []int{0, 1, 2})
+
+		stats, err := queue.Stats()
+		gtest.Assert(err, nil)
+		gtest.Assert(stats.Pending, 0)
+		gtest.Assert(stats.Dead, 1)
+		entries, err := redis.DoVar("XRANGE", "gf.queue.3:dead", "-", "+")
+		gtest.Assert(err, nil)
+		gtest.Assert(len(entries.Interfaces()), 1)
+	})
+}
+
+func Test_Queue_Shutdown(t *testing.T) {
+	gtest.Case(t, func() {
+		redis := gredis.New(config)
+		defer redis.Close()
+		defer redis.Del("gf.queue.4", "gf.queue.4:delayed", "gf.queue.4:dead")
+
+		queue := redis.Queue("gf.queue.4")
+		done := gtype.NewBool()
+		gtest.Assert(queue.Start(func(job *gredis.Job) error {
+			time.Sleep(500 * time.Millisecond)
+			done.Set(true)
+			return nil
+		}), nil)
+		queue.Enqueue("slow")
+		time.Sleep(200 * time.Millisecond)
+		// Shutdown waits for the running job.
+		queue.Shutdown()
+		gtest.Assert(done.Val(), true)
+		stats, _ := queue.Stats()
+		gtest.Assert(stats.Pending, 0)
+	})
+}
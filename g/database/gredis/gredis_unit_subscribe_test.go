@@ -0,0 +1,106 @@
+// Copyright 2019 gf Author(https://github.com/gogf/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package gredis_test
+
+import (
+	"github.com/gogf/gf/g/container/gtype"
+	"github.com/gogf/gf/g/database/gredis"
+	"github.com/gogf/gf/g/test/gtest"
+	"testing"
+	"time"
+)
+
+func Test_Subscribe(t *testing.T) {
+	gtest.Case(t, func() {
+		redis := gredis.New(config)
+		defer redis.Close()
+
+		ch := make(chan *gredis.Message, 10)
+		sub, err := redis.Subscribe(func(msg *gredis.Message) {
+			ch <- msg
+		}, "gf.sub")
+		gtest.Assert(err, nil)
+		time.Sleep(100 * time.Millisecond)
+		gtest.Assert(sub.Healthy(), true)
+		gtest.Assert(sub.Channels(), []string{"gf.sub"})
+
+		_, err = redis.Do("PUBLISH", "gf.sub", "gf test")
+		gtest.Assert(err, nil)
+		select {
+		case msg := <-ch:
+			gtest.Assert(msg.Channel, "gf.sub")
+			gtest.Assert(string(msg.Data), "gf test")
+		case <-time.After(time.Second):
+			gtest.Fatal("message not received")
+		}
+
+		// Kills the subscribing connection, it should reconnect and resubscribe.
+		_, err = redis.Do("CLIENT", "KILL", "TYPE", "pubsub")
+		gtest.Assert(err, nil)
+		time.Sleep(500 * time.Millisecond)
+		gtest.Assert(sub.Reconnects(), 1)
+		redis.Do("PUBLISH", "gf.sub", "after reconnect")
+		select {
+		case msg := <-ch:
+			gtest.Assert(string(msg.Data), "after reconnect")
+		case <-time.After(time.Second):
+			gtest.Fatal("message not received after reconnecting")
+		}
+
+		gtest.Assert(sub.Close(), nil)
+		gtest.Assert(sub.Healthy(), false)
+		gtest.AssertNE(sub.Ping(), nil)
+	})
+}
+
+func Test_PSubscribe(t *testing.T) {
+	gtest.Case(t, func() {
+		redis := gredis.New(config)
+		defer redis.Close()
+
+		ch := make(chan *gredis.Message, 10)
+		sub, err := redis.PSubscribe(func(msg *gredis.Message) {
+			ch <- msg
+		}, "gf.psub.*")
+		gtest.Assert(err, nil)
+		defer sub.Close()
+		time.Sleep(100 * time.Millisecond)
+
+		redis.Do("PUBLISH", "gf.psub.1", "1")
+		select {
+		case msg := <-ch:
+			gtest.Assert(msg.Pattern, "gf.psub.*")
+			gtest.Assert(msg.Channel, "gf.psub.1")
+			gtest.Assert(string(msg.Data), "1")
+		case <-time.After(time.Second):
+			gtest.Fatal("message not received")
+		}
+	})
+}
+
+func Test_Subscribe_Close(t *testing.T) {
+	gtest.Case(t, func() {
+		redis := gredis.New(config)
+		defer redis.Close()
+
+		handled := gtype.NewInt()
+		sub, err := redis.Subscribe(func(msg *gredis.Message) {
+			time.Sleep(10 * time.Millisecond)
+			handled.Add(1)
+		}, "gf.sub.close")
+		gtest.Assert(err, nil)
+		time.Sleep(100 * time.Millisecond)
+
+		for i := 0; i < 100; i++ {
+			redis.Do("PUBLISH", "gf.sub.close", i)
+		}
+		time.Sleep(100 * time.Millisecond)
+		// Close waits until the queued messages are handled.
+		gtest.Assert(sub.Close(), nil)
+		gtest.Assert(handled.Val(), 100)
+	})
+}
@@ -207,3 +207,14 @@ func Test_Basic(t *testing.T) {
 		time.Sleep(time.Second)
 	})
 }
+
+func Test_Slot(t *testing.T) {
+	gtest.Case(t, func() {
+		gtest.Assert(gredis.Slot("123456789"), 12739)
+		gtest.Assert(gredis.Slot("foo"), 12182)
+		gtest.Assert(gredis.Slot("{user1000}.following"), gredis.Slot("{user1000}.followers"))
+		gtest.Assert(gredis.Slot("{user1000}.following"), gredis.Slot("user1000"))
+		// Empty hash tag is not used.
+		gtest.AssertNE(gredis.Slot("{}.a"), gredis.Slot("{}.b"))
+	})
+}
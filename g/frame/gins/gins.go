@@ -19,6 +19,7 @@ import (
 	"github.com/gogf/gf/g/text/gregex"
 	"github.com/gogf/gf/g/text/gstr"
 	"github.com/gogf/gf/g/util/gconv"
+	"strings"
 	"time"
 )
 
@@ -182,7 +183,7 @@ func Redis(name...string) *gredis.Redis {
     key    := fmt.Sprintf("%s.%s", gFRAME_CORE_COMPONENT_NAME_REDIS, group)
     result := instances.GetOrSetFuncLock(key, func() interface{} {
         if m := config.GetMap("redis"); m != nil {
-            // host:port[,db,pass?maxIdle=x&maxActive=x&idleTimeout=x&maxConnLifetime=x]
+            // host:port[,db,pass?maxIdle=x&maxActive=x&idleTimeout=x&maxConnLifetime=x&mode=x&addrs=x&masterName=x]
             if v, ok := m[group]; ok {
                 line := gconv.String(v)
                 array, _ := gregex.MatchString(`(.+):(\d+),{0,1}(\d*),{0,1}(.*)\?(.+)`, line)
@@ -206,6 +207,19 @@ func Redis(name...string) *gredis.Redis {
                     if v, ok := parse["maxConnLifetime"]; ok {
                         redisConfig.MaxConnLifetime = gconv.Duration(v)*time.Second
                     }
+                    // mode=sentinel|cluster&addrs=host1:port1,host2:port2&masterName=x
+                    if v, ok := parse["mode"]; ok {
+                        switch strings.ToLower(gconv.String(v)) {
+                            case "sentinel": redisConfig.Mode = gredis.MODE_SENTINEL
+                            case "cluster":  redisConfig.Mode = gredis.MODE_CLUSTER
+                        }
+                    }
+                    if v, ok := parse["addrs"]; ok {
+                        redisConfig.Addrs = append([]string{fmt.Sprintf("%s:%s", array[1], array[2])}, strings.Split(gconv.String(v), ",")...)
+                    }
+                    if v, ok := parse["masterName"]; ok {
+                        redisConfig.MasterName = gconv.String(v)
+                    }
                     addConfigMonitor(key, config)
                     return gredis.New(redisConfig)
                 }
@@ -0,0 +1,207 @@
+// Copyright 2019 gf Author(https://github.com/gogf/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://github.com/gogf/gf.
+
+// Package ipaddr implements the address arithmetic, network prefix and prefix trie
+// shared by IPv4 and IPv6, in which the address length in bits is 32 or 128.
+package ipaddr
+
+import (
+    "math/bits"
+    "net"
+    "sort"
+)
+
+// Addr is an IP address in 128 bits, an IPv4 address is stored in the lowest 32 bits.
+type Addr struct {
+    Hi uint64
+    Lo uint64
+}
+
+// Prefix is a network of address <Addr> with the leading <Len> bits,
+// the host bits of <Addr> are always zero.
+type Prefix struct {
+    Addr Addr
+    Len  int
+}
+
+// FromIP converts <ip> to Addr, <length> is 32 for IPv4 and 128 for IPv6.
+func FromIP(ip net.IP, length int) (Addr, bool) {
+    if length == 32 {
+        if ip = ip.To4(); ip == nil {
+            return Addr{}, false
+        }
+        return Addr{Lo : uint64(ip[0]) << 24 | uint64(ip[1]) << 16 | uint64(ip[2]) << 8 | uint64(ip[3])}, true
+    }
+    if ip = ip.To16(); ip == nil {
+        return Addr{}, false
+    }
+    a := Addr{}
+    for i := 0; i < 8; i++ {
+        a.Hi = a.Hi << 8 | uint64(ip[i])
+        a.Lo = a.Lo << 8 | uint64(ip[i + 8])
+    }
+    return a, true
+}
+
+// IP converts the address to net.IP, <length> is 32 for IPv4 and 128 for IPv6.
+func (a Addr) IP(length int) net.IP {
+    if length == 32 {
+        return net.IPv4(byte(a.Lo >> 24), byte(a.Lo >> 16), byte(a.Lo >> 8), byte(a.Lo)).To4()
+    }
+    ip := make(net.IP, 16)
+    for i := 0; i < 8; i++ {
+        ip[i]     = byte(a.Hi >> uint(56 - 8*i))
+        ip[i + 8] = byte(a.Lo >> uint(56 - 8*i))
+    }
+    return ip
+}
+
+// Cmp compares the address with <b>, it returns -1 if a < b, 0 if a == b and 1 if a > b.
+func (a Addr) Cmp(b Addr) int {
+    switch {
+        case a.Hi < b.Hi: return -1
+        case a.Hi > b.Hi: return  1
+        case a.Lo < b.Lo: return -1
+        case a.Lo > b.Lo: return  1
+    }
+    return 0
+}
+
+// Next returns the next address a+1, which overflows to zero.
+func (a Addr) Next() Addr {
+    if a.Lo++; a.Lo == 0 {
+        a.Hi++
+    }
+    return a
+}
+
+// Bit returns the <i>th bit counting from the most significant bit.
+func (a Addr) Bit(i int, length int) int {
+    pos := uint(length - 1 - i)
+    if pos >= 64 {
+        return int(a.Hi >> (pos - 64) & 1)
+    }
+    return int(a.Lo >> pos & 1)
+}
+
+// Mask returns the network address of the address with the leading <prefix> bits.
+func (a Addr) Mask(prefix int, length int) Addr {
+    m := HostMask(length - prefix)
+    return Addr{a.Hi &^ m.Hi, a.Lo &^ m.Lo}
+}
+
+// Last returns the last address of the network of the address with the leading <prefix> bits.
+func (a Addr) Last(prefix int, length int) Addr {
+    m := HostMask(length - prefix)
+    return Addr{a.Hi | m.Hi, a.Lo | m.Lo}
+}
+
+// TrailingZeros returns the number of trailing zero bits, which is <length> for zero address.
+func (a Addr) TrailingZeros(length int) int {
+    n := 128
+    if a.Lo != 0 {
+        n = bits.TrailingZeros64(a.Lo)
+    } else if a.Hi != 0 {
+        n = 64 + bits.TrailingZeros64(a.Hi)
+    }
+    if n > length {
+        n = length
+    }
+    return n
+}
+
+// CommonLen returns the number of the leading common bits of the address and <b>.
+func (a Addr) CommonLen(b Addr, length int) int {
+    n := 128
+    if x := a.Hi ^ b.Hi; x != 0 {
+        n = bits.LeadingZeros64(x)
+    } else if x := a.Lo ^ b.Lo; x != 0 {
+        n = 64 + bits.LeadingZeros64(x)
+    }
+    return n - (128 - length)
+}
+
+// HostMask returns the address with the lowest <n> bits set.
+func HostMask(n int) Addr {
+    switch {
+        case n <= 0:
+            return Addr{}
+        case n >= 128:
+            return Addr{^uint64(0), ^uint64(0)}
+        case n >= 64:
+            return Addr{1 << uint(n - 64) - 1, ^uint64(0)}
+    }
+    return Addr{0, 1 << uint(n) - 1}
+}
+
+// Last returns the last address of the network.
+func (p Prefix) Last(length int) Addr {
+    return p.Addr.Last(p.Len, length)
+}
+
+// Contains checks whether the network contains address <a>.
+func (p Prefix) Contains(a Addr, length int) bool {
+    return a.Mask(p.Len, length) == p.Addr
+}
+
+// ContainsPrefix checks whether the network contains the whole network <q>.
+func (p Prefix) ContainsPrefix(q Prefix, length int) bool {
+    return q.Len >= p.Len && p.Contains(q.Addr, length)
+}
+
+// Overlaps checks whether the network and <q> have any address in common.
+func (p Prefix) Overlaps(q Prefix, length int) bool {
+    return p.ContainsPrefix(q, length) || q.ContainsPrefix(p, length)
+}
+
+// RangeToPrefixes converts the address range from <start> to <end> (both inclusive)
+// to the minimal list of networks covering exactly the range.
+func RangeToPrefixes(start, end Addr, length int) []Prefix {
+    prefixes := make([]Prefix, 0)
+    for start.Cmp(end) <= 0 {
+        // The largest network starting at <start> and not exceeding <end>.
+        n := start.TrailingZeros(length)
+        for n > 0 && start.Last(length - n, length).Cmp(end) > 0 {
+            n--
+        }
+        prefixes = append(prefixes, Prefix{start, length - n})
+        last := start.Last(length - n, length)
+        if last == HostMask(length) {
+            break
+        }
+        start = last.Next()
+    }
+    return prefixes
+}
+
+// Merge merges the overlapping and adjacent networks of <prefixes>,
+// and returns the minimal sorted list of networks covering the same addresses.
+func Merge(prefixes []Prefix, length int) []Prefix {
+    if len(prefixes) == 0 {
+        return nil
+    }
+    sorted := make([]Prefix, len(prefixes))
+    copy(sorted, prefixes)
+    sort.Slice(sorted, func(i, j int) bool {
+        return sorted[i].Addr.Cmp(sorted[j].Addr) < 0
+    })
+    result := make([]Prefix, 0)
+    start  := sorted[0].Addr
+    end    := sorted[0].Last(length)
+    for _, p := range sorted[1 : ] {
+        // Merges the network if it overlaps or is adjacent to the current range.
+        if end == HostMask(length) || p.Addr.Cmp(end.Next()) <= 0 {
+            if last := p.Last(length); last.Cmp(end) > 0 {
+                end = last
+            }
+            continue
+        }
+        result = append(result, RangeToPrefixes(start, end, length)...)
+        start  = p.Addr
+        end    = p.Last(length)
+    }
+    return append(result, RangeToPrefixes(start, end, length)...)
+}
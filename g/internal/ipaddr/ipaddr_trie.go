@@ -0,0 +1,184 @@
+// Copyright 2019 gf Author(https://github.com/gogf/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package ipaddr
+
+// Trie is a path-compressed binary prefix trie of networks carrying values,
+// which supports longest prefix match lookup. It is not concurrent safe.
+type Trie struct {
+    length int
+    root   *trieNode
+    size   int
+}
+
+// trieNode is a node of the trie, the node without value is a glue node joining two children.
+type trieNode struct {
+    prefix   Prefix
+    children [2]*trieNode
+    value    interface{}
+    has      bool
+}
+
+// NewTrie creates and returns a trie for addresses of <length> bits.
+func NewTrie(length int) *Trie {
+    return &Trie{length : length}
+}
+
+// Size returns the number of networks in the trie.
+func (t *Trie) Size() int {
+    return t.size
+}
+
+// Insert inserts network <p> with <value>, the value is replaced if the network exists.
+func (t *Trie) Insert(p Prefix, value interface{}) {
+    node := &t.root
+    for {
+        n := *node
+        if n == nil {
+            *node = &trieNode{prefix : p, value : value, has : true}
+            t.size++
+            return
+        }
+        common := n.prefix.Addr.CommonLen(p.Addr, t.length)
+        if common > n.prefix.Len {
+            common = n.prefix.Len
+        }
+        if common > p.Len {
+            common = p.Len
+        }
+        switch {
+            // The same network.
+            case common == n.prefix.Len && common == p.Len:
+                if !n.has {
+                    t.size++
+                }
+                n.value, n.has = value, true
+                return
+
+            // The network is in the subtree of the node.
+            case common == n.prefix.Len:
+                node = &n.children[p.Addr.Bit(common, t.length)]
+
+            // The node is in the subtree of the network.
+            case common == p.Len:
+                parent := &trieNode{prefix : p, value : value, has : true}
+                parent.children[n.prefix.Addr.Bit(common, t.length)] = n
+                *node = parent
+                t.size++
+                return
+
+            // Splits by a glue node of the common prefix.
+            default:
+                glue := &trieNode{prefix : Prefix{p.Addr.Mask(common, t.length), common}}
+                glue.children[n.prefix.Addr.Bit(common, t.length)] = n
+                glue.children[p.Addr.Bit(common, t.length)] = &trieNode{prefix : p, value : value, has : true}
+                *node = glue
+                t.size++
+                return
+        }
+    }
+}
+
+// Get returns the value of the exact network <p>.
+func (t *Trie) Get(p Prefix) (interface{}, bool) {
+    if node := t.find(p); node != nil {
+        return (*node).value, true
+    }
+    return nil, false
+}
+
+// Lookup searches the longest network containing address <a>,
+// and returns the network and its value.
+func (t *Trie) Lookup(a Addr) (Prefix, interface{}, bool) {
+    var best *trieNode
+    for n := t.root; n != nil && n.prefix.Contains(a, t.length); {
+        if n.has {
+            best = n
+        }
+        if n.prefix.Len == t.length {
+            break
+        }
+        n = n.children[a.Bit(n.prefix.Len, t.length)]
+    }
+    if best == nil {
+        return Prefix{}, nil, false
+    }
+    return best.prefix, best.value, true
+}
+
+// Remove removes the exact network <p>, it returns false if the network does not exist.
+func (t *Trie) Remove(p Prefix) bool {
+    var (
+        parent *trieNode
+        pnode  **trieNode
+        node   = &t.root
+    )
+    for *node != nil && (*node).prefix.ContainsPrefix(p, t.length) {
+        n := *node
+        if n.prefix.Len == p.Len {
+            if !n.has {
+                return false
+            }
+            n.value, n.has = nil, false
+            t.size--
+            t.compact(node)
+            // The parent glue node may have only one child left.
+            if parent != nil && !parent.has {
+                t.compact(pnode)
+            }
+            return true
+        }
+        parent, pnode = n, node
+        node = &n.children[p.Addr.Bit(n.prefix.Len, t.length)]
+    }
+    return false
+}
+
+// Walk calls <f> for each network in ascending order of address until <f> returns false.
+func (t *Trie) Walk(f func(p Prefix, value interface{}) bool) {
+    t.walk(t.root, f)
+}
+
+func (t *Trie) walk(n *trieNode, f func(p Prefix, value interface{}) bool) bool {
+    if n == nil {
+        return true
+    }
+    if n.has && !f(n.prefix, n.value) {
+        return false
+    }
+    return t.walk(n.children[0], f) && t.walk(n.children[1], f)
+}
+
+// find returns the pointer to the node of the exact network <p>, or nil if it does not exist.
+func (t *Trie) find(p Prefix) **trieNode {
+    node := &t.root
+    for *node != nil && (*node).prefix.ContainsPrefix(p, t.length) {
+        n := *node
+        if n.prefix.Len == p.Len {
+            if n.has {
+                return node
+            }
+            return nil
+        }
+        node = &n.children[p.Addr.Bit(n.prefix.Len, t.length)]
+    }
+    return nil
+}
+
+// compact removes the node without value, which has less than two children.
+func (t *Trie) compact(node **trieNode) {
+    n := *node
+    if n.has {
+        return
+    }
+    switch {
+        case n.children[0] != nil && n.children[1] != nil:
+        case n.children[0] != nil:
+            *node = n.children[0]
+        default:
+            *node = n.children[1]
+    }
+}
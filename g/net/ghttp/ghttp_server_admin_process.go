@@ -9,10 +9,12 @@ package ghttp
 
 import (
     "bytes"
+    "context"
     "errors"
     "fmt"
     "github.com/gogf/gf/g/container/gtype"
     "github.com/gogf/gf/g/encoding/gjson"
+    "github.com/gogf/gf/g/net/gtcp"
     "github.com/gogf/gf/g/os/glog"
     "github.com/gogf/gf/g/os/gproc"
     "github.com/gogf/gf/g/os/gtime"
@@ -33,6 +35,7 @@ const (
     gADMIN_ACTION_RELOAD_ENVKEY  = "GF_SERVER_RELOAD"
     gADMIN_ACTION_RESTART_ENVKEY = "GF_SERVER_RESTART"
     gADMIN_GPROC_COMM_GROUP      = "GF_GPROC_HTTP_SERVER"
+    gADMIN_TCP_SHUTDOWN_TIMEOUT  = 30*time.Second // 平滑重启时等待gtcp连接处理完成的最大时间
 )
 
 // 用于服务管理的对象
@@ -128,7 +131,18 @@ func forkReloadProcess(newExeFilePath...string) error {
     }
     buffer, _ := gjson.Encode(sfm)
     p.Env = append(p.Env, gADMIN_ACTION_RELOAD_ENVKEY + "=" + string(buffer))
-    if _, err := p.Start(); err != nil {
+    // gtcp服务的监听文件描述符同样传递给子进程，子进程中同名(或者同地址)的gtcp.Server运行时自动继承
+    files, env := gtcp.ReloadFiles(3 + len(p.ExtraFiles))
+    if len(files) > 0 {
+        p.ExtraFiles = append(p.ExtraFiles, files...)
+        p.Env        = append(p.Env, env)
+    }
+    _, err := p.Start()
+    // 子进程创建后(无论成功与否)关闭父进程中复制的gtcp监听文件描述符
+    for _, file := range files {
+        file.Close()
+    }
+    if err != nil {
         glog.Errorf("%d: fork process failed, error:%s, %s", gproc.Pid(), err.Error(), string(buffer))
         return err
     }
@@ -243,6 +257,15 @@ func gracefulShutdownWebServers() {
     })
 }
 
+// 优雅关闭进程中所有运行的gtcp.Server服务，超时后强制关闭剩余的连接
+func gracefulShutdownTcpServers() {
+    ctx, cancel := context.WithTimeout(context.Background(), gADMIN_TCP_SHUTDOWN_TIMEOUT)
+    defer cancel()
+    if err := gtcp.ShutdownAll(ctx); err != nil {
+        glog.Errorf("%d: tcp server shutdown error: %v", gproc.Pid(), err)
+    }
+}
+
 // 强制关闭进程所有端口的Web Server服务
 // 注意，只是关闭Web Server服务，并不是退出进程
 func forceCloseWebServers() {
@@ -261,6 +284,7 @@ func handleProcessMessage() {
         if msg := gproc.Receive(gADMIN_GPROC_COMM_GROUP); msg != nil {
             if bytes.EqualFold(msg.Data, []byte("exit")) {
                 gracefulShutdownWebServers()
+                gracefulShutdownTcpServers()
                 allDoneChan <- struct{}{}
                 return
             }
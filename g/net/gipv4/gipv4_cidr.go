@@ -0,0 +1,160 @@
+// Copyright 2019 gf Author(https://github.com/gogf/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package gipv4
+
+import (
+    "fmt"
+    "github.com/gogf/gf/g/internal/ipaddr"
+    "net"
+    "strings"
+)
+
+// IPv4地址的位数
+const gIPV4_BITS = 32
+
+// IPv4网段(CIDR)，例如：192.168.1.0/24
+type CIDR struct {
+    prefix ipaddr.Prefix
+}
+
+// 解析CIDR字符串，例如：192.168.1.0/24，不带前缀长度的IP地址视为/32网段；
+// 主机位不为0时(例如：192.168.1.1/24)将被清零
+func ParseCIDR(cidr string) (*CIDR, error) {
+    s := cidr
+    if strings.IndexByte(s, '/') == -1 {
+        s += "/32"
+    }
+    _, ipNet, err := net.ParseCIDR(s)
+    if err != nil || strings.IndexByte(s, ':') != -1 {
+        return nil, fmt.Errorf(`invalid IPv4 CIDR "%s"`, cidr)
+    }
+    addr, _ := ipaddr.FromIP(ipNet.IP, gIPV4_BITS)
+    ones, _ := ipNet.Mask.Size()
+    return &CIDR{ipaddr.Prefix{Addr : addr, Len : ones}}, nil
+}
+
+// 判断IP是否属于指定的CIDR网段，CIDR或者IP无效时返回false
+func ContainsIP(cidr string, ip string) bool {
+    if c, err := ParseCIDR(cidr); err == nil {
+        return c.Contains(ip)
+    }
+    return false
+}
+
+// 合并重叠或者相邻的网段，返回覆盖相同地址的最少网段列表(按照地址升序)
+func MergeCIDR(cidrs...*CIDR) []*CIDR {
+    prefixes := make([]ipaddr.Prefix, len(cidrs))
+    for i, c := range cidrs {
+        prefixes[i] = c.prefix
+    }
+    return newCIDRs(ipaddr.Merge(prefixes, gIPV4_BITS))
+}
+
+// 将IP地址范围(包含start和end)转换为恰好覆盖该范围的最少网段列表，
+// 例如：192.168.1.0 - 192.168.1.130 -> 192.168.1.0/25, 192.168.1.128/31, 192.168.1.130/32
+func Range2CIDR(start, end string) ([]*CIDR, error) {
+    s, ok := parseIP(start)
+    if !ok {
+        return nil, fmt.Errorf(`invalid IPv4 address "%s"`, start)
+    }
+    e, ok := parseIP(end)
+    if !ok {
+        return nil, fmt.Errorf(`invalid IPv4 address "%s"`, end)
+    }
+    if s.Cmp(e) > 0 {
+        return nil, fmt.Errorf(`invalid IPv4 range "%s - %s"`, start, end)
+    }
+    return newCIDRs(ipaddr.RangeToPrefixes(s, e, gIPV4_BITS)), nil
+}
+
+// 网段字符串，例如：192.168.1.0/24
+func (c *CIDR) String() string {
+    return fmt.Sprintf("%s/%d", c.IP(), c.prefix.Len)
+}
+
+// 网段的网络地址，例如：192.168.1.0
+func (c *CIDR) IP() string {
+    return c.prefix.Addr.IP(gIPV4_BITS).String()
+}
+
+// 网段的前缀长度，例如：24
+func (c *CIDR) Prefix() int {
+    return c.prefix.Len
+}
+
+// 网段的子网掩码，例如：255.255.255.0
+func (c *CIDR) Mask() string {
+    return net.IP(net.CIDRMask(c.prefix.Len, gIPV4_BITS)).String()
+}
+
+// 网段的第一个地址(即网络地址)
+func (c *CIDR) First() string {
+    return c.IP()
+}
+
+// 网段的最后一个地址，例如：192.168.1.255
+func (c *CIDR) Last() string {
+    return c.prefix.Last(gIPV4_BITS).IP(gIPV4_BITS).String()
+}
+
+// 网段包含的地址数量
+func (c *CIDR) Size() uint64 {
+    return 1 << uint(gIPV4_BITS - c.prefix.Len)
+}
+
+// 转换为标准库的*net.IPNet对象
+func (c *CIDR) IPNet() *net.IPNet {
+    return &net.IPNet {
+        IP   : c.prefix.Addr.IP(gIPV4_BITS),
+        Mask : net.CIDRMask(c.prefix.Len, gIPV4_BITS),
+    }
+}
+
+// 判断网段是否包含指定IP，IP无效时返回false
+func (c *CIDR) Contains(ip string) bool {
+    if a, ok := parseIP(ip); ok {
+        return c.prefix.Contains(a, gIPV4_BITS)
+    }
+    return false
+}
+
+// 判断网段是否完整包含另一个网段
+func (c *CIDR) ContainsCIDR(cidr *CIDR) bool {
+    return c.prefix.ContainsPrefix(cidr.prefix, gIPV4_BITS)
+}
+
+// 判断两个网段是否有重叠的地址
+func (c *CIDR) Overlaps(cidr *CIDR) bool {
+    return c.prefix.Overlaps(cidr.prefix, gIPV4_BITS)
+}
+
+// 按照升序遍历网段中的所有地址，回调方法返回false时停止遍历
+func (c *CIDR) Iterator(f func(ip string) bool) {
+    last := c.prefix.Last(gIPV4_BITS)
+    for a := c.prefix.Addr; ; a = a.Next() {
+        if !f(a.IP(gIPV4_BITS).String()) || a == last {
+            return
+        }
+    }
+}
+
+// 解析IPv4地址
+func parseIP(ip string) (ipaddr.Addr, bool) {
+    if strings.IndexByte(ip, ':') != -1 {
+        return ipaddr.Addr{}, false
+    }
+    return ipaddr.FromIP(net.ParseIP(ip), gIPV4_BITS)
+}
+
+// 将网段前缀列表转换为CIDR列表
+func newCIDRs(prefixes []ipaddr.Prefix) []*CIDR {
+    cidrs := make([]*CIDR, len(prefixes))
+    for i, p := range prefixes {
+        cidrs[i] = &CIDR{p}
+    }
+    return cidrs
+}
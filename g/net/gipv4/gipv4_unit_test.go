@@ -0,0 +1,258 @@
+// Copyright 2019 gf Author(https://github.com/gogf/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package gipv4_test
+
+import (
+	"fmt"
+	"github.com/gogf/gf/g/net/gipv4"
+	"github.com/gogf/gf/g/test/gtest"
+	"math/rand"
+	"testing"
+)
+
+// cidrStrings converts <cidrs> to string slice.
+func cidrStrings(cidrs []*gipv4.CIDR) []string {
+	array := make([]string, len(cidrs))
+	for i, c := range cidrs {
+		array[i] = c.String()
+	}
+	return array
+}
+
+// mustParse parses <cidr> ignoring the error.
+func mustParse(cidr string) *gipv4.CIDR {
+	c, _ := gipv4.ParseCIDR(cidr)
+	return c
+}
+
+func Test_ParseCIDR(t *testing.T) {
+	gtest.Case(t, func() {
+		c, err := gipv4.ParseCIDR("192.168.1.100/24")
+		gtest.Assert(err, nil)
+		gtest.Assert(c.String(), "192.168.1.0/24")
+		gtest.Assert(c.IP(), "192.168.1.0")
+		gtest.Assert(c.Prefix(), 24)
+		gtest.Assert(c.Mask(), "255.255.255.0")
+		gtest.Assert(c.First(), "192.168.1.0")
+		gtest.Assert(c.Last(), "192.168.1.255")
+		gtest.Assert(c.Size(), 256)
+		gtest.Assert(c.IPNet().String(), "192.168.1.0/24")
+
+		c, err = gipv4.ParseCIDR("10.0.0.1")
+		gtest.Assert(err, nil)
+		gtest.Assert(c.String(), "10.0.0.1/32")
+		gtest.Assert(c.Size(), 1)
+
+		c, err = gipv4.ParseCIDR("0.0.0.0/0")
+		gtest.Assert(err, nil)
+		gtest.Assert(c.Last(), "255.255.255.255")
+		gtest.Assert(c.Size(), uint64(1)<<32)
+
+		for _, s := range []string{"", "192.168.1.0/33", "256.0.0.0/8", "::1/128", "2001:db8::", "abc"} {
+			_, err = gipv4.ParseCIDR(s)
+			gtest.AssertNE(err, nil)
+		}
+	})
+}
+
+func Test_CIDR_Contains(t *testing.T) {
+	gtest.Case(t, func() {
+		c := mustParse("192.168.0.0/16")
+		gtest.Assert(c.Contains("192.168.0.0"), true)
+		gtest.Assert(c.Contains("192.168.255.255"), true)
+		gtest.Assert(c.Contains("192.169.0.0"), false)
+		gtest.Assert(c.Contains("::1"), false)
+		gtest.Assert(c.Contains("invalid"), false)
+		gtest.Assert(c.ContainsCIDR(mustParse("192.168.1.0/24")), true)
+		gtest.Assert(c.ContainsCIDR(mustParse("192.0.0.0/8")), false)
+		gtest.Assert(c.Overlaps(mustParse("192.0.0.0/8")), true)
+		gtest.Assert(c.Overlaps(mustParse("192.168.100.0/24")), true)
+		gtest.Assert(c.Overlaps(mustParse("10.0.0.0/8")), false)
+
+		gtest.Assert(gipv4.ContainsIP("10.0.0.0/8", "10.1.2.3"), true)
+		gtest.Assert(gipv4.ContainsIP("10.0.0.0/8", "11.1.2.3"), false)
+		gtest.Assert(gipv4.ContainsIP("invalid", "10.1.2.3"), false)
+	})
+}
+
+func Test_CIDR_Iterator(t *testing.T) {
+	gtest.Case(t, func() {
+		ips := make([]string, 0)
+		mustParse("192.168.1.4/30").Iterator(func(ip string) bool {
+			ips = append(ips, ip)
+			return true
+		})
+		gtest.Assert(ips, []string{"192.168.1.4", "192.168.1.5", "192.168.1.6", "192.168.1.7"})
+
+		ips = ips[:0]
+		mustParse("255.255.255.254/31").Iterator(func(ip string) bool {
+			ips = append(ips, ip)
+			return true
+		})
+		gtest.Assert(ips, []string{"255.255.255.254", "255.255.255.255"})
+
+		count := 0
+		mustParse("10.0.0.0/8").Iterator(func(ip string) bool {
+			count++
+			return count < 10
+		})
+		gtest.Assert(count, 10)
+	})
+}
+
+func Test_Range2CIDR(t *testing.T) {
+	gtest.Case(t, func() {
+		cidrs, err := gipv4.Range2CIDR("192.168.1.0", "192.168.1.130")
+		gtest.Assert(err, nil)
+		gtest.Assert(cidrStrings(cidrs), []string{"192.168.1.0/25", "192.168.1.128/31", "192.168.1.130/32"})
+
+		cidrs, err = gipv4.Range2CIDR("10.0.0.1", "10.0.0.6")
+		gtest.Assert(err, nil)
+		gtest.Assert(cidrStrings(cidrs), []string{"10.0.0.1/32", "10.0.0.2/31", "10.0.0.4/31", "10.0.0.6/32"})
+
+		cidrs, err = gipv4.Range2CIDR("0.0.0.0", "255.255.255.255")
+		gtest.Assert(err, nil)
+		gtest.Assert(cidrStrings(cidrs), []string{"0.0.0.0/0"})
+
+		cidrs, err = gipv4.Range2CIDR("255.255.255.255", "255.255.255.255")
+		gtest.Assert(err, nil)
+		gtest.Assert(cidrStrings(cidrs), []string{"255.255.255.255/32"})
+
+		_, err = gipv4.Range2CIDR("10.0.0.2", "10.0.0.1")
+		gtest.AssertNE(err, nil)
+		_, err = gipv4.Range2CIDR("10.0.0.1", "::1")
+		gtest.AssertNE(err, nil)
+	})
+}
+
+func Test_MergeCIDR(t *testing.T) {
+	gtest.Case(t, func() {
+		cidrs := gipv4.MergeCIDR(
+			mustParse("192.168.1.0/25"),
+			mustParse("192.168.1.128/25"),
+			mustParse("10.0.0.0/8"),
+			mustParse("10.1.0.0/16"),
+			mustParse("172.16.0.1/32"),
+			mustParse("172.16.0.2/32"),
+			mustParse("255.255.255.255/32"),
+			mustParse("255.255.255.254/32"),
+		)
+		gtest.Assert(cidrStrings(cidrs), []string{
+			"10.0.0.0/8", "172.16.0.1/32", "172.16.0.2/32", "192.168.1.0/24", "255.255.255.254/31",
+		})
+		gtest.Assert(len(gipv4.MergeCIDR()), 0)
+	})
+}
+
+func Test_Trie(t *testing.T) {
+	gtest.Case(t, func() {
+		trie := gipv4.NewTrie()
+		gtest.Assert(trie.Add("0.0.0.0/0", "default"), nil)
+		gtest.Assert(trie.Add("10.0.0.0/8", "a"), nil)
+		gtest.Assert(trie.Add("10.1.0.0/16", "b"), nil)
+		gtest.Assert(trie.Add("10.1.2.0/24", "c"), nil)
+		gtest.Assert(trie.Add("10.1.2.3", "d"), nil)
+		gtest.Assert(trie.AddRange("192.168.1.0", "192.168.1.130", "range"), nil)
+		gtest.AssertNE(trie.Add("invalid", "x"), nil)
+		gtest.AssertNE(trie.AddRange("10.0.0.2", "10.0.0.1", "x"), nil)
+		gtest.Assert(trie.Size(), 8)
+
+		for ip, expect := range map[string]string{
+			"10.1.2.3":      "10.1.2.3/32:d",
+			"10.1.2.4":      "10.1.2.0/24:c",
+			"10.1.3.4":      "10.1.0.0/16:b",
+			"10.2.3.4":      "10.0.0.0/8:a",
+			"11.2.3.4":      "0.0.0.0/0:default",
+			"192.168.1.129": "192.168.1.128/31:range",
+			"192.168.1.130": "192.168.1.130/32:range",
+			"192.168.1.131": "0.0.0.0/0:default",
+		} {
+			cidr, value, ok := trie.Lookup(ip)
+			gtest.Assert(ok, true)
+			gtest.Assert(fmt.Sprintf("%s:%v", cidr, value), expect)
+		}
+		_, _, ok := trie.Lookup("invalid")
+		gtest.Assert(ok, false)
+
+		value, ok := trie.Get("10.1.0.0/16")
+		gtest.Assert(ok, true)
+		gtest.Assert(value, "b")
+		_, ok = trie.Get("10.1.0.0/15")
+		gtest.Assert(ok, false)
+
+		// Replaces the value of existing network.
+		gtest.Assert(trie.Add("10.1.0.0/16", "bb"), nil)
+		gtest.Assert(trie.Size(), 8)
+		_, value, _ = trie.Lookup("10.1.3.4")
+		gtest.Assert(value, "bb")
+
+		gtest.Assert(trie.Remove("10.1.0.0/16"), true)
+		gtest.Assert(trie.Remove("10.1.0.0/16"), false)
+		gtest.Assert(trie.Remove("10.1.0.0/15"), false)
+		gtest.Assert(trie.Size(), 7)
+		cidr, value, _ := trie.Lookup("10.1.3.4")
+		gtest.Assert(cidr.String(), "10.0.0.0/8")
+		gtest.Assert(value, "a")
+		_, value, _ = trie.Lookup("10.1.2.3")
+		gtest.Assert(value, "d")
+
+		gtest.Assert(trie.Remove("0.0.0.0/0"), true)
+		gtest.Assert(trie.Contains("11.2.3.4"), false)
+		gtest.Assert(trie.Contains("10.2.3.4"), true)
+
+		cidrs := make([]string, 0)
+		trie.Iterator(func(cidr *gipv4.CIDR, value interface{}) bool {
+			cidrs = append(cidrs, cidr.String())
+			return true
+		})
+		gtest.Assert(cidrs, []string{
+			"10.0.0.0/8", "10.1.2.0/24", "10.1.2.3/32",
+			"192.168.1.0/25", "192.168.1.128/31", "192.168.1.130/32",
+		})
+	})
+}
+
+// Test_Trie_Random checks the longest prefix match result of the trie with brute force searching.
+func Test_Trie_Random(t *testing.T) {
+	gtest.Case(t, func() {
+		r := rand.New(rand.NewSource(1))
+		randomIP := func() string {
+			// Uses a small address space to generate nested networks.
+			return fmt.Sprintf("10.%d.%d.%d", r.Intn(4), r.Intn(256), r.Intn(256))
+		}
+		trie := gipv4.NewTrie()
+		cidrs := make(map[string]*gipv4.CIDR)
+		for i := 0; i < 2000; i++ {
+			c := mustParse(fmt.Sprintf("%s/%d", randomIP(), 8+r.Intn(25)))
+			trie.AddCIDR(c, c.String())
+			cidrs[c.String()] = c
+		}
+		// Removes some networks.
+		for k := range cidrs {
+			if r.Intn(3) == 0 {
+				gtest.Assert(trie.Remove(k), true)
+				delete(cidrs, k)
+			}
+		}
+		gtest.Assert(trie.Size(), len(cidrs))
+		for i := 0; i < 2000; i++ {
+			ip := randomIP()
+			var best *gipv4.CIDR
+			for _, c := range cidrs {
+				if c.Contains(ip) && (best == nil || c.Prefix() > best.Prefix()) {
+					best = c
+				}
+			}
+			cidr, value, ok := trie.Lookup(ip)
+			gtest.Assert(ok, best != nil)
+			if best != nil {
+				gtest.Assert(cidr.String(), best.String())
+				gtest.Assert(value, best.String())
+			}
+		}
+	})
+}
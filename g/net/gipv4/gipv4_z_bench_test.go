@@ -0,0 +1,26 @@
+// Copyright 2019 gf Author(https://github.com/gogf/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://github.com/gogf/gf.
+
+// go test *.go -bench=".*" -benchmem
+
+package gipv4_test
+
+import (
+	"github.com/gogf/gf/g/net/gipv4"
+	"testing"
+)
+
+func Benchmark_Trie_Lookup(b *testing.B) {
+	// One million /24 networks.
+	benchTrie := gipv4.NewTrie()
+	for i := 0; i < 1000000; i++ {
+		benchTrie.Add(gipv4.Long2ip(uint32(i)<<8)+"/24", i)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		benchTrie.Lookup(gipv4.Long2ip(uint32(i) << 4))
+	}
+}
@@ -0,0 +1,156 @@
+// Copyright 2019 gf Author(https://github.com/gogf/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package gipv6
+
+import (
+    "fmt"
+    "github.com/gogf/gf/g/internal/ipaddr"
+    "math/big"
+    "net"
+    "strings"
+)
+
+// IPv6地址的位数
+const gIPV6_BITS = 128
+
+// IPv6网段(CIDR)，例如：2001:db8::/32
+type CIDR struct {
+    prefix ipaddr.Prefix
+}
+
+// 解析CIDR字符串，例如：2001:db8::/32，不带前缀长度的IP地址视为/128网段；
+// 主机位不为0时(例如：2001:db8::1/32)将被清零
+func ParseCIDR(cidr string) (*CIDR, error) {
+    s := cidr
+    if strings.IndexByte(s, '/') == -1 {
+        s += "/128"
+    }
+    _, ipNet, err := net.ParseCIDR(s)
+    if err != nil || strings.IndexByte(s, ':') == -1 {
+        return nil, fmt.Errorf(`invalid IPv6 CIDR "%s"`, cidr)
+    }
+    addr, _ := ipaddr.FromIP(ipNet.IP, gIPV6_BITS)
+    ones, _ := ipNet.Mask.Size()
+    return &CIDR{ipaddr.Prefix{Addr : addr, Len : ones}}, nil
+}
+
+// 判断IP是否属于指定的CIDR网段，CIDR或者IP无效时返回false
+func ContainsIP(cidr string, ip string) bool {
+    if c, err := ParseCIDR(cidr); err == nil {
+        return c.Contains(ip)
+    }
+    return false
+}
+
+// 合并重叠或者相邻的网段，返回覆盖相同地址的最少网段列表(按照地址升序)
+func MergeCIDR(cidrs...*CIDR) []*CIDR {
+    prefixes := make([]ipaddr.Prefix, len(cidrs))
+    for i, c := range cidrs {
+        prefixes[i] = c.prefix
+    }
+    return newCIDRs(ipaddr.Merge(prefixes, gIPV6_BITS))
+}
+
+// 将IP地址范围(包含start和end)转换为恰好覆盖该范围的最少网段列表，
+// 例如：2001:db8:: - 2001:db8::2 -> 2001:db8::/127, 2001:db8::2/128
+func Range2CIDR(start, end string) ([]*CIDR, error) {
+    s, ok := parseIP(start)
+    if !ok {
+        return nil, fmt.Errorf(`invalid IPv6 address "%s"`, start)
+    }
+    e, ok := parseIP(end)
+    if !ok {
+        return nil, fmt.Errorf(`invalid IPv6 address "%s"`, end)
+    }
+    if s.Cmp(e) > 0 {
+        return nil, fmt.Errorf(`invalid IPv6 range "%s - %s"`, start, end)
+    }
+    return newCIDRs(ipaddr.RangeToPrefixes(s, e, gIPV6_BITS)), nil
+}
+
+// 网段字符串，例如：2001:db8::/32
+func (c *CIDR) String() string {
+    return fmt.Sprintf("%s/%d", c.IP(), c.prefix.Len)
+}
+
+// 网段的网络地址，例如：2001:db8::
+func (c *CIDR) IP() string {
+    return c.prefix.Addr.IP(gIPV6_BITS).String()
+}
+
+// 网段的前缀长度，例如：32
+func (c *CIDR) Prefix() int {
+    return c.prefix.Len
+}
+
+// 网段的第一个地址(即网络地址)
+func (c *CIDR) First() string {
+    return c.IP()
+}
+
+// 网段的最后一个地址，例如：2001:db8:ffff:ffff:ffff:ffff:ffff:ffff
+func (c *CIDR) Last() string {
+    return c.prefix.Last(gIPV6_BITS).IP(gIPV6_BITS).String()
+}
+
+// 网段包含的地址数量
+func (c *CIDR) Size() *big.Int {
+    return new(big.Int).Lsh(big.NewInt(1), uint(gIPV6_BITS - c.prefix.Len))
+}
+
+// 转换为标准库的*net.IPNet对象
+func (c *CIDR) IPNet() *net.IPNet {
+    return &net.IPNet {
+        IP   : c.prefix.Addr.IP(gIPV6_BITS),
+        Mask : net.CIDRMask(c.prefix.Len, gIPV6_BITS),
+    }
+}
+
+// 判断网段是否包含指定IP，IP无效时返回false
+func (c *CIDR) Contains(ip string) bool {
+    if a, ok := parseIP(ip); ok {
+        return c.prefix.Contains(a, gIPV6_BITS)
+    }
+    return false
+}
+
+// 判断网段是否完整包含另一个网段
+func (c *CIDR) ContainsCIDR(cidr *CIDR) bool {
+    return c.prefix.ContainsPrefix(cidr.prefix, gIPV6_BITS)
+}
+
+// 判断两个网段是否有重叠的地址
+func (c *CIDR) Overlaps(cidr *CIDR) bool {
+    return c.prefix.Overlaps(cidr.prefix, gIPV6_BITS)
+}
+
+// 按照升序遍历网段中的所有地址，回调方法返回false时停止遍历(注意IPv6网段的地址数量可能非常大)
+func (c *CIDR) Iterator(f func(ip string) bool) {
+    last := c.prefix.Last(gIPV6_BITS)
+    for a := c.prefix.Addr; ; a = a.Next() {
+        if !f(a.IP(gIPV6_BITS).String()) || a == last {
+            return
+        }
+    }
+}
+
+// 解析IPv6地址
+func parseIP(ip string) (ipaddr.Addr, bool) {
+    if strings.IndexByte(ip, ':') == -1 {
+        return ipaddr.Addr{}, false
+    }
+    return ipaddr.FromIP(net.ParseIP(ip), gIPV6_BITS)
+}
+
+// 将网段前缀列表转换为CIDR列表
+func newCIDRs(prefixes []ipaddr.Prefix) []*CIDR {
+    cidrs := make([]*CIDR, len(prefixes))
+    for i, p := range prefixes {
+        cidrs[i] = &CIDR{p}
+    }
+    return cidrs
+}
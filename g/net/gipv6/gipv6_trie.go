@@ -0,0 +1,117 @@
+// Copyright 2019 gf Author(https://github.com/gogf/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package gipv6
+
+import (
+    "github.com/gogf/gf/g/internal/ipaddr"
+    "github.com/gogf/gf/g/internal/rwmutex"
+)
+
+// IPv6网段集合，使用路径压缩的前缀树实现，每个网段可以携带自定义数据，
+// 支持最长前缀匹配查询，适用于大量网段的IP过滤、IP归属地查询等场景
+type Trie struct {
+    mu   *rwmutex.RWMutex
+    trie *ipaddr.Trie
+}
+
+// 创建一个网段集合，参数unsafe用于指定是否关闭并发安全，默认开启
+func NewTrie(unsafe...bool) *Trie {
+    return &Trie {
+        mu   : rwmutex.New(unsafe...),
+        trie : ipaddr.NewTrie(gIPV6_BITS),
+    }
+}
+
+// 添加网段及其数据，例如：2001:db8::/32，网段已存在时替换数据
+func (t *Trie) Add(cidr string, value interface{}) error {
+    c, err := ParseCIDR(cidr)
+    if err != nil {
+        return err
+    }
+    t.AddCIDR(c, value)
+    return nil
+}
+
+// 添加网段及其数据，网段已存在时替换数据
+func (t *Trie) AddCIDR(cidr *CIDR, value interface{}) {
+    t.mu.Lock()
+    t.trie.Insert(cidr.prefix, value)
+    t.mu.Unlock()
+}
+
+// 添加IP地址范围(包含start和end)及其数据，地址范围将被转换为多个网段添加
+func (t *Trie) AddRange(start, end string, value interface{}) error {
+    cidrs, err := Range2CIDR(start, end)
+    if err != nil {
+        return err
+    }
+    t.mu.Lock()
+    for _, c := range cidrs {
+        t.trie.Insert(c.prefix, value)
+    }
+    t.mu.Unlock()
+    return nil
+}
+
+// 获取与指定网段完全相同的网段的数据
+func (t *Trie) Get(cidr string) (value interface{}, ok bool) {
+    c, err := ParseCIDR(cidr)
+    if err != nil {
+        return nil, false
+    }
+    t.mu.RLock()
+    defer t.mu.RUnlock()
+    return t.trie.Get(c.prefix)
+}
+
+// 查询包含指定IP的最长前缀(最小)网段，返回该网段及其数据，不存在时ok为false
+func (t *Trie) Lookup(ip string) (cidr *CIDR, value interface{}, ok bool) {
+    a, valid := parseIP(ip)
+    if !valid {
+        return nil, nil, false
+    }
+    t.mu.RLock()
+    prefix, value, ok := t.trie.Lookup(a)
+    t.mu.RUnlock()
+    if !ok {
+        return nil, nil, false
+    }
+    return &CIDR{prefix}, value, true
+}
+
+// 判断集合中是否有网段包含指定IP
+func (t *Trie) Contains(ip string) bool {
+    _, _, ok := t.Lookup(ip)
+    return ok
+}
+
+// 删除与指定网段完全相同的网段，不存在时返回false
+func (t *Trie) Remove(cidr string) bool {
+    c, err := ParseCIDR(cidr)
+    if err != nil {
+        return false
+    }
+    t.mu.Lock()
+    defer t.mu.Unlock()
+    return t.trie.Remove(c.prefix)
+}
+
+// 网段数量
+func (t *Trie) Size() int {
+    t.mu.RLock()
+    defer t.mu.RUnlock()
+    return t.trie.Size()
+}
+
+// 按照地址升序遍历所有网段及其数据(包含关系的网段中较大的网段在前)，回调方法返回false时停止遍历
+func (t *Trie) Iterator(f func(cidr *CIDR, value interface{}) bool) {
+    t.mu.RLock()
+    defer t.mu.RUnlock()
+    t.trie.Walk(func(p ipaddr.Prefix, value interface{}) bool {
+        return f(&CIDR{p}, value)
+    })
+}
@@ -0,0 +1,209 @@
+// Copyright 2019 gf Author(https://github.com/gogf/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package gipv6_test
+
+import (
+	"fmt"
+	"github.com/gogf/gf/g/net/gipv6"
+	"github.com/gogf/gf/g/test/gtest"
+	"math/rand"
+	"testing"
+)
+
+// cidrStrings converts <cidrs> to string slice.
+func cidrStrings(cidrs []*gipv6.CIDR) []string {
+	array := make([]string, len(cidrs))
+	for i, c := range cidrs {
+		array[i] = c.String()
+	}
+	return array
+}
+
+// mustParse parses <cidr> ignoring the error.
+func mustParse(cidr string) *gipv6.CIDR {
+	c, _ := gipv6.ParseCIDR(cidr)
+	return c
+}
+
+func Test_ParseCIDR(t *testing.T) {
+	gtest.Case(t, func() {
+		c, err := gipv6.ParseCIDR("2001:db8::1/32")
+		gtest.Assert(err, nil)
+		gtest.Assert(c.String(), "2001:db8::/32")
+		gtest.Assert(c.IP(), "2001:db8::")
+		gtest.Assert(c.Prefix(), 32)
+		gtest.Assert(c.First(), "2001:db8::")
+		gtest.Assert(c.Last(), "2001:db8:ffff:ffff:ffff:ffff:ffff:ffff")
+		gtest.Assert(c.Size().String(), "79228162514264337593543950336")
+		gtest.Assert(c.IPNet().String(), "2001:db8::/32")
+
+		c, err = gipv6.ParseCIDR("::1")
+		gtest.Assert(err, nil)
+		gtest.Assert(c.String(), "::1/128")
+		gtest.Assert(c.Size().String(), "1")
+
+		c, err = gipv6.ParseCIDR("::/0")
+		gtest.Assert(err, nil)
+		gtest.Assert(c.Last(), "ffff:ffff:ffff:ffff:ffff:ffff:ffff:ffff")
+
+		for _, s := range []string{"", "2001:db8::/129", "192.168.1.0/24", "10.0.0.1", "abc"} {
+			_, err = gipv6.ParseCIDR(s)
+			gtest.AssertNE(err, nil)
+		}
+	})
+}
+
+func Test_CIDR_Contains(t *testing.T) {
+	gtest.Case(t, func() {
+		c := mustParse("2001:db8::/32")
+		gtest.Assert(c.Contains("2001:db8::"), true)
+		gtest.Assert(c.Contains("2001:db8:ffff::1"), true)
+		gtest.Assert(c.Contains("2001:db9::"), false)
+		gtest.Assert(c.Contains("192.168.1.1"), false)
+		gtest.Assert(c.ContainsCIDR(mustParse("2001:db8:1::/48")), true)
+		gtest.Assert(c.ContainsCIDR(mustParse("2001::/16")), false)
+		gtest.Assert(c.Overlaps(mustParse("2001::/16")), true)
+		gtest.Assert(c.Overlaps(mustParse("fe80::/10")), false)
+
+		gtest.Assert(gipv6.ContainsIP("fe80::/10", "fe80::1"), true)
+		gtest.Assert(gipv6.ContainsIP("fe80::/10", "fec0::1"), false)
+	})
+}
+
+func Test_CIDR_Iterator(t *testing.T) {
+	gtest.Case(t, func() {
+		ips := make([]string, 0)
+		mustParse("ffff:ffff:ffff:ffff:ffff:ffff:ffff:fffe/127").Iterator(func(ip string) bool {
+			ips = append(ips, ip)
+			return true
+		})
+		gtest.Assert(ips, []string{"ffff:ffff:ffff:ffff:ffff:ffff:ffff:fffe", "ffff:ffff:ffff:ffff:ffff:ffff:ffff:ffff"})
+
+		// Carries from the low 64 bits to the high 64 bits.
+		ips = ips[:0]
+		mustParse("2001:db8::ffff:ffff:ffff:fffe/126").Iterator(func(ip string) bool {
+			ips = append(ips, ip)
+			return len(ips) < 3
+		})
+		gtest.Assert(ips, []string{"2001:db8::ffff:ffff:ffff:fffc", "2001:db8::ffff:ffff:ffff:fffd", "2001:db8::ffff:ffff:ffff:fffe"})
+	})
+}
+
+func Test_Range2CIDR(t *testing.T) {
+	gtest.Case(t, func() {
+		cidrs, err := gipv6.Range2CIDR("2001:db8::", "2001:db8::2")
+		gtest.Assert(err, nil)
+		gtest.Assert(cidrStrings(cidrs), []string{"2001:db8::/127", "2001:db8::2/128"})
+
+		cidrs, err = gipv6.Range2CIDR("2001:db8::ffff:ffff:ffff:ffff", "2001:db8:0:1::")
+		gtest.Assert(err, nil)
+		gtest.Assert(cidrStrings(cidrs), []string{"2001:db8::ffff:ffff:ffff:ffff/128", "2001:db8:0:1::/128"})
+
+		cidrs, err = gipv6.Range2CIDR("::", "ffff:ffff:ffff:ffff:ffff:ffff:ffff:ffff")
+		gtest.Assert(err, nil)
+		gtest.Assert(cidrStrings(cidrs), []string{"::/0"})
+
+		cidrs, err = gipv6.Range2CIDR("2001:db8::", "2001:db8:ffff:ffff:ffff:ffff:ffff:ffff")
+		gtest.Assert(err, nil)
+		gtest.Assert(cidrStrings(cidrs), []string{"2001:db8::/32"})
+
+		_, err = gipv6.Range2CIDR("2001:db8::2", "2001:db8::1")
+		gtest.AssertNE(err, nil)
+		_, err = gipv6.Range2CIDR("2001:db8::2", "10.0.0.1")
+		gtest.AssertNE(err, nil)
+	})
+}
+
+func Test_MergeCIDR(t *testing.T) {
+	gtest.Case(t, func() {
+		cidrs := gipv6.MergeCIDR(
+			mustParse("2001:db8::/33"),
+			mustParse("2001:db8:8000::/33"),
+			mustParse("fe80::/10"),
+			mustParse("fe80::1/128"),
+			mustParse("::1/128"),
+		)
+		gtest.Assert(cidrStrings(cidrs), []string{"::1/128", "2001:db8::/32", "fe80::/10"})
+	})
+}
+
+func Test_Trie(t *testing.T) {
+	gtest.Case(t, func() {
+		trie := gipv6.NewTrie()
+		gtest.Assert(trie.Add("::/0", "default"), nil)
+		gtest.Assert(trie.Add("2001:db8::/32", "a"), nil)
+		gtest.Assert(trie.Add("2001:db8:1::/48", "b"), nil)
+		gtest.Assert(trie.Add("2001:db8:1::1", "c"), nil)
+		gtest.Assert(trie.AddRange("fe80::", "fe80::2", "range"), nil)
+		gtest.AssertNE(trie.Add("10.0.0.0/8", "x"), nil)
+		gtest.Assert(trie.Size(), 6)
+
+		for ip, expect := range map[string]string{
+			"2001:db8:1::1": "2001:db8:1::1/128:c",
+			"2001:db8:1::2": "2001:db8:1::/48:b",
+			"2001:db8:2::1": "2001:db8::/32:a",
+			"2001:db9::1":   "::/0:default",
+			"fe80::1":       "fe80::/127:range",
+			"fe80::2":       "fe80::2/128:range",
+		} {
+			cidr, value, ok := trie.Lookup(ip)
+			gtest.Assert(ok, true)
+			gtest.Assert(fmt.Sprintf("%s:%v", cidr, value), expect)
+		}
+		_, _, ok := trie.Lookup("10.0.0.1")
+		gtest.Assert(ok, false)
+
+		gtest.Assert(trie.Remove("2001:db8:1::/48"), true)
+		gtest.Assert(trie.Remove("2001:db8:1::/48"), false)
+		gtest.Assert(trie.Size(), 5)
+		_, value, _ := trie.Lookup("2001:db8:1::2")
+		gtest.Assert(value, "a")
+		value, ok = trie.Get("2001:db8:1::1/128")
+		gtest.Assert(ok, true)
+		gtest.Assert(value, "c")
+	})
+}
+
+// Test_Trie_Random checks the longest prefix match result of the trie with brute force searching.
+func Test_Trie_Random(t *testing.T) {
+	gtest.Case(t, func() {
+		r := rand.New(rand.NewSource(1))
+		randomIP := func() string {
+			// Uses a small address space across the 64 bits boundary to generate nested networks.
+			return fmt.Sprintf("2001:db8::%x:%x:0:%x", r.Intn(4), r.Intn(65536), r.Intn(65536))
+		}
+		trie := gipv6.NewTrie()
+		cidrs := make(map[string]*gipv6.CIDR)
+		for i := 0; i < 2000; i++ {
+			c := mustParse(fmt.Sprintf("%s/%d", randomIP(), 32+r.Intn(97)))
+			trie.AddCIDR(c, c.String())
+			cidrs[c.String()] = c
+		}
+		for k := range cidrs {
+			if r.Intn(3) == 0 {
+				gtest.Assert(trie.Remove(k), true)
+				delete(cidrs, k)
+			}
+		}
+		gtest.Assert(trie.Size(), len(cidrs))
+		for i := 0; i < 2000; i++ {
+			ip := randomIP()
+			var best *gipv6.CIDR
+			for _, c := range cidrs {
+				if c.Contains(ip) && (best == nil || c.Prefix() > best.Prefix()) {
+					best = c
+				}
+			}
+			cidr, value, ok := trie.Lookup(ip)
+			gtest.Assert(ok, best != nil)
+			if best != nil {
+				gtest.Assert(cidr.String(), best.String())
+				gtest.Assert(value, best.String())
+			}
+		}
+	})
+}
@@ -9,19 +9,49 @@
 // eg:
 // s := smtp.New("smtp.exmail.qq.com:25", "notify@a.com", "password")
 // glog.Println(s.SendMail("notify@a.com", "ulric@b.com;rain@c.com", "subject", "body, <font color=red>red</font>"))
+//
+// m := gsmtp.NewMessage().From("notify@a.com").To("ulric@b.com").Subject("subject").Html("<b>body</b>")
+// glog.Println(s.Send(m))
 package gsmtp
 
 import (
-    "encoding/base64"
+    "crypto/tls"
+    "errors"
     "fmt"
+    "net"
     "net/smtp"
     "strings"
+    "time"
+)
+
+// TLS modes of the connection to the mail server.
+const (
+    TLS_AUTO     = 0 // Switches to TLS using STARTTLS if the server supports it (default).
+    TLS_NONE     = 1 // Never uses TLS.
+    TLS_STARTTLS = 2 // Requires STARTTLS, fails if the server does not support it.
+    TLS_IMPLICIT = 3 // Connects using TLS directly, usually on port 465.
+)
+
+const (
+    // Default timeout for dialing and each mail transaction.
+    gDEFAULT_TIMEOUT = 30*time.Second
 )
 
 type SMTP struct {
-    Address  string
-    Username string
-    Password string
+    Address   string        // Server address in "host:port" format.
+    Username  string        // Username for authentication, no authentication if empty.
+    Password  string        // Password for authentication.
+    TLSMode   int           // TLS mode, TLS_AUTO in default.
+    TLSConfig *tls.Config   // Optional TLS configuration, the ServerName is the host of Address in default.
+    Timeout   time.Duration // Timeout for dialing and each mail transaction, 30 seconds in default.
+}
+
+// client wraps a smtp.Client with its underlying connection.
+type client struct {
+    *smtp.Client
+    conn     net.Conn
+    timeout  time.Duration
+    lastUsed time.Time
 }
 
 // New creates and returns a new SMTP object.
@@ -38,52 +68,140 @@ func New(address, username, password string) *SMTP {
 // and then sends an email from address from, to addresses to, with
 // message msg.
 func (s *SMTP) SendMail(from, tos, subject, body string, contentType ...string) error {
-    if s.Address == "" {
-        return fmt.Errorf("address is necessary")
-    }
-
-    hp := strings.Split(s.Address, ":")
-    if len(hp) != 2 {
-        return fmt.Errorf("address format error")
-    }
-
-    arr := strings.Split(tos, ";")
-    count := len(arr)
-    safeArr := make([]string, 0, count)
-    for i := 0; i < count; i++ {
-        if arr[i] == "" {
-            continue
+    safeArr := make([]string, 0)
+    for _, to := range strings.Split(tos, ";") {
+        if to != "" {
+            safeArr = append(safeArr, to)
         }
-        safeArr = append(safeArr, arr[i])
     }
-
     if len(safeArr) == 0 {
         return fmt.Errorf("tos invalid")
     }
+    m := NewMessage().From(from).To(safeArr...).Subject(subject)
+    if len(contentType) > 0 && contentType[0] == "html" {
+        m.Html(body)
+    } else {
+        m.Text(body)
+    }
+    return s.Send(m)
+}
 
-    tos  = strings.Join(safeArr, ";")
-    b64 := base64.NewEncoding("ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789+/")
+// Send sends the messages <msgs> using one connection to the server.
+// It stops and returns the error if any message fails sending.
+func (s *SMTP) Send(msgs...*Message) error {
+    c, err := s.dial()
+    if err != nil {
+        return err
+    }
+    defer c.Close()
+    for _, m := range msgs {
+        if err := c.send(m); err != nil {
+            return err
+        }
+    }
+    return c.Quit()
+}
 
-    header                := make(map[string]string)
-    header["From"]         = from
-    header["To"]           = tos
-    header["Subject"]      = fmt.Sprintf("=?UTF-8?B?%s?=", b64.EncodeToString([]byte(subject)))
-    header["MIME-Version"] = "1.0"
+// NewPool creates and returns a pooled sender for bulk mail,
+// which keeps at most <size> connections to the server and reuses them.
+func (s *SMTP) NewPool(size int) *Pool {
+    return newPool(s, size)
+}
 
-    ct := "text/plain; charset=UTF-8"
-    if len(contentType) > 0 && contentType[0] == "html" {
-        ct = "text/html; charset=UTF-8"
+// dial connects to the server, switches to TLS and authenticates according to the configuration.
+func (s *SMTP) dial() (*client, error) {
+    if s.Address == "" {
+        return nil, errors.New("address is necessary")
     }
+    host, _, err := net.SplitHostPort(s.Address)
+    if err != nil {
+        return nil, fmt.Errorf("address format error: %s", err.Error())
+    }
+    timeout := s.Timeout
+    if timeout <= 0 {
+        timeout = gDEFAULT_TIMEOUT
+    }
+    tlsConfig := s.TLSConfig
+    if tlsConfig == nil {
+        tlsConfig = &tls.Config{ServerName : host}
+    } else if tlsConfig.ServerName == "" {
+        tlsConfig = tlsConfig.Clone()
+        tlsConfig.ServerName = host
+    }
+    var conn net.Conn
+    dialer := &net.Dialer{Timeout : timeout}
+    if s.TLSMode == TLS_IMPLICIT {
+        conn, err = tls.DialWithDialer(dialer, "tcp", s.Address, tlsConfig)
+    } else {
+        conn, err = dialer.Dial("tcp", s.Address)
+    }
+    if err != nil {
+        return nil, err
+    }
+    conn.SetDeadline(time.Now().Add(timeout))
+    c, err := smtp.NewClient(conn, host)
+    if err != nil {
+        conn.Close()
+        return nil, err
+    }
+    if err := s.handshake(c, host, tlsConfig); err != nil {
+        c.Close()
+        return nil, err
+    }
+    return &client {
+        Client   : c,
+        conn     : conn,
+        timeout  : timeout,
+        lastUsed : time.Now(),
+    }, nil
+}
 
-    header["Content-Type"]              = ct
-    header["Content-Transfer-Encoding"] = "base64"
-
-    message := ""
-    for k, v := range header {
-        message += fmt.Sprintf("%s: %s\r\n", k, v)
+// handshake switches the connection to TLS using STARTTLS and authenticates if necessary.
+func (s *SMTP) handshake(c *smtp.Client, host string, tlsConfig *tls.Config) error {
+    if s.TLSMode == TLS_AUTO || s.TLSMode == TLS_STARTTLS {
+        if ok, _ := c.Extension("STARTTLS"); ok {
+            if err := c.StartTLS(tlsConfig); err != nil {
+                return err
+            }
+        } else if s.TLSMode == TLS_STARTTLS {
+            return errors.New("server does not support STARTTLS")
+        }
     }
-    message += "\r\n" + b64.EncodeToString([]byte(body))
+    if s.Username != "" {
+        if ok, _ := c.Extension("AUTH"); ok {
+            if err := c.Auth(smtp.PlainAuth("", s.Username, s.Password, host)); err != nil {
+                return err
+            }
+        }
+    }
+    return nil
+}
 
-    auth := smtp.PlainAuth("", s.Username, s.Password, hp[0])
-    return smtp.SendMail(s.Address, auth, from, strings.Split(tos, ";"), []byte(message))
-}
\ No newline at end of file
+// send sends one message in a mail transaction.
+func (c *client) send(m *Message) error {
+    data, err := m.Bytes()
+    if err != nil {
+        return err
+    }
+    c.conn.SetDeadline(time.Now().Add(c.timeout))
+    defer func() {
+        c.lastUsed = time.Now()
+    }()
+    if err := c.Mail(m.envelopeFrom()); err != nil {
+        return err
+    }
+    for _, rcpt := range m.Recipients() {
+        if err := c.Rcpt(rcpt); err != nil {
+            return err
+        }
+    }
+    w, err := c.Data()
+    if err != nil {
+        return err
+    }
+    if _, err := w.Write(data); err != nil {
+        w.Close()
+        return err
+    }
+    return w.Close()
+}
@@ -0,0 +1,467 @@
+// Copyright 2019 gf Author(https://github.com/gogf/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package gsmtp
+
+import (
+    "bytes"
+    "crypto/rand"
+    "encoding/base64"
+    "encoding/hex"
+    "errors"
+    "fmt"
+    "github.com/gogf/gf/g/os/gfile"
+    "github.com/gogf/gf/g/os/gview"
+    "io"
+    "io/ioutil"
+    "mime"
+    "mime/multipart"
+    "mime/quotedprintable"
+    "net/mail"
+    "net/textproto"
+    "strings"
+    "time"
+)
+
+// Message is a mail message builder,
+// which composes multipart MIME content with text/HTML bodies, attachments and inline files.
+type Message struct {
+    from        string
+    to          []string
+    cc          []string
+    bcc         []string
+    replyTo     []string
+    subject     string
+    text        string
+    html        string
+    headers     []messageHeader
+    attachments []*messageFile
+    inlines     []*messageFile
+    err         error // The first error occurred in building, which is returned by Bytes.
+}
+
+// messageHeader is a custom header of the message.
+type messageHeader struct {
+    key   string
+    value string
+}
+
+// messageFile is an attachment or an inline file of the message.
+type messageFile struct {
+    name        string
+    data        []byte
+    contentType string
+}
+
+// Maximum line length of base64 encoded content.
+const gBASE64_LINE_LENGTH = 76
+
+// NewMessage creates and returns a new message.
+func NewMessage() *Message {
+    return &Message{}
+}
+
+// From sets the sender address, eg: "notify@a.com" or "Notify <notify@a.com>".
+func (m *Message) From(address string) *Message {
+    m.from = address
+    return m
+}
+
+// To adds recipient addresses.
+func (m *Message) To(addresses...string) *Message {
+    m.to = append(m.to, addresses...)
+    return m
+}
+
+// Cc adds carbon copy recipient addresses.
+func (m *Message) Cc(addresses...string) *Message {
+    m.cc = append(m.cc, addresses...)
+    return m
+}
+
+// Bcc adds blind carbon copy recipient addresses,
+// which receive the message but do not appear in the headers.
+func (m *Message) Bcc(addresses...string) *Message {
+    m.bcc = append(m.bcc, addresses...)
+    return m
+}
+
+// ReplyTo adds reply addresses.
+func (m *Message) ReplyTo(addresses...string) *Message {
+    m.replyTo = append(m.replyTo, addresses...)
+    return m
+}
+
+// Subject sets the subject.
+func (m *Message) Subject(subject string) *Message {
+    m.subject = subject
+    return m
+}
+
+// Text sets the plain text body.
+// The message is composed as multipart/alternative if both the text and HTML bodies are set.
+func (m *Message) Text(body string) *Message {
+    m.text = body
+    return m
+}
+
+// Html sets the HTML body, inline files can be referenced using "cid:name" in the HTML body.
+func (m *Message) Html(body string) *Message {
+    m.html = body
+    return m
+}
+
+// TextTemplate sets the plain text body by parsing template <file> with <params>.
+// The optional parameter <view> specifies the view object, which is gview.Instance() in default.
+func (m *Message) TextTemplate(file string, params gview.Params, view...*gview.View) *Message {
+    if body, err := parseTemplate(file, params, view...); err != nil {
+        m.setError(err)
+    } else {
+        m.text = body
+    }
+    return m
+}
+
+// HtmlTemplate sets the HTML body by parsing template <file> with <params>.
+// The optional parameter <view> specifies the view object, which is gview.Instance() in default.
+func (m *Message) HtmlTemplate(file string, params gview.Params, view...*gview.View) *Message {
+    if body, err := parseTemplate(file, params, view...); err != nil {
+        m.setError(err)
+    } else {
+        m.html = body
+    }
+    return m
+}
+
+// Header adds a custom header, eg: X-Priority.
+func (m *Message) Header(key, value string) *Message {
+    m.headers = append(m.headers, messageHeader{textproto.CanonicalMIMEHeaderKey(key), value})
+    return m
+}
+
+// Attach adds an attachment with file name <name> and content <data>.
+// The optional parameter <contentType> is detected from the file extension in default.
+func (m *Message) Attach(name string, data []byte, contentType...string) *Message {
+    m.attachments = append(m.attachments, newMessageFile(name, data, contentType...))
+    return m
+}
+
+// AttachFile adds the file of <path> as an attachment.
+func (m *Message) AttachFile(path string) *Message {
+    if data, err := ioutil.ReadFile(path); err != nil {
+        m.setError(err)
+    } else {
+        m.Attach(gfile.Basename(path), data)
+    }
+    return m
+}
+
+// Embed adds an inline file with content <data>,
+// which can be referenced using "cid:<name>" in the HTML body, eg: <img src="cid:logo.png">.
+// If <name> contains characters other than letters, digits, '.', '_' and '-', use ContentId
+// to get the content id for referencing, eg: "my logo.png" is referenced by "cid:my_logo.png".
+func (m *Message) Embed(name string, data []byte, contentType...string) *Message {
+    m.inlines = append(m.inlines, newMessageFile(name, data, contentType...))
+    return m
+}
+
+// EmbedFile adds the file of <path> as an inline file,
+// which can be referenced using "cid:" with its base name in the HTML body.
+func (m *Message) EmbedFile(path string) *Message {
+    if data, err := ioutil.ReadFile(path); err != nil {
+        m.setError(err)
+    } else {
+        m.Embed(gfile.Basename(path), data)
+    }
+    return m
+}
+
+// Recipients returns all the distinct recipient addresses of To, Cc and Bcc.
+func (m *Message) Recipients() []string {
+    recipients := make([]string, 0, len(m.to) + len(m.cc) + len(m.bcc))
+    exists     := make(map[string]struct{})
+    for _, list := range [][]string{m.to, m.cc, m.bcc} {
+        for _, v := range list {
+            address := parseAddress(v).Address
+            if _, ok := exists[address]; !ok {
+                exists[address] = struct{}{}
+                recipients = append(recipients, address)
+            }
+        }
+    }
+    return recipients
+}
+
+// Bytes composes and returns the MIME content of the message.
+func (m *Message) Bytes() ([]byte, error) {
+    if m.err != nil {
+        return nil, m.err
+    }
+    if m.from == "" {
+        return nil, errors.New("from address is necessary")
+    }
+    if len(m.to) + len(m.cc) + len(m.bcc) == 0 {
+        return nil, errors.New("recipient address is necessary")
+    }
+    for _, list := range [][]string{{m.from}, m.to, m.cc, m.bcc, m.replyTo} {
+        for _, v := range list {
+            if _, err := mail.ParseAddress(v); err != nil {
+                return nil, fmt.Errorf(`invalid address "%s": %s`, v, err.Error())
+            }
+        }
+    }
+    buffer := bytes.NewBuffer(nil)
+    writeHeader(buffer, "From", formatAddresses([]string{m.from}))
+    if len(m.to) > 0 {
+        writeHeader(buffer, "To", formatAddresses(m.to))
+    }
+    if len(m.cc) > 0 {
+        writeHeader(buffer, "Cc", formatAddresses(m.cc))
+    }
+    if len(m.replyTo) > 0 {
+        writeHeader(buffer, "Reply-To", formatAddresses(m.replyTo))
+    }
+    writeHeader(buffer, "Subject", mime.BEncoding.Encode("UTF-8", m.subject))
+    writeHeader(buffer, "Date", time.Now().Format(time.RFC1123Z))
+    writeHeader(buffer, "Message-Id", m.messageId())
+    writeHeader(buffer, "MIME-Version", "1.0")
+    for _, h := range m.headers {
+        writeHeader(buffer, h.key, mime.QEncoding.Encode("UTF-8", h.value))
+    }
+    root := m.build()
+    for _, key := range []string{"Content-Type", "Content-Transfer-Encoding"} {
+        if value := root.header.Get(key); value != "" {
+            writeHeader(buffer, key, value)
+        }
+    }
+    buffer.WriteString("\r\n")
+    if err := root.writeBody(buffer); err != nil {
+        return nil, err
+    }
+    return buffer.Bytes(), nil
+}
+
+// build creates the MIME part tree of the message:
+// multipart/mixed(multipart/related(multipart/alternative(text, html), inlines...), attachments...).
+func (m *Message) build() *mimePart {
+    var body *mimePart
+    switch {
+        case m.text != "" && m.html != "":
+            body = newMultipart("alternative", newTextPart("text/plain", m.text), newTextPart("text/html", m.html))
+        case m.html != "":
+            body = newTextPart("text/html", m.html)
+        default:
+            body = newTextPart("text/plain", m.text)
+    }
+    if len(m.inlines) > 0 {
+        parts := []*mimePart{body}
+        for _, f := range m.inlines {
+            parts = append(parts, newFilePart(f, true))
+        }
+        body = newMultipart("related", parts...)
+    }
+    if len(m.attachments) > 0 {
+        parts := []*mimePart{body}
+        for _, f := range m.attachments {
+            parts = append(parts, newFilePart(f, false))
+        }
+        body = newMultipart("mixed", parts...)
+    }
+    return body
+}
+
+// envelopeFrom returns the sender address used in the SMTP MAIL command.
+func (m *Message) envelopeFrom() string {
+    return parseAddress(m.from).Address
+}
+
+// messageId generates a unique message id using the domain of the sender address.
+func (m *Message) messageId() string {
+    domain  := "localhost"
+    address := parseAddress(m.from).Address
+    if index := strings.LastIndexByte(address, '@'); index != -1 {
+        domain = address[index + 1 : ]
+    }
+    return fmt.Sprintf("<%d.%s@%s>", time.Now().UnixNano(), randomHex(8), domain)
+}
+
+// setError records the first error occurred in building.
+func (m *Message) setError(err error) {
+    if m.err == nil {
+        m.err = err
+    }
+}
+
+// mimePart is a part of the MIME content, which is a leaf with encoded body or a multipart with children.
+type mimePart struct {
+    header   textproto.MIMEHeader
+    body     []byte
+    boundary string
+    children []*mimePart
+}
+
+// newTextPart creates a quoted-printable encoded text part.
+func newTextPart(contentType string, content string) *mimePart {
+    buffer := bytes.NewBuffer(nil)
+    writer := quotedprintable.NewWriter(buffer)
+    writer.Write([]byte(content))
+    writer.Close()
+    return &mimePart {
+        header : textproto.MIMEHeader {
+            "Content-Type"              : {contentType + "; charset=UTF-8"},
+            "Content-Transfer-Encoding" : {"quoted-printable"},
+        },
+        body   : buffer.Bytes(),
+    }
+}
+
+// newFilePart creates a base64 encoded attachment or inline file part.
+func newFilePart(f *messageFile, inline bool) *mimePart {
+    disposition := "attachment"
+    if inline {
+        disposition = "inline"
+    }
+    header := textproto.MIMEHeader {
+        "Content-Type"              : {fileContentType(f.contentType, f.name)},
+        "Content-Disposition"       : {mime.FormatMediaType(disposition, map[string]string{"filename" : f.name})},
+        "Content-Transfer-Encoding" : {"base64"},
+    }
+    if inline {
+        header.Set("Content-Id", "<" + ContentId(f.name) + ">")
+    }
+    return &mimePart {
+        header : header,
+        body   : encodeBase64Lines(f.data),
+    }
+}
+
+// fileContentType returns the Content-Type header value of file <name>,
+// the parameters of <contentType> like charset are kept.
+func fileContentType(contentType string, name string) string {
+    mediaType, params, err := mime.ParseMediaType(contentType)
+    if err != nil {
+        mediaType, params = "application/octet-stream", nil
+    }
+    if params == nil {
+        params = make(map[string]string)
+    }
+    params["name"] = name
+    return mime.FormatMediaType(mediaType, params)
+}
+
+// ContentId returns the content id of inline file <name>, which is used for referencing
+// the file by "cid:" in the HTML body. The characters of <name> other than letters, digits,
+// '.', '_' and '-' are replaced by '_', so it's a valid content id and needs no escaping in URL.
+func ContentId(name string) string {
+    id := []rune(name)
+    for i, r := range id {
+        if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '.' || r == '_' || r == '-') {
+            id[i] = '_'
+        }
+    }
+    if len(id) == 0 {
+        return "_"
+    }
+    return string(id)
+}
+
+// newMultipart creates a multipart part of <subtype> with <children>.
+func newMultipart(subtype string, children...*mimePart) *mimePart {
+    boundary := randomHex(16)
+    return &mimePart {
+        header   : textproto.MIMEHeader {
+            "Content-Type" : {fmt.Sprintf(`multipart/%s; boundary="%s"`, subtype, boundary)},
+        },
+        boundary : boundary,
+        children : children,
+    }
+}
+
+// writeBody writes the body of the part, the children are written recursively for multipart.
+func (p *mimePart) writeBody(w io.Writer) error {
+    if len(p.children) == 0 {
+        _, err := w.Write(p.body)
+        return err
+    }
+    writer := multipart.NewWriter(w)
+    if err := writer.SetBoundary(p.boundary); err != nil {
+        return err
+    }
+    for _, child := range p.children {
+        pw, err := writer.CreatePart(child.header)
+        if err != nil {
+            return err
+        }
+        if err := child.writeBody(pw); err != nil {
+            return err
+        }
+    }
+    return writer.Close()
+}
+
+// newMessageFile creates a file of the message, the content type is detected from the extension of <name> if not given.
+func newMessageFile(name string, data []byte, contentType...string) *messageFile {
+    f := &messageFile {
+        name : name,
+        data : data,
+    }
+    if len(contentType) > 0 && contentType[0] != "" {
+        f.contentType = contentType[0]
+    } else if f.contentType = mime.TypeByExtension(gfile.Ext(name)); f.contentType == "" {
+        f.contentType = "application/octet-stream"
+    }
+    return f
+}
+
+// parseTemplate parses template <file> using <view> or the default view object.
+func parseTemplate(file string, params gview.Params, view...*gview.View) (string, error) {
+    v := gview.Instance()
+    if len(view) > 0 && view[0] != nil {
+        v = view[0]
+    }
+    return v.Parse(file, params)
+}
+
+// parseAddress parses <address>, it returns the address as it is if parsing fails.
+func parseAddress(address string) *mail.Address {
+    if a, err := mail.ParseAddress(address); err == nil {
+        return a
+    }
+    return &mail.Address{Address : address}
+}
+
+// formatAddresses formats <addresses> for the header, the names are encoded if necessary.
+func formatAddresses(addresses []string) string {
+    array := make([]string, len(addresses))
+    for i, v := range addresses {
+        array[i] = parseAddress(v).String()
+    }
+    return strings.Join(array, ", ")
+}
+
+// writeHeader writes a header line to <buffer>.
+func writeHeader(buffer *bytes.Buffer, key, value string) {
+    buffer.WriteString(key + ": " + value + "\r\n")
+}
+
+// encodeBase64Lines encodes <data> using base64 with line length limit.
+func encodeBase64Lines(data []byte) []byte {
+    encoded := base64.StdEncoding.EncodeToString(data)
+    buffer  := bytes.NewBuffer(make([]byte, 0, len(encoded) + len(encoded)/gBASE64_LINE_LENGTH*2 + 2))
+    for len(encoded) > gBASE64_LINE_LENGTH {
+        buffer.WriteString(encoded[ : gBASE64_LINE_LENGTH] + "\r\n")
+        encoded = encoded[gBASE64_LINE_LENGTH : ]
+    }
+    buffer.WriteString(encoded)
+    return buffer.Bytes()
+}
+
+// randomHex returns a random hex string of <size> bytes.
+func randomHex(size int) string {
+    b := make([]byte, size)
+    rand.Read(b)
+    return hex.EncodeToString(b)
+}
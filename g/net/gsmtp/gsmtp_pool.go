@@ -0,0 +1,151 @@
+// Copyright 2019 gf Author(https://github.com/gogf/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package gsmtp
+
+import (
+    "errors"
+    "sync"
+    "time"
+)
+
+// Pool is a pooled sender for bulk mail, which reuses the connections to the server.
+// It is concurrent safe, and the number of connections is limited to the pool size.
+type Pool struct {
+    mu          sync.Mutex
+    smtp        *SMTP
+    idle        []*client     // Idle connections, the last one is the most recently used.
+    sem         chan struct{} // Limits the number of connections in use.
+    idleTimeout time.Duration // Idle connections exceeding this duration are closed instead of reused.
+    closed      bool
+}
+
+const (
+    // Default idle timeout of the pooled connections,
+    // which should be less than the server side timeout.
+    gDEFAULT_POOL_IDLE_TIMEOUT = 30*time.Second
+)
+
+var (
+    // ErrPoolClosed is returned when sending using a closed pool.
+    ErrPoolClosed = errors.New("smtp pool closed")
+)
+
+// newPool creates and returns a pool of at most <size> connections.
+func newPool(s *SMTP, size int) *Pool {
+    if size <= 0 {
+        size = 1
+    }
+    return &Pool {
+        smtp        : s,
+        sem         : make(chan struct{}, size),
+        idleTimeout : gDEFAULT_POOL_IDLE_TIMEOUT,
+    }
+}
+
+// SetIdleTimeout sets the idle timeout of the pooled connections.
+func (p *Pool) SetIdleTimeout(timeout time.Duration) {
+    p.mu.Lock()
+    p.idleTimeout = timeout
+    p.mu.Unlock()
+}
+
+// Send sends the messages <msgs> using a pooled connection,
+// it blocks if all the connections are in use.
+// It stops and returns the error if any message fails sending.
+func (p *Pool) Send(msgs...*Message) error {
+    p.sem <- struct{}{}
+    defer func() {
+        <-p.sem
+    }()
+    c, err := p.get()
+    if err != nil {
+        return err
+    }
+    for _, m := range msgs {
+        if err = c.send(m); err != nil {
+            break
+        }
+    }
+    // The connection is reusable if the failed transaction can be reset.
+    if err != nil && c.Reset() != nil {
+        c.Close()
+        return err
+    }
+    p.put(c)
+    return err
+}
+
+// Size returns the number of idle connections.
+func (p *Pool) Size() int {
+    p.mu.Lock()
+    defer p.mu.Unlock()
+    return len(p.idle)
+}
+
+// Close closes the idle connections and the pool,
+// the connections in use are closed after their sending.
+func (p *Pool) Close() error {
+    p.mu.Lock()
+    idle := p.idle
+    p.idle   = nil
+    p.closed = true
+    p.mu.Unlock()
+    for _, c := range idle {
+        c.quit()
+    }
+    return nil
+}
+
+// get retrieves an available idle connection, or creates a new one if there's none.
+func (p *Pool) get() (*client, error) {
+    for {
+        p.mu.Lock()
+        if p.closed {
+            p.mu.Unlock()
+            return nil, ErrPoolClosed
+        }
+        if len(p.idle) == 0 {
+            p.mu.Unlock()
+            return p.smtp.dial()
+        }
+        c := p.idle[len(p.idle) - 1]
+        p.idle = p.idle[ : len(p.idle) - 1]
+        idleTimeout := p.idleTimeout
+        p.mu.Unlock()
+        if time.Since(c.lastUsed) > idleTimeout {
+            c.quit()
+            continue
+        }
+        // Checks whether the connection is still alive.
+        c.conn.SetDeadline(time.Now().Add(c.timeout))
+        if err := c.Noop(); err != nil {
+            c.Close()
+            continue
+        }
+        return c, nil
+    }
+}
+
+// put puts the connection back to the pool, or closes it if the pool is closed.
+func (p *Pool) put(c *client) {
+    p.mu.Lock()
+    if !p.closed {
+        p.idle = append(p.idle, c)
+        p.mu.Unlock()
+        return
+    }
+    p.mu.Unlock()
+    c.quit()
+}
+
+// quit sends the QUIT command and closes the connection.
+func (c *client) quit() {
+    c.conn.SetDeadline(time.Now().Add(c.timeout))
+    if c.Quit() != nil {
+        c.Close()
+    }
+}
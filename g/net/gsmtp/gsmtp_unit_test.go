@@ -0,0 +1,487 @@
+// Copyright 2019 gf Author(https://github.com/gogf/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package gsmtp_test
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"github.com/gogf/gf/g/net/gsmtp"
+	"github.com/gogf/gf/g/os/gview"
+	"github.com/gogf/gf/g/test/gtest"
+	"io"
+	"io/ioutil"
+	"math/big"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// stubMail is a mail received by the stub server.
+type stubMail struct {
+	from  string
+	rcpts []string
+	data  []byte
+	tls   bool
+	user  string
+}
+
+// stubServer is a minimal SMTP server for testing.
+type stubServer struct {
+	listener  net.Listener
+	tlsConfig *tls.Config
+	startTLS  bool // Whether advertising STARTTLS.
+	auth      bool // Whether advertising AUTH PLAIN.
+	implicit  bool // Whether the listener is a TLS listener.
+	mu        sync.Mutex
+	mails     []*stubMail
+	conns     int
+}
+
+// newStubServer starts a stub server listening on a random local port.
+func newStubServer(startTLS, auth, implicit bool) *stubServer {
+	s := &stubServer{
+		tlsConfig: newTLSConfig(),
+		startTLS:  startTLS,
+		auth:      auth,
+		implicit:  implicit,
+	}
+	if implicit {
+		s.listener, _ = tls.Listen("tcp", "127.0.0.1:0", s.tlsConfig)
+	} else {
+		s.listener, _ = net.Listen("tcp", "127.0.0.1:0")
+	}
+	go func() {
+		for {
+			conn, err := s.listener.Accept()
+			if err != nil {
+				return
+			}
+			s.mu.Lock()
+			s.conns++
+			s.mu.Unlock()
+			go s.handle(conn)
+		}
+	}()
+	return s
+}
+
+func (s *stubServer) Address() string {
+	return s.listener.Addr().String()
+}
+
+func (s *stubServer) Close() {
+	s.listener.Close()
+}
+
+func (s *stubServer) Mails() []*stubMail {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]*stubMail(nil), s.mails...)
+}
+
+func (s *stubServer) Conns() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.conns
+}
+
+func (s *stubServer) handle(conn net.Conn) {
+	defer conn.Close()
+	var (
+		tp    = textproto.NewConn(conn)
+		isTLS = s.implicit
+		user  = ""
+		mail  = &stubMail{}
+	)
+	tp.PrintfLine("220 stub ESMTP")
+	for {
+		line, err := tp.ReadLine()
+		if err != nil {
+			return
+		}
+		command := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+		argument := strings.TrimSpace(strings.TrimPrefix(line, strings.SplitN(line, " ", 2)[0]))
+		switch command {
+		case "EHLO", "HELO":
+			lines := []string{"stub"}
+			if s.startTLS && !isTLS {
+				lines = append(lines, "STARTTLS")
+			}
+			if s.auth {
+				lines = append(lines, "AUTH PLAIN")
+			}
+			for i, v := range lines {
+				if i < len(lines)-1 {
+					tp.PrintfLine("250-%s", v)
+				} else {
+					tp.PrintfLine("250 %s", v)
+				}
+			}
+		case "STARTTLS":
+			tp.PrintfLine("220 ready")
+			tlsConn := tls.Server(conn, s.tlsConfig)
+			if err := tlsConn.Handshake(); err != nil {
+				return
+			}
+			conn, tp, isTLS = tlsConn, textproto.NewConn(tlsConn), true
+		case "AUTH":
+			array := strings.Fields(argument)
+			decoded, _ := base64.StdEncoding.DecodeString(array[len(array)-1])
+			if string(decoded) == "\x00user\x00pass" {
+				user = "user"
+				tp.PrintfLine("235 ok")
+			} else {
+				tp.PrintfLine("535 authentication failed")
+			}
+		case "MAIL":
+			mail = &stubMail{from: parseStubAddress(argument), tls: isTLS, user: user}
+			tp.PrintfLine("250 ok")
+		case "RCPT":
+			address := parseStubAddress(argument)
+			if strings.Contains(address, "reject") {
+				tp.PrintfLine("550 rejected")
+			} else {
+				mail.rcpts = append(mail.rcpts, address)
+				tp.PrintfLine("250 ok")
+			}
+		case "DATA":
+			tp.PrintfLine("354 go ahead")
+			if mail.data, err = tp.ReadDotBytes(); err != nil {
+				return
+			}
+			s.mu.Lock()
+			s.mails = append(s.mails, mail)
+			s.mu.Unlock()
+			tp.PrintfLine("250 ok")
+		case "RSET":
+			mail = &stubMail{}
+			tp.PrintfLine("250 ok")
+		case "NOOP":
+			tp.PrintfLine("250 ok")
+		case "QUIT":
+			tp.PrintfLine("221 bye")
+			return
+		default:
+			tp.PrintfLine("502 unknown command")
+		}
+	}
+}
+
+// parseStubAddress parses the address from argument like "FROM:<a@b.com>".
+func parseStubAddress(argument string) string {
+	start := strings.IndexByte(argument, '<')
+	end := strings.IndexByte(argument, '>')
+	if start == -1 || end < start {
+		return ""
+	}
+	return argument[start+1 : end]
+}
+
+var (
+	certOnce   sync.Once
+	certPool   *x509.CertPool
+	serverCert tls.Certificate
+)
+
+// newTLSConfig returns a server TLS config using a self-signed certificate for 127.0.0.1.
+func newTLSConfig() *tls.Config {
+	certOnce.Do(func() {
+		key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		template := &x509.Certificate{
+			SerialNumber:          big.NewInt(1),
+			Subject:               pkix.Name{CommonName: "127.0.0.1"},
+			NotBefore:             time.Now().Add(-time.Hour),
+			NotAfter:              time.Now().Add(time.Hour),
+			IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+			KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+			ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+			BasicConstraintsValid: true,
+			IsCA:                  true,
+		}
+		der, _ := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+		cert, _ := x509.ParseCertificate(der)
+		certPool = x509.NewCertPool()
+		certPool.AddCert(cert)
+		serverCert = tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+	})
+	return &tls.Config{Certificates: []tls.Certificate{serverCert}}
+}
+
+// newClient returns a SMTP client trusting the certificate of the stub server.
+func newClient(address string, mode int) *gsmtp.SMTP {
+	s := gsmtp.New(address, "", "")
+	s.TLSMode = mode
+	s.TLSConfig = &tls.Config{RootCAs: certPool}
+	s.Timeout = 5 * time.Second
+	return s
+}
+
+// readParts reads all parts of multipart <body> with <contentType>.
+func readParts(contentType string, body io.Reader) ([]*multipart.Part, [][]byte) {
+	_, params, _ := mime.ParseMediaType(contentType)
+	reader := multipart.NewReader(body, params["boundary"])
+	parts := make([]*multipart.Part, 0)
+	contents := make([][]byte, 0)
+	for {
+		part, err := reader.NextPart()
+		if err != nil {
+			break
+		}
+		content, _ := ioutil.ReadAll(part)
+		parts = append(parts, part)
+		contents = append(contents, content)
+	}
+	return parts, contents
+}
+
+func Test_Message_Bytes(t *testing.T) {
+	gtest.Case(t, func() {
+		m := gsmtp.NewMessage().
+			From("Notify 通知 <notify@a.com>").
+			To("john@b.com", "Rain <rain@c.com>").
+			Cc("cc@b.com").
+			Bcc("bcc@b.com", "john@b.com").
+			ReplyTo("reply@a.com").
+			Subject("标题 subject").
+			Text("hello text").
+			Html(`<p>hello html</p><img src="cid:logo.png">`).
+			Header("x-priority", "1").
+			Embed("logo.png", []byte("png-data")).
+			Attach("report.txt", bytes.Repeat([]byte("0123456789"), 100))
+		gtest.Assert(m.Recipients(), []string{"john@b.com", "rain@c.com", "cc@b.com", "bcc@b.com"})
+		data, err := m.Bytes()
+		gtest.Assert(err, nil)
+
+		msg, err := mail.ReadMessage(bytes.NewReader(data))
+		gtest.Assert(err, nil)
+		decoder := new(mime.WordDecoder)
+		subject, _ := decoder.DecodeHeader(msg.Header.Get("Subject"))
+		gtest.Assert(subject, "标题 subject")
+		from, _ := msg.Header.AddressList("From")
+		gtest.Assert(from[0].Name, "Notify 通知")
+		gtest.Assert(from[0].Address, "notify@a.com")
+		to, _ := msg.Header.AddressList("To")
+		gtest.Assert(len(to), 2)
+		gtest.Assert(msg.Header.Get("Cc"), "<cc@b.com>")
+		gtest.Assert(msg.Header.Get("Bcc"), "")
+		gtest.Assert(msg.Header.Get("Reply-To"), "<reply@a.com>")
+		gtest.Assert(msg.Header.Get("X-Priority"), "1")
+		gtest.Assert(msg.Header.Get("MIME-Version"), "1.0")
+		gtest.Assert(strings.HasSuffix(msg.Header.Get("Message-Id"), "@a.com>"), true)
+		gtest.AssertNE(msg.Header.Get("Date"), "")
+
+		// multipart/mixed(multipart/related(multipart/alternative(text, html), inline), attachment)
+		contentType := msg.Header.Get("Content-Type")
+		gtest.Assert(strings.HasPrefix(contentType, "multipart/mixed"), true)
+		mixed, mixedContents := readParts(contentType, msg.Body)
+		gtest.Assert(len(mixed), 2)
+		gtest.Assert(strings.HasPrefix(mixed[1].Header.Get("Content-Disposition"), "attachment"), true)
+		gtest.Assert(mixed[1].FileName(), "report.txt")
+		// The parameters of detected content type are kept.
+		mediaType, params, err := mime.ParseMediaType(mixed[1].Header.Get("Content-Type"))
+		gtest.Assert(err, nil)
+		gtest.Assert(mediaType, "text/plain")
+		gtest.Assert(params, map[string]string{"charset": "utf-8", "name": "report.txt"})
+		attachment, _ := base64.StdEncoding.DecodeString(strings.Replace(string(mixedContents[1]), "\r\n", "", -1))
+		gtest.Assert(string(attachment), strings.Repeat("0123456789", 100))
+
+		related, relatedContents := readParts(mixed[0].Header.Get("Content-Type"), bytes.NewReader(mixedContents[0]))
+		gtest.Assert(len(related), 2)
+		gtest.Assert(related[1].Header.Get("Content-Id"), "<logo.png>")
+		gtest.Assert(strings.HasPrefix(related[1].Header.Get("Content-Type"), "image/png"), true)
+		inline, _ := base64.StdEncoding.DecodeString(string(relatedContents[1]))
+		gtest.Assert(string(inline), "png-data")
+
+		alternative, alternativeContents := readParts(related[0].Header.Get("Content-Type"), bytes.NewReader(relatedContents[0]))
+		gtest.Assert(len(alternative), 2)
+		gtest.Assert(alternative[0].Header.Get("Content-Type"), "text/plain; charset=UTF-8")
+		gtest.Assert(string(alternativeContents[0]), "hello text")
+		gtest.Assert(alternative[1].Header.Get("Content-Type"), "text/html; charset=UTF-8")
+		gtest.Assert(string(alternativeContents[1]), `<p>hello html</p><img src="cid:logo.png">`)
+	})
+	// Inline file with special characters in name.
+	gtest.Case(t, func() {
+		data, err := gsmtp.NewMessage().From("a@a.com").To("b@b.com").
+			Html(`<img src="cid:`+gsmtp.ContentId("my 图标.png")+`">`).
+			Embed("my 图标.png", []byte("png-data")).
+			Bytes()
+		gtest.Assert(err, nil)
+		msg, _ := mail.ReadMessage(bytes.NewReader(data))
+		related, _ := readParts(msg.Header.Get("Content-Type"), msg.Body)
+		gtest.Assert(len(related), 2)
+		gtest.Assert(related[1].Header.Get("Content-Id"), "<my___.png>")
+		gtest.Assert(related[1].FileName(), "my 图标.png")
+		mediaType, params, err := mime.ParseMediaType(related[1].Header.Get("Content-Type"))
+		gtest.Assert(err, nil)
+		gtest.Assert(mediaType, "image/png")
+		gtest.Assert(params["name"], "my 图标.png")
+
+		gtest.Assert(gsmtp.ContentId("logo.png"), "logo.png")
+		gtest.Assert(gsmtp.ContentId("<a@b>"), "_a_b_")
+		gtest.Assert(gsmtp.ContentId(""), "_")
+	})
+	// Single part message.
+	gtest.Case(t, func() {
+		data, err := gsmtp.NewMessage().From("a@a.com").To("b@b.com").Html("<b>中文</b>").Bytes()
+		gtest.Assert(err, nil)
+		msg, _ := mail.ReadMessage(bytes.NewReader(data))
+		gtest.Assert(msg.Header.Get("Content-Type"), "text/html; charset=UTF-8")
+		gtest.Assert(msg.Header.Get("Content-Transfer-Encoding"), "quoted-printable")
+	})
+	gtest.Case(t, func() {
+		_, err := gsmtp.NewMessage().To("b@b.com").Bytes()
+		gtest.AssertNE(err, nil)
+		_, err = gsmtp.NewMessage().From("a@a.com").Bytes()
+		gtest.AssertNE(err, nil)
+		_, err = gsmtp.NewMessage().From("a@a.com").To("invalid").Bytes()
+		gtest.AssertNE(err, nil)
+		_, err = gsmtp.NewMessage().From("a@a.com").To("b@b.com").AttachFile("/none-exist-file").Bytes()
+		gtest.AssertNE(err, nil)
+	})
+}
+
+func Test_Message_Template(t *testing.T) {
+	gtest.Case(t, func() {
+		dir, _ := ioutil.TempDir("", "gsmtp")
+		defer os.RemoveAll(dir)
+		ioutil.WriteFile(filepath.Join(dir, "mail.html"), []byte("<p>hello {{.name}}</p>"), 0644)
+		ioutil.WriteFile(filepath.Join(dir, "mail.txt"), []byte("hello {{.name}}"), 0644)
+		view := gview.New(dir)
+		params := gview.Params{"name": "john"}
+		data, err := gsmtp.NewMessage().From("a@a.com").To("b@b.com").
+			HtmlTemplate("mail.html", params, view).
+			TextTemplate("mail.txt", params, view).
+			Bytes()
+		gtest.Assert(err, nil)
+		gtest.Assert(strings.Contains(string(data), "<p>hello john</p>"), true)
+		gtest.Assert(strings.Contains(string(data), "\r\n\r\nhello john\r\n"), true)
+
+		_, err = gsmtp.NewMessage().From("a@a.com").To("b@b.com").HtmlTemplate("none.html", params, view).Bytes()
+		gtest.AssertNE(err, nil)
+	})
+}
+
+func Test_SMTP_Send(t *testing.T) {
+	server := newStubServer(false, false, false)
+	defer server.Close()
+	gtest.Case(t, func() {
+		s := newClient(server.Address(), gsmtp.TLS_AUTO)
+		m1 := gsmtp.NewMessage().From("Notify <notify@a.com>").To("john@b.com").Bcc("bcc@b.com").Subject("1").Text("1")
+		m2 := gsmtp.NewMessage().From("notify@a.com").To("rain@b.com").Subject("2").Text(".leading dot")
+		gtest.Assert(s.Send(m1, m2), nil)
+		gtest.Assert(server.Conns(), 1)
+		mails := server.Mails()
+		gtest.Assert(len(mails), 2)
+		gtest.Assert(mails[0].from, "notify@a.com")
+		gtest.Assert(mails[0].rcpts, []string{"john@b.com", "bcc@b.com"})
+		gtest.Assert(mails[0].tls, false)
+		gtest.Assert(strings.Contains(string(mails[0].data), "bcc@b.com"), false)
+		gtest.Assert(mails[1].rcpts, []string{"rain@b.com"})
+		gtest.Assert(strings.Contains(string(mails[1].data), "\n.leading dot"), true)
+
+		gtest.Assert(s.SendMail("notify@a.com", "x@b.com;y@b.com;", "subject", "<b>body</b>", "html"), nil)
+		mails = server.Mails()
+		gtest.Assert(len(mails), 3)
+		gtest.Assert(mails[2].rcpts, []string{"x@b.com", "y@b.com"})
+		gtest.Assert(strings.Contains(string(mails[2].data), "Content-Type: text/html; charset=UTF-8"), true)
+
+		gtest.AssertNE(s.Send(gsmtp.NewMessage().From("notify@a.com").To("reject@b.com")), nil)
+		gtest.AssertNE(s.SendMail("notify@a.com", ";", "subject", "body"), nil)
+	})
+	// STARTTLS is required but not supported.
+	gtest.Case(t, func() {
+		s := newClient(server.Address(), gsmtp.TLS_STARTTLS)
+		gtest.AssertNE(s.Send(gsmtp.NewMessage().From("a@a.com").To("b@b.com")), nil)
+	})
+}
+
+func Test_SMTP_TLS(t *testing.T) {
+	gtest.Case(t, func() {
+		server := newStubServer(true, true, false)
+		defer server.Close()
+		for _, mode := range []int{gsmtp.TLS_AUTO, gsmtp.TLS_STARTTLS} {
+			s := newClient(server.Address(), mode)
+			s.Username, s.Password = "user", "pass"
+			gtest.Assert(s.Send(gsmtp.NewMessage().From("a@a.com").To("b@b.com")), nil)
+		}
+		// Wrong password.
+		s := newClient(server.Address(), gsmtp.TLS_STARTTLS)
+		s.Username, s.Password = "user", "wrong"
+		gtest.AssertNE(s.Send(gsmtp.NewMessage().From("a@a.com").To("b@b.com")), nil)
+		// Plain connection is used without TLS.
+		s = newClient(server.Address(), gsmtp.TLS_NONE)
+		gtest.Assert(s.Send(gsmtp.NewMessage().From("a@a.com").To("b@b.com")), nil)
+
+		mails := server.Mails()
+		gtest.Assert(len(mails), 3)
+		gtest.Assert(mails[0].tls, true)
+		gtest.Assert(mails[0].user, "user")
+		gtest.Assert(mails[1].tls, true)
+		gtest.Assert(mails[2].tls, false)
+	})
+	gtest.Case(t, func() {
+		server := newStubServer(false, true, true)
+		defer server.Close()
+		s := newClient(server.Address(), gsmtp.TLS_IMPLICIT)
+		s.Username, s.Password = "user", "pass"
+		gtest.Assert(s.Send(gsmtp.NewMessage().From("a@a.com").To("b@b.com")), nil)
+		mails := server.Mails()
+		gtest.Assert(len(mails), 1)
+		gtest.Assert(mails[0].tls, true)
+		gtest.Assert(mails[0].user, "user")
+	})
+}
+
+func Test_Pool(t *testing.T) {
+	server := newStubServer(false, false, false)
+	defer server.Close()
+	gtest.Case(t, func() {
+		pool := newClient(server.Address(), gsmtp.TLS_AUTO).NewPool(2)
+		wg := sync.WaitGroup{}
+		for i := 0; i < 20; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				gtest.Assert(pool.Send(gsmtp.NewMessage().From("a@a.com").To("b@b.com").Text("bulk")), nil)
+			}()
+		}
+		wg.Wait()
+		gtest.Assert(len(server.Mails()), 20)
+		gtest.AssertLE(server.Conns(), 2)
+		gtest.AssertGT(pool.Size(), 0)
+
+		// The connection is reused after a failed transaction.
+		conns := server.Conns()
+		gtest.AssertNE(pool.Send(gsmtp.NewMessage().From("a@a.com").To("reject@b.com")), nil)
+		gtest.Assert(pool.Send(gsmtp.NewMessage().From("a@a.com").To("b@b.com")), nil)
+		gtest.Assert(server.Conns(), conns)
+
+		// Idle connections exceeding the idle timeout are not reused.
+		pool.SetIdleTimeout(time.Millisecond)
+		time.Sleep(10 * time.Millisecond)
+		gtest.Assert(pool.Send(gsmtp.NewMessage().From("a@a.com").To("b@b.com")), nil)
+		gtest.Assert(server.Conns(), conns+1)
+
+		gtest.Assert(pool.Close(), nil)
+		gtest.Assert(pool.Size(), 0)
+		gtest.Assert(pool.Send(gsmtp.NewMessage().From("a@a.com").To("b@b.com")), gsmtp.ErrPoolClosed)
+	})
+}
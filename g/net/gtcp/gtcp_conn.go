@@ -10,6 +10,7 @@ import (
 	"bufio"
 	"bytes"
 	"crypto/tls"
+	"github.com/gogf/gf/g/container/gtype"
 	"io"
 	"net"
 	"time"
@@ -19,10 +20,14 @@ import (
 type Conn struct {
     conn           net.Conn      // 底层tcp对象
     reader         *bufio.Reader // 当前链接的缓冲读取对象
-    buffer         []byte        // 读取缓冲区(用于数据读取时的缓冲区处理)
+    buffer         []byte        // 简单协议的读取缓冲区，在多次读取之间复用
+    bufferStart    int           // 读取缓冲区中未处理数据的起始位置
+    bufferEnd      int           // 读取缓冲区中未处理数据的结束位置
     recvDeadline   time.Time     // 读取超时时间
     sendDeadline   time.Time     // 写入超时时间
     recvBufferWait time.Duration // 读取全部缓冲区数据时，读取缓冲区完毕后的等待间隔
+    recvTimeout    time.Duration // 每次读取操作的超时时间(未设置读取超时时间时有效)
+    active         *gtype.Int64  // (纳秒)最近一次读取或者写入数据的时间
 }
 
 const (
@@ -65,6 +70,7 @@ func NewConnByNetConn(conn net.Conn) *Conn {
         recvDeadline   : time.Time{},
         sendDeadline   : time.Time{},
         recvBufferWait : gRECV_ALL_WAIT_TIMEOUT,
+        active         : gtype.NewInt64(time.Now().UnixNano()),
     }
 }
 
@@ -93,6 +99,7 @@ func (c *Conn) Send(data []byte, retry...Retry) error {
                 time.Sleep(time.Duration(retry[0].Interval) * time.Millisecond)
             }
         } else {
+            c.active.Set(time.Now().UnixNano())
             return nil
         }
     }
@@ -116,6 +123,12 @@ func (c *Conn) Recv(length int, retry...Retry) ([]byte, error) {
     } else {
         buffer = make([]byte, gDEFAULT_READ_BUFFER_SIZE)
     }
+    // 没有设置读取超时时间时，使用每次读取操作的超时时间
+    if c.recvTimeout > 0 && c.recvDeadline.IsZero() {
+        if err = c.conn.SetReadDeadline(time.Now().Add(c.recvTimeout)); err != nil {
+            return nil, err
+        }
+    }
 
     for {
         // 缓冲区数据写入等待处理。
@@ -130,6 +143,7 @@ func (c *Conn) Recv(length int, retry...Retry) ([]byte, error) {
         }
         size, err = c.reader.Read(buffer[index:])
         if size > 0 {
+            c.active.Set(time.Now().UnixNano())
             index += size
             if length > 0 {
                 // 如果指定了读取大小，那么必须读取到指定长度才返回
@@ -183,6 +197,34 @@ func (c *Conn) Recv(length int, retry...Retry) ([]byte, error) {
     return buffer[:index], err
 }
 
+// 读取一次数据到p中，返回读取的长度，失败时按照重试策略重试
+func (c *Conn) read(p []byte, retry...Retry) (int, error) {
+    // 没有设置读取超时时间时，使用每次读取操作的超时时间
+    if c.recvTimeout > 0 && c.recvDeadline.IsZero() {
+        if err := c.conn.SetReadDeadline(time.Now().Add(c.recvTimeout)); err != nil {
+            return 0, err
+        }
+    }
+    for {
+        n, err := c.reader.Read(p)
+        if n > 0 {
+            c.active.Set(time.Now().UnixNano())
+            return n, nil
+        }
+        if err == nil {
+            continue
+        }
+        if err == io.EOF || len(retry) == 0 || retry[0].Count == 0 {
+            return 0, err
+        }
+        retry[0].Count--
+        if retry[0].Interval == 0 {
+            retry[0].Interval = gDEFAULT_RETRY_INTERVAL
+        }
+        time.Sleep(time.Duration(retry[0].Interval) * time.Millisecond)
+    }
+}
+
 // 按行读取数据，阻塞读取，直到完成一行读取位置(末尾以'\n'结尾，返回数据不包含换行符)
 func (c *Conn) RecvLine(retry...Retry) ([]byte, error) {
     var err    error
@@ -267,6 +309,16 @@ func (c *Conn) SetSendDeadline(t time.Time) error {
     return err
 }
 
+// 设置每次读取操作的超时时间，在没有通过SetDeadline/SetRecvDeadline设置读取超时时间时有效，为0表示不超时。
+func (c *Conn) SetRecvTimeout(timeout time.Duration) {
+    c.recvTimeout = timeout
+}
+
+// 获取最近一次读取或者写入数据的时间
+func (c *Conn) LastActive() time.Time {
+    return time.Unix(0, c.active.Val())
+}
+
 // 读取全部缓冲区数据时，读取完毕后的写入等待间隔，如果超过该等待时间后仍无可读数据，那么读取操作返回。
 // 该时间间隔不能设置得太大，会影响Recv读取时长(默认为1毫秒)。
 func (c *Conn) SetRecvBufferWait(bufferWaitDuration time.Duration) {
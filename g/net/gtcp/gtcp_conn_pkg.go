@@ -7,9 +7,6 @@
 package gtcp
 
 import (
-	"encoding/binary"
-	"errors"
-	"fmt"
 	"time"
 )
 
@@ -18,12 +15,15 @@ const (
 	gPKG_MAX_DATA_SIZE = 65535
 	// 简单协议包头大小
 	gPKG_HEADER_SIZE   = 3
+	// 简单协议读取缓冲区的初始大小(byte)
+	gPKG_BUFFER_SIZE   = 4096
 )
 
 // 数据读取选项
 type PkgOption struct {
-	MaxSize int    // (byte)数据读取的最大包大小，最大不能超过3字节(0xFFFFFF,15MB)，默认为65535byte
+	MaxSize int    // (byte)数据读取的最大包大小，默认为65535byte，同时受编解码长度头部能表示的最大长度限制，默认编解码为3字节(0xFFFFFF,15MB)
 	Retry   Retry  // 失败重试
+	Codec   Codec  // 数据包编解码，默认为3字节大端长度头部，参考NewLengthCodec/NewVarintCodec/NewDelimiterCodec/NewChecksumCodec
 }
 
 // getPkgOption wraps and returns the PkgOption.
@@ -35,36 +35,33 @@ func getPkgOption(option...PkgOption) (*PkgOption, error) {
 	}
 	if pkgOption.MaxSize == 0 {
 		pkgOption.MaxSize = gPKG_MAX_DATA_SIZE
-	} else if pkgOption.MaxSize > 0xFFFFFF {
-		return nil, fmt.Errorf(`package size %d exceeds allowed max size %d`, pkgOption.MaxSize, 0xFFFFFF)
+	}
+	if pkgOption.Codec == nil {
+		pkgOption.Codec = defaultCodec
 	}
 	return &pkgOption, nil
 }
 
-// 根据简单协议发送数据包。
+// 根据简单协议发送数据包，数据包格式由PkgOption.Codec决定。
 //
-// 简单协议数据格式：数据长度(24bit)|数据字段(变长)。
+// 默认的简单协议数据格式：数据长度(24bit)|数据字段(变长)。
 //
 // 注意：
 // 1. "数据长度"仅为"数据字段"的长度，不包含头信息的长度字段3字节。
-// 2. 由于"数据长度"为3字节，并且使用的BigEndian字节序，因此这里最后返回的buffer使用了buffer[1:]。
+// 2. "数据长度"使用BigEndian字节序。
 func (c *Conn) SendPkg(data []byte, option...PkgOption) error {
 	pkgOption, err := getPkgOption(option...)
 	if err != nil {
 		return err
 	}
-	length := len(data)
-	if length > pkgOption.MaxSize {
-		return errors.New(fmt.Sprintf(`data size %d exceeds max pkg size %d`, length, gPKG_MAX_DATA_SIZE))
+	buffer, err := pkgOption.Codec.Encode(data, pkgOption.MaxSize)
+	if err != nil {
+		return err
 	}
-	buffer := make([]byte, gPKG_HEADER_SIZE + 1 + len(data))
-	binary.BigEndian.PutUint32(buffer[0 : ], uint32(length))
-	copy(buffer[gPKG_HEADER_SIZE + 1 : ], data)
 	if pkgOption.Retry.Count > 0 {
-		return c.Send(buffer[1:], pkgOption.Retry)
+		return c.Send(buffer, pkgOption.Retry)
 	}
-	//fmt.Println("SendPkg:", buffer[1:])
-	return c.Send(buffer[1:])
+	return c.Send(buffer)
 }
 
 // 简单协议: 带超时时间的数据发送
@@ -95,46 +92,64 @@ func (c *Conn) SendRecvPkgWithTimeout(data []byte, timeout time.Duration, option
 }
 
 // 简单协议: 获取一个数据包。
+//
+// 读取缓冲区在多次读取之间复用，因此返回的数据只在下一次对该连接执行RecvPkg之前有效，
+// 如果需要保留数据，调用端应当复制一份。
 func (c *Conn) RecvPkg(option...PkgOption) (result []byte, err error) {
-	var temp   []byte
-	var length int
+	var n int
 	pkgOption, err := getPkgOption(option...)
 	if err != nil {
 		return nil, err
 	}
 	for {
-		// 先根据对象的缓冲区数据进行计算
-		for {
-			if len(c.buffer) >= gPKG_HEADER_SIZE {
-				// 注意"数据长度"为3个字节，不满足4个字节的uint32类型，因此这里"低位"补0
-				length = int(binary.BigEndian.Uint32([]byte{0, c.buffer[0], c.buffer[1], c.buffer[2]}))
-				// 解析的大小是否符合规范，清空从该连接接收到的所有数据包
-				if length < 0 || length > pkgOption.MaxSize {
-					c.buffer = c.buffer[:0]
-					return nil, fmt.Errorf(`invalid package size %d`, length)
+		// 先根据对象的缓冲区数据进行解码
+		if c.bufferEnd > c.bufferStart {
+			result, n, err = pkgOption.Codec.Decode(c.buffer[c.bufferStart : c.bufferEnd], pkgOption.MaxSize)
+			if n > 0 {
+				c.bufferStart += n
+				if err != nil {
+					return nil, err
 				}
-				// 不满足包大小，需要继续读取
-				if len(c.buffer) < length + gPKG_HEADER_SIZE {
-					break
-				}
-				result   = c.buffer[gPKG_HEADER_SIZE : gPKG_HEADER_SIZE + length]
-				c.buffer = c.buffer[gPKG_HEADER_SIZE + length: ]
-				return
-			} else {
-				break
+				// 限制返回数据的容量，防止调用端append时覆盖缓冲区数据
+				return result[: len(result) : len(result)], nil
+			}
+			// 数据包不可解析，清空从该连接接收到的所有数据
+			if err != nil {
+				c.bufferStart = 0
+				c.bufferEnd   = 0
+				return nil, err
 			}
 		}
-		// 读取系统socket缓冲区的完整数据
-		temp, err = c.Recv(0, pkgOption.Retry)
-		if err != nil {
-			break
-		}
-		if len(temp) > 0 {
-			c.buffer = append(c.buffer, temp...)
+		// 读取系统socket缓冲区的数据到缓冲区的剩余空间
+		c.growBuffer()
+		if n, err = c.read(c.buffer[c.bufferEnd:], pkgOption.Retry); err != nil {
+			return nil, err
 		}
-		//fmt.Println("RecvPkg:", c.buffer)
+		c.bufferEnd += n
+	}
+}
+
+// 为读取缓冲区准备剩余空间：已读取的数据移动到缓冲区头部，缓冲区已满时自动增长
+func (c *Conn) growBuffer() {
+	if c.buffer == nil {
+		c.buffer = make([]byte, gPKG_BUFFER_SIZE)
+	}
+	if c.bufferStart == c.bufferEnd {
+		c.bufferStart = 0
+		c.bufferEnd   = 0
+	}
+	if c.bufferEnd < len(c.buffer) {
+		return
+	}
+	if c.bufferStart > 0 {
+		copy(c.buffer, c.buffer[c.bufferStart : c.bufferEnd])
+		c.bufferEnd  -= c.bufferStart
+		c.bufferStart = 0
+		return
 	}
-	return
+	buffer  := make([]byte, 2*len(c.buffer))
+	copy(buffer, c.buffer[: c.bufferEnd])
+	c.buffer = buffer
 }
 
 // 简单协议: 带超时时间的消息包获取
@@ -0,0 +1,223 @@
+// Copyright 2019 gf Author(https://github.com/gogf/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package gtcp
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// 简单协议的数据包编解码接口，通过PkgOption.Codec指定，默认为3字节大端长度头部的编解码。
+type Codec interface {
+	// 将数据编码为数据包，maxSize为允许的最大数据大小(byte)
+	Encode(data []byte, maxSize int) ([]byte, error)
+	// 从缓冲区头部解码出一个数据包，返回数据以及该数据包在缓冲区中占用的长度n。
+	// 缓冲区数据不足一个完整数据包时返回n=0；
+	// 返回错误且n>0时表示该数据包已被跳过，n=0时表示缓冲区数据已不可解析。
+	Decode(buffer []byte, maxSize int) (data []byte, n int, err error)
+}
+
+// 长度头部编解码
+type lengthCodec struct {
+	size         int  // 头部大小(byte)
+	littleEndian bool // 是否使用小端字节序
+}
+
+// 变长整数(varint)长度头部编解码
+type varintCodec struct {}
+
+// 分隔符编解码
+type delimiterCodec struct {
+	delimiter []byte // 数据包分隔符
+}
+
+// 校验和编解码，在数据末尾增加4字节的校验和(大端字节序)
+type checksumCodec struct {
+	codec Codec // 底层编解码
+}
+
+// 校验和大小(byte)
+const gPKG_CHECKSUM_SIZE = 4
+
+// 默认的编解码：3字节大端长度头部
+var defaultCodec = NewLengthCodec(gPKG_HEADER_SIZE)
+
+// 创建使用固定长度头部的编解码，数据格式：数据长度(headerSize字节)|数据字段(变长)。
+// headerSize为1-8字节，常用为2/4/8字节，默认使用大端字节序，littleEndian为true时使用小端字节序。
+func NewLengthCodec(headerSize int, littleEndian...bool) Codec {
+	c := &lengthCodec {
+		size : headerSize,
+	}
+	if len(littleEndian) > 0 {
+		c.littleEndian = littleEndian[0]
+	}
+	return c
+}
+
+// 创建使用变长整数长度头部的编解码，数据格式：数据长度(uvarint，1-10字节)|数据字段(变长)。
+func NewVarintCodec() Codec {
+	return &varintCodec{}
+}
+
+// 创建使用分隔符的编解码，数据格式：数据字段(变长)|分隔符，数据字段中不能包含分隔符。
+func NewDelimiterCodec(delimiter []byte) Codec {
+	return &delimiterCodec {
+		delimiter : delimiter,
+	}
+}
+
+// 创建带校验和的编解码，使用Checksum计算数据的校验和并附加在数据末尾，解码时进行校验。
+func NewChecksumCodec(codec Codec) Codec {
+	return &checksumCodec {
+		codec : codec,
+	}
+}
+
+// 检查头部大小，并返回实际允许的最大数据大小，maxSize不能超过头部能表示的最大长度
+func (c *lengthCodec) checkMaxSize(maxSize int) (int, error) {
+	if c.size < 1 || c.size > 8 {
+		return 0, fmt.Errorf(`invalid package header size %d`, c.size)
+	}
+	if c.size < 8 && uint64(maxSize) > uint64(1) << uint(8*c.size) - 1 {
+		return int(uint64(1) << uint(8*c.size) - 1), nil
+	}
+	return maxSize, nil
+}
+
+func (c *lengthCodec) Encode(data []byte, maxSize int) ([]byte, error) {
+	maxSize, err := c.checkMaxSize(maxSize)
+	if err != nil {
+		return nil, err
+	}
+	length := len(data)
+	if length > maxSize {
+		return nil, fmt.Errorf(`data size %d exceeds max pkg size %d`, length, maxSize)
+	}
+	buffer := make([]byte, c.size + length)
+	for i := 0; i < c.size; i++ {
+		b := byte(uint64(length) >> uint(8*i))
+		if c.littleEndian {
+			buffer[i] = b
+		} else {
+			buffer[c.size - 1 - i] = b
+		}
+	}
+	copy(buffer[c.size:], data)
+	return buffer, nil
+}
+
+func (c *lengthCodec) Decode(buffer []byte, maxSize int) ([]byte, int, error) {
+	maxSize, err := c.checkMaxSize(maxSize)
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(buffer) < c.size {
+		return nil, 0, nil
+	}
+	length := uint64(0)
+	for i := 0; i < c.size; i++ {
+		if c.littleEndian {
+			length |= uint64(buffer[i]) << uint(8*i)
+		} else {
+			length  = length << 8 | uint64(buffer[i])
+		}
+	}
+	// 解析的大小是否符合规范
+	if length > uint64(maxSize) {
+		return nil, 0, fmt.Errorf(`invalid package size %d`, length)
+	}
+	if len(buffer) < c.size + int(length) {
+		return nil, 0, nil
+	}
+	return buffer[c.size : c.size + int(length)], c.size + int(length), nil
+}
+
+func (c *varintCodec) Encode(data []byte, maxSize int) ([]byte, error) {
+	length := len(data)
+	if length > maxSize {
+		return nil, fmt.Errorf(`data size %d exceeds max pkg size %d`, length, maxSize)
+	}
+	buffer := make([]byte, binary.MaxVarintLen64 + length)
+	n      := binary.PutUvarint(buffer, uint64(length))
+	copy(buffer[n:], data)
+	return buffer[: n + length], nil
+}
+
+func (c *varintCodec) Decode(buffer []byte, maxSize int) ([]byte, int, error) {
+	length, n := binary.Uvarint(buffer)
+	if n == 0 {
+		return nil, 0, nil
+	}
+	if n < 0 || length > uint64(maxSize) {
+		return nil, 0, fmt.Errorf(`invalid package size %d`, length)
+	}
+	if len(buffer) < n + int(length) {
+		return nil, 0, nil
+	}
+	return buffer[n : n + int(length)], n + int(length), nil
+}
+
+func (c *delimiterCodec) Encode(data []byte, maxSize int) ([]byte, error) {
+	if len(c.delimiter) == 0 {
+		return nil, errors.New("empty package delimiter")
+	}
+	if len(data) > maxSize {
+		return nil, fmt.Errorf(`data size %d exceeds max pkg size %d`, len(data), maxSize)
+	}
+	if bytes.Contains(data, c.delimiter) {
+		return nil, errors.New("data contains package delimiter")
+	}
+	buffer := make([]byte, len(data) + len(c.delimiter))
+	copy(buffer, data)
+	copy(buffer[len(data):], c.delimiter)
+	return buffer, nil
+}
+
+func (c *delimiterCodec) Decode(buffer []byte, maxSize int) ([]byte, int, error) {
+	if len(c.delimiter) == 0 {
+		return nil, 0, errors.New("empty package delimiter")
+	}
+	index := bytes.Index(buffer, c.delimiter)
+	if index < 0 {
+		// 缓冲区数据已超过最大包大小仍未找到分隔符
+		if len(buffer) > maxSize + len(c.delimiter) {
+			return nil, 0, fmt.Errorf(`invalid package size %d`, len(buffer))
+		}
+		return nil, 0, nil
+	}
+	if index > maxSize {
+		return nil, index + len(c.delimiter), fmt.Errorf(`invalid package size %d`, index)
+	}
+	return buffer[:index], index + len(c.delimiter), nil
+}
+
+func (c *checksumCodec) Encode(data []byte, maxSize int) ([]byte, error) {
+	if len(data) > maxSize {
+		return nil, fmt.Errorf(`data size %d exceeds max pkg size %d`, len(data), maxSize)
+	}
+	buffer := make([]byte, len(data) + gPKG_CHECKSUM_SIZE)
+	copy(buffer, data)
+	binary.BigEndian.PutUint32(buffer[len(data):], Checksum(data))
+	return c.codec.Encode(buffer, maxSize + gPKG_CHECKSUM_SIZE)
+}
+
+func (c *checksumCodec) Decode(buffer []byte, maxSize int) ([]byte, int, error) {
+	payload, n, err := c.codec.Decode(buffer, maxSize + gPKG_CHECKSUM_SIZE)
+	if err != nil || n == 0 {
+		return nil, n, err
+	}
+	if len(payload) < gPKG_CHECKSUM_SIZE {
+		return nil, n, errors.New("package checksum missing")
+	}
+	data := payload[ : len(payload) - gPKG_CHECKSUM_SIZE]
+	if binary.BigEndian.Uint32(payload[len(data):]) != Checksum(data) {
+		return nil, n, errors.New("package checksum mismatch")
+	}
+	return data, n, nil
+}
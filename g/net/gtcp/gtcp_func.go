@@ -24,8 +24,7 @@ type Retry struct {
     Interval int  // 重试间隔(毫秒)
 }
 
-// Deprecated.
-// 常见的二进制数据校验方式，生成校验结果
+// 常见的二进制数据校验方式(字节累加和)，生成校验结果，NewChecksumCodec使用该方法进行数据包校验
 func Checksum(buffer []byte) uint32 {
     var checksum uint32
     for _, b := range buffer {
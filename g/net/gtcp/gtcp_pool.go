@@ -7,16 +7,55 @@
 package gtcp
 
 import (
-    "time"
+    "errors"
     "github.com/gogf/gf/g/container/gmap"
-    "github.com/gogf/gf/g/container/gpool"
+    "github.com/gogf/gf/g/os/gtimer"
+    "sync"
+    "time"
 )
 
+// 链接池对象，管理指向同一地址的TCP链接，可以限制空闲链接数以及链接总数
+type ConnPool struct {
+    mu           sync.Mutex
+    address      string                 // 链接地址
+    timeout      []int                  // (毫秒)创建链接的超时时间
+    idle         []*poolIdleConn        // 空闲链接列表，头部为最早放回的链接
+    active       int                    // 当前链接总数(包括空闲链接以及使用中的链接)
+    maxIdle      int                    // 最大空闲链接数，0表示不限制
+    maxActive    int                    // 最大链接总数，0表示不限制
+    wait         bool                   // 链接总数达到上限时是否阻塞等待，否则立即返回错误
+    waitTimeout  time.Duration          // 阻塞等待的超时时间，0表示一直等待
+    idleTimeout  time.Duration          // 空闲链接的过期时间，0表示不过期
+    testOnBorrow func(conn *Conn) error // 获取空闲链接时的检测方法，检测失败的链接将被关闭
+    waiters      []chan struct{}        // 阻塞等待的获取操作
+    closed       bool                   // 链接池是否已关闭
+    stats        ConnPoolStats          // 统计信息
+    entry        *gtimer.Entry          // 检查空闲链接过期的定时任务
+}
+
+// 链接池统计信息
+type ConnPoolStats struct {
+    Active   int   // 当前链接总数(包括空闲链接以及使用中的链接)
+    Idle     int   // 当前空闲链接数
+    Hits     int64 // 复用空闲链接的次数
+    Misses   int64 // 没有空闲链接而创建新链接的次数
+    Waits    int64 // 链接总数达到上限而阻塞等待的次数
+    Timeouts int64 // 阻塞等待超时的次数
+    Discards int64 // 空闲链接过期或者检测失败而被关闭的次数
+}
+
 // 链接池链接对象
 type PoolConn struct {
     *Conn              // 继承底层链接接口对象
-    pool   *gpool.Pool // 对应的链接池对象
+    pool   *ConnPool   // 对应的链接池对象
     status int         // 当前对象的状态，主要用于失败重连判断
+    closed bool        // 是否已关闭(放回链接池或者关闭底层链接)，重复关闭时不做任何操作
+}
+
+// 空闲链接
+type poolIdleConn struct {
+    conn *PoolConn // 链接对象
+    time time.Time // 放回链接池的时间
 }
 
 const (
@@ -24,51 +63,323 @@ const (
     gCONN_STATUS_UNKNOWN = 0     // 未知，表示未经过连通性操作;
     gCONN_STATUS_ACTIVE  = 1     // 正常，表示已经经过连通性操作
     gCONN_STATUS_ERROR   = 2     // 错误，表示该接口操作产生了错误，不应当被循环使用了
-
+    gPOOL_CHECK_INTERVAL = time.Second       // 检查空闲链接过期的时间间隔
+    gPOOL_PROBE_TIMEOUT  = time.Millisecond  // 检测空闲链接是否已被对端关闭的读取等待时间
 )
 
 var (
     // 连接池对象map，键名为地址端口，键值为对应的连接池对象
     pools = gmap.NewStrAnyMap()
+
+    // 链接池已关闭
+    ErrPoolClosed    = errors.New("connection pool is closed")
+    // 链接总数达到上限，并且链接池未设置阻塞等待
+    ErrPoolExhausted = errors.New("connection pool exhausted")
+    // 阻塞等待链接超时
+    ErrPoolTimeout   = errors.New("connection pool waiting timeout")
 )
 
-// 创建TCP链接池对象
+// 从指定地址对应的默认链接池获取TCP链接，默认链接池不限制链接数，空闲链接60秒后过期
 func NewPoolConn(addr string, timeout...int) (*PoolConn, error) {
-    var pool *gpool.Pool
-    if v := pools.Get(addr); v == nil {
-        pools.LockFunc(func(m map[string]interface{}) {
-            if v, ok := m[addr]; ok {
-                pool = v.(*gpool.Pool)
-            } else {
-                pool = gpool.New(gDEFAULT_POOL_EXPIRE, func() (interface{}, error) {
-                    if conn, err := NewConn(addr, timeout...); err == nil {
-                        return &PoolConn { conn, pool, gCONN_STATUS_ACTIVE }, nil
-                    } else {
-                        return nil, err
-                    }
-                })
-                m[addr] = pool
+    return GetConnPool(addr, timeout...).Get()
+}
+
+// 获取指定地址对应的默认链接池，不存在时创建，可以用于修改默认链接池的配置。
+// timeout参数仅在创建时有效。
+func GetConnPool(addr string, timeout...int) *ConnPool {
+    return pools.GetOrSetFuncLock(addr, func() interface{} {
+        return NewConnPool(addr, timeout...)
+    }).(*ConnPool)
+}
+
+// 创建独立的TCP链接池，默认不限制链接数，空闲链接60秒后过期
+func NewConnPool(addr string, timeout...int) *ConnPool {
+    p := &ConnPool {
+        address     : addr,
+        timeout     : timeout,
+        idleTimeout : gDEFAULT_POOL_EXPIRE * time.Millisecond,
+    }
+    p.entry = gtimer.AddSingleton(gPOOL_CHECK_INTERVAL, p.checkIdle)
+    return p
+}
+
+// 设置最大空闲链接数，超过限制时放回的链接将被关闭，0表示不限制
+func (p *ConnPool) SetMaxIdle(max int) {
+    p.mu.Lock()
+    p.maxIdle = max
+    p.mu.Unlock()
+}
+
+// 设置最大链接总数(包括空闲链接以及使用中的链接)，0表示不限制
+func (p *ConnPool) SetMaxActive(max int) {
+    p.mu.Lock()
+    p.maxActive = max
+    p.mu.Unlock()
+}
+
+// 设置链接总数达到上限时获取链接是否阻塞等待，以及等待的超时时间(0表示一直等待)；
+// 不阻塞等待时立即返回ErrPoolExhausted错误
+func (p *ConnPool) SetWait(wait bool, timeout...time.Duration) {
+    p.mu.Lock()
+    p.wait        = wait
+    p.waitTimeout = 0
+    if len(timeout) > 0 {
+        p.waitTimeout = timeout[0]
+    }
+    p.mu.Unlock()
+}
+
+// 设置空闲链接的过期时间，0表示不过期
+func (p *ConnPool) SetIdleTimeout(timeout time.Duration) {
+    p.mu.Lock()
+    p.idleTimeout = timeout
+    p.mu.Unlock()
+}
+
+// 设置获取空闲链接时的检测方法，检测返回错误的链接将被关闭并且重新获取，
+// 可以使用ProbeConn检测链接是否已被对端关闭
+func (p *ConnPool) SetTestOnBorrow(f func(conn *Conn) error) {
+    p.mu.Lock()
+    p.testOnBorrow = f
+    p.mu.Unlock()
+}
+
+// 链接池地址
+func (p *ConnPool) Address() string {
+    return p.address
+}
+
+// 获取链接池统计信息
+func (p *ConnPool) Stats() ConnPoolStats {
+    p.mu.Lock()
+    defer p.mu.Unlock()
+    stats       := p.stats
+    stats.Active = p.active
+    stats.Idle   = len(p.idle)
+    return stats
+}
+
+// 从链接池获取链接，优先复用最近放回的空闲链接，没有空闲链接时创建新链接；
+// 链接总数达到上限时根据SetWait的配置阻塞等待或者返回ErrPoolExhausted错误
+func (p *ConnPool) Get() (*PoolConn, error) {
+    var timer <-chan time.Time
+    p.mu.Lock()
+    for {
+        if p.closed {
+            p.mu.Unlock()
+            return nil, ErrPoolClosed
+        }
+        // 复用空闲链接
+        if n := len(p.idle); n > 0 {
+            item    := p.idle[n - 1]
+            p.idle   = p.idle[: n - 1]
+            test    := p.testOnBorrow
+            expired := p.idleTimeout > 0 && time.Since(item.time) > p.idleTimeout
+            p.mu.Unlock()
+            ok := !expired && (test == nil || test(item.conn.Conn) == nil)
+            if !ok {
+                item.conn.Conn.Close()
             }
-        })
-    } else {
-        pool = v.(*gpool.Pool)
+            p.mu.Lock()
+            if ok {
+                p.stats.Hits++
+                p.mu.Unlock()
+                return item.conn, nil
+            }
+            p.stats.Discards++
+            p.release()
+            continue
+        }
+        // 创建新链接
+        if p.maxActive <= 0 || p.active < p.maxActive {
+            p.active++
+            p.stats.Misses++
+            p.mu.Unlock()
+            conn, err := NewConn(p.address, p.timeout...)
+            if err != nil {
+                p.mu.Lock()
+                p.release()
+                p.mu.Unlock()
+                return nil, err
+            }
+            return &PoolConn { conn, p, gCONN_STATUS_ACTIVE, false }, nil
+        }
+        if !p.wait {
+            p.mu.Unlock()
+            return nil, ErrPoolExhausted
+        }
+        // 阻塞等待链接放回或者关闭
+        if timer == nil && p.waitTimeout > 0 {
+            t := time.NewTimer(p.waitTimeout)
+            defer t.Stop()
+            timer = t.C
+        }
+        ch       := make(chan struct{}, 1)
+        p.waiters = append(p.waiters, ch)
+        p.stats.Waits++
+        p.mu.Unlock()
+        select {
+            case <-ch:
+                p.mu.Lock()
+
+            case <-timer:
+                p.mu.Lock()
+                if !p.removeWaiter(ch) {
+                    // 已被唤醒，将唤醒传递给下一个等待者
+                    p.notify()
+                }
+                p.stats.Timeouts++
+                p.mu.Unlock()
+                return nil, ErrPoolTimeout
+        }
     }
+}
 
-    if v, err := pool.Get(); err == nil {
-        return v.(*PoolConn), nil
-    } else {
-        return nil, err
+// 关闭链接池，关闭所有空闲链接，使用中的链接在放回时关闭，阻塞等待的获取操作返回ErrPoolClosed错误
+func (p *ConnPool) Close() error {
+    p.mu.Lock()
+    if p.closed {
+        p.mu.Unlock()
+        return nil
+    }
+    p.closed   = true
+    idle      := p.idle
+    p.idle     = nil
+    p.active  -= len(idle)
+    for _, ch := range p.waiters {
+        ch <- struct{}{}
+    }
+    p.waiters = nil
+    p.mu.Unlock()
+    p.entry.Close()
+    for _, item := range idle {
+        item.conn.Conn.Close()
+    }
+    // 默认链接池关闭后从map中删除，以便重新创建
+    pools.LockFunc(func(m map[string]interface{}) {
+        if m[p.address] == p {
+            delete(m, p.address)
+        }
+    })
+    return nil
+}
+
+// 将链接放回链接池，链接池已关闭或者空闲链接数达到上限时关闭该链接；
+// 放回的是新的链接对象，原有的链接对象不再关联链接池，避免重复关闭时重复放回
+func (p *ConnPool) put(c *PoolConn) {
+    p.mu.Lock()
+    if p.closed || (p.maxIdle > 0 && len(p.idle) >= p.maxIdle) {
+        p.release()
+        p.mu.Unlock()
+        c.Conn.Close()
+        return
+    }
+    p.idle = append(p.idle, &poolIdleConn {
+        conn : &PoolConn { c.Conn, p, gCONN_STATUS_UNKNOWN, false },
+        time : time.Now(),
+    })
+    p.notify()
+    p.mu.Unlock()
+}
+
+// 链接关闭后减少链接总数，并唤醒等待者(调用时需要加锁)
+func (p *ConnPool) release() {
+    p.active--
+    p.notify()
+}
+
+// 唤醒第一个等待者(调用时需要加锁)
+func (p *ConnPool) notify() {
+    if len(p.waiters) > 0 {
+        p.waiters[0] <- struct{}{}
+        p.waiters = p.waiters[1:]
+    }
+}
+
+// 删除等待者，返回是否删除成功，删除失败表示已被唤醒(调用时需要加锁)
+func (p *ConnPool) removeWaiter(ch chan struct{}) bool {
+    for i, v := range p.waiters {
+        if v == ch {
+            p.waiters = append(p.waiters[:i], p.waiters[i + 1:]...)
+            return true
+        }
+    }
+    return false
+}
+
+// 定时关闭过期的空闲链接
+func (p *ConnPool) checkIdle() {
+    p.mu.Lock()
+    expired := 0
+    if p.idleTimeout > 0 {
+        for expired < len(p.idle) && time.Since(p.idle[expired].time) > p.idleTimeout {
+            expired++
+        }
+    }
+    items := p.idle[: expired]
+    p.idle = append([]*poolIdleConn(nil), p.idle[expired:]...)
+    for range items {
+        p.stats.Discards++
+        p.release()
+    }
+    p.mu.Unlock()
+    for _, item := range items {
+        item.conn.Conn.Close()
     }
 }
 
-// (方法覆盖)覆盖底层接口对象的Close方法
+// 检测链接是否已被对端关闭，用于SetTestOnBorrow。
+// 检测时短暂等待读取数据，读取超时表示链接正常，已缓冲的数据保留在链接中。
+func ProbeConn(conn *Conn) error {
+    if conn.reader.Buffered() > 0 || conn.bufferEnd > conn.bufferStart {
+        return nil
+    }
+    if err := conn.conn.SetReadDeadline(time.Now().Add(gPOOL_PROBE_TIMEOUT)); err != nil {
+        return err
+    }
+    defer conn.conn.SetReadDeadline(conn.recvDeadline)
+    if _, err := conn.reader.Peek(1); err != nil {
+        if e, ok := err.(interface{ Timeout() bool }); ok && e.Timeout() {
+            return nil
+        }
+        return err
+    }
+    return nil
+}
+
+// (方法覆盖)覆盖底层接口对象的Close方法，正常的链接放回链接池，产生错误的链接被关闭；
+// 重复关闭时不做任何操作
 func (c *PoolConn) Close() error {
-    if c.pool != nil && c.status == gCONN_STATUS_ACTIVE {
-        c.status = gCONN_STATUS_UNKNOWN
-        c.pool.Put(c)
-    } else {
+    if c.closed {
+        return nil
+    }
+    c.closed = true
+    pool    := c.pool
+    c.pool   = nil
+    if pool == nil {
+        return c.Conn.Close()
+    }
+    if c.status == gCONN_STATUS_ERROR {
+        pool.mu.Lock()
+        pool.release()
+        pool.mu.Unlock()
         return c.Conn.Close()
     }
+    pool.put(c)
+    return nil
+}
+
+// 复用的空闲链接操作失败时重新创建链接
+func (c *PoolConn) reconnect() error {
+    if c.pool == nil {
+        return ErrPoolClosed
+    }
+    conn, err := NewConn(c.pool.address, c.pool.timeout...)
+    if err != nil {
+        return err
+    }
+    c.Conn.Close()
+    c.Conn = conn
     return nil
 }
 
@@ -76,11 +387,8 @@ func (c *PoolConn) Close() error {
 func (c *PoolConn) Send(data []byte, retry...Retry) error {
     var err error
     if err = c.Conn.Send(data, retry...); err != nil && c.status == gCONN_STATUS_UNKNOWN {
-        if v, e := c.pool.NewFunc(); e == nil {
-            c.Conn = v.(*PoolConn).Conn
-            err    = c.Conn.Send(data, retry...)
-        } else {
-            err    = e
+        if err = c.reconnect(); err == nil {
+            err = c.Conn.Send(data, retry...)
         }
     }
     if err != nil {
@@ -147,4 +455,4 @@ func (c *PoolConn) SendRecvWithTimeout(data []byte, receive int, timeout time.Du
     } else {
         return nil, err
     }
-}
\ No newline at end of file
+}
@@ -13,11 +13,8 @@ import (
 // 简单协议: (方法覆盖)发送数据
 func (c *PoolConn) SendPkg(data []byte, option...PkgOption) (err error) {
     if err = c.Conn.SendPkg(data, option...); err != nil && c.status == gCONN_STATUS_UNKNOWN {
-        if v, e := c.pool.NewFunc(); e == nil {
-            c.Conn = v.(*PoolConn).Conn
-            err    = c.Conn.SendPkg(data, option...)
-        } else {
-            err    = e
+        if err = c.reconnect(); err == nil {
+            err = c.Conn.SendPkg(data, option...)
         }
     }
     if err != nil {
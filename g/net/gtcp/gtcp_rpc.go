@@ -0,0 +1,137 @@
+// Copyright 2019 gf Author(https://github.com/gogf/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package gtcp
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/gogf/gf/g/encoding/gbinary"
+)
+
+// RPC payload encodings.
+const (
+    RPC_ENCODING_JSON   = 0 // JSON encoding, which is the default encoding.
+    RPC_ENCODING_BINARY = 1 // Binary encoding using gbinary, for basic types, []byte and fixed-size values.
+)
+
+const (
+    gRPC_TYPE_REQUEST     = 1        // Message type of request.
+    gRPC_TYPE_RESPONSE    = 2        // Message type of successful response.
+    gRPC_TYPE_ERROR       = 3        // Message type of error response, whose payload is the error message.
+    gRPC_HEADER_SIZE      = 12       // Size of message header before method name.
+    gRPC_DEFAULT_MAX_SIZE = 4 << 20  // Default maximum size of a message.
+)
+
+// rpcMessage is a request or response message of RPC.
+//
+// Message format: type(1)|encoding(1)|id(8)|method length(2)|method|payload.
+// The id is chosen by client to match responses with requests on a multiplexed connection,
+// and the method is empty for responses.
+type rpcMessage struct {
+    kind     int
+    encoding int
+    id       uint64
+    method   string
+    data     []byte
+}
+
+// RpcRequest is a request received by RPC server.
+type RpcRequest struct {
+    Method   string // Requested method name.
+    Encoding int    // Payload encoding, which is also used to encode the response.
+    Data     []byte // Encoded request payload.
+    Conn     *Conn  // Connection the request is received from.
+}
+
+// RpcHandler handles a request and returns the response value, which is encoded using the request encoding.
+// If error is returned, the client receives the error message as an error.
+type RpcHandler func(r *RpcRequest) (interface{}, error)
+
+// Parse decodes the request payload into <pointer>.
+func (r *RpcRequest) Parse(pointer interface{}) error {
+    return rpcUnmarshal(r.Encoding, r.Data, pointer)
+}
+
+// rpcPkgOption returns the package option for RPC messages with maximum size <maxSize>.
+func rpcPkgOption(maxSize int) PkgOption {
+    return PkgOption {
+        MaxSize : maxSize,
+        Codec   : NewLengthCodec(4),
+    }
+}
+
+// encodeRpcMessage encodes <m> to bytes.
+func encodeRpcMessage(m *rpcMessage) []byte {
+    buffer := make([]byte, 0, gRPC_HEADER_SIZE + len(m.method) + len(m.data))
+    buffer  = append(buffer, byte(m.kind), byte(m.encoding))
+    buffer  = append(buffer, gbinary.EncodeUint64(m.id)...)
+    buffer  = append(buffer, gbinary.EncodeUint16(uint16(len(m.method)))...)
+    buffer  = append(buffer, m.method...)
+    return append(buffer, m.data...)
+}
+
+// decodeRpcMessage decodes bytes to message, the payload is copied from <buffer>.
+func decodeRpcMessage(buffer []byte) (*rpcMessage, error) {
+    if len(buffer) < gRPC_HEADER_SIZE {
+        return nil, errors.New("rpc: invalid message")
+    }
+    length := int(gbinary.DecodeToUint16(buffer[10 : 12]))
+    if len(buffer) < gRPC_HEADER_SIZE + length {
+        return nil, errors.New("rpc: invalid message")
+    }
+    m := &rpcMessage {
+        kind     : int(buffer[0]),
+        encoding : int(buffer[1]),
+        id       : gbinary.DecodeToUint64(buffer[2 : 10]),
+        method   : string(buffer[gRPC_HEADER_SIZE : gRPC_HEADER_SIZE + length]),
+    }
+    m.data = append([]byte(nil), buffer[gRPC_HEADER_SIZE + length:]...)
+    return m, nil
+}
+
+// rpcMarshal encodes <value> using <encoding>.
+func rpcMarshal(encoding int, value interface{}) ([]byte, error) {
+    switch encoding {
+        case RPC_ENCODING_JSON:
+            return json.Marshal(value)
+
+        case RPC_ENCODING_BINARY:
+            // The int and uint values are encoded in fixed size, as their sizes vary with platforms.
+            switch v := value.(type) {
+                case nil:  return nil, nil
+                case int:  return gbinary.EncodeInt64(int64(v)), nil
+                case uint: return gbinary.EncodeUint64(uint64(v)), nil
+            }
+            return gbinary.Encode(value), nil
+    }
+    return nil, fmt.Errorf(`rpc: unknown encoding %d`, encoding)
+}
+
+// rpcUnmarshal decodes <data> into <pointer> using <encoding>, it does nothing if <pointer> is nil.
+func rpcUnmarshal(encoding int, data []byte, pointer interface{}) error {
+    if pointer == nil {
+        return nil
+    }
+    switch encoding {
+        case RPC_ENCODING_JSON:
+            return json.Unmarshal(data, pointer)
+
+        case RPC_ENCODING_BINARY:
+            switch v := pointer.(type) {
+                case *[]byte: *v = append([]byte(nil), data...)
+                case *string: *v = string(data)
+                case *bool:   *v = gbinary.DecodeToBool(data)
+                case *int:    *v = int(gbinary.DecodeToInt64(data))
+                case *uint:   *v = uint(gbinary.DecodeToUint64(data))
+                default:
+                    return gbinary.Decode(data, pointer)
+            }
+            return nil
+    }
+    return fmt.Errorf(`rpc: unknown encoding %d`, encoding)
+}
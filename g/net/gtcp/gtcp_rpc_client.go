@@ -0,0 +1,292 @@
+// Copyright 2019 gf Author(https://github.com/gogf/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package gtcp
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gogf/gf/g/container/gtype"
+	"sync"
+	"time"
+)
+
+const (
+    gRPC_DEFAULT_CONNS   = 2                // Default count of multiplexed connections of a client.
+    gRPC_DEFAULT_TIMEOUT = 10 * time.Second // Default timeout of calls.
+)
+
+var (
+    // errRpcClientClosed is returned when calling on a closed client.
+    errRpcClientClosed = errors.New("rpc: client is closed")
+)
+
+// RpcClient is a client of RpcServer.
+//
+// Each call is sent with a unique request id, so many calls can be in flight concurrently on
+// one connection, and the responses are matched with calls by id. The client keeps a fixed count
+// of multiplexed connections taken from the connection pool of PoolConn, and the calls are
+// distributed to the connections in turn. The broken connections are replaced on demand.
+type RpcClient struct {
+    mu       sync.Mutex
+    address  string
+    conns    []*rpcConn       // Multiplexed connections, which are created lazily.
+    index    *gtype.Int       // Index for choosing connections in turn.
+    id       *gtype.Uint64    // Last request id.
+    timeout  time.Duration    // Default timeout of calls.
+    encoding int              // Payload encoding of requests.
+    option   PkgOption        // Package option for messages.
+    closed   *gtype.Bool      // Whether the client is closed.
+}
+
+// rpcConn is a multiplexed connection of client.
+type rpcConn struct {
+    client  *RpcClient
+    conn    *PoolConn
+    sendMu  sync.Mutex                        // Lock for sending messages.
+    mu      sync.Mutex                        // Lock for <pending> and <err>.
+    pending map[uint64]chan *rpcMessage       // Request id => channel receiving the response.
+    err     error                             // Error breaking the connection.
+    done    chan struct{}                     // Closed when the receiving goroutine exits.
+}
+
+// NewRpcClient creates and returns a new RPC client of server at <address>.
+// The param <conns> is optional, which specifies the count of multiplexed connections, default is 2.
+func NewRpcClient(address string, conns...int) *RpcClient {
+    size := gRPC_DEFAULT_CONNS
+    if len(conns) > 0 && conns[0] > 0 {
+        size = conns[0]
+    }
+    return &RpcClient {
+        address  : address,
+        conns    : make([]*rpcConn, size),
+        index    : gtype.NewInt(),
+        id       : gtype.NewUint64(),
+        timeout  : gRPC_DEFAULT_TIMEOUT,
+        encoding : RPC_ENCODING_JSON,
+        option   : rpcPkgOption(gRPC_DEFAULT_MAX_SIZE),
+        closed   : gtype.NewBool(),
+    }
+}
+
+// SetTimeout sets the default timeout of calls.
+func (c *RpcClient) SetTimeout(timeout time.Duration) {
+    c.timeout = timeout
+}
+
+// SetEncoding sets the payload encoding of requests, which is RPC_ENCODING_JSON in default.
+func (c *RpcClient) SetEncoding(encoding int) {
+    c.encoding = encoding
+}
+
+// SetMaxSize sets the maximum size of messages in bytes, which should be the same as the server.
+// It should be called before any call.
+func (c *RpcClient) SetMaxSize(size int) {
+    c.option = rpcPkgOption(size)
+}
+
+// Call calls <method> with <request> using the default timeout,
+// and decodes the response into <response> if it's not nil.
+func (c *RpcClient) Call(method string, request interface{}, response interface{}) error {
+    return c.CallWithTimeout(method, request, response, c.timeout)
+}
+
+// CallWithTimeout calls <method> with <request> using <timeout>,
+// and decodes the response into <response> if it's not nil.
+func (c *RpcClient) CallWithTimeout(method string, request interface{}, response interface{}, timeout time.Duration) error {
+    if c.closed.Val() {
+        return errRpcClientClosed
+    }
+    if len(method) > 0xFFFF {
+        return fmt.Errorf(`rpc: method name too long`)
+    }
+    data, err := rpcMarshal(c.encoding, request)
+    if err != nil {
+        return err
+    }
+    id      := c.id.Add(1)
+    message := encodeRpcMessage(&rpcMessage {
+        kind     : gRPC_TYPE_REQUEST,
+        encoding : c.encoding,
+        id       : id,
+        method   : method,
+        data     : data,
+    })
+    if len(message) > c.option.MaxSize {
+        return fmt.Errorf(`rpc: request size %d exceeds max size %d`, len(message), c.option.MaxSize)
+    }
+    conn, err := c.getConn()
+    if err != nil {
+        return err
+    }
+    ch := make(chan *rpcMessage, 1)
+    if err := conn.add(id, ch); err != nil {
+        return err
+    }
+    defer conn.remove(id)
+    if err := conn.send(message, timeout); err != nil {
+        return err
+    }
+    timer := time.NewTimer(timeout)
+    defer timer.Stop()
+    select {
+        case m := <-ch:
+            if m == nil {
+                return conn.lastError()
+            }
+            if m.kind == gRPC_TYPE_ERROR {
+                return errors.New(string(m.data))
+            }
+            return rpcUnmarshal(m.encoding, m.data, response)
+
+        case <-timer.C:
+            return fmt.Errorf(`rpc: call "%s" timeout after %v`, method, timeout)
+    }
+}
+
+// Close closes the client, the idle connections are returned to the connection pool.
+// The calls in flight fail with error.
+func (c *RpcClient) Close() error {
+    if c.closed.Set(true) {
+        return nil
+    }
+    c.mu.Lock()
+    defer c.mu.Unlock()
+    for i, conn := range c.conns {
+        if conn != nil {
+            conn.release()
+            c.conns[i] = nil
+        }
+    }
+    return nil
+}
+
+// getConn returns a multiplexed connection in turn, it creates a new connection if the connection is broken.
+func (c *RpcClient) getConn() (*rpcConn, error) {
+    i := c.index.Add(1) % len(c.conns)
+    c.mu.Lock()
+    defer c.mu.Unlock()
+    if c.closed.Val() {
+        return nil, errRpcClientClosed
+    }
+    if conn := c.conns[i]; conn != nil {
+        if conn.lastError() == nil {
+            return conn, nil
+        }
+        conn.release()
+    }
+    pc, err := NewPoolConn(c.address)
+    if err != nil {
+        return nil, err
+    }
+    conn := &rpcConn {
+        client  : c,
+        conn    : pc,
+        pending : make(map[uint64]chan *rpcMessage),
+        done    : make(chan struct{}),
+    }
+    go conn.receive()
+    c.conns[i] = conn
+    return conn, nil
+}
+
+// add adds a pending call with request id <id>.
+func (c *rpcConn) add(id uint64, ch chan *rpcMessage) error {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+    if c.err != nil {
+        return c.err
+    }
+    c.pending[id] = ch
+    return nil
+}
+
+// remove removes the pending call with request id <id>.
+func (c *rpcConn) remove(id uint64) {
+    c.mu.Lock()
+    delete(c.pending, id)
+    c.mu.Unlock()
+}
+
+// lastError returns the error breaking the connection, or nil if it's healthy.
+func (c *rpcConn) lastError() error {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+    return c.err
+}
+
+// fail breaks the connection with <err>, all the pending calls fail.
+func (c *rpcConn) fail(err error) {
+    c.mu.Lock()
+    c.doFail(err)
+    c.mu.Unlock()
+}
+
+// doFail breaks the connection with <err> without locking.
+func (c *rpcConn) doFail(err error) {
+    if c.err == nil {
+        c.err = err
+    }
+    for id, ch := range c.pending {
+        close(ch)
+        delete(c.pending, id)
+    }
+}
+
+// send sends request <message> with <timeout>.
+func (c *rpcConn) send(message []byte, timeout time.Duration) error {
+    c.sendMu.Lock()
+    defer c.sendMu.Unlock()
+    if err := c.conn.Conn.SendPkgWithTimeout(message, timeout, c.client.option); err != nil {
+        c.fail(err)
+        // Interrupts the receiving goroutine as the message may be sent partially.
+        c.conn.Conn.conn.Close()
+        return err
+    }
+    return nil
+}
+
+// receive receives responses and delivers them to the pending calls until the connection is broken.
+func (c *rpcConn) receive() {
+    defer close(c.done)
+    for {
+        data, err := c.conn.Conn.RecvPkg(c.client.option)
+        if err != nil {
+            c.fail(err)
+            return
+        }
+        m, err := decodeRpcMessage(data)
+        if err != nil {
+            c.fail(err)
+            return
+        }
+        c.mu.Lock()
+        ch := c.pending[m.id]
+        delete(c.pending, m.id)
+        c.mu.Unlock()
+        if ch != nil {
+            ch <- m
+        }
+    }
+}
+
+// release stops the connection, it's returned to the connection pool if it's healthy and idle,
+// or else it's closed.
+func (c *rpcConn) release() {
+    c.mu.Lock()
+    idle := c.err == nil && len(c.pending) == 0
+    c.doFail(errRpcClientClosed)
+    c.mu.Unlock()
+    // Interrupts the receiving goroutine, the data received partially is kept in the buffer of connection.
+    c.conn.Conn.conn.SetReadDeadline(time.Now())
+    <-c.done
+    if idle && c.conn.Conn.conn.SetReadDeadline(time.Time{}) == nil {
+        c.conn.status = gCONN_STATUS_ACTIVE
+    } else {
+        c.conn.status = gCONN_STATUS_ERROR
+    }
+    c.conn.Close()
+}
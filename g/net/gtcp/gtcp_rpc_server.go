@@ -0,0 +1,134 @@
+// Copyright 2019 gf Author(https://github.com/gogf/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package gtcp
+
+import (
+	"fmt"
+	"github.com/gogf/gf/g/container/gmap"
+	"github.com/gogf/gf/g/os/glog"
+	"github.com/gogf/gf/g/os/grpool"
+	"sync"
+)
+
+// RpcServer is a TCP server serving RPC requests, which dispatches the requests to registered handlers by method name.
+//
+// The requests received from one connection are handled concurrently in a goroutine pool,
+// and the responses are sent back in the order they are done, so that a client can have
+// many calls in flight on one connection.
+type RpcServer struct {
+    *Server                       // Underlying TCP server.
+    methods  *gmap.StrAnyMap      // Method name => RpcHandler.
+    pool     *grpool.Pool         // Goroutine pool for executing handlers.
+    option   PkgOption            // Package option for messages.
+}
+
+// NewRpcServer creates and returns a new RPC server listening on <address>.
+// The param <name> is optional, which is used to specify the instance name of the underlying TCP server.
+func NewRpcServer(address string, name...string) *RpcServer {
+    s := &RpcServer {
+        methods : gmap.NewStrAnyMap(),
+        pool    : grpool.New(),
+        option  : rpcPkgOption(gRPC_DEFAULT_MAX_SIZE),
+    }
+    s.Server = NewServer(address, s.handleConn, name...)
+    return s
+}
+
+// Register registers <handler> for <method>, the handler of the same method is replaced.
+func (s *RpcServer) Register(method string, handler RpcHandler) {
+    s.methods.Set(method, handler)
+}
+
+// Unregister removes the handler of <method>.
+func (s *RpcServer) Unregister(method string) {
+    s.methods.Remove(method)
+}
+
+// Methods returns the names of registered methods.
+func (s *RpcServer) Methods() []string {
+    return s.methods.Keys()
+}
+
+// SetWorkers sets the maximum count of goroutines executing handlers, which is not limited in default.
+// It should be called before the server runs.
+func (s *RpcServer) SetWorkers(limit int) {
+    s.pool = grpool.New(limit)
+}
+
+// SetMaxSize sets the maximum size of messages in bytes, which should be the same as the clients.
+// It should be called before the server runs.
+func (s *RpcServer) SetMaxSize(size int) {
+    s.option = rpcPkgOption(size)
+}
+
+// handleConn receives requests from <conn> and dispatches them to the goroutine pool.
+func (s *RpcServer) handleConn(conn *Conn) {
+    defer conn.Close()
+    mu := sync.Mutex{}
+    for {
+        data, err := conn.RecvPkg(s.option)
+        if err != nil {
+            return
+        }
+        request, err := decodeRpcMessage(data)
+        if err != nil {
+            glog.Warningf(`rpc: %s: %v`, conn.RemoteAddr(), err)
+            return
+        }
+        if request.kind != gRPC_TYPE_REQUEST {
+            continue
+        }
+        s.pool.Add(func() {
+            response := s.call(conn, request)
+            mu.Lock()
+            defer mu.Unlock()
+            if err := conn.SendPkg(encodeRpcMessage(response), s.option); err != nil {
+                conn.Close()
+            }
+        })
+    }
+}
+
+// call calls the handler of <request> and returns the response message.
+func (s *RpcServer) call(conn *Conn, request *rpcMessage) (response *rpcMessage) {
+    response = &rpcMessage {
+        kind     : gRPC_TYPE_RESPONSE,
+        encoding : request.encoding,
+        id       : request.id,
+    }
+    result, err := s.doCall(conn, request)
+    if err == nil {
+        response.data, err = rpcMarshal(request.encoding, result)
+    }
+    if err == nil && gRPC_HEADER_SIZE + len(response.data) > s.option.MaxSize {
+        err = fmt.Errorf(`rpc: response size %d exceeds max size %d`, gRPC_HEADER_SIZE + len(response.data), s.option.MaxSize)
+    }
+    if err != nil {
+        response.kind = gRPC_TYPE_ERROR
+        response.data = []byte(err.Error())
+    }
+    return
+}
+
+// doCall calls the handler of <request>, the panic of handler is returned as an error.
+func (s *RpcServer) doCall(conn *Conn, request *rpcMessage) (result interface{}, err error) {
+    v := s.methods.Get(request.method)
+    if v == nil {
+        return nil, fmt.Errorf(`rpc: method "%s" not found`, request.method)
+    }
+    defer func() {
+        if e := recover(); e != nil {
+            err = fmt.Errorf(`rpc: method "%s" panics: %v`, request.method, e)
+        }
+    }()
+    return v.(RpcHandler)(&RpcRequest {
+        Method   : request.method,
+        Encoding : request.encoding,
+        Data     : request.data,
+        Conn     : conn,
+    })
+}
@@ -8,24 +8,41 @@
 package gtcp
 
 import (
+	"context"
 	"crypto/tls"
 	"errors"
 	"github.com/gogf/gf/g/container/gmap"
+	"github.com/gogf/gf/g/container/gset"
+	"github.com/gogf/gf/g/container/gtype"
 	"github.com/gogf/gf/g/os/glog"
 	"github.com/gogf/gf/g/util/gconv"
 	"net"
+	"sync"
+	"time"
 )
 
 const (
     gDEFAULT_SERVER = "default"
+    // Minimum interval for checking idle connections.
+    gMIN_IDLE_CHECK_INTERVAL = 100 * time.Millisecond
 )
 
 // TCP Server.
 type Server struct {
-	listen    net.Listener
-    address   string
-    handler   func (*Conn)
-	tlsConfig *tls.Config
+    mu          sync.Mutex
+    name        string         // Server name, which is also used to identify the listener in graceful restart.
+	listen      net.Listener   // Listener accepting connections, which may be a TLS listener.
+    rawListen   net.Listener   // Underlying TCP listener.
+    address     string
+    handler     func (*Conn)
+	tlsConfig   *tls.Config
+    maxConns    int            // Maximum count of connections handled concurrently, 0 means no limit.
+    idleTimeout time.Duration  // Connections having no data sent or received for the duration are closed.
+    recvTimeout time.Duration  // Timeout for each receiving operation of connections.
+    conns       *gset.Set      // Live connections being handled.
+    wg          sync.WaitGroup // Waiting group of running handlers.
+    closed      *gtype.Bool    // Whether the server is closed.
+    done        chan struct{}  // Closed when the server is closed.
 }
 
 // Map for name to server, for singleton purpose.
@@ -40,23 +57,34 @@ func GetServer(name...interface{}) *Server {
         serverName = gconv.String(name[0])
     }
     return serverMapping.GetOrSetFuncLock(serverName, func() interface{} {
-	    return NewServer("", nil)
+        s     := newServer("", nil)
+        s.name = serverName
+	    return s
     }).(*Server)
 }
 
 // NewServer creates and returns a new normal TCP server.
 // The param <name> is optional, which is used to specify the instance name of the server.
 func NewServer(address string, handler func (*Conn), name...string) *Server {
-    s := &Server{
-    	address : address,
-    	handler : handler,
-    }
+    s := newServer(address, handler)
     if len(name) > 0 {
+        s.name = name[0]
         serverMapping.Set(name[0], s)
     }
     return s
 }
 
+// newServer creates and returns a new TCP server without name.
+func newServer(address string, handler func (*Conn)) *Server {
+    return &Server{
+    	address : address,
+    	handler : handler,
+        conns   : gset.New(),
+        closed  : gtype.NewBool(),
+        done    : make(chan struct{}),
+    }
+}
+
 // NewServerTLS creates and returns a new TCP server with TLS support.
 // The param <name> is optional, which is used to specify the instance name of the server.
 func NewServerTLS(address string, tlsConfig *tls.Config, handler func (*Conn), name...string) *Server {
@@ -100,44 +128,243 @@ func (s *Server) SetTLSConfig(tlsConfig *tls.Config) {
 	s.tlsConfig = tlsConfig
 }
 
-// Close closes the listener and shutdowns the server.
+// SetMaxConns sets the maximum count of connections handled concurrently.
+// If the limit is reached, the server stops accepting new connections until any connection is done.
+func (s *Server) SetMaxConns(max int) {
+    s.maxConns = max
+}
+
+// SetIdleTimeout sets the idle timeout of connections,
+// the connections having no data sent or received for <timeout> are closed by server.
+func (s *Server) SetIdleTimeout(timeout time.Duration) {
+    s.idleTimeout = timeout
+}
+
+// SetRecvTimeout sets the timeout for each receiving operation of connections,
+// see Conn.SetRecvTimeout.
+func (s *Server) SetRecvTimeout(timeout time.Duration) {
+    s.recvTimeout = timeout
+}
+
+// GetListenedAddress returns the address the server is listening on,
+// which is useful if the server is listening on a random port like ":0".
+func (s *Server) GetListenedAddress() string {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    if s.rawListen != nil {
+        return s.rawListen.Addr().String()
+    }
+    return ""
+}
+
+// ConnCount returns the count of live connections being handled.
+func (s *Server) ConnCount() int {
+    return s.conns.Size()
+}
+
+// IteratorConn iterates the live connections with given callback function <f>,
+// if <f> returns true then continue iterating; or false to stop.
+func (s *Server) IteratorConn(f func(conn *Conn) bool) {
+    for _, v := range s.conns.Slice() {
+        if !f(v.(*Conn)) {
+            break
+        }
+    }
+}
+
+// Broadcast sends <data> to all live connections, it returns the last error if any sending fails.
+func (s *Server) Broadcast(data []byte, retry...Retry) (err error) {
+    s.IteratorConn(func(conn *Conn) bool {
+        if e := conn.Send(data, retry...); e != nil {
+            err = e
+        }
+        return true
+    })
+    return
+}
+
+// BroadcastPkg sends <data> to all live connections using simple package protocol,
+// it returns the last error if any sending fails.
+func (s *Server) BroadcastPkg(data []byte, option...PkgOption) (err error) {
+    s.IteratorConn(func(conn *Conn) bool {
+        if e := conn.SendPkg(data, option...); e != nil {
+            err = e
+        }
+        return true
+    })
+    return
+}
+
+// Close closes the listener and all live connections immediately.
 func (s *Server) Close() error {
-	return s.listen.Close()
+    err := s.stop()
+    s.IteratorConn(func(conn *Conn) bool {
+        conn.Close()
+        return true
+    })
+    return err
+}
+
+// Shutdown gracefully shuts down the server, it closes the listener,
+// and waits for all running handlers to finish.
+// If <ctx> is done before that, it closes all live connections, and returns the error of <ctx>.
+func (s *Server) Shutdown(ctx context.Context) error {
+    err  := s.stop()
+    done := make(chan struct{})
+    go func() {
+        s.wg.Wait()
+        close(done)
+    }()
+    select {
+        case <-done:
+            return err
+        case <-ctx.Done():
+            s.IteratorConn(func(conn *Conn) bool {
+                conn.Close()
+                return true
+            })
+            return ctx.Err()
+    }
+}
+
+// stop marks the server closed and closes the listener.
+func (s *Server) stop() error {
+    s.mu.Lock()
+    if s.closed.Set(true) {
+        s.mu.Unlock()
+        return nil
+    }
+    close(s.done)
+    listen := s.listen
+    s.mu.Unlock()
+    if listen == nil {
+        return nil
+    }
+    if v := runningServers.Get(s.gracefulKey()); v == s {
+        runningServers.Remove(s.gracefulKey())
+    }
+    return listen.Close()
 }
 
 // Run starts running the TCP Server.
+// It returns nil if the server is closed by Close or Shutdown.
 func (s *Server) Run() (err error) {
     if s.handler == nil {
         err = errors.New("start running failed: socket handler not defined")
         glog.Error(err)
         return
     }
-    if s.tlsConfig != nil {
-    	// TLS Server
-	    s.listen, err = tls.Listen("tcp", s.address, s.tlsConfig)
-	    if err != nil {
-	    	glog.Error(err)
-	    	return
-	    }
-    } else {
-    	// Normal Server
-	    addr, err := net.ResolveTCPAddr("tcp", s.address)
-	    if err != nil {
-		    glog.Error(err)
-		    return err
-	    }
-	    s.listen, err = net.ListenTCP("tcp", addr)
-	    if err != nil {
-		    glog.Error(err)
-		    return err
-	    }
+    if err = s.doListen(); err != nil {
+        glog.Error(err)
+        return
+    }
+    if s.idleTimeout > 0 {
+        go s.checkIdle()
+    }
+    // Semaphore limiting the count of connections.
+    sem := (chan struct{})(nil)
+    if s.maxConns > 0 {
+        sem = make(chan struct{}, s.maxConns)
     }
     for {
-        if conn, err := s.listen.Accept(); err != nil {
+        if sem != nil {
+            select {
+                case sem <- struct{}{}:
+                case <-s.done:
+                    return nil
+            }
+        }
+        conn, err := s.listen.Accept()
+        if err != nil {
+            if s.closed.Val() {
+                return nil
+            }
             glog.Error(err)
             return err
-        } else if conn != nil {
-            go s.handler(NewConnByNetConn(conn))
         }
+        s.mu.Lock()
+        if s.closed.Val() {
+            s.mu.Unlock()
+            conn.Close()
+            return nil
+        }
+        s.wg.Add(1)
+        s.mu.Unlock()
+        go s.serve(NewConnByNetConn(conn), sem)
+    }
+}
+
+// doListen creates the listener, or uses the listener inherited from parent process in graceful restart.
+func (s *Server) doListen() error {
+    ln, err := getInheritedListener(s.gracefulKey())
+    if err != nil {
+        return err
+    }
+    if ln == nil {
+        addr, err := net.ResolveTCPAddr("tcp", s.address)
+        if err != nil {
+            return err
+        }
+        if ln, err = net.ListenTCP("tcp", addr); err != nil {
+            return err
+        }
+    }
+    s.mu.Lock()
+    if s.closed.Val() {
+        s.mu.Unlock()
+        ln.Close()
+        return errors.New("server is closed")
+    }
+    s.rawListen = ln
+    if s.tlsConfig != nil {
+        s.listen = tls.NewListener(ln, s.tlsConfig)
+    } else {
+        s.listen = ln
+    }
+    s.mu.Unlock()
+    runningServers.Set(s.gracefulKey(), s)
+    // The server may be closed concurrently.
+    if s.closed.Val() {
+        runningServers.Remove(s.gracefulKey())
+    }
+    return nil
+}
+
+// serve handles the connection using server handler, and unregisters it after that.
+func (s *Server) serve(conn *Conn, sem chan struct{}) {
+    s.conns.Add(conn)
+    defer func() {
+        s.conns.Remove(conn)
+        if sem != nil {
+            <-sem
+        }
+        s.wg.Done()
+    }()
+    if s.recvTimeout > 0 {
+        conn.SetRecvTimeout(s.recvTimeout)
+    }
+    s.handler(conn)
+}
+
+// checkIdle closes the idle connections periodically until the server is closed.
+func (s *Server) checkIdle() {
+    interval := s.idleTimeout/2
+    if interval < gMIN_IDLE_CHECK_INTERVAL {
+        interval = gMIN_IDLE_CHECK_INTERVAL
+    }
+    ticker := time.NewTicker(interval)
+    defer ticker.Stop()
+    for {
+        select {
+            case <-s.done:
+                return
+            case <-ticker.C:
+        }
+        s.IteratorConn(func(conn *Conn) bool {
+            if time.Since(conn.LastActive()) > s.idleTimeout {
+                conn.Close()
+            }
+            return true
+        })
     }
 }
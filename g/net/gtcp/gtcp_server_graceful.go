@@ -0,0 +1,132 @@
+// Copyright 2019 gf Author(https://github.com/gogf/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package gtcp
+
+import (
+    "context"
+    "encoding/json"
+    "fmt"
+    "github.com/gogf/gf/g/container/gmap"
+    "net"
+    "os"
+    "sync"
+)
+
+const (
+    // Environment variable passing the inherited listener descriptors to child process,
+    // which is a JSON object mapping server key to descriptor number.
+    gRELOAD_ENVKEY = "GF_TCP_SERVER_RELOAD"
+)
+
+var (
+    // Running servers, key => *Server, in which the key is the server name or listening address.
+    runningServers = gmap.NewStrAnyMap()
+    // Listener descriptors inherited from parent process, key => descriptor number.
+    inheritedFds   map[string]int
+    inheritedMu    sync.Mutex
+)
+
+func init() {
+    // The environment variable is removed after parsing, so it's never passed to the processes forked
+    // by current process with os.Environ(), in which the descriptors are invalid.
+    if v := os.Getenv(gRELOAD_ENVKEY); v != "" {
+        json.Unmarshal([]byte(v), &inheritedFds)
+    }
+    os.Unsetenv(gRELOAD_ENVKEY)
+}
+
+// gracefulKey returns the key identifying the server listener in graceful restart,
+// which is the server name, or the listening address if the server has no name.
+func (s *Server) gracefulKey() string {
+    if s.name != "" {
+        return s.name
+    }
+    return s.address
+}
+
+// ReloadFiles returns the listener files of all running servers and the environment variable
+// describing them, which are passed to child process in graceful restart, like ghttp does.
+// The child process inherits the listeners automatically when the servers with the same name
+// (or the same address for servers without name) run.
+//
+// The parameter <fd> is the descriptor number of the first returned file in child process,
+// which is 3 + len(exec.Cmd.ExtraFiles) before appending the files.
+// The returned files are duplicates of the listeners, which should be closed by the caller
+// after the child process starts.
+func ReloadFiles(fd int) (files []*os.File, env string) {
+    fds := make(map[string]int)
+    for key, s := range getRunningServers() {
+        s.mu.Lock()
+        ln, ok := s.rawListen.(*net.TCPListener)
+        s.mu.Unlock()
+        if !ok {
+            continue
+        }
+        if file, err := ln.File(); err == nil {
+            fds[key] = fd + len(files)
+            files    = append(files, file)
+        }
+    }
+    if len(files) > 0 {
+        b, _ := json.Marshal(fds)
+        env   = gRELOAD_ENVKEY + "=" + string(b)
+    }
+    return
+}
+
+// ShutdownAll gracefully shuts down all running servers, see Server.Shutdown.
+func ShutdownAll(ctx context.Context) (err error) {
+    wg := sync.WaitGroup{}
+    mu := sync.Mutex{}
+    for _, s := range getRunningServers() {
+        wg.Add(1)
+        go func(s *Server) {
+            defer wg.Done()
+            if e := s.Shutdown(ctx); e != nil {
+                mu.Lock()
+                err = e
+                mu.Unlock()
+            }
+        }(s)
+    }
+    wg.Wait()
+    return
+}
+
+// getRunningServers returns a copy of the running servers.
+func getRunningServers() map[string]*Server {
+    servers := make(map[string]*Server)
+    runningServers.RLockFunc(func(m map[string]interface{}) {
+        for k, v := range m {
+            servers[k] = v.(*Server)
+        }
+    })
+    return servers
+}
+
+// getInheritedListener returns the listener inherited from parent process for <key>,
+// it returns nil if there's no such listener. Each inherited listener can be used only once.
+func getInheritedListener(key string) (*net.TCPListener, error) {
+    inheritedMu.Lock()
+    fd, ok := inheritedFds[key]
+    delete(inheritedFds, key)
+    inheritedMu.Unlock()
+    if !ok || fd <= 0 {
+        return nil, nil
+    }
+    file := os.NewFile(uintptr(fd), "")
+    defer file.Close()
+    ln, err := net.FileListener(file)
+    if err != nil {
+        return nil, fmt.Errorf("inheriting listener of %s failed: %v", key, err)
+    }
+    if tcpLn, ok := ln.(*net.TCPListener); ok {
+        return tcpLn, nil
+    }
+    ln.Close()
+    return nil, fmt.Errorf("inherited listener of %s is not TCP listener", key)
+}
@@ -0,0 +1,164 @@
+// Copyright 2019 gf Author(https://github.com/gogf/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package gtcp_test
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/gogf/gf/g/net/gtcp"
+	"github.com/gogf/gf/g/test/gtest"
+	"testing"
+)
+
+func Test_Codec_Length(t *testing.T) {
+	gtest.Case(t, func() {
+		data := []byte("hello")
+		b, err := gtcp.NewLengthCodec(2).Encode(data, 100)
+		gtest.Assert(err, nil)
+		gtest.Assert(b, []byte{0, 5, 'h', 'e', 'l', 'l', 'o'})
+		b, err = gtcp.NewLengthCodec(4, true).Encode(data, 100)
+		gtest.Assert(err, nil)
+		gtest.Assert(b, []byte{5, 0, 0, 0, 'h', 'e', 'l', 'l', 'o'})
+
+		// Incomplete package.
+		result, n, err := gtcp.NewLengthCodec(4, true).Decode(b[:6], 100)
+		gtest.Assert(err, nil)
+		gtest.Assert(n, 0)
+		result, n, err = gtcp.NewLengthCodec(4, true).Decode(b, 100)
+		gtest.Assert(err, nil)
+		gtest.Assert(n, 9)
+		gtest.Assert(result, data)
+		_, _, err = gtcp.NewLengthCodec(4, true).Decode(b, 4)
+		gtest.AssertNE(err, nil)
+
+		_, err = gtcp.NewLengthCodec(2).Encode(data, 4)
+		gtest.AssertNE(err, nil)
+		_, err = gtcp.NewLengthCodec(2).Encode(make([]byte, 0x10000), 0x20000)
+		gtest.AssertNE(err, nil)
+		_, err = gtcp.NewLengthCodec(9).Encode(data, 100)
+		gtest.AssertNE(err, nil)
+	})
+}
+
+func Test_Codec_Others(t *testing.T) {
+	gtest.Case(t, func() {
+		data := bytes.Repeat([]byte("a"), 200)
+		b, err := gtcp.NewVarintCodec().Encode(data, 1000)
+		gtest.Assert(err, nil)
+		gtest.Assert(len(b), 202)
+		result, n, err := gtcp.NewVarintCodec().Decode(b, 1000)
+		gtest.Assert(err, nil)
+		gtest.Assert(n, 202)
+		gtest.Assert(result, data)
+
+		codec := gtcp.NewDelimiterCodec([]byte("\r\n"))
+		b, err = codec.Encode([]byte("hello"), 100)
+		gtest.Assert(err, nil)
+		gtest.Assert(string(b), "hello\r\n")
+		_, err = codec.Encode([]byte("a\r\nb"), 100)
+		gtest.AssertNE(err, nil)
+		result, n, err = codec.Decode([]byte("hello\r\nworld"), 100)
+		gtest.Assert(err, nil)
+		gtest.Assert(n, 7)
+		gtest.Assert(string(result), "hello")
+		_, n, err = codec.Decode([]byte("world"), 100)
+		gtest.Assert(err, nil)
+		gtest.Assert(n, 0)
+
+		codec = gtcp.NewChecksumCodec(gtcp.NewLengthCodec(2))
+		b, err = codec.Encode([]byte("hello"), 100)
+		gtest.Assert(err, nil)
+		gtest.Assert(len(b), 11)
+		result, n, err = codec.Decode(b, 100)
+		gtest.Assert(err, nil)
+		gtest.Assert(n, 11)
+		gtest.Assert(string(result), "hello")
+		b[2] = 'H'
+		_, n, err = codec.Decode(b, 100)
+		gtest.AssertNE(err, nil)
+		gtest.Assert(n, 11)
+	})
+}
+
+func Test_Conn_Pkg_Codec(t *testing.T) {
+	options := []gtcp.PkgOption{
+		{},
+		{Codec: gtcp.NewLengthCodec(2, true)},
+		{Codec: gtcp.NewLengthCodec(8), MaxSize: 1 << 20},
+		{Codec: gtcp.NewVarintCodec(), MaxSize: 1 << 20},
+		{Codec: gtcp.NewDelimiterCodec([]byte("\n"))},
+		{Codec: gtcp.NewChecksumCodec(gtcp.NewLengthCodec(4))},
+	}
+	for _, option := range options {
+		option := option
+		gtest.Case(t, func() {
+			s := gtcp.NewServer("127.0.0.1:0", func(conn *gtcp.Conn) {
+				defer conn.Close()
+				for {
+					data, err := conn.RecvPkg(option)
+					if err != nil {
+						return
+					}
+					if err := conn.SendPkg(data, option); err != nil {
+						return
+					}
+				}
+			})
+			defer s.Close()
+			conn, err := gtcp.NewConn(startServer(s))
+			gtest.Assert(err, nil)
+			defer conn.Close()
+
+			// Pipelined packages make multiple packages in one read.
+			for i := 0; i < 1000; i++ {
+				gtest.Assert(conn.SendPkg([]byte(fmt.Sprintf("data-%d", i)), option), nil)
+			}
+			for i := 0; i < 1000; i++ {
+				data, err := conn.RecvPkg(option)
+				gtest.Assert(err, nil)
+				gtest.Assert(string(data), fmt.Sprintf("data-%d", i))
+			}
+			// Package larger than the initial read buffer.
+			if option.MaxSize > 0 {
+				data := bytes.Repeat([]byte("0123456789"), 10000)
+				result, err := conn.SendRecvPkg(data, option)
+				gtest.Assert(err, nil)
+				gtest.Assert(bytes.Equal(result, data), true)
+			}
+		})
+	}
+}
+
+func Test_Conn_Pkg_Checksum(t *testing.T) {
+	gtest.Case(t, func() {
+		option := gtcp.PkgOption{Codec: gtcp.NewChecksumCodec(gtcp.NewLengthCodec(2))}
+		errs := make(chan error, 10)
+		s := gtcp.NewServer("127.0.0.1:0", func(conn *gtcp.Conn) {
+			defer conn.Close()
+			for {
+				data, err := conn.RecvPkg(option)
+				if err != nil {
+					errs <- err
+					continue
+				}
+				conn.SendPkg(data, option)
+			}
+		})
+		defer s.Close()
+		conn, err := gtcp.NewConn(startServer(s))
+		gtest.Assert(err, nil)
+		defer conn.Close()
+
+		b, _ := option.Codec.Encode([]byte("hello"), 100)
+		b[len(b)-1]++
+		gtest.Assert(conn.Send(b), nil)
+		data, err := conn.SendRecvPkg([]byte("world"), option)
+		gtest.Assert(err, nil)
+		gtest.Assert(string(data), "world")
+		gtest.AssertNE(<-errs, nil)
+	})
+}
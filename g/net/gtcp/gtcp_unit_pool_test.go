@@ -0,0 +1,202 @@
+// Copyright 2019 gf Author(https://github.com/gogf/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package gtcp_test
+
+import (
+	"github.com/gogf/gf/g/net/gtcp"
+	"github.com/gogf/gf/g/test/gtest"
+	"testing"
+	"time"
+)
+
+func Test_ConnPool_Reuse(t *testing.T) {
+	s := gtcp.NewServer("127.0.0.1:0", echoHandler)
+	defer s.Close()
+	address := startServer(s)
+	gtest.Case(t, func() {
+		pool := gtcp.NewConnPool(address)
+		defer pool.Close()
+		conn1, err := pool.Get()
+		gtest.Assert(err, nil)
+		data, err := conn1.SendRecv([]byte("hello\n"), -1)
+		gtest.Assert(err, nil)
+		gtest.Assert(string(data), "hello\n")
+		local := conn1.LocalAddr().String()
+		gtest.Assert(conn1.Close(), nil)
+
+		conn2, err := pool.Get()
+		gtest.Assert(err, nil)
+		gtest.Assert(conn2.LocalAddr().String(), local)
+		conn2.Close()
+
+		stats := pool.Stats()
+		gtest.Assert(stats.Active, 1)
+		gtest.Assert(stats.Idle, 1)
+		gtest.Assert(stats.Hits, 1)
+		gtest.Assert(stats.Misses, 1)
+	})
+	gtest.Case(t, func() {
+		pool := gtcp.NewConnPool(address)
+		defer pool.Close()
+		conn, err := pool.Get()
+		gtest.Assert(err, nil)
+		// Closing twice puts the connection back only once.
+		gtest.Assert(conn.Close(), nil)
+		gtest.Assert(conn.Close(), nil)
+		gtest.Assert(pool.Stats().Idle, 1)
+
+		conn1, err := pool.Get()
+		gtest.Assert(err, nil)
+		conn2, err := pool.Get()
+		gtest.Assert(err, nil)
+		gtest.AssertNE(conn1.LocalAddr().String(), conn2.LocalAddr().String())
+		// The closed handle does not affect the reused connection.
+		gtest.Assert(conn.Close(), nil)
+		data, err := conn1.SendRecv([]byte("hello\n"), -1)
+		gtest.Assert(err, nil)
+		gtest.Assert(string(data), "hello\n")
+		conn1.Close()
+		conn2.Close()
+		gtest.Assert(pool.Stats().Active, 2)
+		gtest.Assert(pool.Stats().Idle, 2)
+	})
+	gtest.Case(t, func() {
+		pool := gtcp.NewConnPool(address)
+		defer pool.Close()
+		pool.SetMaxIdle(1)
+		conns := make([]*gtcp.PoolConn, 3)
+		for i := range conns {
+			conns[i], _ = pool.Get()
+		}
+		gtest.Assert(pool.Stats().Active, 3)
+		for _, conn := range conns {
+			conn.Close()
+		}
+		gtest.Assert(pool.Stats().Active, 1)
+		gtest.Assert(pool.Stats().Idle, 1)
+	})
+	gtest.Case(t, func() {
+		pool := gtcp.NewConnPool(address)
+		defer pool.Close()
+		pool.SetIdleTimeout(50 * time.Millisecond)
+		conn, _ := pool.Get()
+		conn.Close()
+		time.Sleep(100 * time.Millisecond)
+		conn, err := pool.Get()
+		gtest.Assert(err, nil)
+		conn.Close()
+		stats := pool.Stats()
+		gtest.Assert(stats.Hits, 0)
+		gtest.Assert(stats.Misses, 2)
+		gtest.Assert(stats.Discards, 1)
+	})
+}
+
+func Test_ConnPool_MaxActive(t *testing.T) {
+	s := gtcp.NewServer("127.0.0.1:0", echoHandler)
+	defer s.Close()
+	address := startServer(s)
+	gtest.Case(t, func() {
+		pool := gtcp.NewConnPool(address)
+		defer pool.Close()
+		pool.SetMaxActive(1)
+		conn, err := pool.Get()
+		gtest.Assert(err, nil)
+		_, err = pool.Get()
+		gtest.Assert(err, gtcp.ErrPoolExhausted)
+
+		pool.SetWait(true, 100 * time.Millisecond)
+		_, err = pool.Get()
+		gtest.Assert(err, gtcp.ErrPoolTimeout)
+
+		go func() {
+			time.Sleep(100 * time.Millisecond)
+			conn.Close()
+		}()
+		pool.SetWait(true)
+		conn, err = pool.Get()
+		gtest.Assert(err, nil)
+
+		stats := pool.Stats()
+		gtest.Assert(stats.Active, 1)
+		gtest.Assert(stats.Waits, 2)
+		gtest.Assert(stats.Timeouts, 1)
+
+		// Blocking acquiring returns error if the pool is closed.
+		go func() {
+			time.Sleep(100 * time.Millisecond)
+			pool.Close()
+		}()
+		_, err = pool.Get()
+		gtest.Assert(err, gtcp.ErrPoolClosed)
+		// The connection in use is closed when it's returned to the closed pool.
+		conn.Close()
+		gtest.Assert(pool.Stats().Active, 0)
+	})
+}
+
+func Test_ConnPool_TestOnBorrow(t *testing.T) {
+	// The server closes the connection if "bye" is received.
+	s := gtcp.NewServer("127.0.0.1:0", func(conn *gtcp.Conn) {
+		defer conn.Close()
+		for {
+			data, err := conn.RecvLine()
+			if err != nil || string(data) == "bye" {
+				return
+			}
+			conn.Send(append(data, '\n'))
+		}
+	})
+	defer s.Close()
+	address := startServer(s)
+	gtest.Case(t, func() {
+		pool := gtcp.NewConnPool(address)
+		defer pool.Close()
+		pool.SetTestOnBorrow(gtcp.ProbeConn)
+		conn, err := pool.Get()
+		gtest.Assert(err, nil)
+		gtest.Assert(conn.Send([]byte("bye\n")), nil)
+		conn.Close()
+		time.Sleep(100 * time.Millisecond)
+
+		conn, err = pool.Get()
+		gtest.Assert(err, nil)
+		data, err := conn.SendRecv([]byte("hello\n"), -1)
+		gtest.Assert(err, nil)
+		gtest.Assert(string(data), "hello\n")
+		conn.Close()
+		stats := pool.Stats()
+		gtest.Assert(stats.Misses, 2)
+		gtest.Assert(stats.Discards, 1)
+
+		// The healthy connection passes the probe and is reused.
+		conn, err = pool.Get()
+		gtest.Assert(err, nil)
+		data, err = conn.SendRecv([]byte("world\n"), -1)
+		gtest.Assert(err, nil)
+		gtest.Assert(string(data), "world\n")
+		conn.Close()
+		gtest.Assert(pool.Stats().Hits, 1)
+	})
+}
+
+func Test_ConnPool_Default(t *testing.T) {
+	s := gtcp.NewServer("127.0.0.1:0", echoHandler)
+	defer s.Close()
+	address := startServer(s)
+	gtest.Case(t, func() {
+		conn, err := gtcp.NewPoolConn(address)
+		gtest.Assert(err, nil)
+		conn.Close()
+		pool := gtcp.GetConnPool(address)
+		gtest.Assert(pool.Stats().Idle, 1)
+		pool.Close()
+		// A new default pool is created after the former one is closed.
+		gtest.AssertNE(gtcp.GetConnPool(address), pool)
+		gtcp.GetConnPool(address).Close()
+	})
+}
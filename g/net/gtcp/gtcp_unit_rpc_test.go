@@ -0,0 +1,158 @@
+// Copyright 2019 gf Author(https://github.com/gogf/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package gtcp_test
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gogf/gf/g/net/gtcp"
+	"github.com/gogf/gf/g/test/gtest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type rpcUser struct {
+	Id   int
+	Name string
+}
+
+// startRpcServer runs an RPC server with methods for testing and returns it with its listening address.
+func startRpcServer() (*gtcp.RpcServer, string) {
+	s := gtcp.NewRpcServer("127.0.0.1:0")
+	s.Register("echo", func(r *gtcp.RpcRequest) (interface{}, error) {
+		var data []byte
+		err := r.Parse(&data)
+		return data, err
+	})
+	s.Register("upper", func(r *gtcp.RpcRequest) (interface{}, error) {
+		user := new(rpcUser)
+		if err := r.Parse(user); err != nil {
+			return nil, err
+		}
+		user.Name = strings.ToUpper(user.Name)
+		return user, nil
+	})
+	s.Register("sleep", func(r *gtcp.RpcRequest) (interface{}, error) {
+		var ms int
+		if err := r.Parse(&ms); err != nil {
+			return nil, err
+		}
+		time.Sleep(time.Duration(ms) * time.Millisecond)
+		return ms, nil
+	})
+	s.Register("error", func(r *gtcp.RpcRequest) (interface{}, error) {
+		return nil, errors.New("handler error")
+	})
+	s.Register("panic", func(r *gtcp.RpcRequest) (interface{}, error) {
+		panic("handler panic")
+	})
+	return s, startServer(s.Server)
+}
+
+func Test_Rpc_Call(t *testing.T) {
+	s, address := startRpcServer()
+	defer s.Close()
+	gtest.Case(t, func() {
+		client := gtcp.NewRpcClient(address)
+		defer client.Close()
+		user := new(rpcUser)
+		gtest.Assert(client.Call("upper", &rpcUser{1, "john"}, user), nil)
+		gtest.Assert(user.Id, 1)
+		gtest.Assert(user.Name, "JOHN")
+
+		err := client.Call("error", nil, nil)
+		gtest.AssertNE(err, nil)
+		gtest.Assert(err.Error(), "handler error")
+		err = client.Call("panic", nil, nil)
+		gtest.AssertNE(err, nil)
+		gtest.Assert(strings.Contains(err.Error(), "handler panic"), true)
+		err = client.Call("none", nil, nil)
+		gtest.AssertNE(err, nil)
+
+		// The connections are still usable after errors.
+		gtest.Assert(client.Call("upper", &rpcUser{2, "smith"}, user), nil)
+		gtest.Assert(user.Name, "SMITH")
+	})
+	gtest.Case(t, func() {
+		client := gtcp.NewRpcClient(address)
+		client.SetEncoding(gtcp.RPC_ENCODING_BINARY)
+		defer client.Close()
+		result := ""
+		gtest.Assert(client.Call("echo", "hello", &result), nil)
+		gtest.Assert(result, "hello")
+		ms := 0
+		gtest.Assert(client.Call("sleep", 10, &ms), nil)
+		gtest.Assert(ms, 10)
+	})
+}
+
+func Test_Rpc_Concurrent(t *testing.T) {
+	s, address := startRpcServer()
+	defer s.Close()
+	gtest.Case(t, func() {
+		client := gtcp.NewRpcClient(address, 1)
+		defer client.Close()
+		// All the calls are in flight concurrently on one connection,
+		// so it costs about the time of one call.
+		wg    := sync.WaitGroup{}
+		start := time.Now()
+		errs  := make(chan error, 100)
+		for i := 0; i < 100; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				ms := 0
+				if err := client.Call("sleep", 100 + i%10, &ms); err != nil {
+					errs <- err
+				} else if ms != 100 + i%10 {
+					errs <- fmt.Errorf("unexpected result %d", ms)
+				}
+			}(i)
+		}
+		wg.Wait()
+		close(errs)
+		for err := range errs {
+			gtest.Assert(err, nil)
+		}
+		gtest.Assert(time.Since(start) < time.Second, true)
+	})
+}
+
+func Test_Rpc_Timeout(t *testing.T) {
+	s, address := startRpcServer()
+	defer s.Close()
+	gtest.Case(t, func() {
+		client := gtcp.NewRpcClient(address)
+		defer client.Close()
+		client.SetTimeout(50 * time.Millisecond)
+		gtest.AssertNE(client.Call("sleep", 200, nil), nil)
+		ms := 0
+		gtest.Assert(client.CallWithTimeout("sleep", 100, &ms, time.Second), nil)
+		gtest.Assert(ms, 100)
+		gtest.Assert(client.Call("sleep", 0, &ms), nil)
+		gtest.Assert(ms, 0)
+	})
+}
+
+func Test_Rpc_Close(t *testing.T) {
+	s, address := startRpcServer()
+	gtest.Case(t, func() {
+		client := gtcp.NewRpcClient(address)
+		gtest.Assert(client.Call("sleep", 0, nil), nil)
+		// The calls in flight fail if the server is closed.
+		go func() {
+			time.Sleep(100 * time.Millisecond)
+			s.Close()
+		}()
+		gtest.AssertNE(client.Call("sleep", 500, nil), nil)
+
+		gtest.Assert(client.Close(), nil)
+		gtest.AssertNE(client.Call("sleep", 0, nil), nil)
+	})
+}
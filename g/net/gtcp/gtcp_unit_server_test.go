@@ -0,0 +1,150 @@
+// Copyright 2019 gf Author(https://github.com/gogf/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package gtcp_test
+
+import (
+	"context"
+	"github.com/gogf/gf/g/net/gtcp"
+	"github.com/gogf/gf/g/test/gtest"
+	"testing"
+	"time"
+)
+
+// echoHandler sends back each line received.
+func echoHandler(conn *gtcp.Conn) {
+	defer conn.Close()
+	for {
+		data, err := conn.RecvLine()
+		if err != nil {
+			return
+		}
+		if err := conn.Send(append(data, '\n')); err != nil {
+			return
+		}
+	}
+}
+
+// startServer runs the server asynchronously and returns its listening address.
+func startServer(s *gtcp.Server) string {
+	go s.Run()
+	for i := 0; i < 100; i++ {
+		if address := s.GetListenedAddress(); address != "" {
+			return address
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return ""
+}
+
+func Test_Server_Conns(t *testing.T) {
+	gtest.Case(t, func() {
+		s := gtcp.NewServer("127.0.0.1:0", echoHandler)
+		address := startServer(s)
+		conns := make([]*gtcp.Conn, 0)
+		for i := 0; i < 3; i++ {
+			conn, err := gtcp.NewConn(address)
+			gtest.Assert(err, nil)
+			conns = append(conns, conn)
+		}
+		time.Sleep(100 * time.Millisecond)
+		gtest.Assert(s.ConnCount(), 3)
+		count := 0
+		s.IteratorConn(func(conn *gtcp.Conn) bool {
+			count++
+			return true
+		})
+		gtest.Assert(count, 3)
+
+		gtest.Assert(s.Broadcast([]byte("hello\n")), nil)
+		for _, conn := range conns {
+			data, err := conn.RecvLine()
+			gtest.Assert(err, nil)
+			gtest.Assert(string(data), "hello")
+		}
+
+		for _, conn := range conns {
+			conn.Close()
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		gtest.Assert(s.Shutdown(ctx), nil)
+		gtest.Assert(s.ConnCount(), 0)
+		_, err := gtcp.NewConn(address)
+		gtest.AssertNE(err, nil)
+	})
+}
+
+func Test_Server_Shutdown_Timeout(t *testing.T) {
+	gtest.Case(t, func() {
+		s := gtcp.NewServer("127.0.0.1:0", echoHandler)
+		address := startServer(s)
+		conn, err := gtcp.NewConn(address)
+		gtest.Assert(err, nil)
+		defer conn.Close()
+		time.Sleep(100 * time.Millisecond)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+		gtest.Assert(s.Shutdown(ctx), context.DeadlineExceeded)
+		// The connection is closed by server.
+		_, err = conn.RecvLine()
+		gtest.AssertNE(err, nil)
+	})
+}
+
+func Test_Server_MaxConns(t *testing.T) {
+	gtest.Case(t, func() {
+		s := gtcp.NewServer("127.0.0.1:0", echoHandler)
+		s.SetMaxConns(1)
+		address := startServer(s)
+		defer s.Close()
+
+		conn1, err := gtcp.NewConn(address)
+		gtest.Assert(err, nil)
+		data, err := conn1.SendRecv([]byte("1\n"), -1)
+		gtest.Assert(err, nil)
+		gtest.Assert(string(data), "1\n")
+
+		conn2, err := gtcp.NewConn(address)
+		gtest.Assert(err, nil)
+		defer conn2.Close()
+		gtest.Assert(conn2.Send([]byte("2\n")), nil)
+		_, err = conn2.RecvWithTimeout(-1, 200*time.Millisecond)
+		gtest.AssertNE(err, nil)
+		gtest.Assert(s.ConnCount(), 1)
+
+		conn1.Close()
+		data, err = conn2.RecvWithTimeout(-1, time.Second)
+		gtest.Assert(err, nil)
+		gtest.Assert(string(data), "2\n")
+	})
+}
+
+func Test_Server_Timeout(t *testing.T) {
+	gtest.Case(t, func() {
+		s1 := gtcp.NewServer("127.0.0.1:0", echoHandler)
+		s1.SetIdleTimeout(200 * time.Millisecond)
+		s2 := gtcp.NewServer("127.0.0.1:0", echoHandler)
+		s2.SetRecvTimeout(200 * time.Millisecond)
+		defer s1.Close()
+		defer s2.Close()
+
+		for _, s := range []*gtcp.Server{s1, s2} {
+			conn, err := gtcp.NewConn(startServer(s))
+			gtest.Assert(err, nil)
+			data, err := conn.SendRecv([]byte("1\n"), -1)
+			gtest.Assert(err, nil)
+			gtest.Assert(string(data), "1\n")
+			gtest.Assert(s.ConnCount(), 1)
+			time.Sleep(500 * time.Millisecond)
+			gtest.Assert(s.ConnCount(), 0)
+			_, err = conn.Recv(-1)
+			gtest.AssertNE(err, nil)
+			conn.Close()
+		}
+	})
+}
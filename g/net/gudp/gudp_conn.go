@@ -55,7 +55,8 @@ func NewConnByNetConn(udp *net.UDPConn) *Conn {
 // 发送数据
 func (c *Conn) Send(data []byte, retry...Retry) (err error) {
     for {
-        if c.raddr != nil {
+        // 已连接的链接(例如通过NewConn创建)只能使用Write发送数据
+        if c.raddr != nil && c.conn.RemoteAddr() == nil {
             _, err = c.conn.WriteToUDP(data, c.raddr)
         } else {
             _, err = c.conn.Write(data)
@@ -8,24 +8,42 @@
 package gudp
 
 import (
+    "github.com/gogf/gf/g/container/gtype"
     "github.com/gogf/gf/g/os/glog"
+    "github.com/gogf/gf/g/os/grpool"
     "net"
     "errors"
     "github.com/gogf/gf/g/container/gmap"
     "github.com/gogf/gf/g/util/gconv"
+    "sync"
+    "time"
 )
 
 const (
-    gDEFAULT_SERVER = "default"
+    gDEFAULT_SERVER          = "default"
+    gDEFAULT_MAX_PACKET_SIZE = 65507 // (byte)默认允许的最大数据包大小，即IPv4下UDP数据包的最大数据长度
 )
 
-// tcp server结构体
+// udp server结构体
 type Server struct {
-	conn    *Conn        // UDP server connection object.
-    address string       // Listening address.
-    handler func (*Conn)
+    mu             sync.Mutex
+	conn           *Conn           // UDP server connection object.
+    address        string          // Listening address.
+    handler        func (*Conn)
+    packetHandler  PacketHandler   // 数据包处理方法，设置后由Server读取数据包并分发给处理方法
+    pool           *grpool.Pool    // 执行数据包处理方法的goroutine池
+    wg             sync.WaitGroup  // 数据包读取循环以及执行中的处理方法
+    maxPacketSize  int             // (byte)允许的最大数据包大小，超过该大小的数据包将被丢弃
+    sessions       *gmap.StrAnyMap // 对端地址 => *Session
+    sessionTimeout time.Duration   // 会话的空闲过期时间，大于0时启用会话
+    sessionClose   func(*Session)  // 会话关闭时的回调方法
+    closed         *gtype.Bool     // Server是否已关闭
+    done           chan struct{}   // Server关闭时关闭
 }
 
+// 数据包处理方法，pkt为接收到的数据包，from为对端地址，reply用于向对端回复数据包
+type PacketHandler func(pkt []byte, from *net.UDPAddr, reply func([]byte) error)
+
 // Server表，用以存储和检索名称与Server对象之间的关联关系
 var serverMapping = gmap.NewStrAnyMap()
 
@@ -47,8 +65,13 @@ func GetServer(name...interface{}) *Server {
 // 创建一个tcp server对象，并且可以选择指定一个单例名字
 func NewServer(address string, handler func (*Conn), names...string) *Server {
     s := &Server{
-    	address : address,
-    	handler : handler,
+    	address       : address,
+    	handler       : handler,
+        pool          : grpool.New(),
+        maxPacketSize : gDEFAULT_MAX_PACKET_SIZE,
+        sessions      : gmap.NewStrAnyMap(),
+        closed        : gtype.NewBool(),
+        done          : make(chan struct{}),
     }
     if len(names) > 0 {
         serverMapping.Set(names[0], s)
@@ -66,15 +89,65 @@ func (s *Server) SetHandler(handler func (*Conn)) {
     s.handler = handler
 }
 
+// 创建一个使用数据包处理方法的udp server对象，并且可以选择指定一个单例名字，
+// 数据包由Server读取并分发到goroutine池中执行处理方法
+func NewPacketServer(address string, handler PacketHandler, names...string) *Server {
+    s := NewServer(address, nil, names...)
+    s.SetPacketHandler(handler)
+    return s
+}
+
+// 设置参数 - 数据包处理方法，设置后handler参数将不再生效
+func (s *Server) SetPacketHandler(handler PacketHandler) {
+    s.packetHandler = handler
+}
+
+// 设置参数 - 执行数据包处理方法的最大goroutine数量，默认不限制，需要在Run之前设置
+func (s *Server) SetWorkers(limit int) {
+    s.pool = grpool.New(limit)
+}
+
+// 设置参数 - 允许的最大数据包大小(byte)，默认为65507，超过该大小的数据包将被丢弃
+func (s *Server) SetMaxPacketSize(size int) {
+    s.maxPacketSize = size
+}
+
+// 获取监听的地址，Server未运行时返回空字符串
+func (s *Server) GetListenedAddress() string {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    if s.conn == nil {
+        return ""
+    }
+    return s.conn.LocalAddr().String()
+}
+
 // Close closes the connection.
 // It will make server shutdowns immediately.
+// In packet handler mode, it waits until the running handlers are done,
+// and then closes all the sessions.
 func (s *Server) Close() error {
-	return s.conn.Close()
+    if s.closed.Set(true) {
+        return nil
+    }
+    close(s.done)
+    s.mu.Lock()
+    conn := s.conn
+    s.mu.Unlock()
+    if conn == nil {
+        return nil
+    }
+    err := conn.Close()
+    s.wg.Wait()
+    for _, session := range s.Sessions() {
+        session.Close()
+    }
+    return err
 }
 
 // 执行监听
 func (s *Server) Run() error {
-    if s.handler == nil {
+    if s.handler == nil && s.packetHandler == nil {
         err := errors.New("start running failed: socket handler not defined")
         glog.Error(err)
         return err
@@ -89,7 +162,59 @@ func (s *Server) Run() error {
         glog.Error(err)
         return err
     }
+    s.mu.Lock()
+    if s.closed.Val() {
+        s.mu.Unlock()
+        return conn.Close()
+    }
     s.conn = NewConnByNetConn(conn)
+    if s.packetHandler != nil {
+        s.wg.Add(1)
+    }
+    s.mu.Unlock()
+    if s.packetHandler != nil {
+        return s.serve()
+    }
     s.handler(s.conn)
     return nil
 }
+
+// 读取数据包并分发到goroutine池中执行处理方法，直到Server关闭
+func (s *Server) serve() error {
+    defer s.wg.Done()
+    if s.sessionTimeout > 0 {
+        go s.checkSessions()
+    }
+    buffer := make([]byte, s.maxPacketSize + 1)
+    for {
+        n, from, err := s.conn.conn.ReadFromUDP(buffer)
+        if err != nil {
+            if s.closed.Val() {
+                return nil
+            }
+            if netErr, ok := err.(net.Error); ok && netErr.Temporary() {
+                continue
+            }
+            glog.Error(err)
+            return err
+        }
+        // 数据包超过允许的最大大小时丢弃
+        if n > s.maxPacketSize {
+            continue
+        }
+        pkt := make([]byte, n)
+        copy(pkt, buffer)
+        if s.sessionTimeout > 0 {
+            s.getOrNewSession(from).touch()
+        }
+        reply := func(data []byte) error {
+            _, err := s.conn.conn.WriteToUDP(data, from)
+            return err
+        }
+        s.wg.Add(1)
+        s.pool.Add(func() {
+            defer s.wg.Done()
+            s.packetHandler(pkt, from, reply)
+        })
+    }
+}
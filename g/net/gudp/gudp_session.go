@@ -0,0 +1,147 @@
+// Copyright 2019 gf Author(https://github.com/gogf/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package gudp
+
+import (
+    "github.com/gogf/gf/g/container/gmap"
+    "github.com/gogf/gf/g/container/gtype"
+    "net"
+    "time"
+)
+
+// 对端会话对象，在数据包处理模式下启用会话后，Server为每个对端地址维护一个会话，
+// 会话在一段时间内没有收到数据包后过期关闭
+type Session struct {
+    server  *Server
+    addr    *net.UDPAddr    // 对端地址
+    created time.Time       // 创建时间
+    active  *gtype.Int64    // (纳秒)最近一次收到数据包的时间
+    data    *gmap.StrAnyMap // 会话数据
+    closed  *gtype.Bool     // 会话是否已关闭
+}
+
+// 会话过期检查的最小时间间隔
+const gMIN_SESSION_CHECK_INTERVAL = 100 * time.Millisecond
+
+// 设置参数 - 会话的空闲过期时间，大于0时启用会话，需要在Run之前设置；
+// onClose为可选的会话关闭回调方法，会话过期或者Server关闭时调用
+func (s *Server) SetSessionTimeout(timeout time.Duration, onClose...func(*Session)) {
+    s.sessionTimeout = timeout
+    if len(onClose) > 0 {
+        s.sessionClose = onClose[0]
+    }
+}
+
+// 获取对端地址对应的会话，未启用会话或者会话不存在时返回nil
+func (s *Server) Session(addr *net.UDPAddr) *Session {
+    if v := s.sessions.Get(addr.String()); v != nil {
+        return v.(*Session)
+    }
+    return nil
+}
+
+// 获取所有的会话
+func (s *Server) Sessions() []*Session {
+    values   := s.sessions.Values()
+    sessions := make([]*Session, len(values))
+    for i, v := range values {
+        sessions[i] = v.(*Session)
+    }
+    return sessions
+}
+
+// 获取会话数量
+func (s *Server) SessionCount() int {
+    return s.sessions.Size()
+}
+
+// 获取对端地址对应的会话，不存在时创建
+func (s *Server) getOrNewSession(addr *net.UDPAddr) *Session {
+    return s.sessions.GetOrSetFuncLock(addr.String(), func() interface{} {
+        return &Session {
+            server  : s,
+            addr    : addr,
+            created : time.Now(),
+            active  : gtype.NewInt64(time.Now().UnixNano()),
+            data    : gmap.NewStrAnyMap(),
+            closed  : gtype.NewBool(),
+        }
+    }).(*Session)
+}
+
+// 定时关闭过期的会话，直到Server关闭
+func (s *Server) checkSessions() {
+    interval := s.sessionTimeout/2
+    if interval < gMIN_SESSION_CHECK_INTERVAL {
+        interval = gMIN_SESSION_CHECK_INTERVAL
+    }
+    ticker := time.NewTicker(interval)
+    defer ticker.Stop()
+    for {
+        select {
+            case <-s.done:
+                return
+            case <-ticker.C:
+        }
+        for _, session := range s.Sessions() {
+            if time.Since(session.LastActive()) > s.sessionTimeout {
+                session.Close()
+            }
+        }
+    }
+}
+
+// 对端地址
+func (s *Session) Addr() *net.UDPAddr {
+    return s.addr
+}
+
+// 会话创建时间
+func (s *Session) Created() time.Time {
+    return s.created
+}
+
+// 最近一次收到数据包的时间
+func (s *Session) LastActive() time.Time {
+    return time.Unix(0, s.active.Val())
+}
+
+// 向对端发送数据包
+func (s *Session) Send(data []byte) error {
+    _, err := s.server.conn.conn.WriteToUDP(data, s.addr)
+    return err
+}
+
+// 获取会话数据
+func (s *Session) Get(key string) interface{} {
+    return s.data.Get(key)
+}
+
+// 设置会话数据
+func (s *Session) Set(key string, value interface{}) {
+    s.data.Set(key, value)
+}
+
+// 关闭会话，该对端再次发送数据包时将创建新的会话
+func (s *Session) Close() {
+    if s.closed.Set(true) {
+        return
+    }
+    s.server.sessions.LockFunc(func(m map[string]interface{}) {
+        if m[s.addr.String()] == s {
+            delete(m, s.addr.String())
+        }
+    })
+    if s.server.sessionClose != nil {
+        s.server.sessionClose(s)
+    }
+}
+
+// 收到数据包时更新活跃时间
+func (s *Session) touch() {
+    s.active.Set(time.Now().UnixNano())
+}
@@ -0,0 +1,111 @@
+// Copyright 2019 gf Author(https://github.com/gogf/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package gudp_test
+
+import (
+	"github.com/gogf/gf/g/container/gtype"
+	"github.com/gogf/gf/g/net/gudp"
+	"github.com/gogf/gf/g/test/gtest"
+	"net"
+	"testing"
+	"time"
+)
+
+// startServer runs the server asynchronously and returns its listening address.
+func startServer(s *gudp.Server) string {
+	go s.Run()
+	for i := 0; i < 100; i++ {
+		if address := s.GetListenedAddress(); address != "" {
+			return address
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return ""
+}
+
+func Test_Server_Packet(t *testing.T) {
+	gtest.Case(t, func() {
+		s := gudp.NewPacketServer("127.0.0.1:0", func(pkt []byte, from *net.UDPAddr, reply func([]byte) error) {
+			reply(append([]byte("echo:"), pkt...))
+		})
+		s.SetMaxPacketSize(10)
+		address := startServer(s)
+		defer s.Close()
+
+		conn, err := gudp.NewConn(address)
+		gtest.Assert(err, nil)
+		defer conn.Close()
+		for i := 0; i < 10; i++ {
+			data, err := conn.SendRecvWithTimeout([]byte("hello"), -1, time.Second)
+			gtest.Assert(err, nil)
+			gtest.Assert(string(data), "echo:hello")
+		}
+		// The packet exceeding the max size is dropped.
+		_, err = conn.SendRecvWithTimeout([]byte("hello world"), -1, 200*time.Millisecond)
+		gtest.AssertNE(err, nil)
+	})
+}
+
+func Test_Server_Close(t *testing.T) {
+	gtest.Case(t, func() {
+		done := gtype.NewInt()
+		s    := gudp.NewPacketServer("127.0.0.1:0", func(pkt []byte, from *net.UDPAddr, reply func([]byte) error) {
+			time.Sleep(200 * time.Millisecond)
+			done.Add(1)
+		})
+		address := startServer(s)
+		for i := 0; i < 5; i++ {
+			gtest.Assert(gudp.Send(address, []byte("hello")), nil)
+		}
+		time.Sleep(100 * time.Millisecond)
+		// Close waits until the running handlers are done.
+		gtest.Assert(s.Close(), nil)
+		gtest.Assert(done.Val(), 5)
+		gtest.Assert(s.Close(), nil)
+	})
+}
+
+func Test_Server_Session(t *testing.T) {
+	gtest.Case(t, func() {
+		closed := make(chan string, 10)
+		var s *gudp.Server
+		s = gudp.NewPacketServer("127.0.0.1:0", func(pkt []byte, from *net.UDPAddr, reply func([]byte) error) {
+			session := s.Session(from)
+			count, _ := session.Get("count").(int)
+			session.Set("count", count + 1)
+			session.Send([]byte{byte(count + 1)})
+		})
+		s.SetWorkers(1)
+		s.SetSessionTimeout(200 * time.Millisecond, func(session *gudp.Session) {
+			closed <- session.Addr().String()
+		})
+		address := startServer(s)
+		defer s.Close()
+
+		conn1, _ := gudp.NewConn(address)
+		conn2, _ := gudp.NewConn(address)
+		defer conn1.Close()
+		defer conn2.Close()
+		for i := 1; i <= 3; i++ {
+			data, err := conn1.SendRecvWithTimeout([]byte("hello"), -1, time.Second)
+			gtest.Assert(err, nil)
+			gtest.Assert(data, []byte{byte(i)})
+		}
+		data, err := conn2.SendRecvWithTimeout([]byte("hello"), -1, time.Second)
+		gtest.Assert(err, nil)
+		gtest.Assert(data, []byte{1})
+		gtest.Assert(s.SessionCount(), 2)
+
+		// The sessions expire after idle timeout, and new sessions are created for later packets.
+		gtest.AssertIN(<-closed, []string{conn1.LocalAddr().String(), conn2.LocalAddr().String()})
+		gtest.AssertIN(<-closed, []string{conn1.LocalAddr().String(), conn2.LocalAddr().String()})
+		gtest.Assert(s.SessionCount(), 0)
+		data, err = conn1.SendRecvWithTimeout([]byte("hello"), -1, time.Second)
+		gtest.Assert(err, nil)
+		gtest.Assert(data, []byte{1})
+	})
+}
@@ -85,3 +85,8 @@ func Values() []interface{} {
 func Size() int {
     return cache.Size()
 }
+
+// 设置全局缓存对象的缓存适配器，例如切换为gredis.CacheAdapter实现分布式共享缓存
+func SetAdapter(adapter Adapter) {
+    cache.SetAdapter(adapter)
+}
@@ -0,0 +1,47 @@
+// Copyright 2019 gf Author(https://github.com/gogf/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package gcache
+
+// 缓存适配器接口，Cache对象的所有操作均由适配器实现。
+// 默认使用进程内存缓存适配器，也可通过SetAdapter切换为外部缓存(例如gredis.CacheAdapter)，调用端代码无需修改。
+// 过期时间参数expire单位均为**毫秒**，expire为0表示不过期。
+type Adapter interface {
+    // 设置kv缓存键值对
+    Set(key interface{}, value interface{}, expire int)
+    // 当键名不存在时写入，并返回true；否则返回false
+    SetIfNotExist(key interface{}, value interface{}, expire int) bool
+    // 批量设置kv缓存键值对
+    Sets(data map[interface{}]interface{}, expire int)
+    // 获取指定键名的值，不存在时返回nil
+    Get(key interface{}) interface{}
+    // 当键名存在时返回其键值，否则写入指定的键值
+    GetOrSet(key interface{}, value interface{}, expire int) interface{}
+    // 当键名存在时返回其键值，否则写入指定的键值，键值由指定的函数生成
+    GetOrSetFunc(key interface{}, f func() interface{}, expire int) interface{}
+    // 与GetOrSetFunc不同的是，f是在锁机制内执行(外部缓存适配器应当使用分布式锁)
+    GetOrSetFuncLock(key interface{}, f func() interface{}, expire int) interface{}
+    // 是否存在指定的键名
+    Contains(key interface{}) bool
+    // 删除指定键值对，并返回被删除的键值
+    Remove(key interface{}) interface{}
+    // 批量删除键值对
+    Removes(keys []interface{})
+    // 返回缓存的所有数据键值对(不包含已过期数据)
+    Data() map[interface{}]interface{}
+    // 获得所有的键名
+    Keys() []interface{}
+    // 获得所有的键名，组成字符串数组返回
+    KeyStrings() []string
+    // 获得所有的值
+    Values() []interface{}
+    // 获得缓存的键值对数量
+    Size() int
+    // 清空缓存中的所有数据
+    Clear()
+    // 关闭缓存适配器，释放相关资源
+    Close()
+}
@@ -7,31 +7,137 @@
 package gcache
 
 import (
-    "github.com/gogf/gf/g/os/gtimer"
-    "sync/atomic"
-    "time"
-    "unsafe"
+    "github.com/gogf/gf/g/container/gtype"
 )
 
-// 缓存对象。
-// 底层只有一个缓存对象，如果需要提高并发性能，可新增缓存对象无锁哈希表，用键名做固定分区。
+// 缓存对象，所有操作由底层的缓存适配器实现，默认为进程内存缓存。
 type Cache struct {
-    *memCache
+    adapter *gtype.Interface // 缓存适配器(Adapter)，支持运行时并发安全切换
 }
 
-// Cache对象按照缓存键名首字母做了分组
+// 创建基于进程内存的缓存对象，lruCap用于限制缓存池大小，超过大小则按照LRU算法进行缓存过期处理
 func New(lruCap...int) *Cache {
-    c := &Cache {
-        memCache : newMemCache(lruCap...),
+    return NewWithAdapter(newMemCache(lruCap...))
+}
+
+// 根据选项创建基于进程内存的缓存对象，支持缓存数量及字节数限制、LRU/LFU淘汰策略以及淘汰回调
+func NewWithOption(option Option) *Cache {
+    return NewWithAdapter(newMemCacheWithOption(option))
+}
+
+// 创建使用指定缓存适配器的缓存对象
+func NewWithAdapter(adapter Adapter) *Cache {
+    return &Cache {
+        adapter : gtype.NewInterface(adapter),
+    }
+}
+
+// 设置缓存适配器，之后的所有操作将由新的适配器执行；旧的适配器不会被关闭，由调用端决定是否关闭
+func (c *Cache) SetAdapter(adapter Adapter) {
+    c.adapter.Set(adapter)
+}
+
+// 获取当前的缓存适配器
+func (c *Cache) Adapter() Adapter {
+    return c.adapter.Val().(Adapter)
+}
+
+// 注册缓存数据项被淘汰(过期、被淘汰策略淘汰、被删除)时的回调函数，仅内存缓存适配器支持
+func (c *Cache) OnEvict(handler func(key, value interface{}, reason EvictReason)) {
+    if v, ok := c.Adapter().(interface{ OnEvict(func(key, value interface{}, reason EvictReason)) }); ok {
+        v.OnEvict(handler)
+    }
+}
+
+// 获取缓存统计信息，仅内存缓存适配器支持，其他适配器只返回缓存数量
+func (c *Cache) Stats() Stats {
+    if v, ok := c.Adapter().(interface{ Stats() Stats }); ok {
+        return v.Stats()
     }
-    gtimer.AddSingleton(time.Second, c.syncEventAndClearExpired)
-    return c
+    return Stats{Size : c.Size()}
+}
+
+// 设置kv缓存键值对，过期时间单位为**毫秒**，expire为0表示不过期
+func (c *Cache) Set(key interface{}, value interface{}, expire int) {
+    c.Adapter().Set(key, value, expire)
+}
+
+// 当键名不存在时写入，并返回true；否则返回false。
+func (c *Cache) SetIfNotExist(key interface{}, value interface{}, expire int) bool {
+    return c.Adapter().SetIfNotExist(key, value, expire)
+}
+
+// 批量设置kv缓存键值对，过期时间单位为**毫秒**
+func (c *Cache) Sets(data map[interface{}]interface{}, expire int) {
+    c.Adapter().Sets(data, expire)
+}
+
+// 获取指定键名的值
+func (c *Cache) Get(key interface{}) interface{} {
+    return c.Adapter().Get(key)
+}
+
+// 当键名存在时返回其键值，否则写入指定的键值
+func (c *Cache) GetOrSet(key interface{}, value interface{}, expire int) interface{} {
+    return c.Adapter().GetOrSet(key, value, expire)
+}
+
+// 当键名存在时返回其键值，否则写入指定的键值，键值由指定的函数生成
+func (c *Cache) GetOrSetFunc(key interface{}, f func() interface{}, expire int) interface{} {
+    return c.Adapter().GetOrSetFunc(key, f, expire)
+}
+
+// 与GetOrSetFunc不同的是，f是在写锁机制内执行
+func (c *Cache) GetOrSetFuncLock(key interface{}, f func() interface{}, expire int) interface{} {
+    return c.Adapter().GetOrSetFuncLock(key, f, expire)
+}
+
+// 是否存在指定的键名，true表示存在，false表示不存在。
+func (c *Cache) Contains(key interface{}) bool {
+    return c.Adapter().Contains(key)
+}
+
+// 删除指定键值对，并返回被删除的键值
+func (c *Cache) Remove(key interface{}) interface{} {
+    return c.Adapter().Remove(key)
+}
+
+// 批量删除键值对
+func (c *Cache) Removes(keys []interface{}) {
+    c.Adapter().Removes(keys)
+}
+
+// 返回缓存的所有数据键值对(不包含已过期数据)
+func (c *Cache) Data() map[interface{}]interface{} {
+    return c.Adapter().Data()
+}
+
+// 获得所有的键名，组成数组返回
+func (c *Cache) Keys() []interface{} {
+    return c.Adapter().Keys()
+}
+
+// 获得所有的键名，组成字符串数组返回
+func (c *Cache) KeyStrings() []string {
+    return c.Adapter().KeyStrings()
+}
+
+// 获得所有的值，组成数组返回
+func (c *Cache) Values() []interface{} {
+    return c.Adapter().Values()
+}
+
+// 获得缓存对象的键值对数量
+func (c *Cache) Size() int {
+    return c.Adapter().Size()
 }
 
 // 清空缓存中的所有数据
 func (c *Cache) Clear() {
-    // 使用原子操作替换缓存对象
-    old := atomic.SwapPointer((*unsafe.Pointer)(unsafe.Pointer(&c.memCache)), unsafe.Pointer(newMemCache()))
-    // 关闭旧的缓存对象
-    (*memCache)(old).Close()
-}
\ No newline at end of file
+    c.Adapter().Clear()
+}
+
+// 关闭缓存对象
+func (c *Cache) Close() {
+    c.Adapter().Close()
+}
@@ -15,6 +15,7 @@ import (
     "github.com/gogf/gf/g/util/gconv"
     "math"
     "sync"
+    "time"
 )
 
 
@@ -24,23 +25,49 @@ type memCache struct {
     expireTimeMu sync.RWMutex
     expireSetMu  sync.RWMutex
 
-    cap          int                            // 控制缓存池大小，超过大小则按照LRU算法进行缓存过期处理(默认为0表示不进行限制)
+    cap          int                            // 控制缓存池大小，超过大小则按照淘汰策略(默认LRU)进行缓存过期处理(默认为0表示不进行限制)
     data         map[interface{}]memCacheItem   // 缓存数据(所有的缓存数据存放哈希表)
     expireTimes  map[interface{}]int64          // 键名对应的分组过期时间(用于相同键名过期时间快速更新)，键值为1秒级时间戳
     expireSets   map[int64]*gset.Set            // 分组过期时间对应的键名列表(用于自动过期快速删除)，键值为1秒级时间戳
 
-    lru          *memCacheLru                   // LRU缓存限制(只有限定cap池大小时才启用)
-    lruGetList   *glist.List                    // Get操作的LRU记录
+    maxBytes     int64                          // 控制缓存字节数，超过大小则按照淘汰策略进行缓存过期处理(默认为0表示不进行限制)
+    sizeFunc     memCacheSizeFunc               // 计算缓存数据项字节数的方法
+    bytes        *gtype.Int64                   // 当前缓存字节数
+
+    policy       memCachePolicy                 // 淘汰策略(只有限定cap池大小或者字节数时才启用)
+    lruGetList   *glist.List                    // Get操作的访问记录(用于淘汰策略)
     eventList    *glist.List                    // 异步处理队列
     closed       *gtype.Bool                    // 关闭事件通知
+
+    hits         *gtype.Int64                   // 命中次数
+    misses       *gtype.Int64                   // 未命中次数
+    evictions    *gtype.Int64                   // 淘汰次数
+    evictHandler *gtype.Interface               // 淘汰回调函数
+}
+
+// 缓存淘汰策略
+type memCachePolicy interface {
+    Push(key interface{})   // 记录键名的写入/访问(异步处理)
+    Remove(key interface{}) // 删除指定键名
+    Pop() interface{}       // 按照淘汰策略删除并返回一个键名
+    Size() int              // 记录的键名数量
+    Clear()                 // 清空记录
+    Close()                 // 关闭
 }
 
 // 缓存数据项
 type memCacheItem struct {
     v interface{} // 键值
     e int64       // 过期时间
+    s int64       // 字节数
 }
 
+// 计算缓存数据项字节数的方法
+type memCacheSizeFunc func(key, value interface{}) int64
+
+// 淘汰回调函数
+type memCacheEvictHandler func(key, value interface{}, reason EvictReason)
+
 // 异步队列数据项
 type memCacheEvent struct {
     k interface{} // 键名
@@ -54,21 +81,93 @@ const (
 
 // 创建底层的缓存对象
 func newMemCache(lruCap...int) *memCache {
+    option := Option{}
+    if len(lruCap) > 0 {
+        option.Cap = lruCap[0]
+    }
+    return newMemCacheWithOption(option)
+}
+
+// 根据选项创建底层的缓存对象
+func newMemCacheWithOption(option Option) *memCache {
     c := &memCache {
-        lruGetList  : glist.New(),
-        data        : make(map[interface{}]memCacheItem),
-        expireTimes : make(map[interface{}]int64),
-        expireSets  : make(map[int64]*gset.Set),
-        eventList   : glist.New(),
-        closed      : gtype.NewBool(),
+        cap          : option.Cap,
+        sizeFunc     : option.SizeFunc,
+        bytes        : gtype.NewInt64(),
+        lruGetList   : glist.New(),
+        data         : make(map[interface{}]memCacheItem),
+        expireTimes  : make(map[interface{}]int64),
+        expireSets   : make(map[int64]*gset.Set),
+        eventList    : glist.New(),
+        closed       : gtype.NewBool(),
+        hits         : gtype.NewInt64(),
+        misses       : gtype.NewInt64(),
+        evictions    : gtype.NewInt64(),
+        evictHandler : gtype.NewInterface(),
     }
-    if len(lruCap) > 0 {
-        c.cap = lruCap[0]
-        c.lru = newMemCacheLru(c)
+    if option.SizeFunc != nil {
+        c.maxBytes = option.MaxBytes
+    }
+    if option.OnEvict != nil {
+        c.OnEvict(option.OnEvict)
+    }
+    if c.cap > 0 || c.maxBytes > 0 {
+        if option.Policy == POLICY_LFU {
+            c.policy = newMemCacheLfu(c)
+        } else {
+            c.policy = newMemCacheLru(c)
+        }
     }
+    gtimer.AddSingleton(time.Second, c.syncEventAndClearExpired)
     return c
 }
 
+// 注册缓存数据项被淘汰(过期、被淘汰策略淘汰、被删除)时的回调函数，
+// 回调函数在异步处理协程或者删除操作的协程中执行，不应当长时间阻塞。
+func (c *memCache) OnEvict(handler func(key, value interface{}, reason EvictReason)) {
+    c.evictHandler.Set(memCacheEvictHandler(handler))
+}
+
+// 获取缓存统计信息
+func (c *memCache) Stats() Stats {
+    return Stats {
+        Hits      : c.hits.Val(),
+        Misses    : c.misses.Val(),
+        Evictions : c.evictions.Val(),
+        Size      : c.Size(),
+        Bytes     : c.bytes.Val(),
+    }
+}
+
+// 执行淘汰回调函数
+func (c *memCache) doEvictHandler(key, value interface{}, reason EvictReason) {
+    if v := c.evictHandler.Val(); v != nil {
+        v.(memCacheEvictHandler)(key, value, reason)
+    }
+}
+
+// 写入缓存数据项，并更新缓存字节数(需要在dataMu写锁内执行)
+func (c *memCache) setItem(key interface{}, value interface{}, expire int64) {
+    item := memCacheItem{v : value, e : expire}
+    if c.sizeFunc != nil {
+        item.s = c.sizeFunc(key, value)
+    }
+    if old, ok := c.data[key]; ok {
+        c.bytes.Add(item.s - old.s)
+    } else {
+        c.bytes.Add(item.s)
+    }
+    c.data[key] = item
+}
+
+// 删除缓存数据项，并更新缓存字节数(需要在dataMu写锁内执行)
+func (c *memCache) deleteItem(key interface{}) {
+    if item, ok := c.data[key]; ok {
+        c.bytes.Add(-item.s)
+        delete(c.data, key)
+    }
+}
+
 // 计算过期缓存的键名(将毫秒换算成秒的整数毫秒，按照1秒进行分组)
 func (c *memCache) makeExpireKey(expire int64) int64 {
     return int64(math.Ceil(float64(expire/1000) + 1)*1000)
@@ -102,7 +201,7 @@ func (c *memCache) getOrNewExpireSet(expire int64) (expireSet *gset.Set) {
 func (c *memCache) Set(key interface{}, value interface{}, expire int) {
     expireTime := c.getInternalExpire(expire)
     c.dataMu.Lock()
-    c.data[key] = memCacheItem{v : value, e : expireTime}
+    c.setItem(key, value, expireTime)
     c.dataMu.Unlock()
     c.eventList.PushBack(&memCacheEvent{k : key, e : expireTime})
 }
@@ -120,9 +219,10 @@ func (c *memCache) doSetWithLockCheck(key interface{}, value interface{}, expire
         value = f()
     }
     if value == nil {
+        c.dataMu.Unlock()
         return nil
     }
-    c.data[key] = memCacheItem{v : value, e : expireTimestamp}
+    c.setItem(key, value, expireTimestamp)
     c.dataMu.Unlock()
     c.eventList.PushBack(&memCacheEvent{k : key, e : expireTimestamp})
     return value
@@ -151,7 +251,7 @@ func (c *memCache) Sets(data map[interface{}]interface{}, expire int) {
     expireTime := c.getInternalExpire(expire)
     for k, v := range data {
         c.dataMu.Lock()
-        c.data[k] = memCacheItem{v: v, e: expireTime}
+        c.setItem(k, v, expireTime)
         c.dataMu.Unlock()
         c.eventList.PushBack(&memCacheEvent{k: k, e: expireTime})
     }
@@ -163,12 +263,14 @@ func (c *memCache) Get(key interface{}) interface{} {
     item, ok := c.data[key]
     c.dataMu.RUnlock()
     if ok && !item.IsExpired() {
-        // 增加LRU(Least Recently Used)操作记录
-        if c.cap > 0 {
+        // 增加淘汰策略的访问记录
+        if c.policy != nil {
             c.lruGetList.PushBack(key)
         }
+        c.hits.Add(1)
         return item.v
     }
+    c.misses.Add(1)
     return nil
 }
 
@@ -207,15 +309,16 @@ func (c *memCache) Contains(key interface{}) bool {
 
 // 删除指定键值对，并返回被删除的键值
 func (c *memCache) Remove(key interface{}) (value interface{}) {
-    c.dataMu.RLock()
+    c.dataMu.Lock()
     item, ok := c.data[key]
-    c.dataMu.RUnlock()
+    if ok {
+        c.deleteItem(key)
+    }
+    c.dataMu.Unlock()
     if ok {
         value = item.v
-        c.dataMu.Lock()
-        delete(c.data, key)
-        c.dataMu.Unlock()
         c.eventList.PushBack(&memCacheEvent{k: key, e: gtime.Millisecond() - 1000})
+        c.doEvictHandler(key, item.v, REASON_REMOVED)
     }
     return
 }
@@ -279,10 +382,33 @@ func (c *memCache) Size() (size int) {
     return
 }
 
+// 清空缓存中的所有数据，如果注册了淘汰回调函数，将会对每个数据项执行回调
+func (c *memCache) Clear() {
+    c.dataMu.Lock()
+    data  := c.data
+    c.data = make(map[interface{}]memCacheItem)
+    c.bytes.Set(0)
+    c.dataMu.Unlock()
+    c.expireTimeMu.Lock()
+    c.expireTimes = make(map[interface{}]int64)
+    c.expireTimeMu.Unlock()
+    c.expireSetMu.Lock()
+    c.expireSets = make(map[int64]*gset.Set)
+    c.expireSetMu.Unlock()
+    if c.policy != nil {
+        c.policy.Clear()
+    }
+    if c.evictHandler.Val() != nil {
+        for k, v := range data {
+            c.doEvictHandler(k, v.v, REASON_REMOVED)
+        }
+    }
+}
+
 // 删除缓存对象
 func (c *memCache) Close()  {
-    if c.cap > 0 {
-        c.lru.Close()
+    if c.policy != nil {
+        c.policy.Close()
     }
     c.closed.Set(true)
 }
@@ -323,16 +449,16 @@ func (c *memCache) syncEventAndClearExpired() {
             c.expireTimes[event.k] = newExpireTime
             c.expireTimeMu.Unlock()
         }
-        // 写入操作也会增加到LRU(Least Recently Used)操作记录
-        if c.cap > 0 {
-            c.lru.Push(event.k)
+        // 写入操作也会增加到淘汰策略的访问记录
+        if c.policy != nil {
+            c.policy.Push(event.k)
         }
     }
-    // 异步处理读取操作的LRU列表
-    if c.cap > 0 && c.lruGetList.Len() > 0 {
+    // 异步处理读取操作的访问记录
+    if c.policy != nil && c.lruGetList.Len() > 0 {
         for {
             if v := c.lruGetList.PopFront(); v != nil {
-                c.lru.Push(v)
+                c.policy.Push(v)
             } else {
                 break
             }
@@ -347,7 +473,7 @@ func (c *memCache) syncEventAndClearExpired() {
         if expireSet := c.getExpireSet(expireTime); expireSet != nil {
             // 遍历Set，执行数据过期删除
             expireSet.Iterator(func(key interface{}) bool {
-                c.clearByKey(key)
+                c.clearByKey(key, REASON_EXPIRED)
                 return true
             })
             // Set数据处理完之后删除该Set
@@ -358,13 +484,26 @@ func (c *memCache) syncEventAndClearExpired() {
     }
 }
 
-// 删除对应键名的缓存数据
-func (c *memCache) clearByKey(key interface{}, force...bool) {
+// 按照淘汰策略淘汰缓存数据，直到缓存数量及字节数不超过限制
+func (c *memCache) evict() {
+    for (c.cap > 0 && c.policy.Size() > c.cap) || (c.maxBytes > 0 && c.bytes.Val() > c.maxBytes) {
+        key := c.policy.Pop()
+        if key == nil {
+            break
+        }
+        c.clearByKey(key, REASON_EVICTED)
+    }
+}
+
+// 删除对应键名的缓存数据，过期删除时只删除真正过期的数据，淘汰删除时强制删除
+func (c *memCache) clearByKey(key interface{}, reason EvictReason) {
     // 删除缓存数据
     c.dataMu.Lock()
     // 删除核对，真正的过期才删除
-    if item, ok := c.data[key]; (ok && item.IsExpired()) || (len(force) > 0 && force[0]) {
-        delete(c.data, key)
+    item, ok := c.data[key]
+    deleted  := ok && (item.IsExpired() || reason == REASON_EVICTED)
+    if deleted {
+        c.deleteItem(key)
     }
     c.dataMu.Unlock()
 
@@ -373,8 +512,13 @@ func (c *memCache) clearByKey(key interface{}, force...bool) {
     delete(c.expireTimes, key)
     c.expireTimeMu.Unlock()
 
-    // 删除LRU管理对象中指定键名
-    if c.cap > 0 {
-        c.lru.Remove(key)
+    // 删除淘汰策略中指定键名
+    if c.policy != nil {
+        c.policy.Remove(key)
+    }
+
+    if deleted {
+        c.evictions.Add(1)
+        c.doEvictHandler(key, item.v, reason)
     }
 }
@@ -0,0 +1,151 @@
+// Copyright 2019 gf Author(https://github.com/gogf/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package gcache
+
+import (
+    "github.com/gogf/gf/g/container/glist"
+    "github.com/gogf/gf/g/container/gtype"
+    "github.com/gogf/gf/g/os/gtimer"
+    "sync"
+    "time"
+)
+
+// LFU算法实现对象，相同使用频率的键名按照访问时间排列在同一个链表中，淘汰和更新的复杂度均为O(1)
+type memCacheLfu struct {
+    mu        sync.Mutex
+    cache     *memCache                       // 所属Cache对象
+    data      map[interface{}]*glist.Element  // 记录键名与频率链表中的位置项指针
+    freqs     map[int]*glist.List             // 使用频率对应的键名链表，链表头为最近访问
+    minFreq   int                             // 当前最小使用频率
+    rawList   *glist.List                     // 事件列表
+    closed    *gtype.Bool                     // 是否关闭
+}
+
+// LFU链表数据项
+type memCacheLfuItem struct {
+    key  interface{} // 键名
+    freq int         // 使用频率
+}
+
+// 创建LFU管理对象
+func newMemCacheLfu(cache *memCache) *memCacheLfu {
+    lfu := &memCacheLfu {
+        cache     : cache,
+        data      : make(map[interface{}]*glist.Element),
+        freqs     : make(map[int]*glist.List),
+        rawList   : glist.New(),
+        closed    : gtype.NewBool(),
+    }
+    gtimer.AddSingleton(time.Second, lfu.SyncAndClear)
+    return lfu
+}
+
+// 关闭LFU对象
+func (lfu *memCacheLfu) Close() {
+    lfu.closed.Set(true)
+}
+
+// 清空LFU数据
+func (lfu *memCacheLfu) Clear() {
+    lfu.mu.Lock()
+    lfu.data    = make(map[interface{}]*glist.Element)
+    lfu.freqs   = make(map[int]*glist.List)
+    lfu.minFreq = 0
+    lfu.mu.Unlock()
+}
+
+// 删除指定数据项
+func (lfu *memCacheLfu) Remove(key interface{}) {
+    lfu.mu.Lock()
+    if e, ok := lfu.data[key]; ok {
+        lfu.unlink(e)
+        delete(lfu.data, key)
+    }
+    lfu.mu.Unlock()
+}
+
+// 当前LFU数据大小
+func (lfu *memCacheLfu) Size() int {
+    lfu.mu.Lock()
+    defer lfu.mu.Unlock()
+    return len(lfu.data)
+}
+
+// 添加LFU数据项(访问记录)
+func (lfu *memCacheLfu) Push(key interface{}) {
+    lfu.rawList.PushBack(key)
+}
+
+// 删除使用频率最低的数据项(相同频率时删除最久未访问的数据项)，并返回对应键名
+func (lfu *memCacheLfu) Pop() interface{} {
+    lfu.mu.Lock()
+    defer lfu.mu.Unlock()
+    if len(lfu.data) == 0 {
+        return nil
+    }
+    // 删除数据项时不会更新最小频率，这里向上查找
+    for {
+        if list, ok := lfu.freqs[lfu.minFreq]; ok && list.Len() > 0 {
+            item := list.PopBack().(*memCacheLfuItem)
+            if list.Len() == 0 {
+                delete(lfu.freqs, lfu.minFreq)
+            }
+            delete(lfu.data, item.key)
+            return item.key
+        }
+        lfu.minFreq++
+    }
+}
+
+// 异步执行协程，将queue中的数据同步到频率链表中
+func (lfu *memCacheLfu) SyncAndClear() {
+    if lfu.closed.Val() {
+        gtimer.Exit()
+        return
+    }
+    // 数据同步
+    for {
+        if v := lfu.rawList.PopFront(); v != nil {
+            lfu.touch(v)
+        } else {
+            break
+        }
+    }
+    // 数据清理
+    lfu.cache.evict()
+}
+
+// 增加键名的使用频率，键名不存在时以频率1加入
+func (lfu *memCacheLfu) touch(key interface{}) {
+    lfu.mu.Lock()
+    defer lfu.mu.Unlock()
+    item := &memCacheLfuItem{key : key, freq : 1}
+    if e, ok := lfu.data[key]; ok {
+        item.freq = lfu.unlink(e).freq + 1
+    }
+    list, ok := lfu.freqs[item.freq]
+    if !ok {
+        list = glist.New(true)
+        lfu.freqs[item.freq] = list
+    }
+    lfu.data[key] = list.PushFront(item)
+    if item.freq < lfu.minFreq || lfu.minFreq == 0 {
+        lfu.minFreq = item.freq
+    }
+}
+
+// 从频率链表中删除数据项，并返回该数据项
+func (lfu *memCacheLfu) unlink(e *glist.Element) *memCacheLfuItem {
+    item := e.Value.(*memCacheLfuItem)
+    if list, ok := lfu.freqs[item.freq]; ok {
+        list.Remove(e)
+        if list.Len() == 0 {
+            delete(lfu.freqs, item.freq)
+        }
+    }
+    return item
+}
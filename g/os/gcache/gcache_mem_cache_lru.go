@@ -42,6 +42,12 @@ func (lru *memCacheLru) Close() {
     lru.closed.Set(true)
 }
 
+// 清空LRU数据
+func (lru *memCacheLru) Clear() {
+    lru.data.Clear()
+    lru.list.RemoveAll()
+}
+
 // 删除指定数据项
 func (lru *memCacheLru) Remove(key interface{}) {
     if v := lru.data.Get(key); v != nil {
@@ -97,9 +103,5 @@ func (lru *memCacheLru) SyncAndClear() {
         }
     }
     // 数据清理
-    for i := lru.Size() - lru.cache.cap; i > 0; i-- {
-        if s := lru.Pop(); s != nil {
-            lru.cache.clearByKey(s, true)
-        }
-    }
+    lru.cache.evict()
 }
\ No newline at end of file
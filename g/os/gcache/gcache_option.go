@@ -0,0 +1,49 @@
+// Copyright 2019 gf Author(https://github.com/gogf/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package gcache
+
+// 缓存淘汰原因
+type EvictReason int
+
+const (
+    REASON_EXPIRED EvictReason = iota + 1 // 缓存过期
+    REASON_EVICTED                        // 超过缓存数量或字节数限制，被淘汰策略淘汰
+    REASON_REMOVED                        // 被删除
+)
+
+const (
+    POLICY_LRU = iota // 淘汰最近最少使用(Least Recently Used)的数据
+    POLICY_LFU        // 淘汰使用频率最低(Least Frequently Used)的数据
+)
+
+// 内存缓存选项
+type Option struct {
+    Cap      int                                               // 缓存数量上限，默认为0表示不限制
+    MaxBytes int64                                             // 缓存字节数上限，需要同时设置SizeFunc，默认为0表示不限制
+    SizeFunc func(key, value interface{}) int64                // 计算缓存数据项字节数的方法
+    Policy   int                                               // 超过上限时的淘汰策略，POLICY_LRU(默认)或者POLICY_LFU
+    OnEvict  func(key, value interface{}, reason EvictReason)  // 缓存数据项被淘汰时的回调函数
+}
+
+// 缓存统计信息
+type Stats struct {
+    Hits      int64 // 命中次数
+    Misses    int64 // 未命中次数
+    Evictions int64 // 淘汰次数(包括过期以及被淘汰策略淘汰，不包括删除)
+    Size      int   // 缓存数量
+    Bytes     int64 // 缓存字节数(设置SizeFunc时有效)
+}
+
+// 淘汰原因的字符串表示
+func (r EvictReason) String() string {
+    switch r {
+        case REASON_EXPIRED: return "expired"
+        case REASON_EVICTED: return "evicted"
+        case REASON_REMOVED: return "removed"
+    }
+    return "unknown"
+}
@@ -0,0 +1,418 @@
+// Copyright 2019 gf Author(https://github.com/gogf/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package gcache
+
+import (
+    "encoding/json"
+    "github.com/gogf/gf/g/os/gtime"
+    "github.com/gogf/gf/g/util/gconv"
+    "github.com/gogf/gf/g/util/grand"
+    "math"
+    "math/rand"
+    "sync"
+)
+
+const (
+    gTIERED_DEFAULT_LOCAL_EXPIRE    = 60000 // 默认本地缓存过期时间(毫秒)
+    gTIERED_DEFAULT_NEGATIVE_EXPIRE = 5000  // 默认空值缓存过期时间(毫秒)
+    gTIERED_DEFAULT_BETA            = 1.0   // 默认提前刷新系数
+)
+
+// 多级缓存失效通知器，用于在多个实例之间广播本地缓存的失效消息，
+// 例如基于redis发布/订阅的gredis.CacheInvalidator。
+type Invalidator interface {
+    // 广播失效消息
+    Publish(message []byte) error
+    // 订阅失效消息，收到消息时回调handler(包括当前实例发出的消息)
+    Subscribe(handler func(message []byte)) error
+    // 关闭通知器
+    Close() error
+}
+
+// 多级缓存适配器，由本地LRU内存缓存及远程缓存适配器(例如gredis.CacheAdapter)组成。
+// 1、读取时优先读取本地缓存，不存在时读取远程缓存并写入本地缓存，本地缓存的过期时间不超过LocalExpire；
+// 2、同一键名的并发加载只会执行一次(single-flight)，GetOrSetFuncLock在远程缓存的锁机制内执行加载函数，防止多个实例同时加载；
+// 3、通过GetOrSetFunc*加载的热点数据在本地缓存过期前按照概率提前刷新(XFetch算法)，避免缓存过期时的集中加载；
+// 4、不存在的键名(或加载结果为nil)在本地缓存NegativeExpire时间，避免频繁穿透到远程缓存；
+// 5、写入/删除操作会通过Invalidator广播失效消息，其他实例收到后删除对应的本地缓存，保证本地缓存的一致性。
+type TieredAdapter struct {
+    mu             sync.Mutex
+    id             string                 // 实例唯一标识，用于忽略自身发出的失效消息
+    local          *memCache              // 本地缓存，键名统一转换为字符串，键值为*tieredItem
+    remote         Adapter                // 远程缓存适配器
+    calls          map[string]*tieredCall // 正在执行的加载操作
+    localExpire    int                    // 本地缓存过期时间(毫秒)
+    negativeExpire int                    // 空值缓存过期时间(毫秒)，为0表示不缓存空值
+    beta           float64                // 提前刷新系数，越大越提前，为0表示不提前刷新
+    invalidator    Invalidator            // 失效通知器
+}
+
+// 本地缓存数据项
+type tieredItem struct {
+    v interface{} // 键值，nil表示空值缓存
+    e int64       // 本地过期时间(毫秒)
+    d int64       // 加载耗时(毫秒)
+    r bool        // 远程缓存是否与本地同时过期(由当前实例写入)，提前刷新时需要重新执行加载函数
+    f bool        // 空值缓存是否由加载函数生成，未执行过加载函数的空值缓存不影响带加载函数的读取
+}
+
+// 正在执行的加载操作
+type tieredCall struct {
+    wg sync.WaitGroup
+    v  interface{}
+}
+
+// 失效消息
+type tieredMessage struct {
+    Id    string   `json:"i"`           // 发送实例标识
+    Keys  []string `json:"k,omitempty"` // 失效的键名列表
+    Clear bool     `json:"c,omitempty"` // 是否清空所有本地缓存
+}
+
+// 创建多级缓存适配器，lruCap用于限制本地缓存大小，超过大小则按照LRU算法进行淘汰
+func NewTieredAdapter(remote Adapter, lruCap int) *TieredAdapter {
+    return &TieredAdapter {
+        id             : grand.Str(16),
+        local          : newMemCache(lruCap),
+        remote         : remote,
+        calls          : make(map[string]*tieredCall),
+        localExpire    : gTIERED_DEFAULT_LOCAL_EXPIRE,
+        negativeExpire : gTIERED_DEFAULT_NEGATIVE_EXPIRE,
+        beta           : gTIERED_DEFAULT_BETA,
+    }
+}
+
+// 设置本地缓存过期时间(毫秒)，也是未使用失效通知器时本地缓存的最大不一致时间
+func (a *TieredAdapter) SetLocalExpire(expire int) {
+    a.localExpire = expire
+}
+
+// 设置空值缓存过期时间(毫秒)，为0表示不缓存空值
+func (a *TieredAdapter) SetNegativeExpire(expire int) {
+    a.negativeExpire = expire
+}
+
+// 设置提前刷新系数，默认为1，为0表示不提前刷新
+func (a *TieredAdapter) SetEarlyRefresh(beta float64) {
+    a.beta = beta
+}
+
+// 设置并订阅失效通知器
+func (a *TieredAdapter) SetInvalidator(invalidator Invalidator) error {
+    if err := invalidator.Subscribe(a.onInvalidate); err != nil {
+        return err
+    }
+    a.invalidator = invalidator
+    return nil
+}
+
+// 获取远程缓存适配器
+func (a *TieredAdapter) Remote() Adapter {
+    return a.remote
+}
+
+// 设置kv缓存键值对，过期时间单位为毫秒
+func (a *TieredAdapter) Set(key interface{}, value interface{}, expire int) {
+    k := gconv.String(key)
+    a.remote.Set(key, value, expire)
+    if value == nil || expire < 0 {
+        a.local.Remove(k)
+    } else {
+        a.setLocal(k, value, expire, 0)
+    }
+    a.publish(k)
+}
+
+// 当键名不存在时写入，并返回true；否则返回false。
+func (a *TieredAdapter) SetIfNotExist(key interface{}, value interface{}, expire int) bool {
+    if a.remote.SetIfNotExist(key, value, expire) {
+        k := gconv.String(key)
+        a.local.Remove(k)
+        a.publish(k)
+        return true
+    }
+    return false
+}
+
+// 批量设置kv缓存键值对，过期时间单位为毫秒
+func (a *TieredAdapter) Sets(data map[interface{}]interface{}, expire int) {
+    a.remote.Sets(data, expire)
+    keys := make([]string, 0, len(data))
+    for k := range data {
+        key := gconv.String(k)
+        a.local.Remove(key)
+        keys = append(keys, key)
+    }
+    a.publish(keys...)
+}
+
+// 获取指定键名的值，优先读取本地缓存
+func (a *TieredAdapter) Get(key interface{}) interface{} {
+    k := gconv.String(key)
+    if item := a.getLocal(k); item != nil {
+        return item.v
+    }
+    return a.doOnce(k, func() interface{} {
+        return a.load(k, key, nil, 0, false)
+    }, true)
+}
+
+// 当键名存在时返回其键值，否则写入指定的键值
+func (a *TieredAdapter) GetOrSet(key interface{}, value interface{}, expire int) interface{} {
+    if v := a.Get(key); v != nil {
+        return v
+    }
+    k := gconv.String(key)
+    v := a.remote.GetOrSet(key, value, expire)
+    if v != nil {
+        a.setLocal(k, v, 0, 0)
+    } else {
+        a.local.Remove(k)
+    }
+    a.publish(k)
+    return v
+}
+
+// 当键名存在时返回其键值，否则写入指定的键值，键值由指定的函数生成。
+// 同一键名的并发加载在当前实例只会执行一次。
+func (a *TieredAdapter) GetOrSetFunc(key interface{}, f func() interface{}, expire int) interface{} {
+    return a.getOrLoad(key, f, expire, false)
+}
+
+// 与GetOrSetFunc不同的是，f在远程缓存的锁机制内执行，因此多个实例也只会执行一次
+func (a *TieredAdapter) GetOrSetFuncLock(key interface{}, f func() interface{}, expire int) interface{} {
+    return a.getOrLoad(key, f, expire, true)
+}
+
+// 是否存在指定的键名
+func (a *TieredAdapter) Contains(key interface{}) bool {
+    if item := a.getLocal(gconv.String(key)); item != nil {
+        return item.v != nil
+    }
+    return a.remote.Contains(key)
+}
+
+// 删除指定键值对，并返回被删除的键值
+func (a *TieredAdapter) Remove(key interface{}) interface{} {
+    k := gconv.String(key)
+    v := a.remote.Remove(key)
+    a.local.Remove(k)
+    a.publish(k)
+    return v
+}
+
+// 批量删除键值对
+func (a *TieredAdapter) Removes(keys []interface{}) {
+    a.remote.Removes(keys)
+    array := make([]string, len(keys))
+    for i, key := range keys {
+        array[i] = gconv.String(key)
+        a.local.Remove(array[i])
+    }
+    a.publish(array...)
+}
+
+// 返回远程缓存的所有数据键值对
+func (a *TieredAdapter) Data() map[interface{}]interface{} {
+    return a.remote.Data()
+}
+
+// 获得远程缓存的所有键名
+func (a *TieredAdapter) Keys() []interface{} {
+    return a.remote.Keys()
+}
+
+// 获得远程缓存的所有键名，组成字符串数组返回
+func (a *TieredAdapter) KeyStrings() []string {
+    return a.remote.KeyStrings()
+}
+
+// 获得远程缓存的所有键值
+func (a *TieredAdapter) Values() []interface{} {
+    return a.remote.Values()
+}
+
+// 获得远程缓存的键值对数量
+func (a *TieredAdapter) Size() int {
+    return a.remote.Size()
+}
+
+// 清空远程缓存及所有实例的本地缓存
+func (a *TieredAdapter) Clear() {
+    a.remote.Clear()
+    a.local.Clear()
+    a.broadcast(&tieredMessage{Id : a.id, Clear : true})
+}
+
+// 关闭本地缓存、远程缓存及失效通知器
+func (a *TieredAdapter) Close() {
+    if a.invalidator != nil {
+        a.invalidator.Close()
+    }
+    a.local.Close()
+    a.remote.Close()
+}
+
+// 读取键值，不存在时使用f加载；本地缓存即将过期时按照概率异步提前刷新
+func (a *TieredAdapter) getOrLoad(key interface{}, f func() interface{}, expire int, lock bool) interface{} {
+    k := gconv.String(key)
+    if item := a.getLocal(k); item != nil && (item.v != nil || item.f || f == nil) {
+        if item.v != nil && a.shouldRefresh(item) {
+            go a.doOnce(k, func() interface{} {
+                if item.r {
+                    return a.compute(k, key, f, expire)
+                }
+                return a.load(k, key, f, expire, lock)
+            }, false)
+        }
+        return item.v
+    }
+    return a.doOnce(k, func() interface{} {
+        return a.load(k, key, f, expire, lock)
+    }, true)
+}
+
+// 从远程缓存加载键值到本地缓存，远程缓存不存在且f不为nil时，使用f生成键值并写入远程缓存
+func (a *TieredAdapter) load(k string, key interface{}, f func() interface{}, expire int, lock bool) interface{} {
+    start    := gtime.Millisecond()
+    value    := a.remote.Get(key)
+    computed := false
+    if value == nil && f != nil {
+        loader := func() interface{} {
+            computed = true
+            return f()
+        }
+        if lock {
+            value = a.remote.GetOrSetFuncLock(key, loader, expire)
+        } else {
+            value = a.remote.GetOrSetFunc(key, loader, expire)
+        }
+    }
+    if value == nil {
+        if a.negativeExpire > 0 {
+            a.local.Set(k, &tieredItem{e : gtime.Millisecond() + int64(a.negativeExpire), f : f != nil}, a.negativeExpire)
+        }
+        return nil
+    }
+    if computed {
+        a.setLocal(k, value, expire, gtime.Millisecond() - start)
+        // 其他实例可能缓存了空值
+        a.publish(k)
+    } else {
+        a.setLocal(k, value, 0, gtime.Millisecond() - start)
+    }
+    return value
+}
+
+// 使用f重新生成键值，并写入远程缓存及本地缓存
+func (a *TieredAdapter) compute(k string, key interface{}, f func() interface{}, expire int) interface{} {
+    start := gtime.Millisecond()
+    value := f()
+    if value == nil {
+        return nil
+    }
+    a.remote.Set(key, value, expire)
+    a.setLocal(k, value, expire, gtime.Millisecond() - start)
+    a.publish(k)
+    return value
+}
+
+// 获取本地缓存数据项，不存在时返回nil
+func (a *TieredAdapter) getLocal(k string) *tieredItem {
+    if v := a.local.Get(k); v != nil {
+        return v.(*tieredItem)
+    }
+    return nil
+}
+
+// 写入本地缓存，expire为远程缓存的过期时间(毫秒)，cost为加载耗时(毫秒)
+func (a *TieredAdapter) setLocal(k string, value interface{}, expire int, cost int64) {
+    item := &tieredItem {
+        v : value,
+        d : cost,
+    }
+    localExpire := a.localExpire
+    if expire > 0 && (localExpire <= 0 || expire <= localExpire) {
+        localExpire = expire
+        item.r      = true
+    }
+    if localExpire > 0 {
+        item.e = gtime.Millisecond() + int64(localExpire)
+    } else {
+        item.e = gDEFAULT_MAX_EXPIRE
+    }
+    a.local.Set(k, item, localExpire)
+}
+
+// 是否需要提前刷新(XFetch算法)，越接近过期时间、加载耗时越长，提前刷新的概率越大
+func (a *TieredAdapter) shouldRefresh(item *tieredItem) bool {
+    if a.beta <= 0 || item.e == gDEFAULT_MAX_EXPIRE {
+        return false
+    }
+    cost := item.d
+    if cost < 1 {
+        cost = 1
+    }
+    return gtime.Millisecond() - int64(float64(cost)*a.beta*math.Log(rand.Float64())) >= item.e
+}
+
+// 同一键名的加载操作只执行一次，wait为true时等待正在执行的加载操作并返回其结果，否则直接返回nil
+func (a *TieredAdapter) doOnce(k string, f func() interface{}, wait bool) interface{} {
+    a.mu.Lock()
+    if call, ok := a.calls[k]; ok {
+        a.mu.Unlock()
+        if !wait {
+            return nil
+        }
+        call.wg.Wait()
+        return call.v
+    }
+    call := new(tieredCall)
+    call.wg.Add(1)
+    a.calls[k] = call
+    a.mu.Unlock()
+    defer func() {
+        a.mu.Lock()
+        delete(a.calls, k)
+        a.mu.Unlock()
+        call.wg.Done()
+    }()
+    call.v = f()
+    return call.v
+}
+
+// 广播指定键名的失效消息
+func (a *TieredAdapter) publish(keys...string) {
+    if len(keys) > 0 {
+        a.broadcast(&tieredMessage{Id : a.id, Keys : keys})
+    }
+}
+
+// 广播失效消息
+func (a *TieredAdapter) broadcast(msg *tieredMessage) {
+    if a.invalidator == nil {
+        return
+    }
+    if b, err := json.Marshal(msg); err == nil {
+        a.invalidator.Publish(b)
+    }
+}
+
+// 处理失效消息，删除对应的本地缓存
+func (a *TieredAdapter) onInvalidate(message []byte) {
+    msg := new(tieredMessage)
+    if err := json.Unmarshal(message, msg); err != nil || msg.Id == a.id {
+        return
+    }
+    if msg.Clear {
+        a.local.Clear()
+        return
+    }
+    for _, k := range msg.Keys {
+        a.local.Remove(k)
+    }
+}
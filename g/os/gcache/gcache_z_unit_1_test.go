@@ -271,3 +271,26 @@ func TestCache_Basic(t *testing.T) {
 		}
 	})
 }
+
+func TestCache_Adapter(t *testing.T) {
+	gtest.Case(t, func() {
+		cache1 := gcache.New()
+		cache2 := gcache.NewWithAdapter(cache1.Adapter())
+		cache1.Set(1, 11, 0)
+		gtest.Assert(cache2.Get(1), 11)
+
+		cache2.SetAdapter(gcache.New().Adapter())
+		gtest.Assert(cache2.Get(1), nil)
+		cache2.Set(2, 22, 0)
+		gtest.Assert(cache1.Get(2), nil)
+		gtest.Assert(cache2.Get(2), 22)
+
+		cache1.Clear()
+		gtest.Assert(cache1.Size(), 0)
+		gtest.Assert(cache1.Get(1), nil)
+		cache1.Set(1, 11, 0)
+		gtest.Assert(cache1.Get(1), 11)
+		cache1.Close()
+		cache2.Close()
+	})
+}
@@ -0,0 +1,115 @@
+// Copyright 2019 gf Author(https://github.com/gogf/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package gcache_test
+
+import (
+	"github.com/gogf/gf/g/container/gmap"
+	"github.com/gogf/gf/g/os/gcache"
+	"github.com/gogf/gf/g/test/gtest"
+	"testing"
+	"time"
+)
+
+func TestCache_OnEvict(t *testing.T) {
+	gtest.Case(t, func() {
+		reasons := gmap.New()
+		handler := func(key, value interface{}, reason gcache.EvictReason) {
+			reasons.Set(key, reason.String())
+		}
+		cache1 := gcache.New()
+		cache1.OnEvict(handler)
+		cache2 := gcache.NewWithOption(gcache.Option{
+			Cap:     1,
+			OnEvict: handler,
+		})
+		defer cache1.Close()
+		defer cache2.Close()
+
+		cache1.Set(1, 11, 0)
+		gtest.Assert(cache1.Remove(1), 11)
+		gtest.Assert(reasons.Get(1), "removed")
+
+		cache1.Set(2, 22, 100)
+		cache2.Set(3, 33, 0)
+		cache2.Set(4, 44, 0)
+		time.Sleep(3 * time.Second)
+		gtest.Assert(reasons.Get(2), "expired")
+		gtest.Assert(reasons.Get(3), "evicted")
+		gtest.Assert(reasons.Contains(4), false)
+		gtest.Assert(cache1.Stats().Evictions, 1)
+		gtest.Assert(cache2.Stats().Evictions, 1)
+
+		cache2.Clear()
+		gtest.Assert(reasons.Get(4), "removed")
+	})
+}
+
+func TestCache_Stats(t *testing.T) {
+	gtest.Case(t, func() {
+		cache := gcache.New()
+		defer cache.Close()
+		cache.Set(1, 11, 0)
+		cache.Get(1)
+		cache.Get(1)
+		cache.Get(2)
+		stats := cache.Stats()
+		gtest.Assert(stats.Hits, 2)
+		gtest.Assert(stats.Misses, 1)
+		gtest.Assert(stats.Evictions, 0)
+		gtest.Assert(stats.Size, 1)
+	})
+}
+
+func TestCache_MaxBytes(t *testing.T) {
+	gtest.Case(t, func() {
+		cache := gcache.NewWithOption(gcache.Option{
+			MaxBytes: 10,
+			SizeFunc: func(key, value interface{}) int64 {
+				return int64(len(value.(string)))
+			},
+		})
+		defer cache.Close()
+		cache.Set("a", "12345", 0)
+		cache.Set("b", "12345", 0)
+		gtest.Assert(cache.Stats().Bytes, 10)
+		cache.Set("b", "1234", 0)
+		gtest.Assert(cache.Stats().Bytes, 9)
+		cache.Set("c", "123", 0)
+		gtest.Assert(cache.Stats().Bytes, 12)
+		time.Sleep(3 * time.Second)
+		gtest.Assert(cache.Get("a"), nil)
+		gtest.Assert(cache.Get("b"), "1234")
+		gtest.Assert(cache.Get("c"), "123")
+		gtest.Assert(cache.Stats().Bytes, 7)
+		cache.Remove("b")
+		gtest.Assert(cache.Stats().Bytes, 3)
+	})
+}
+
+func TestCache_LFU(t *testing.T) {
+	gtest.Case(t, func() {
+		cache := gcache.NewWithOption(gcache.Option{
+			Cap:    2,
+			Policy: gcache.POLICY_LFU,
+		})
+		defer cache.Close()
+		cache.Set(1, 11, 0)
+		cache.Set(2, 22, 0)
+		cache.Get(1)
+		cache.Get(1)
+		cache.Get(1)
+		time.Sleep(1500 * time.Millisecond)
+		// LRU evicts 1 as it's least recently used, but LFU evicts 3 as it's least frequently used.
+		cache.Set(3, 33, 0)
+		cache.Get(2)
+		time.Sleep(3 * time.Second)
+		gtest.Assert(cache.Size(), 2)
+		gtest.Assert(cache.Get(1), 11)
+		gtest.Assert(cache.Get(2), 22)
+		gtest.Assert(cache.Get(3), nil)
+	})
+}
@@ -0,0 +1,163 @@
+// Copyright 2019 gf Author(https://github.com/gogf/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package gcache_test
+
+import (
+	"github.com/gogf/gf/g/container/gtype"
+	"github.com/gogf/gf/g/os/gcache"
+	"github.com/gogf/gf/g/test/gtest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// testInvalidator delivers messages to all subscribers synchronously, acting as a message broker.
+type testInvalidator struct {
+	mu       sync.Mutex
+	handlers []func(message []byte)
+}
+
+func (i *testInvalidator) Publish(message []byte) error {
+	i.mu.Lock()
+	handlers := i.handlers
+	i.mu.Unlock()
+	for _, h := range handlers {
+		h(message)
+	}
+	return nil
+}
+
+func (i *testInvalidator) Subscribe(handler func(message []byte)) error {
+	i.mu.Lock()
+	i.handlers = append(i.handlers, handler)
+	i.mu.Unlock()
+	return nil
+}
+
+func (i *testInvalidator) Close() error {
+	return nil
+}
+
+func TestTieredAdapter_Invalidation(t *testing.T) {
+	gtest.Case(t, func() {
+		remote      := gcache.New().Adapter()
+		invalidator := new(testInvalidator)
+		adapter1    := gcache.NewTieredAdapter(remote, 100)
+		adapter2    := gcache.NewTieredAdapter(remote, 100)
+		gtest.Assert(adapter1.SetInvalidator(invalidator), nil)
+		gtest.Assert(adapter2.SetInvalidator(invalidator), nil)
+		cache1 := gcache.NewWithAdapter(adapter1)
+		cache2 := gcache.NewWithAdapter(adapter2)
+		defer cache1.Close()
+
+		cache1.Set(1, 11, 0)
+		gtest.Assert(cache1.Get(1), 11)
+		gtest.Assert(cache2.Get(1), 11)
+		gtest.Assert(cache2.Get("1"), 11)
+
+		// Local tier of cache2 is invalidated by cache1.
+		cache1.Set(1, 111, 0)
+		gtest.Assert(cache2.Get(1), 111)
+		gtest.Assert(cache1.Remove(1), 111)
+		gtest.Assert(cache2.Get(1), nil)
+		gtest.Assert(cache2.Contains(1), false)
+
+		// Negative cache of cache1 is invalidated by cache2.
+		gtest.Assert(cache2.SetIfNotExist(1, 1, 0), true)
+		gtest.Assert(cache1.Get(1), 1)
+
+		cache1.Sets(map[interface{}]interface{}{2: 22, 3: 33}, 0)
+		gtest.Assert(cache2.Get(2), 22)
+		gtest.Assert(cache2.Size(), 3)
+		cache2.Clear()
+		gtest.Assert(cache1.Get(2), nil)
+		gtest.Assert(cache1.Size(), 0)
+	})
+}
+
+func TestTieredAdapter_Negative(t *testing.T) {
+	gtest.Case(t, func() {
+		remote  := gcache.New().Adapter()
+		adapter := gcache.NewTieredAdapter(remote, 100)
+		adapter.SetNegativeExpire(200)
+		defer adapter.Close()
+
+		gtest.Assert(adapter.Get(1), nil)
+		// Set to remote directly, which is not visible until the negative cache expires.
+		remote.Set(1, 11, 0)
+		gtest.Assert(adapter.Get(1), nil)
+		time.Sleep(300 * time.Millisecond)
+		gtest.Assert(adapter.Get(1), 11)
+
+		count := 0
+		for i := 0; i < 3; i++ {
+			gtest.Assert(adapter.GetOrSetFunc(2, func() interface{} {
+				count++
+				return nil
+			}, 0), nil)
+		}
+		gtest.Assert(count, 1)
+
+		// Negative cache of Get does not skip the loading function of GetOrSetFunc.
+		gtest.Assert(adapter.Get(3), nil)
+		gtest.Assert(adapter.GetOrSetFunc(3, func() interface{} {
+			return 33
+		}, 0), 33)
+		gtest.Assert(adapter.Get(3), 33)
+		gtest.Assert(remote.Get(3), 33)
+	})
+}
+
+func TestTieredAdapter_SingleFlight(t *testing.T) {
+	gtest.Case(t, func() {
+		adapter := gcache.NewTieredAdapter(gcache.New().Adapter(), 100)
+		defer adapter.Close()
+
+		count := gtype.NewInt()
+		wg    := sync.WaitGroup{}
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				v := adapter.GetOrSetFuncLock(1, func() interface{} {
+					count.Add(1)
+					time.Sleep(100 * time.Millisecond)
+					return 11
+				}, 0)
+				gtest.Assert(v, 11)
+			}()
+		}
+		wg.Wait()
+		gtest.Assert(count.Val(), 1)
+		gtest.Assert(adapter.Remote().Get(1), 11)
+	})
+}
+
+func TestTieredAdapter_EarlyRefresh(t *testing.T) {
+	gtest.Case(t, func() {
+		adapter := gcache.NewTieredAdapter(gcache.New().Adapter(), 100)
+		defer adapter.Close()
+
+		count := gtype.NewInt()
+		f     := func() interface{} {
+			return count.Add(1)
+		}
+		gtest.Assert(adapter.GetOrSetFunc(1, f, 1000), 1)
+		// Disabled early refresh.
+		adapter.SetEarlyRefresh(0)
+		gtest.Assert(adapter.GetOrSetFunc(1, f, 1000), 1)
+		gtest.Assert(count.Val(), 1)
+		// The value is always refreshed asynchronously with large beta.
+		adapter.SetEarlyRefresh(1e9)
+		gtest.Assert(adapter.GetOrSetFunc(1, f, 1000), 1)
+		time.Sleep(100 * time.Millisecond)
+		gtest.Assert(count.Val(), 2)
+		gtest.Assert(adapter.Remote().Get(1), 2)
+		adapter.SetEarlyRefresh(0)
+		gtest.Assert(adapter.GetOrSetFunc(1, f, 1000), 2)
+	})
+}
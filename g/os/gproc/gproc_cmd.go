@@ -0,0 +1,235 @@
+// Copyright 2019 gf Author(https://github.com/gogf/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package gproc
+
+import (
+    "bytes"
+    "context"
+    "io"
+    "os"
+    "os/exec"
+    "strings"
+    "sync"
+    "time"
+)
+
+// 命令执行对象，通过链式操作设置执行参数，参数直接传递给命令，不经过shell解析
+type Cmd struct {
+    name     string             // 命令名称或者路径
+    args     []string           // 命令参数
+    dir      string             // 工作目录
+    env      []string           // 覆盖的环境变量(K=V)
+    stdin    io.Reader          // 标准输入
+    timeout  time.Duration      // 执行超时时间
+    ctx      context.Context    // 执行上下文
+    onStdout func(line string)  // 标准输出按行回调
+    onStderr func(line string)  // 标准错误输出按行回调
+}
+
+// 命令执行结果
+type Result struct {
+    Pid      int           // 进程ID
+    ExitCode int           // 退出状态码，被信号终止或者未能执行时为-1
+    Duration time.Duration // 执行时间
+    Stdout   []byte        // 标准输出内容
+    Stderr   []byte        // 标准错误输出内容
+}
+
+// 按行回调的输出写入对象，不完整的行缓存到下一次写入，结束时通过flush回调
+type lineWriter struct {
+    mu      sync.Mutex
+    buffer  []byte
+    handler func(line []byte)
+}
+
+// 创建一个命令执行对象，args为命令参数
+func Command(name string, args...string) *Cmd {
+    return &Cmd {
+        name : name,
+        args : args,
+    }
+}
+
+// 创建一个通过shell执行命令字符串的命令执行对象
+func ShellCommand(cmd string) *Cmd {
+    return Command(getShell(), getShellOption(), cmd)
+}
+
+// 设置工作目录，默认为当前进程的工作目录
+func (c *Cmd) Dir(dir string) *Cmd {
+    c.dir = dir
+    return c
+}
+
+// 设置环境变量，格式为K=V，在当前进程的环境变量基础上覆盖同名的环境变量
+func (c *Cmd) Env(env...string) *Cmd {
+    c.env = append(c.env, env...)
+    return c
+}
+
+// 设置标准输入
+func (c *Cmd) Stdin(reader io.Reader) *Cmd {
+    c.stdin = reader
+    return c
+}
+
+// 设置执行超时时间，超时后命令所在的进程组将被Kill
+func (c *Cmd) Timeout(timeout time.Duration) *Cmd {
+    c.timeout = timeout
+    return c
+}
+
+// 设置执行上下文，上下文结束后命令所在的进程组将被Kill
+func (c *Cmd) Context(ctx context.Context) *Cmd {
+    c.ctx = ctx
+    return c
+}
+
+// 设置标准输出的按行回调方法(不包含换行符)
+func (c *Cmd) OnStdout(handler func(line string)) *Cmd {
+    c.onStdout = handler
+    return c
+}
+
+// 设置标准错误输出的按行回调方法(不包含换行符)
+func (c *Cmd) OnStderr(handler func(line string)) *Cmd {
+    c.onStderr = handler
+    return c
+}
+
+// 阻塞执行命令，返回执行结果。命令未能执行时返回的Result为nil；
+// 命令退出状态码不为0时返回*exec.ExitError；超时或者上下文结束时返回上下文的错误。
+func (c *Cmd) Run() (*Result, error) {
+    ctx := c.ctx
+    if ctx == nil {
+        ctx = context.Background()
+    }
+    if c.timeout > 0 {
+        var cancel context.CancelFunc
+        ctx, cancel = context.WithTimeout(ctx, c.timeout)
+        defer cancel()
+    }
+    if err := ctx.Err(); err != nil {
+        return nil, err
+    }
+    var (
+        stdout = bytes.NewBuffer(nil)
+        stderr = bytes.NewBuffer(nil)
+        outW   = newLineWriter(stdout, c.onStdout)
+        errW   = newLineWriter(stderr, c.onStderr)
+        cmd    = exec.Command(c.name, c.args...)
+    )
+    cmd.Dir    = c.dir
+    cmd.Env    = mergeEnv(os.Environ(), c.env)
+    cmd.Stdin  = c.stdin
+    cmd.Stdout = outW
+    cmd.Stderr = errW
+    setProcessGroup(cmd)
+    start := time.Now()
+    if err := cmd.Start(); err != nil {
+        return nil, err
+    }
+    done := make(chan error, 1)
+    go func() {
+        done <- cmd.Wait()
+    }()
+    var err error
+    select {
+        case err = <-done:
+        case <-ctx.Done():
+            killProcessGroup(cmd.Process)
+            <-done
+            err = ctx.Err()
+    }
+    outW.flush()
+    errW.flush()
+    result := &Result {
+        Pid      : cmd.Process.Pid,
+        ExitCode : -1,
+        Duration : time.Since(start),
+        Stdout   : stdout.Bytes(),
+        Stderr   : stderr.Bytes(),
+    }
+    if cmd.ProcessState != nil {
+        result.ExitCode = cmd.ProcessState.ExitCode()
+    }
+    return result, err
+}
+
+// 标准输出内容(去掉首尾空白字符)
+func (r *Result) String() string {
+    return strings.TrimSpace(string(r.Stdout))
+}
+
+// 创建按行回调的输出写入对象，writer不为nil时同时写入原始内容，handler为nil时不回调
+func newLineWriter(writer io.Writer, handler func(line string)) *lineWriter {
+    return &lineWriter {
+        handler : func(line []byte) {
+            if writer != nil {
+                writer.Write(line)
+            }
+            if handler != nil {
+                handler(string(bytes.TrimRight(bytes.TrimSuffix(line, []byte{'\n'}), "\r")))
+            }
+        },
+    }
+}
+
+// 写入输出内容，每一个完整的行(包含换行符)回调一次
+func (w *lineWriter) Write(data []byte) (int, error) {
+    w.mu.Lock()
+    defer w.mu.Unlock()
+    w.buffer = append(w.buffer, data...)
+    for {
+        index := bytes.IndexByte(w.buffer, '\n')
+        if index == -1 {
+            break
+        }
+        w.handler(w.buffer[ : index + 1])
+        w.buffer = w.buffer[index + 1 : ]
+    }
+    return len(data), nil
+}
+
+// 回调缓存的不完整行
+func (w *lineWriter) flush() {
+    if w == nil {
+        return
+    }
+    w.mu.Lock()
+    defer w.mu.Unlock()
+    if len(w.buffer) > 0 {
+        w.handler(w.buffer)
+        w.buffer = nil
+    }
+}
+
+// 使用env覆盖base中同名的环境变量，返回新的环境变量列表
+func mergeEnv(base []string, env []string) []string {
+    if len(env) == 0 {
+        return base
+    }
+    keys := make(map[string]struct{}, len(env))
+    for _, v := range env {
+        keys[envKey(v)] = struct{}{}
+    }
+    result := make([]string, 0, len(base) + len(env))
+    for _, v := range base {
+        if _, ok := keys[envKey(v)]; !ok {
+            result = append(result, v)
+        }
+    }
+    return append(result, env...)
+}
+
+// 获取K=V格式环境变量的键名
+func envKey(kv string) string {
+    if index := strings.IndexByte(kv, '='); index != -1 {
+        return kv[ : index]
+    }
+    return kv
+}
@@ -0,0 +1,31 @@
+// Copyright 2019 gf Author(https://github.com/gogf/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://github.com/gogf/gf.
+
+// +build !windows
+
+package gproc
+
+import (
+    "os"
+    "os/exec"
+    "syscall"
+)
+
+// 将命令放到新的进程组中执行，以便结束时Kill整个进程组
+func setProcessGroup(cmd *exec.Cmd) {
+    if cmd.SysProcAttr == nil {
+        cmd.SysProcAttr = &syscall.SysProcAttr{}
+    }
+    cmd.SysProcAttr.Setpgid = true
+}
+
+// Kill进程所在的整个进程组，包括其创建的子进程
+func killProcessGroup(process *os.Process) error {
+    if err := syscall.Kill(-process.Pid, syscall.SIGKILL); err != nil {
+        return process.Kill()
+    }
+    return nil
+}
@@ -9,23 +9,335 @@
 package gproc
 
 import (
-    "os"
+    "errors"
+    "fmt"
+    "github.com/gogf/gf/g/container/gmap"
+    "github.com/gogf/gf/g/container/gqueue"
+    "github.com/gogf/gf/g/container/gtype"
+    "github.com/gogf/gf/g/encoding/gbinary"
+    "github.com/gogf/gf/g/net/gtcp"
     "github.com/gogf/gf/g/os/gfile"
     "github.com/gogf/gf/g/util/gconv"
-    "github.com/gogf/gf/g/container/gmap"
+    "net"
+    "os"
+    "sync"
+    "time"
+)
+
+const (
+    gPROC_COMM_KIND_MSG     = 1                // 普通消息，接收进程放入消息队列后回复确认
+    gPROC_COMM_KIND_REQUEST = 2                // 请求消息，接收进程通过Msg.Reply回复
+    gPROC_COMM_KIND_ACK     = 3                // 消息确认
+    gPROC_COMM_KIND_REPLY   = 4                // 请求回复
+    gPROC_COMM_KIND_ERROR   = 5                // 错误回复，数据为错误信息
+    gPROC_COMM_VERSION      = 1                // 数据帧格式版本，格式变化时递增，接收方拒绝不支持的版本
+    gPROC_COMM_HEADER_SIZE  = 16               // 数据帧头部大小
+    gPROC_COMM_CHUNK_SIZE   = 64*1024          // 每个数据帧的最大数据大小(byte)，超过时分块发送
+    gPROC_COMM_MAX_MSG_SIZE = 64*1024*1024     // 单个消息的最大数据大小(byte)
 )
 
 // 本地进程通信接收消息队列(按照分组进行构建的map，键值为*gqueue.Queue对象)
 var commReceiveQueues = gmap.NewStrAnyMap()
 
-// (用于发送)已建立的PID对应的Conn通信对象，键值为一个Pool，防止并行使用同一个通信对象造成数据重叠
+// (用于发送)已建立的PID对应的持久通信链接，键值为*commConn对象，同一链接上可以并发发送多个消息
 var commPidConnMap    = gmap.NewIntAnyMap()
 
+// 消息ID生成器，用于匹配消息与确认/回复
+var commMsgId         = gtype.NewUint64()
+
+// 进程间通信数据帧的打包选项
+var commPkgOption     = gtcp.PkgOption {
+    MaxSize : gPROC_COMM_HEADER_SIZE + 255 + gPROC_COMM_CHUNK_SIZE,
+    Codec   : gtcp.NewLengthCodec(4),
+}
+
 // TCP通信数据结构定义
 type Msg struct {
-    Pid   int     // PID，来源哪个进程
-    Data  []byte  // 数据
-    Group string  // 分组名称
+    Pid     int         // PID，来源哪个进程
+    Data    []byte      // 数据
+    Group   string      // 分组名称
+    conn    *commConn   // 接收该消息的链接，用于回复请求
+    id      uint64      // 消息ID
+    request bool        // 是否为请求消息(通过Request发送)
+    replied *gtype.Bool // 请求是否已回复
+}
+
+// 进程间通信数据帧
+// 数据格式：版本(8bit)|类型(8bit)|是否有后续分块(8bit)|消息ID(64bit)|发送进程PID(32bit)|分组长度(8bit)|分组名称(变长)|数据(变长)
+type commFrame struct {
+    kind  int
+    more  bool
+    id    uint64
+    pid   int
+    group string
+    data  []byte
+}
+
+// 进程间持久通信链接，发送方与接收方使用相同的结构
+type commConn struct {
+    pid     int                          // 对端进程PID，接收方链接为0
+    conn    *gtcp.Conn                   // 底层链接(Unix Domain Socket或者本地TCP)
+    sendMu  sync.Mutex                   // 发送数据帧的互斥锁
+    mu      sync.Mutex                   // pending以及err的互斥锁
+    pending map[uint64]chan *commFrame   // 等待确认/回复的消息
+    err     error                        // 链接中断的错误
+    chunks  map[uint64]*commFrame        // 接收中的分块消息，只在接收goroutine中访问
+}
+
+// 回复请求消息，只能回复一次
+func (m *Msg) Reply(data []byte) error {
+    if !m.request || m.conn == nil {
+        return errors.New("message is not a request")
+    }
+    if len(data) > gPROC_COMM_MAX_MSG_SIZE {
+        return fmt.Errorf("message size %d exceeds the limit %d", len(data), gPROC_COMM_MAX_MSG_SIZE)
+    }
+    if m.replied.Set(true) {
+        return errors.New("request is already replied")
+    }
+    _, err := m.conn.send(&commFrame {
+        kind : gPROC_COMM_KIND_REPLY,
+        id   : m.id,
+        pid  : Pid(),
+        data : data,
+    }, gPROC_COMM_SEND_TIMEOUT*time.Millisecond)
+    return err
+}
+
+// 创建通信链接对象
+func newCommConn(conn net.Conn, pid int) *commConn {
+    return &commConn {
+        pid     : pid,
+        conn    : gtcp.NewConnByNetConn(conn),
+        pending : make(map[uint64]chan *commFrame),
+        chunks  : make(map[uint64]*commFrame),
+    }
+}
+
+// 发送消息并等待确认/回复，返回回复的数据，以及消息是否已经(部分)发送
+func (c *commConn) call(kind int, group string, data []byte, timeout time.Duration) (result []byte, sent bool, err error) {
+    id := commMsgId.Add(1)
+    ch := make(chan *commFrame, 1)
+    c.mu.Lock()
+    if c.err != nil {
+        c.mu.Unlock()
+        return nil, false, c.err
+    }
+    c.pending[id] = ch
+    c.mu.Unlock()
+    defer func() {
+        c.mu.Lock()
+        delete(c.pending, id)
+        c.mu.Unlock()
+    }()
+    if sent, err := c.send(&commFrame {
+        kind  : kind,
+        id    : id,
+        pid   : Pid(),
+        group : group,
+        data  : data,
+    }, timeout); err != nil {
+        return nil, sent, err
+    }
+    timer := time.NewTimer(timeout)
+    defer timer.Stop()
+    select {
+        case f := <-ch:
+            if f == nil {
+                return nil, true, c.lastError()
+            }
+            if f.kind == gPROC_COMM_KIND_ERROR {
+                return nil, true, errors.New(string(f.data))
+            }
+            return f.data, true, nil
+
+        case <-timer.C:
+            return nil, true, errors.New("waiting for peer process response timeout")
+    }
+}
+
+// 发送消息，超过分块大小的数据分块发送，发送失败时中断链接。
+// 返回的sent表示是否已有数据帧发送成功，即对端可能已收到部分数据。
+func (c *commConn) send(f *commFrame, timeout time.Duration) (sent bool, err error) {
+    data := f.data
+    for {
+        chunk := data
+        if len(chunk) > gPROC_COMM_CHUNK_SIZE {
+            chunk = chunk[ : gPROC_COMM_CHUNK_SIZE]
+        }
+        data = data[len(chunk):]
+        c.sendMu.Lock()
+        err = c.conn.SendPkgWithTimeout(encodeCommFrame(&commFrame {
+            kind  : f.kind,
+            more  : len(data) > 0,
+            id    : f.id,
+            pid   : f.pid,
+            group : f.group,
+            data  : chunk,
+        }), timeout, commPkgOption)
+        c.sendMu.Unlock()
+        if err != nil {
+            c.fail(err)
+            c.conn.Close()
+            return sent, err
+        }
+        sent = true
+        if len(data) == 0 {
+            return sent, nil
+        }
+    }
+}
+
+// 接收数据帧直到链接中断
+func (c *commConn) receive() {
+    for {
+        buffer, err := c.conn.RecvPkg(commPkgOption)
+        if err != nil {
+            c.fail(err)
+            break
+        }
+        f, err := decodeCommFrame(buffer)
+        if err != nil {
+            c.fail(err)
+            break
+        }
+        c.handle(f)
+    }
+    c.conn.Close()
+    if c.pid > 0 {
+        commPidConnMap.LockFunc(func(m map[int]interface{}) {
+            if m[c.pid] == c {
+                delete(m, c.pid)
+            }
+        })
+    }
+}
+
+// 处理接收到的数据帧，分块的数据帧合并完整后再处理
+func (c *commConn) handle(f *commFrame) {
+    if prev, ok := c.chunks[f.id]; ok {
+        // 超过大小限制的消息丢弃后续分块(类型标记为0)，直到最后一个分块
+        if prev.kind == 0 || len(prev.data) + len(f.data) > gPROC_COMM_MAX_MSG_SIZE {
+            if prev.kind == gPROC_COMM_KIND_MSG || prev.kind == gPROC_COMM_KIND_REQUEST {
+                c.reply(gPROC_COMM_KIND_ERROR, f.id, []byte("message size exceeds the limit"))
+            }
+            prev.kind = 0
+            prev.data = nil
+            if !f.more {
+                delete(c.chunks, f.id)
+            }
+            return
+        }
+        prev.data = append(prev.data, f.data...)
+        prev.more = f.more
+        f         = prev
+    }
+    if f.more {
+        c.chunks[f.id] = f
+        return
+    }
+    delete(c.chunks, f.id)
+    switch f.kind {
+        case gPROC_COMM_KIND_ACK, gPROC_COMM_KIND_REPLY, gPROC_COMM_KIND_ERROR:
+            c.mu.Lock()
+            ch := c.pending[f.id]
+            delete(c.pending, f.id)
+            c.mu.Unlock()
+            if ch != nil {
+                ch <- f
+            }
+
+        case gPROC_COMM_KIND_MSG, gPROC_COMM_KIND_REQUEST:
+            c.deliver(f)
+    }
+}
+
+// 将接收完整的消息放入分组消息队列，队列已满时阻塞，从而阻塞该链接的发送方(背压)
+func (c *commConn) deliver(f *commFrame) {
+    v := commReceiveQueues.Get(f.group)
+    if v == nil {
+        c.reply(gPROC_COMM_KIND_ERROR, f.id, []byte("group ["+ f.group +"] does not exist"))
+        return
+    }
+    v.(*gqueue.Queue).Push(&Msg {
+        Pid     : f.pid,
+        Data    : f.data,
+        Group   : f.group,
+        conn    : c,
+        id      : f.id,
+        request : f.kind == gPROC_COMM_KIND_REQUEST,
+        replied : gtype.NewBool(),
+    })
+    if f.kind == gPROC_COMM_KIND_MSG {
+        c.reply(gPROC_COMM_KIND_ACK, f.id, nil)
+    }
+}
+
+// 回复确认/错误信息
+func (c *commConn) reply(kind int, id uint64, data []byte) {
+    c.send(&commFrame {
+        kind : kind,
+        id   : id,
+        pid  : Pid(),
+        data : data,
+    }, gPROC_COMM_SEND_TIMEOUT*time.Millisecond)
+}
+
+// 中断链接，所有等待确认/回复的消息返回错误
+func (c *commConn) fail(err error) {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+    if c.err == nil {
+        c.err = err
+    }
+    for id, ch := range c.pending {
+        close(ch)
+        delete(c.pending, id)
+    }
+}
+
+// 获取链接中断的错误，链接正常时返回nil
+func (c *commConn) lastError() error {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+    return c.err
+}
+
+// 数据帧打包
+func encodeCommFrame(f *commFrame) []byte {
+    more := 0
+    if f.more {
+        more = 1
+    }
+    buffer := make([]byte, 0, gPROC_COMM_HEADER_SIZE + len(f.group) + len(f.data))
+    buffer  = append(buffer, gPROC_COMM_VERSION, byte(f.kind), byte(more))
+    buffer  = append(buffer, gbinary.EncodeUint64(f.id)...)
+    buffer  = append(buffer, gbinary.EncodeUint32(uint32(f.pid))...)
+    buffer  = append(buffer, byte(len(f.group)))
+    buffer  = append(buffer, f.group...)
+    return append(buffer, f.data...)
+}
+
+// 数据帧解包，数据字段从buffer中复制
+func decodeCommFrame(buffer []byte) (*commFrame, error) {
+    if len(buffer) < gPROC_COMM_HEADER_SIZE {
+        return nil, errors.New("invalid message frame")
+    }
+    if buffer[0] != gPROC_COMM_VERSION {
+        return nil, fmt.Errorf("unsupported message frame version %d", buffer[0])
+    }
+    groupLen := int(buffer[15])
+    if len(buffer) < gPROC_COMM_HEADER_SIZE + groupLen {
+        return nil, errors.New("invalid message frame")
+    }
+    return &commFrame {
+        kind  : int(buffer[1]),
+        more  : buffer[2] == 1,
+        id    : gbinary.DecodeToUint64(buffer[3 : 11]),
+        pid   : int(gbinary.DecodeToUint32(buffer[11 : 15])),
+        group : string(buffer[gPROC_COMM_HEADER_SIZE : gPROC_COMM_HEADER_SIZE + groupLen]),
+        data  : append([]byte(nil), buffer[gPROC_COMM_HEADER_SIZE + groupLen:]...),
+    }, nil
 }
 
 // 获取指定进程的通信文件地址
@@ -33,6 +345,11 @@ func getCommFilePath(pid int) string {
     return getCommDirPath() + gfile.Separator + gconv.String(pid)
 }
 
+// 获取指定进程的Unix Domain Socket文件地址
+func getCommSockPath(pid int) string {
+    return getCommFilePath(pid) + ".sock"
+}
+
 // 获取进程间通信目录地址
 func getCommDirPath() string {
     tempDir := os.Getenv(gPROC_TEMP_DIR_ENV_KEY)
@@ -40,4 +357,4 @@ func getCommDirPath() string {
         tempDir = gfile.TempDir()
     }
     return tempDir + gfile.Separator + "gproc"
-}
\ No newline at end of file
+}
@@ -12,11 +12,10 @@ package gproc
 import (
     "fmt"
     "net"
+    "os"
     "github.com/gogf/gf/g/os/glog"
-    "github.com/gogf/gf/g/net/gtcp"
     "github.com/gogf/gf/g/os/gfile"
     "github.com/gogf/gf/g/util/gconv"
-    "github.com/gogf/gf/g/encoding/gbinary"
     "github.com/gogf/gf/g/container/gqueue"
     "github.com/gogf/gf/g/container/gtype"
 )
@@ -27,16 +26,18 @@ const (
 )
 
 var (
-    // 是否已开启TCP端口监听服务
+    // 是否已开启通信监听服务
     tcpListened = gtype.NewBool()
 )
 
 // 获取其他进程传递到当前进程的消息包，阻塞执行。
 // 进程只有在执行该方法后才会打开请求端口，默认情况下不允许进程间通信。
+// 分组消息队列已满时，发送方将被阻塞直到队列有空闲位置。
+// 通过Request发送的消息需要使用Msg.Reply进行回复。
 func Receive(group...string) *Msg {
     // 一个进程只能开启一个监听goroutine
     if tcpListened.Set(true) == false {
-        go startTcpListening()
+        go startListening()
     }
     queue     := (*gqueue.Queue)(nil)
     groupName := gPROC_COMM_DEAFULT_GRUOP_NAME
@@ -62,101 +63,46 @@ func Receive(group...string) *Msg {
     return nil
 }
 
-// 创建本地进程TCP通信服务
-func startTcpListening() {
-    var listen *net.TCPListener
-    for i := gPROC_DEFAULT_TCP_PORT; ; i++ {
-        addr, err := net.ResolveTCPAddr("tcp", fmt.Sprintf("127.0.0.1:%d", i))
-        if err != nil {
-            continue
-        }
-        listen, err = net.ListenTCP("tcp", addr)
-        if err != nil {
-            continue
-        }
-        // 将监听的端口保存到通信文件中(字符串类型存放)
-        gfile.PutContents(getCommFilePath(Pid()), gconv.String(i))
-        break
+// 创建本地进程通信服务，优先使用Unix Domain Socket，不支持时使用本地TCP端口
+func startListening() {
+    listen := listenUnix()
+    if listen == nil {
+        listen = listenTcp()
     }
     for  {
         if conn, err := listen.Accept(); err != nil {
             glog.Error(err)
         } else if conn != nil {
-            go tcpServiceHandler(gtcp.NewConnByNetConn(conn))
+            go newCommConn(conn, 0).receive()
         }
     }
 }
 
-// TCP数据通信处理回调函数
-func tcpServiceHandler(conn *gtcp.Conn) {
-    retry := gtcp.Retry {
-        Count   : 3,
-        Interval: 10,
+// 创建Unix Domain Socket监听，并将"unix:"前缀的文件地址保存到通信文件中，失败时返回nil
+func listenUnix() net.Listener {
+    path := getCommSockPath(Pid())
+    if err := gfile.Mkdir(getCommDirPath()); err != nil {
+        return nil
     }
-    for {
-        var result []byte
-        buffer, err := conn.Recv(-1, retry)
-        if len(buffer) > 0 {
-            var msgs []*Msg
-            for _, msg := range bufferToMsgs(buffer) {
-                if v := commReceiveQueues.Get(msg.Group); v != nil {
-                    msgs = append(msgs, msg)
-                } else {
-                    result = []byte(fmt.Sprintf("group [%s] does not exist", msg.Group))
-                    break
-                }
-            }
-            // 成功时会返回ok给peer
-            if len(result) == 0 {
-                result = []byte("ok")
-                for _, msg := range msgs {
-                    if v := commReceiveQueues.Get(msg.Group); v != nil {
-                        v.(*gqueue.Queue).Push(msg)
-                    }
-                }
-            }
-        }
-        // 产生错误(或者对方已经关闭链接)时，退出接收循环
-        if err == nil {
-            conn.Send(result, retry)
-        } else {
-            conn.Close()
-            return
-        }
+    // 删除相同PID的进程遗留的文件
+    os.Remove(path)
+    listen, err := net.Listen("unix", path)
+    if err != nil {
+        return nil
     }
+    gfile.PutContents(getCommFilePath(Pid()), "unix:" + path)
+    return listen
 }
 
-// 数据解包，防止黏包
-// 数据格式：总长度(24bit)|发送进程PID(24bit)|接收进程PID(24bit)|分组长度(8bit)|分组名称(变长)|校验(32bit)|参数(变长)
-func bufferToMsgs(buffer []byte) []*Msg {
-    s    := 0
-    msgs := make([]*Msg, 0)
-    for s < len(buffer) {
-        // 长度解析及校验
-        length := gbinary.DecodeToInt(buffer[s : s + 3])
-        if length < 14 || length > len(buffer) {
-            s++
-            continue
-        }
-        // 分组信息解析
-        groupLen  := gbinary.DecodeToInt(buffer[s + 9 : s + 10])
-        // checksum校验(仅对参数做校验，提高校验效率)
-        checksum1 := gbinary.DecodeToUint32(buffer[s + 10 + groupLen : s + 10 + groupLen + 4])
-        checksum2 := gtcp.Checksum(buffer[s + 10 + groupLen + 4 : s + length])
-        if checksum1 != checksum2 {
-            s++
+// 创建本地TCP端口监听，并将"tcp:"前缀的端口号保存到通信文件中，
+// 不带前缀的端口号表示旧版本的进程，发送方使用旧版本的数据格式与其通信
+func listenTcp() net.Listener {
+    for i := gPROC_DEFAULT_TCP_PORT; ; i++ {
+        listen, err := net.Listen("tcp", fmt.Sprintf("127.0.0.1:%d", i))
+        if err != nil {
             continue
         }
-        // 接收进程PID校验
-        if Pid() ==  gbinary.DecodeToInt(buffer[s + 6 : s + 9]) {
-            msgs = append(msgs, &Msg {
-                Pid   : gbinary.DecodeToInt(buffer[s + 3 : s + 6]),
-                Data  : buffer[s + 10 + groupLen + 4 : s + length],
-                Group : string(buffer[s + 10 : s + 10 + groupLen]),
-            })
-        }
-        s += length
+        gfile.PutContents(getCommFilePath(Pid()), "tcp:" + gconv.String(i))
+        return listen
     }
-    return msgs
 }
-
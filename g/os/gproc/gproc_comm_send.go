@@ -12,79 +12,141 @@ import (
     "fmt"
     "github.com/gogf/gf/g/encoding/gbinary"
     "github.com/gogf/gf/g/net/gtcp"
-    "github.com/gogf/gf/g/os/gfcache"
+    "github.com/gogf/gf/g/os/gfile"
     "github.com/gogf/gf/g/os/glog"
     "github.com/gogf/gf/g/util/gconv"
     "io"
+    "net"
+    "strings"
     "time"
 )
 
 const (
-    gPROC_COMM_FAILURE_RETRY_COUNT   = 3    // 失败重试次数
-    gPROC_COMM_FAILURE_RETRY_TIMEOUT = 1000 // (毫秒)失败重试间隔
-    gPROC_COMM_SEND_TIMEOUT          = 5000 // (毫秒)发送超时时间
-    gPROC_COMM_DEAFULT_GRUOP_NAME    = ""   // 默认分组名称
+    gPROC_COMM_FAILURE_RETRY_COUNT   = 3     // 失败重试次数
+    gPROC_COMM_FAILURE_RETRY_TIMEOUT = 1000  // (毫秒)失败重试间隔
+    gPROC_COMM_SEND_TIMEOUT          = 10000 // (毫秒)发送超时时间，包括等待对端确认的时间
+    gPROC_COMM_REQUEST_TIMEOUT       = 30000 // (毫秒)请求超时时间，包括等待对端回复的时间
+    gPROC_COMM_DEAFULT_GRUOP_NAME    = ""    // 默认分组名称
 )
 
-// 向指定gproc进程发送数据.
-// 数据格式：总长度(24bit)|发送进程PID(24bit)|接收进程PID(24bit)|分组长度(8bit)|分组名称(变长)|校验(32bit)|参数(变长)
+// 向指定gproc进程发送数据，阻塞等待对端进程确认消息已放入消息队列。
+// 对端消息队列已满时阻塞等待(最长为发送超时时间)，而不是丢弃消息。
+// 超过分块大小的数据将被分块发送，单个消息最大为64MB。
 func Send(pid int, data []byte, group...string) error {
+    _, err := doSend(pid, gPROC_COMM_KIND_MSG, data, gPROC_COMM_SEND_TIMEOUT*time.Millisecond, group...)
+    return err
+}
+
+// 向指定gproc进程发送请求，并阻塞等待对端进程通过Msg.Reply回复，返回回复的数据。
+func Request(pid int, data []byte, group...string) ([]byte, error) {
+    return doSend(pid, gPROC_COMM_KIND_REQUEST, data, gPROC_COMM_REQUEST_TIMEOUT*time.Millisecond, group...)
+}
+
+// 执行发送流程，链接失败并且消息未发送时重试
+func doSend(pid int, kind int, data []byte, timeout time.Duration, group...string) ([]byte, error) {
     groupName := gPROC_COMM_DEAFULT_GRUOP_NAME
     if len(group) > 0 {
         groupName = group[0]
     }
-    buffer := make([]byte, 0)
-    buffer  = append(buffer, gbinary.EncodeByLength(3, len(groupName) + len(data) + 14)...)
-    buffer  = append(buffer, gbinary.EncodeByLength(3, Pid())...)
-    buffer  = append(buffer, gbinary.EncodeByLength(3, pid)...)
-    buffer  = append(buffer, gbinary.EncodeByLength(1, len(groupName))...)
-    buffer  = append(buffer, []byte(groupName)...)
-    buffer  = append(buffer, gbinary.EncodeUint32(gtcp.Checksum(data))...)
-    buffer  = append(buffer, data...)
-    // 执行发送流程
-    var err  error
-    var buf  []byte
-    var conn *gtcp.Conn
+    if len(groupName) > 255 {
+        return nil, errors.New("group name too long")
+    }
+    if len(data) > gPROC_COMM_MAX_MSG_SIZE {
+        return nil, fmt.Errorf("message size %d exceeds the limit %d", len(data), gPROC_COMM_MAX_MSG_SIZE)
+    }
+    // 对端为旧版本的进程时，使用旧版本的数据格式发送(不支持请求消息)
+    if network, address, legacy, err := getCommAddressByPid(pid); err == nil && legacy {
+        if kind != gPROC_COMM_KIND_MSG {
+            return nil, fmt.Errorf("process %d does not support requests", pid)
+        }
+        return nil, sendLegacy(network, address, pid, groupName, data, timeout)
+    }
+    var err error
     for i := gPROC_COMM_FAILURE_RETRY_COUNT; i > 0; i-- {
-        if conn, err = getConnByPid(pid); err == nil {
-            defer conn.Close()
-            buf, err = conn.SendRecvWithTimeout(buffer, -1, gPROC_COMM_SEND_TIMEOUT*time.Millisecond)
-            if len(buf) > 0 {
-                // 如果有返回值，如果不是"ok"，那么表示是错误信息
-                if !bytes.EqualFold(buf, []byte("ok")) {
-                    err = errors.New(string(buf))
-                    break
-                }
-            }
-            // EOF不算异常错误
-            if err == nil || err == io.EOF {
-                break
-            } else {
-                glog.Error(err)
+        var conn *commConn
+        if conn, err = getCommConnByPid(pid); err == nil {
+            result, sent, e := conn.call(kind, groupName, data, timeout)
+            // 消息已发送时不再重试，防止对端重复接收
+            if e == nil || sent {
+                return result, e
             }
+            err = e
         }
+        glog.Error(err)
         time.Sleep(gPROC_COMM_FAILURE_RETRY_TIMEOUT*time.Millisecond)
     }
-    return err
+    return nil, err
 }
 
-
-// 获取指定进程的TCP通信对象
-func getConnByPid(pid int) (*gtcp.Conn, error) {
-    port := getPortByPid(pid)
-    if port > 0 {
-        if conn, err := gtcp.NewConn(fmt.Sprintf("127.0.0.1:%d", port)); err == nil {
-            return conn, nil
+// 获取指定进程的持久通信链接，不存在或者已中断时创建
+func getCommConnByPid(pid int) (*commConn, error) {
+    if v := commPidConnMap.Get(pid); v != nil && v.(*commConn).lastError() == nil {
+        return v.(*commConn), nil
+    }
+    network, address, _, err := getCommAddressByPid(pid)
+    if err != nil {
+        return nil, err
+    }
+    netConn, err := net.DialTimeout(network, address, gPROC_COMM_SEND_TIMEOUT*time.Millisecond)
+    if err != nil {
+        return nil, err
+    }
+    conn := newCommConn(netConn, pid)
+    commPidConnMap.LockFunc(func(m map[int]interface{}) {
+        // 并发创建时使用已有的链接
+        if v, ok := m[pid]; ok && v.(*commConn).lastError() == nil {
+            netConn.Close()
+            conn = v.(*commConn)
         } else {
-            return nil, err
+            m[pid] = conn
+            go conn.receive()
         }
+    })
+    return conn, nil
+}
+
+// 获取指定进程监听的通信地址，通信文件内容为"unix:"前缀的Unix Domain Socket文件地址，或者"tcp:"前缀的本地TCP端口号。
+// 不带前缀的端口号为旧版本进程的通信地址，此时legacy为true。
+func getCommAddressByPid(pid int) (network, address string, legacy bool, err error) {
+    content := strings.TrimSpace(gfile.GetContents(getCommFilePath(pid)))
+    if strings.HasPrefix(content, "unix:") {
+        return "unix", content[5:], false, nil
     }
-    return nil, errors.New(fmt.Sprintf("could not find port for pid: %d" , pid))
+    if strings.HasPrefix(content, "tcp:") {
+        content = content[4:]
+    } else {
+        legacy  = true
+    }
+    if port := gconv.Int(content); port > 0 {
+        return "tcp", fmt.Sprintf("127.0.0.1:%d", port), legacy, nil
+    }
+    return "", "", false, errors.New(fmt.Sprintf("could not find address for pid: %d" , pid))
 }
 
-// 获取指定进程监听的端口号
-func getPortByPid(pid int) int {
-    path    := getCommFilePath(pid)
-    content := gfcache.GetContents(path)
-    return gconv.Int(content)
-}
\ No newline at end of file
+// 使用旧版本的数据格式向旧版本的进程发送消息，每个消息使用一个新的链接，对端返回"ok"表示接收成功。
+// 数据格式：总长度(24bit)|发送进程PID(24bit)|接收进程PID(24bit)|分组长度(8bit)|分组名称(变长)|校验(32bit)|参数(变长)
+func sendLegacy(network, address string, pid int, group string, data []byte, timeout time.Duration) error {
+    buffer := make([]byte, 0, 14 + len(group) + len(data))
+    buffer  = append(buffer, gbinary.EncodeByLength(3, len(group) + len(data) + 14)...)
+    buffer  = append(buffer, gbinary.EncodeByLength(3, Pid())...)
+    buffer  = append(buffer, gbinary.EncodeByLength(3, pid)...)
+    buffer  = append(buffer, byte(len(group)))
+    buffer  = append(buffer, group...)
+    buffer  = append(buffer, gbinary.EncodeUint32(gtcp.Checksum(data))...)
+    buffer  = append(buffer, data...)
+    netConn, err := net.DialTimeout(network, address, timeout)
+    if err != nil {
+        return err
+    }
+    conn := gtcp.NewConnByNetConn(netConn)
+    defer conn.Close()
+    result, err := conn.SendRecvWithTimeout(buffer, -1, timeout)
+    if len(result) > 0 && !bytes.EqualFold(result, []byte("ok")) {
+        return errors.New(string(result))
+    }
+    // EOF不算异常错误
+    if err == io.EOF {
+        return nil
+    }
+    return err
+}
@@ -9,12 +9,15 @@ package gproc
 
 import (
     "os"
+    "sync"
+    "time"
     "github.com/gogf/gf/g/container/gmap"
 )
 
 // 进程管理器
 type Manager struct {
-    processes *gmap.IntAnyMap // 所管理的子进程map
+    processes    *gmap.IntAnyMap     // 所管理的子进程map
+    eventHandler func(*ProcessEvent) // 所管理子进程的生命周期事件回调
 }
 
 // 创建一个进程管理器
@@ -31,6 +34,11 @@ func (m *Manager) NewProcess(path string, args []string, environment []string) *
     return p
 }
 
+// 设置所管理子进程的生命周期事件回调方法，需要在子进程Start之前设置
+func (m *Manager) SetEventHandler(handler func(*ProcessEvent)) {
+    m.eventHandler = handler
+}
+
 // 获取当前进程管理器中的一个进程
 func (m *Manager) GetProcess(pid int) *Process {
     if v := m.processes.Get(pid); v != nil {
@@ -91,6 +99,28 @@ func (m *Manager) KillAll() error {
     return nil
 }
 
+// 并发平滑停止所有的进程，每个进程等待timeout(默认10秒)后强制Kill，返回第一个失败的错误
+func (m *Manager) StopAll(timeout...time.Duration) error {
+    var err error
+    mu := sync.Mutex{}
+    wg := sync.WaitGroup{}
+    for _, p := range m.Processes() {
+        wg.Add(1)
+        go func(p *Process) {
+            defer wg.Done()
+            if e := p.Stop(timeout...); e != nil {
+                mu.Lock()
+                if err == nil {
+                    err = e
+                }
+                mu.Unlock()
+            }
+        }(p)
+    }
+    wg.Wait()
+    return err
+}
+
 // 向所有进程发送信号量
 func (m *Manager) SignalAll(sig os.Signal) error {
     for _, p := range m.Processes() {
@@ -12,13 +12,36 @@ import (
     "os/exec"
     "errors"
     "strings"
+    "sync"
+    "syscall"
+    "time"
+    "github.com/gogf/gf/g/os/glog"
+    "github.com/gogf/gf/g/container/gtype"
 )
 
 // 子进程
 type Process struct {
     exec.Cmd
-    Manager     *Manager // 所属进程管理器
-    PPid        int      // 自定义关联的父进程ID
+    Manager      *Manager            // 所属进程管理器
+    PPid         int                 // 自定义关联的父进程ID
+    mu           sync.RWMutex        // 进程重启时替换exec.Cmd的互斥锁
+    template     *exec.Cmd           // 首次启动时的执行参数，用于重启时创建新的exec.Cmd
+    policy       int                 // 重启策略
+    maxRestarts  int                 // 最大重启次数，0表示不限制
+    backoffMin   time.Duration       // 重启的最小退避时间
+    backoffMax   time.Duration       // 重启的最大退避时间
+    restarts     *gtype.Int          // 已重启次数
+    stdoutLogger *glog.Logger        // 标准输出日志对象
+    stderrLogger *glog.Logger        // 标准错误输出日志对象
+    eventHandler func(*ProcessEvent) // 生命周期事件回调
+    startTime    time.Time           // 最近一次启动时间
+    exitTime     time.Time           // 最近一次退出时间
+    exitCode     int                 // 最近一次退出状态码，未退出时为-1
+    exitErr      error               // 最近一次退出错误
+    running      *gtype.Bool         // 是否正在运行
+    stopped      *gtype.Bool         // 是否已主动停止(Stop/Kill)，停止后不再重启
+    stopChan     chan struct{}       // 主动停止时关闭，用于中断重启退避等待
+    done         chan struct{}       // 进程监控结束(不再重启)时关闭
 }
 
 // 创建一个进程(不执行)
@@ -45,6 +68,14 @@ func NewProcess(path string, args []string, environment...[]string) *Process {
             Env        : env,
             ExtraFiles : make([]*os.File, 0),
         },
+        policy      : RESTART_NEVER,
+        backoffMin  : gPROC_RESTART_BACKOFF_MIN,
+        backoffMax  : gPROC_RESTART_BACKOFF_MAX,
+        restarts    : gtype.NewInt(),
+        exitCode    : -1,
+        running     : gtype.NewBool(),
+        stopped     : gtype.NewBool(),
+        stopChan    : make(chan struct{}),
     }
     // 当前工作目录
     if d, err := os.Getwd(); err == nil {
@@ -60,20 +91,26 @@ func NewProcess(path string, args []string, environment...[]string) *Process {
     return p
 }
 
-// 开始执行(非阻塞)
+// 开始执行(非阻塞)，进程启动后由后台goroutine监控进程退出，并根据重启策略进行重启
 func (p *Process) Start() (int, error) {
+    p.mu.Lock()
     if p.Process != nil {
+        p.mu.Unlock()
         return p.Pid(), nil
     }
-    p.Env = append(p.Env, fmt.Sprintf("%s=%d", gPROC_ENV_KEY_PPID_KEY, p.PPid))
-    if err := p.Cmd.Start(); err == nil {
-        if p.Manager != nil {
-            p.Manager.processes.Set(p.Process.Pid, p)
-        }
-        return p.Process.Pid, nil
-    } else {
+    p.Env      = append(p.Env, fmt.Sprintf("%s=%d", gPROC_ENV_KEY_PPID_KEY, p.PPid))
+    p.template = p.copyCmd(&p.Cmd)
+    stdout, stderr := p.setOutputWriters(&p.Cmd)
+    if err := p.Cmd.Start(); err != nil {
+        p.mu.Unlock()
         return 0, err
     }
+    p.started()
+    p.done = make(chan struct{})
+    p.mu.Unlock()
+    p.notify(PROCESS_EVENT_START, nil)
+    go p.supervise(stdout, stderr)
+    return p.Pid(), nil
 }
 
 // 运行进程(阻塞等待执行完毕)
@@ -85,8 +122,20 @@ func (p *Process) Run() error {
     }
 }
 
+// 阻塞等待进程结束，对于设置了重启策略的进程，将会等待直到进程不再重启，
+// 返回最近一次退出的错误
+func (p *Process) Wait() error {
+    if done := p.getDone(); done != nil {
+        <-done
+        return p.ExitError()
+    }
+    return p.Cmd.Wait()
+}
+
 // PID
 func (p *Process) Pid() int {
+    p.mu.RLock()
+    defer p.mu.RUnlock()
     if p.Process != nil {
         return p.Process.Pid
     }
@@ -95,8 +144,8 @@ func (p *Process) Pid() int {
 
 // 向进程发送消息
 func (p *Process) Send(data []byte) error {
-    if p.Process != nil {
-        return Send(p.Process.Pid, data)
+    if pid := p.Pid(); pid > 0 {
+        return Send(pid, data)
     }
     return errors.New("invalid process")
 }
@@ -106,12 +155,14 @@ func (p *Process) Send(data []byte) error {
 // rendering it unusable in the future.
 // Release only needs to be called if Wait is not.
 func (p *Process) Release() error {
-    return p.Process.Release()
+    return p.getProcess().Release()
 }
 
 // Kill causes the Process to exit immediately.
+// The killed process will not be restarted.
 func (p *Process) Kill() error {
-    if err := p.Process.Kill(); err == nil {
+    p.stop()
+    if err := p.getProcess().Kill(); err == nil {
         if p.Manager != nil {
             p.Manager.processes.Remove(p.Pid())
         }
@@ -124,5 +175,149 @@ func (p *Process) Kill() error {
 // Signal sends a signal to the Process.
 // Sending Interrupt on Windows is not implemented.
 func (p *Process) Signal(sig os.Signal) error {
-    return p.Process.Signal(sig)
+    return p.getProcess().Signal(sig)
+}
+
+// 平滑停止进程：发送SIGTERM信号，等待进程退出，超时(默认10秒)后强制Kill，停止后不再重启。
+// 对于不是由当前进程启动的进程(例如通过Manager.AddProcess添加)，只发送信号而不等待。
+func (p *Process) Stop(timeout...time.Duration) error {
+    p.stop()
+    done := p.getDone()
+    if done == nil {
+        if process := p.getProcess(); process != nil {
+            return process.Signal(syscall.SIGTERM)
+        }
+        return errors.New("invalid process")
+    }
+    if !p.running.Val() {
+        <-done
+        return nil
+    }
+    t := gPROC_STOP_TIMEOUT
+    if len(timeout) > 0 {
+        t = timeout[0]
+    }
+    // Windows下不支持SIGTERM，直接Kill
+    if err := p.Signal(syscall.SIGTERM); err != nil {
+        p.getProcess().Kill()
+    }
+    select {
+        case <-done:
+        case <-time.After(t):
+            p.getProcess().Kill()
+            <-done
+    }
+    return nil
+}
+
+// 设置进程退出后的重启策略(RESTART_NEVER/RESTART_ALWAYS/RESTART_ON_FAILURE)，默认不重启，需要在Start之前设置
+func (p *Process) SetRestartPolicy(policy int) {
+    p.policy = policy
+}
+
+// 设置最大重启次数，0表示不限制
+func (p *Process) SetMaxRestarts(max int) {
+    p.maxRestarts = max
+}
+
+// 设置重启的退避时间，连续失败时退避时间从min开始成倍增加，直到max；
+// 进程运行时间超过max时，退避时间重置为min
+func (p *Process) SetRestartBackoff(min, max time.Duration) {
+    if max < min {
+        max = min
+    }
+    p.backoffMin = min
+    p.backoffMax = max
+}
+
+// 设置进程标准输出和标准错误输出按行写入到指定的日志分类中，
+// 可选参数logger为日志对象，默认使用glog默认日志对象，需要在Start之前设置
+func (p *Process) SetOutputCategory(stdoutCategory, stderrCategory string, logger...*glog.Logger) {
+    if len(logger) > 0 && logger[0] != nil {
+        // Cat会修改已经克隆过的日志对象，因此每个输出流使用独立的克隆对象，不影响调用方的日志对象
+        p.stdoutLogger = logger[0].Clone().Cat(stdoutCategory)
+        p.stderrLogger = logger[0].Clone().Cat(stderrCategory)
+    } else {
+        p.stdoutLogger = glog.Cat(stdoutCategory)
+        p.stderrLogger = glog.Cat(stderrCategory)
+    }
+    // 子进程的输出内容不需要打印当前进程的调用链
+    p.stderrLogger.SetBacktrace(false)
+}
+
+// 设置进程生命周期事件回调方法，需要在Start之前设置
+func (p *Process) SetEventHandler(handler func(*ProcessEvent)) {
+    p.eventHandler = handler
+}
+
+// 进程是否正在运行
+func (p *Process) Running() bool {
+    return p.running.Val()
+}
+
+// 已重启次数
+func (p *Process) Restarts() int {
+    return p.restarts.Val()
+}
+
+// 最近一次启动时间
+func (p *Process) StartTime() time.Time {
+    p.mu.RLock()
+    defer p.mu.RUnlock()
+    return p.startTime
+}
+
+// 最近一次退出时间，未退出时返回零值
+func (p *Process) ExitTime() time.Time {
+    p.mu.RLock()
+    defer p.mu.RUnlock()
+    return p.exitTime
+}
+
+// 最近一次退出的状态码，未退出时返回-1
+func (p *Process) ExitCode() int {
+    p.mu.RLock()
+    defer p.mu.RUnlock()
+    return p.exitCode
+}
+
+// 最近一次退出的错误，正常退出时返回nil
+func (p *Process) ExitError() error {
+    p.mu.RLock()
+    defer p.mu.RUnlock()
+    return p.exitErr
+}
+
+// 最近一次启动后的运行时间(毫秒)，进程已退出时返回退出前的运行时间
+func (p *Process) Uptime() int {
+    p.mu.RLock()
+    defer p.mu.RUnlock()
+    if p.startTime.IsZero() {
+        return 0
+    }
+    if p.running.Val() {
+        return int(time.Since(p.startTime)/time.Millisecond)
+    }
+    return int(p.exitTime.Sub(p.startTime)/time.Millisecond)
+}
+
+// 获取进程监控结束的通知channel，不是由当前进程启动的进程返回nil
+func (p *Process) getDone() chan struct{} {
+    p.mu.RLock()
+    defer p.mu.RUnlock()
+    return p.done
+}
+
+// 获取底层的os.Process对象
+func (p *Process) getProcess() *os.Process {
+    p.mu.RLock()
+    defer p.mu.RUnlock()
+    return p.Process
+}
+
+// 标记进程已主动停止
+func (p *Process) stop() {
+    if !p.stopped.Set(true) {
+        close(p.stopChan)
+    }
 }
@@ -0,0 +1,203 @@
+// Copyright 2019 gf Author(https://github.com/gogf/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package gproc
+
+import (
+    "errors"
+    "os"
+    "os/exec"
+    "time"
+)
+
+// 进程重启策略
+const (
+    RESTART_NEVER      = 0 // 进程退出后不重启(默认)
+    RESTART_ALWAYS     = 1 // 进程退出后总是重启
+    RESTART_ON_FAILURE = 2 // 进程异常退出(退出状态码不为0)时重启
+)
+
+// 进程生命周期事件
+const (
+    PROCESS_EVENT_START   = "Start"   // 进程首次启动
+    PROCESS_EVENT_EXIT    = "Exit"    // 进程退出，或者重启时启动失败
+    PROCESS_EVENT_RESTART = "Restart" // 进程重启成功
+    PROCESS_EVENT_STOP    = "Stop"    // 进程不再重启，监控结束
+)
+
+const (
+    gPROC_RESTART_BACKOFF_MIN = time.Second      // 默认重启的最小退避时间
+    gPROC_RESTART_BACKOFF_MAX = 30*time.Second   // 默认重启的最大退避时间
+    gPROC_STOP_TIMEOUT        = 10*time.Second   // 默认平滑停止的等待时间
+)
+
+// 进程生命周期事件对象
+type ProcessEvent struct {
+    Type     string    // 事件类型
+    Process  *Process  // 进程对象
+    Pid      int       // 事件发生时的进程ID
+    ExitCode int       // 最近一次退出状态码，未退出时为-1
+    Error    error     // 退出错误或者启动错误
+    Time     time.Time // 事件发生时间
+}
+
+// 监控进程退出，并根据重启策略重启进程，直到进程不再重启
+func (p *Process) supervise(stdout, stderr *lineWriter) {
+    defer close(p.done)
+    backoff := p.backoffMin
+    for {
+        err := p.Cmd.Wait()
+        stdout.flush()
+        stderr.flush()
+        p.exited(err)
+        p.notify(PROCESS_EVENT_EXIT, err)
+        for {
+            if !p.needRestart() {
+                p.notify(PROCESS_EVENT_STOP, p.ExitError())
+                return
+            }
+            // 进程运行足够长的时间后退出，表示不是连续失败，重置退避时间
+            if p.ExitTime().Sub(p.StartTime()) >= p.backoffMax {
+                backoff = p.backoffMin
+            }
+            select {
+                case <-p.stopChan:
+                    p.notify(PROCESS_EVENT_STOP, p.ExitError())
+                    return
+                case <-time.After(backoff):
+            }
+            if backoff *= 2; backoff > p.backoffMax {
+                backoff = p.backoffMax
+            }
+            p.restarts.Add(1)
+            if stdout, stderr, err = p.restart(); err == nil {
+                p.notify(PROCESS_EVENT_RESTART, nil)
+                break
+            }
+            p.exited(err)
+            p.notify(PROCESS_EVENT_EXIT, err)
+        }
+    }
+}
+
+// 根据重启策略和重启次数判断是否需要重启进程
+func (p *Process) needRestart() bool {
+    if p.stopped.Val() {
+        return false
+    }
+    switch p.policy {
+        case RESTART_ALWAYS:
+        case RESTART_ON_FAILURE:
+            if p.ExitError() == nil {
+                return false
+            }
+        default:
+            return false
+    }
+    if p.maxRestarts > 0 && p.restarts.Val() >= p.maxRestarts {
+        return false
+    }
+    return true
+}
+
+// 使用首次启动时的执行参数重新启动进程
+func (p *Process) restart() (stdout, stderr *lineWriter, err error) {
+    p.mu.Lock()
+    defer p.mu.Unlock()
+    if p.stopped.Val() {
+        return nil, nil, errors.New("process stopped")
+    }
+    oldPid := 0
+    if p.Process != nil {
+        oldPid = p.Process.Pid
+    }
+    p.Cmd = *p.copyCmd(p.template)
+    stdout, stderr = p.setOutputWriters(&p.Cmd)
+    if err = p.Cmd.Start(); err != nil {
+        return nil, nil, err
+    }
+    p.started()
+    if p.Manager != nil {
+        p.Manager.processes.Remove(oldPid)
+        p.Manager.processes.Set(p.Process.Pid, p)
+    }
+    return
+}
+
+// 记录进程启动状态，调用时需要持有p.mu锁
+func (p *Process) started() {
+    p.startTime = time.Now()
+    p.exitTime  = time.Time{}
+    p.exitCode  = -1
+    p.exitErr   = nil
+    p.running.Set(true)
+    if p.Manager != nil {
+        p.Manager.processes.Set(p.Process.Pid, p)
+    }
+}
+
+// 记录进程退出状态
+func (p *Process) exited(err error) {
+    p.mu.Lock()
+    defer p.mu.Unlock()
+    p.exitTime = time.Now()
+    p.exitErr  = err
+    p.exitCode = -1
+    if p.ProcessState != nil {
+        p.exitCode = p.ProcessState.ExitCode()
+    }
+    p.running.Set(false)
+}
+
+// 回调进程及其管理器的生命周期事件处理方法
+func (p *Process) notify(event string, err error) {
+    e := &ProcessEvent {
+        Type     : event,
+        Process  : p,
+        Pid      : p.Pid(),
+        ExitCode : p.ExitCode(),
+        Error    : err,
+        Time     : time.Now(),
+    }
+    if p.eventHandler != nil {
+        p.eventHandler(e)
+    }
+    if p.Manager != nil && p.Manager.eventHandler != nil {
+        p.Manager.eventHandler(e)
+    }
+}
+
+// 复制进程的执行参数
+func (p *Process) copyCmd(c *exec.Cmd) *exec.Cmd {
+    return &exec.Cmd {
+        Path        : c.Path,
+        Args        : append([]string(nil), c.Args...),
+        Env         : append([]string(nil), c.Env...),
+        Dir         : c.Dir,
+        Stdin       : c.Stdin,
+        Stdout      : c.Stdout,
+        Stderr      : c.Stderr,
+        ExtraFiles  : append([]*os.File(nil), c.ExtraFiles...),
+        SysProcAttr : c.SysProcAttr,
+    }
+}
+
+// 设置了输出日志分类时，将进程的输出按行写入日志，内容前加上进程ID
+func (p *Process) setOutputWriters(c *exec.Cmd) (stdout, stderr *lineWriter) {
+    if logger := p.stdoutLogger; logger != nil {
+        stdout   = newLineWriter(nil, func(line string) {
+            logger.Infof("%d: %s", p.Pid(), line)
+        })
+        c.Stdout = stdout
+    }
+    if logger := p.stderrLogger; logger != nil {
+        stderr   = newLineWriter(nil, func(line string) {
+            logger.Errorf("%d: %s", p.Pid(), line)
+        })
+        c.Stderr = stderr
+    }
+    return
+}
@@ -0,0 +1,125 @@
+// Copyright 2019 gf Author(https://github.com/gogf/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package gproc_test
+
+import (
+	"context"
+	"github.com/gogf/gf/g/os/gproc"
+	"github.com/gogf/gf/g/test/gtest"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func Test_Command_Run(t *testing.T) {
+	gtest.Case(t, func() {
+		result, err := gproc.Command("echo", "hello", "a b", "$HOME").Run()
+		gtest.Assert(err, nil)
+		gtest.Assert(result.ExitCode, 0)
+		gtest.Assert(result.String(), "hello a b $HOME")
+		gtest.Assert(len(result.Stderr), 0)
+		gtest.AssertGT(result.Pid, 0)
+		gtest.Assert(result.Duration > 0, true)
+	})
+	gtest.Case(t, func() {
+		result, err := gproc.ShellCommand("echo out; echo err >&2; exit 3").Run()
+		gtest.AssertNE(err, nil)
+		_, ok := err.(*exec.ExitError)
+		gtest.Assert(ok, true)
+		gtest.Assert(result.ExitCode, 3)
+		gtest.Assert(string(result.Stdout), "out\n")
+		gtest.Assert(string(result.Stderr), "err\n")
+	})
+	gtest.Case(t, func() {
+		result, err := gproc.Command("gproc-command-not-exist").Run()
+		gtest.AssertNE(err, nil)
+		gtest.Assert(result, nil)
+	})
+}
+
+func Test_Command_Options(t *testing.T) {
+	gtest.Case(t, func() {
+		dir := os.TempDir()
+		result, err := gproc.ShellCommand("pwd; echo $GPROC_TEST_A $GPROC_TEST_B; cat").
+			Dir(dir).
+			Env("GPROC_TEST_A=1", "GPROC_TEST_B=2").
+			Stdin(strings.NewReader("input")).
+			Run()
+		gtest.Assert(err, nil)
+		lines := strings.Split(string(result.Stdout), "\n")
+		gtest.Assert(len(lines), 3)
+		resolved, _ := os.Stat(lines[0])
+		expected, _ := os.Stat(dir)
+		gtest.Assert(os.SameFile(resolved, expected), true)
+		gtest.Assert(lines[1], "1 2")
+		gtest.Assert(lines[2], "input")
+	})
+	// Environment variable is overridden rather than duplicated.
+	gtest.Case(t, func() {
+		os.Setenv("GPROC_TEST_C", "old")
+		defer os.Unsetenv("GPROC_TEST_C")
+		result, err := gproc.ShellCommand("env | grep GPROC_TEST_C").Env("GPROC_TEST_C=new").Run()
+		gtest.Assert(err, nil)
+		gtest.Assert(result.String(), "GPROC_TEST_C=new")
+	})
+}
+
+func Test_Command_Callback(t *testing.T) {
+	gtest.Case(t, func() {
+		mu     := sync.Mutex{}
+		stdout := make([]string, 0)
+		stderr := make([]string, 0)
+		result, err := gproc.ShellCommand("echo 1; echo 2 >&2; echo 3; printf 4").
+			OnStdout(func(line string) {
+				mu.Lock()
+				stdout = append(stdout, line)
+				mu.Unlock()
+			}).
+			OnStderr(func(line string) {
+				mu.Lock()
+				stderr = append(stderr, line)
+				mu.Unlock()
+			}).
+			Run()
+		gtest.Assert(err, nil)
+		gtest.Assert(stdout, []string{"1", "3", "4"})
+		gtest.Assert(stderr, []string{"2"})
+		gtest.Assert(string(result.Stdout), "1\n3\n4")
+		gtest.Assert(string(result.Stderr), "2\n")
+	})
+}
+
+func Test_Command_Timeout(t *testing.T) {
+	// The child processes of the command are killed too.
+	gtest.Case(t, func() {
+		start := time.Now()
+		result, err := gproc.ShellCommand("echo started; sleep 10 & sleep 10; echo done").
+			Timeout(200*time.Millisecond).
+			Run()
+		gtest.Assert(err, context.DeadlineExceeded)
+		gtest.Assert(time.Since(start) < 5*time.Second, true)
+		gtest.Assert(result.ExitCode, -1)
+		gtest.Assert(result.String(), "started")
+	})
+	gtest.Case(t, func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(100*time.Millisecond, cancel)
+		result, err := gproc.Command("sleep", "10").Context(ctx).Run()
+		gtest.Assert(err, context.Canceled)
+		gtest.Assert(result.ExitCode, -1)
+	})
+	gtest.Case(t, func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		result, err := gproc.Command("echo").Context(ctx).Run()
+		gtest.Assert(err, context.Canceled)
+		gtest.Assert(result, nil)
+	})
+}
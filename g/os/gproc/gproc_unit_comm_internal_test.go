@@ -0,0 +1,102 @@
+// Copyright 2019 gf Author(https://github.com/gogf/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package gproc
+
+import (
+	"github.com/gogf/gf/g/encoding/gbinary"
+	"github.com/gogf/gf/g/net/gtcp"
+	"github.com/gogf/gf/g/os/gfile"
+	"github.com/gogf/gf/g/test/gtest"
+	"github.com/gogf/gf/g/util/gconv"
+	"net"
+	"testing"
+	"time"
+)
+
+func Test_CommFrame_Version(t *testing.T) {
+	gtest.Case(t, func() {
+		buffer := encodeCommFrame(&commFrame{
+			kind:  gPROC_COMM_KIND_MSG,
+			more:  true,
+			id:    100,
+			pid:   200,
+			group: "group",
+			data:  []byte("data"),
+		})
+		gtest.Assert(buffer[0], gPROC_COMM_VERSION)
+		f, err := decodeCommFrame(buffer)
+		gtest.Assert(err, nil)
+		gtest.Assert(f.kind, gPROC_COMM_KIND_MSG)
+		gtest.Assert(f.more, true)
+		gtest.Assert(f.id, 100)
+		gtest.Assert(f.pid, 200)
+		gtest.Assert(f.group, "group")
+		gtest.Assert(f.data, []byte("data"))
+
+		// Frames of unknown version are rejected.
+		buffer[0] = gPROC_COMM_VERSION + 1
+		_, err = decodeCommFrame(buffer)
+		gtest.AssertNE(err, nil)
+	})
+}
+
+func Test_Comm_SendLegacy(t *testing.T) {
+	// Fake process of old version, which listens on TCP and writes the port without prefix.
+	listen, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listen.Close()
+	received := make(chan []byte, 1)
+	go func() {
+		conn, err := listen.Accept()
+		if err != nil {
+			return
+		}
+		c := gtcp.NewConnByNetConn(conn)
+		defer c.Close()
+		buffer, _ := c.Recv(-1)
+		received <- buffer
+		c.Send([]byte("ok"))
+	}()
+	pid := 1<<22 - 1
+	gtest.Assert(gfile.Mkdir(getCommDirPath()), nil)
+	defer gfile.Remove(getCommFilePath(pid))
+	gtest.Case(t, func() {
+		port := listen.Addr().(*net.TCPAddr).Port
+		gtest.Assert(gfile.PutContents(getCommFilePath(pid), gconv.String(port)), nil)
+		network, address, legacy, err := getCommAddressByPid(pid)
+		gtest.Assert(err, nil)
+		gtest.Assert(network, "tcp")
+		gtest.Assert(legacy, true)
+		gtest.Assert(address, listen.Addr().String())
+
+		gtest.Assert(Send(pid, []byte("data"), "group"), nil)
+		_, err = Request(pid, []byte("data"), "group")
+		gtest.AssertNE(err, nil)
+
+		select {
+		case buffer := <-received:
+			// 总长度(24bit)|发送进程PID(24bit)|接收进程PID(24bit)|分组长度(8bit)|分组名称(变长)|校验(32bit)|参数(变长)
+			gtest.Assert(gbinary.DecodeToInt(buffer[0:3]), len(buffer))
+			gtest.Assert(gbinary.DecodeToInt(buffer[3:6]), Pid())
+			gtest.Assert(gbinary.DecodeToInt(buffer[6:9]), pid)
+			gtest.Assert(string(buffer[10:15]), "group")
+			gtest.Assert(gbinary.DecodeToUint32(buffer[15:19]), gtcp.Checksum([]byte("data")))
+			gtest.Assert(string(buffer[19:]), "data")
+		case <-time.After(5 * time.Second):
+			t.Error("receiving legacy message timeout")
+		}
+
+		// Processes of current version write the address with prefix.
+		gtest.Assert(gfile.PutContents(getCommFilePath(pid), "tcp:10000"), nil)
+		network, address, legacy, err = getCommAddressByPid(pid)
+		gtest.Assert(err, nil)
+		gtest.Assert(legacy, false)
+		gtest.Assert(address, "127.0.0.1:10000")
+	})
+}
@@ -0,0 +1,89 @@
+// Copyright 2019 gf Author(https://github.com/gogf/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package gproc_test
+
+import (
+	"bytes"
+	"github.com/gogf/gf/g/os/gproc"
+	"github.com/gogf/gf/g/test/gtest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+var (
+	startReceiving sync.Once
+	receivedMsgs   = make(chan *gproc.Msg, 100)
+)
+
+// receive starts receiving messages of the current process,
+// the messages of group "send" are sent to the returned channel,
+// and the requests of group "request" are replied with upper case data.
+func receive() chan *gproc.Msg {
+	startReceiving.Do(func() {
+		ready := make(chan struct{}, 2)
+		go func() {
+			ready <- struct{}{}
+			for {
+				receivedMsgs <- gproc.Receive("send")
+			}
+		}()
+		go func() {
+			ready <- struct{}{}
+			for {
+				msg := gproc.Receive("request")
+				msg.Reply([]byte(strings.ToUpper(string(msg.Data))))
+			}
+		}()
+		<-ready
+		<-ready
+	})
+	return receivedMsgs
+}
+
+func Test_Comm_Send(t *testing.T) {
+	msgs := receive()
+	gtest.Case(t, func() {
+		gtest.Assert(gproc.Send(gproc.Pid(), []byte("hello"), "send"), nil)
+		msg := <-msgs
+		gtest.Assert(msg.Pid, gproc.Pid())
+		gtest.Assert(msg.Group, "send")
+		gtest.Assert(string(msg.Data), "hello")
+		gtest.AssertNE(msg.Reply([]byte("hello")), nil)
+
+		// Large message is sent in chunks.
+		data := bytes.Repeat([]byte("0123456789"), 100000)
+		gtest.Assert(gproc.Send(gproc.Pid(), data, "send"), nil)
+		msg = <-msgs
+		gtest.Assert(bytes.Equal(msg.Data, data), true)
+
+		err := gproc.Send(gproc.Pid(), []byte("hello"), "none")
+		gtest.AssertNE(err, nil)
+		gtest.Assert(err.Error(), "group [none] does not exist")
+	})
+}
+
+func Test_Comm_Request(t *testing.T) {
+	receive()
+	gtest.Case(t, func() {
+		wg := sync.WaitGroup{}
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				data, err := gproc.Request(gproc.Pid(), []byte("hello"), "request")
+				gtest.Assert(err, nil)
+				gtest.Assert(string(data), "HELLO")
+			}()
+		}
+		wg.Wait()
+		data := bytes.Repeat([]byte("abc"), 100000)
+		result, err := gproc.Request(gproc.Pid(), data, "request")
+		gtest.Assert(err, nil)
+		gtest.Assert(bytes.Equal(result, bytes.ToUpper(data)), true)
+	})
+}
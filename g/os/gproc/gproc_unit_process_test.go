@@ -0,0 +1,196 @@
+// Copyright 2019 gf Author(https://github.com/gogf/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package gproc_test
+
+import (
+	"bytes"
+	"github.com/gogf/gf/g/os/gfile"
+	"github.com/gogf/gf/g/os/glog"
+	"github.com/gogf/gf/g/os/gproc"
+	"github.com/gogf/gf/g/test/gtest"
+	"os/exec"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// eventRecorder records the lifecycle event types of processes.
+type eventRecorder struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (r *eventRecorder) handle(e *gproc.ProcessEvent) {
+	r.mu.Lock()
+	r.events = append(r.events, e.Type)
+	r.mu.Unlock()
+}
+
+func (r *eventRecorder) String() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return strings.Join(r.events, ",")
+}
+
+// syncBuffer is a concurrent safe writer for logger.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+// newShellProcess creates a process executing shell command <cmd>.
+func newShellProcess(cmd string) *gproc.Process {
+	path, _ := exec.LookPath("sh")
+	return gproc.NewProcess(path, []string{"-c", cmd})
+}
+
+func Test_Process_Restart(t *testing.T) {
+	gtest.Case(t, func() {
+		r := &eventRecorder{}
+		p := newShellProcess("exit 3")
+		p.SetRestartPolicy(gproc.RESTART_ON_FAILURE)
+		p.SetMaxRestarts(2)
+		p.SetRestartBackoff(10*time.Millisecond, 20*time.Millisecond)
+		p.SetEventHandler(r.handle)
+		gtest.AssertNE(p.Run(), nil)
+		gtest.Assert(p.Restarts(), 2)
+		gtest.Assert(p.ExitCode(), 3)
+		gtest.Assert(p.Running(), false)
+		gtest.Assert(r.String(), "Start,Exit,Restart,Exit,Restart,Exit,Stop")
+	})
+	gtest.Case(t, func() {
+		p := newShellProcess("exit 0")
+		p.SetRestartPolicy(gproc.RESTART_ON_FAILURE)
+		p.SetRestartBackoff(10*time.Millisecond, 20*time.Millisecond)
+		gtest.Assert(p.Run(), nil)
+		gtest.Assert(p.Restarts(), 0)
+		gtest.Assert(p.ExitCode(), 0)
+	})
+	gtest.Case(t, func() {
+		p := newShellProcess("exit 0")
+		p.SetRestartPolicy(gproc.RESTART_ALWAYS)
+		p.SetMaxRestarts(3)
+		p.SetRestartBackoff(10*time.Millisecond, 20*time.Millisecond)
+		gtest.Assert(p.Run(), nil)
+		gtest.Assert(p.Restarts(), 3)
+	})
+	gtest.Case(t, func() {
+		p := newShellProcess("exit 1")
+		gtest.AssertNE(p.Run(), nil)
+		gtest.Assert(p.Restarts(), 0)
+		gtest.Assert(p.ExitCode(), 1)
+	})
+}
+
+func Test_Process_Status(t *testing.T) {
+	gtest.Case(t, func() {
+		p := newShellProcess("sleep 0.2")
+		gtest.Assert(p.ExitCode(), -1)
+		pid, err := p.Start()
+		gtest.Assert(err, nil)
+		gtest.Assert(pid, p.Pid())
+		gtest.Assert(p.Running(), true)
+		gtest.Assert(p.Wait(), nil)
+		gtest.Assert(p.Running(), false)
+		gtest.Assert(p.ExitCode(), 0)
+		gtest.AssertGE(p.Uptime(), 200)
+		gtest.Assert(p.ExitTime().After(p.StartTime()), true)
+	})
+}
+
+func Test_Process_Output(t *testing.T) {
+	gtest.Case(t, func() {
+		buffer := &syncBuffer{}
+		logger := glog.New()
+		logger.SetWriter(buffer)
+		p := newShellProcess("echo hello; echo world >&2; printf partial")
+		p.SetOutputCategory("stdout", "stderr", logger)
+		gtest.Assert(p.Run(), nil)
+		content := buffer.String()
+		gtest.Assert(strings.Contains(content, "[INFO]"), true)
+		gtest.Assert(strings.Contains(content, "hello"), true)
+		gtest.Assert(strings.Contains(content, "[ERRO]"), true)
+		gtest.Assert(strings.Contains(content, "world"), true)
+		gtest.Assert(strings.Contains(content, "partial"), true)
+		gtest.Assert(strings.Contains(content, "Backtrace"), false)
+	})
+	// The given logger is not changed, even if it's a cloned logger.
+	gtest.Case(t, func() {
+		path := gfile.TempDir() + gfile.Separator + "gproc_output_test"
+		defer gfile.Remove(path)
+		logger := glog.New().Clone()
+		gtest.Assert(logger.SetPath(path), nil)
+		p := newShellProcess("echo hello")
+		p.SetOutputCategory("stdout", "stderr", logger)
+		gtest.Assert(logger.GetPath(), path)
+	})
+}
+
+func Test_Process_Stop(t *testing.T) {
+	gtest.Case(t, func() {
+		r := &eventRecorder{}
+		p := newShellProcess("exec sleep 10")
+		p.SetRestartPolicy(gproc.RESTART_ALWAYS)
+		p.SetEventHandler(r.handle)
+		_, err := p.Start()
+		gtest.Assert(err, nil)
+		start := time.Now()
+		gtest.Assert(p.Stop(time.Second), nil)
+		gtest.Assert(time.Since(start) < time.Second, true)
+		gtest.Assert(p.Running(), false)
+		gtest.Assert(p.Restarts(), 0)
+		gtest.Assert(r.String(), "Start,Exit,Stop")
+	})
+	// The process ignoring SIGTERM is killed after timeout.
+	gtest.Case(t, func() {
+		p := newShellProcess("trap '' TERM; sleep 3")
+		_, err := p.Start()
+		gtest.Assert(err, nil)
+		time.Sleep(100*time.Millisecond)
+		start := time.Now()
+		gtest.Assert(p.Stop(200*time.Millisecond), nil)
+		gtest.Assert(time.Since(start) >= 200*time.Millisecond, true)
+		gtest.Assert(time.Since(start) < time.Second, true)
+		gtest.Assert(p.Running(), false)
+		gtest.Assert(p.ExitCode(), -1)
+	})
+}
+
+func Test_Manager_Supervise(t *testing.T) {
+	gtest.Case(t, func() {
+		r := &eventRecorder{}
+		m := gproc.NewManager()
+		m.SetEventHandler(r.handle)
+		for i := 0; i < 3; i++ {
+			p := m.NewProcess(newShellProcess("").Path, []string{"-c", "exec sleep 10"}, nil)
+			p.SetRestartPolicy(gproc.RESTART_ALWAYS)
+			_, err := p.Start()
+			gtest.Assert(err, nil)
+		}
+		gtest.Assert(m.Size(), 3)
+		gtest.Assert(m.StopAll(time.Second), nil)
+		m.WaitAll()
+		for _, p := range m.Processes() {
+			gtest.Assert(p.Running(), false)
+		}
+		gtest.Assert(strings.Count(r.String(), "Start"), 3)
+		gtest.Assert(strings.Count(r.String(), "Stop"), 3)
+	})
+}
@@ -63,8 +63,8 @@ func (p *Pool) Add(f func()) {
     if p.count.Val() == p.limit {
 		return
     }
-	// ensure atomicity.
-	if p.limit != -1 && p.count.Add(1) > p.limit {
+	// ensure atomicity, the goroutine count should be increased even if it's not limited.
+	if count := p.count.Add(1); p.limit != -1 && count > p.limit {
 		p.count.Add(-1)
 		return
 	}
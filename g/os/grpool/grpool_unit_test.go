@@ -92,4 +92,20 @@ func Test_Limit3(t *testing.T) {
 		gtest.Assert(pool.Jobs(), 900)
 		gtest.Assert(array.Len(), 100)
 	})
+}
+
+func Test_Unlimited(t *testing.T) {
+	gtest.Case(t, func() {
+		array := garray.NewArray()
+		pool  := grpool.New()
+		// New goroutines are forked for jobs added after all goroutines exit.
+		for i := 0; i < 3; i++ {
+			pool.Add(func() {
+				array.Append(1)
+			})
+			time.Sleep(100 * time.Millisecond)
+			gtest.Assert(pool.Size(), 0)
+		}
+		gtest.Assert(array.Len(), 3)
+	})
 }
\ No newline at end of file
@@ -0,0 +1,18 @@
+package main
+
+import (
+	"fmt"
+	"github.com/gogf/gf/g"
+	"github.com/gogf/gf/g/database/gredis"
+)
+
+func main() {
+	sub, err := g.Redis().Subscribe(func(msg *gredis.Message) {
+		fmt.Println(msg.Channel, string(msg.Data))
+	}, "channel")
+	if err != nil {
+		panic(err)
+	}
+	defer sub.Close()
+	select {}
+}